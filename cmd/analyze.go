@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/user/gendocs/internal/agents"
 	"github.com/user/gendocs/internal/config"
 	"github.com/user/gendocs/internal/errors"
 	"github.com/user/gendocs/internal/handlers"
@@ -22,9 +26,18 @@ type analyzeOptions struct {
 	excludeDeps      bool
 	excludeReqFlow   bool
 	excludeAPI       bool
+	excludeSecurity  bool
+	excludeTesting   bool
+	excludeInfra     bool
 	maxWorkers       int
 	forceAnalysis    bool
 	showCacheStats   bool
+	maxTokensPerRun  int
+	maxCostPerRun    float64
+	monorepo         bool
+	verify           string
+	resume           bool
+	sectionUpdates   bool
 }
 
 func newAnalyzeCmd() *cobra.Command {
@@ -39,12 +52,34 @@ func newAnalyzeCmd() *cobra.Command {
   - Data flow through the system
   - Request/response flow
   - API endpoints and contracts
+  - Security posture and a STRIDE threat model
+  - Test suite layout, test commands and coverage gaps
+  - Build, deployment, runtime configuration and CI pipelines
 
 Results are written to .ai/docs/ directory.
 
 By default, incremental analysis is used which only re-analyzes files
 that have changed since the last run. Use --force to perform a full
-re-analysis ignoring the cache.`,
+re-analysis ignoring the cache. With --section-updates, an agent re-run
+because of a change receives its previous document and the diff of the
+changed files, and rewrites only the affected sections.
+
+With --monorepo, each module (go.mod, package.json workspaces, Cargo
+workspace members, pom.xml modules) is analyzed separately into its own
+.ai/docs/, and .ai/docs/architecture_overview.md links them together.
+
+With --max-tokens-per-run or --max-cost-per-run, each analysis gets an
+equal share of the budget and is stopped before exceeding it. The command
+then exits with code 10 (partial success).
+
+With --verify mark, the file paths and symbols each document mentions are
+checked against the repository; references that cannot be found are
+flagged inline and a grounding score is reported per document. With
+--verify repair, the agent is first asked to correct them.
+
+Each agent's conversation is checkpointed to .ai/checkpoints/ as it
+progresses. If a run is interrupted (Ctrl+C, a crash), --resume skips the
+agents that completed and continues the others where they stopped.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAnalyze(cmd, opts)
 		},
@@ -56,9 +91,18 @@ re-analysis ignoring the cache.`,
 	cmd.Flags().BoolVar(&opts.excludeDeps, "exclude-dependencies", false, "Exclude dependency analysis")
 	cmd.Flags().BoolVar(&opts.excludeReqFlow, "exclude-request-flow", false, "Exclude request flow analysis")
 	cmd.Flags().BoolVar(&opts.excludeAPI, "exclude-api-analysis", false, "Exclude API analysis")
+	cmd.Flags().BoolVar(&opts.excludeSecurity, "exclude-security-analysis", false, "Exclude security analysis")
+	cmd.Flags().BoolVar(&opts.excludeTesting, "exclude-testing-analysis", false, "Exclude testing analysis")
+	cmd.Flags().BoolVar(&opts.excludeInfra, "exclude-infrastructure-analysis", false, "Exclude infrastructure analysis")
 	cmd.Flags().IntVar(&opts.maxWorkers, "max-workers", 0, "Maximum concurrent workers (0=auto)")
 	cmd.Flags().BoolVarP(&opts.forceAnalysis, "force", "f", false, "Force full re-analysis, ignoring cache")
 	cmd.Flags().BoolVar(&opts.showCacheStats, "show-cache-stats", false, "Show LLM cache statistics after analysis")
+	cmd.Flags().IntVar(&opts.maxTokensPerRun, "max-tokens-per-run", 0, "Token budget for the run (0=unlimited)")
+	cmd.Flags().Float64Var(&opts.maxCostPerRun, "max-cost-per-run", 0, "Estimated cost budget for the run in USD (0=unlimited)")
+	cmd.Flags().BoolVar(&opts.monorepo, "monorepo", false, "Analyze each module separately and write an architecture overview")
+	cmd.Flags().StringVar(&opts.verify, "verify", "", "Verify documents against the repository: off, mark or repair")
+	cmd.Flags().BoolVar(&opts.resume, "resume", false, "Resume an interrupted analysis from its checkpoints")
+	cmd.Flags().BoolVar(&opts.sectionUpdates, "section-updates", false, "On incremental runs, regenerate only the document sections affected by the changes")
 
 	return cmd
 }
@@ -88,12 +132,39 @@ func runAnalyze(cmd *cobra.Command, opts *analyzeOptions) error {
 	if cmd.Flags().Changed("exclude-api-analysis") {
 		cliOverrides["exclude_api_analysis"] = opts.excludeAPI
 	}
+	if cmd.Flags().Changed("exclude-security-analysis") {
+		cliOverrides["exclude_security_analysis"] = opts.excludeSecurity
+	}
+	if cmd.Flags().Changed("exclude-testing-analysis") {
+		cliOverrides["exclude_testing_analysis"] = opts.excludeTesting
+	}
+	if cmd.Flags().Changed("exclude-infrastructure-analysis") {
+		cliOverrides["exclude_infrastructure_analysis"] = opts.excludeInfra
+	}
 	if cmd.Flags().Changed("max-workers") {
 		cliOverrides["max_workers"] = opts.maxWorkers
 	}
 	if cmd.Flags().Changed("force") {
 		cliOverrides["force"] = opts.forceAnalysis
 	}
+	if cmd.Flags().Changed("max-tokens-per-run") {
+		cliOverrides["max_tokens_per_run"] = opts.maxTokensPerRun
+	}
+	if cmd.Flags().Changed("max-cost-per-run") {
+		cliOverrides["max_cost_per_run"] = opts.maxCostPerRun
+	}
+	if cmd.Flags().Changed("monorepo") {
+		cliOverrides["monorepo"] = opts.monorepo
+	}
+	if cmd.Flags().Changed("verify") {
+		cliOverrides["verify"] = opts.verify
+	}
+	if cmd.Flags().Changed("resume") {
+		cliOverrides["resume"] = opts.resume
+	}
+	if cmd.Flags().Changed("section-updates") {
+		cliOverrides["section_updates"] = opts.sectionUpdates
+	}
 
 	cfg, err := config.LoadAnalyzerConfig(opts.repoPath, cliOverrides)
 	if err != nil {
@@ -124,13 +195,24 @@ func runAnalyze(cmd *cobra.Command, opts *analyzeOptions) error {
 		progress.Start()
 	}
 
-	err = handler.Handle(cmd.Context())
+	// Interrupting stops the agents gracefully so the LLM cache is saved for --resume
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := handler.Run(ctx)
 
 	if showProgress {
 		progress.Stop()
+		if result != nil {
+			progress.SetUsage(result.UsageSummary)
+		}
 		progress.PrintSummary()
 	}
 
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Analysis interrupted: run 'gendocs analyze --resume' to continue")
+	}
+
 	if err != nil {
 		if docErr, ok := err.(*errors.AIDocGenError); ok {
 			if !showProgress {
@@ -145,14 +227,44 @@ func runAnalyze(cmd *cobra.Command, opts *analyzeOptions) error {
 		logger.Info("Analysis complete")
 	}
 
+	if len(result.Grounding) > 0 {
+		displayGrounding(result.Grounding)
+	}
+
 	// Show cache statistics if requested
 	if opts.showCacheStats {
 		displayCacheStats(opts.repoPath)
 	}
 
+	// Analyses stopped by the run budget make this a partial success
+	if result.BudgetExceeded() {
+		fmt.Fprintln(os.Stderr, "Run budget exceeded: some analyses were stopped before completion")
+		_ = logger.Sync()
+		os.Exit(errors.ExitPartialSuccess.Int())
+	}
+
 	return nil
 }
 
+// displayGrounding prints the grounding score of each verified document
+func displayGrounding(grounding map[string]agents.GroundingReport) {
+	names := make([]string, 0, len(grounding))
+	for name := range grounding {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("\n🔎 Grounding Scores")
+	for _, name := range names {
+		report := grounding[name]
+		fmt.Printf("  %-30s %s\n", name, report)
+		for _, ref := range report.Unverified {
+			fmt.Printf("    - unverified: %s\n", ref)
+		}
+	}
+	fmt.Println()
+}
+
 // displayCacheStats loads and displays cache statistics from the disk cache
 func displayCacheStats(repoPath string) {
 	cachePath := filepath.Join(repoPath, llmcache.DefaultCacheFileName)
@@ -16,6 +16,7 @@ type checkOptions struct {
 	outputFormat string
 	verbose      bool
 	exitCode     bool
+	monorepo     bool
 }
 
 func newCheckCmd() *cobra.Command {
@@ -40,7 +41,11 @@ Exit codes (when --exit-code is used):
   0: No drift detected, documentation is up to date
   1: Minor drift detected
   2: Moderate drift detected  
-  3: Major drift or no previous analysis`,
+  3: Major drift or no previous analysis
+
+Repositories analyzed with 'gendocs analyze --monorepo' (or checked with
+--monorepo) are reported per module; the exit code follows the most
+drifted module.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCheck(cmd, opts)
 		},
@@ -50,6 +55,7 @@ Exit codes (when --exit-code is used):
 	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "text", "Output format (text, json)")
 	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "V", false, "Show detailed file lists")
 	cmd.Flags().BoolVar(&opts.exitCode, "exit-code", false, "Use exit code to indicate drift severity")
+	cmd.Flags().BoolVar(&opts.monorepo, "monorepo", false, "Report drift per module")
 
 	return cmd
 }
@@ -65,6 +71,9 @@ func runCheck(cmd *cobra.Command, opts *checkOptions) error {
 		"output_format": opts.outputFormat,
 		"verbose":       opts.verbose,
 	}
+	if cmd.Flags().Changed("monorepo") {
+		cliOverrides["monorepo"] = opts.monorepo
+	}
 
 	cfg, err := config.LoadCheckConfig(opts.repoPath, cliOverrides)
 	if err != nil {
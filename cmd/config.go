@@ -47,6 +47,8 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	model.RegisterSection("analysis", sections.NewAnalysisSection())
 	model.RegisterSection("retry", sections.NewRetrySection())
 	model.RegisterSection("gemini", sections.NewGeminiSection())
+	model.RegisterSection("azure", sections.NewAzureSection())
+	model.RegisterSection("bedrock", sections.NewBedrockSection())
 	model.RegisterSection("gitlab", sections.NewGitLabSection())
 	model.RegisterSection("cronjob", sections.NewCronjobSection())
 	model.RegisterSection("logging", sections.NewLoggingSection())
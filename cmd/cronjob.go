@@ -1,11 +1,12 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/spf13/cobra"
 	"github.com/user/gendocs/internal/config"
 	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/forge"
 	"github.com/user/gendocs/internal/handlers"
 	"github.com/user/gendocs/internal/logging"
 )
@@ -13,24 +14,35 @@ import (
 // cronjobCmd represents the cronjob command
 var cronjobCmd = &cobra.Command{
 	Use:   "cronjob",
-	Short: "Automated batch processing for GitLab projects",
-	Long: `Process multiple GitLab projects automatically, analyzing each
-and creating merge requests with the results.`,
+	Short: "Automated batch processing for GitLab, GitHub and Gitea projects",
+	Long: `Process multiple projects automatically, analyzing each
+and opening merge/pull requests with the results.
+
+The forge is selected with --forge (or cronjob.forge in config):
+  gitlab  GITLAB_OAUTH_TOKEN, GITLAB_API_URL (default https://gitlab.com)
+  github  GITHUB_TOKEN, GITHUB_API_URL (default https://api.github.com)
+  gitea   GITEA_TOKEN, GITEA_API_URL (required)`,
 }
 
 var (
 	cronjobMaxDays     int
 	cronjobWorkingPath string
 	cronjobGroupID     int
+	cronjobGroup       string
+	cronjobForge       string
+	cronjobConcurrency int
+	cronjobTimeout     int
+	cronjobMRPolicy    string
+	cronjobBranchName  string
 )
 
 // cronjobAnalyzeCmd represents the cronjob analyze command
 var cronjobAnalyzeCmd = &cobra.Command{
 	Use:   "analyze",
-	Short: "Analyze all applicable GitLab projects in a group",
-	Long: `Fetch all projects in a GitLab group, filter them based on
-configuration, and run analysis on each applicable project.
-Creates branches and merge requests automatically.`,
+	Short: "Analyze all applicable projects in a group or organization",
+	Long: `Fetch all projects in a GitLab group or GitHub/Gitea organization,
+filter them based on configuration, and run analysis on each applicable project.
+Creates branches and merge/pull requests automatically.`,
 	RunE: runCronjobAnalyze,
 }
 
@@ -41,42 +53,47 @@ func init() {
 	cronjobAnalyzeCmd.Flags().IntVar(&cronjobMaxDays, "max-days-since-last-commit", 14, "Skip projects with no commits in N days")
 	cronjobAnalyzeCmd.Flags().StringVar(&cronjobWorkingPath, "working-path", "./work", "Working directory for cloning repos")
 	cronjobAnalyzeCmd.Flags().IntVar(&cronjobGroupID, "group-project-id", 0, "GitLab group/project ID to analyze")
-	_ = cronjobAnalyzeCmd.MarkFlagRequired("group-project-id")
+	cronjobAnalyzeCmd.Flags().StringVar(&cronjobGroup, "group", "", "Group or organization to analyze (GitHub/Gitea organization or user, GitLab group ID)")
+	cronjobAnalyzeCmd.Flags().StringVar(&cronjobForge, "forge", "gitlab", "Forge hosting the projects: gitlab, github, gitea")
+	cronjobAnalyzeCmd.Flags().IntVar(&cronjobConcurrency, "concurrency", 1, "Number of projects to process in parallel")
+	cronjobAnalyzeCmd.Flags().IntVar(&cronjobTimeout, "project-timeout", 60, "Per-project timeout in minutes")
+	cronjobAnalyzeCmd.Flags().StringVar(&cronjobMRPolicy, "mr-policy", "create", "Handling of existing docs MRs: create (new dated MR, skip if one exists) or update (push onto the open MR)")
+	cronjobAnalyzeCmd.Flags().StringVar(&cronjobBranchName, "branch-name", "ai-analyzer", "Stable branch name used by --mr-policy update")
 }
 
 func runCronjobAnalyze(cmd *cobra.Command, args []string) error {
-	// Validate required GitLab configuration
-	gitLabToken := os.Getenv("GITLAB_OAUTH_TOKEN")
-	if gitLabToken == "" {
-		return errors.NewMissingEnvVarError("GITLAB_OAUTH_TOKEN", "GitLab API authentication token")
+	// Only explicitly set flags override config file values
+	flagKeys := map[string]string{
+		"max-days-since-last-commit": "max_days_since_last_commit",
+		"working-path":               "working_path",
+		"group-project-id":           "group_project_id",
+		"group":                      "group",
+		"forge":                      "forge",
+		"concurrency":                "concurrency",
+		"project-timeout":            "project_timeout",
+		"mr-policy":                  "mr_policy",
+		"branch-name":                "branch_name",
 	}
-
-	gitLabURL := os.Getenv("GITLAB_API_URL")
-	if gitLabURL == "" {
-		gitLabURL = "https://gitlab.com"
+	cronjobOverrides := map[string]interface{}{}
+	for flagName, key := range flagKeys {
+		if !cmd.Flags().Changed(flagName) {
+			continue
+		}
+		flag := cmd.Flags().Lookup(flagName)
+		cronjobOverrides[key] = flag.Value.String()
 	}
 
-	// Build configurations
-	cronjobCfg := config.CronjobConfig{
-		MaxDaysSinceLastCommit: cronjobMaxDays,
-		WorkingPath:            cronjobWorkingPath,
-		GroupProjectID:         cronjobGroupID,
+	cronjobCfg, err := config.LoadCronjobConfig(".", cronjobOverrides)
+	if err != nil {
+		return err
 	}
-
-	gitLabCfg := config.GitLabConfig{
-		APIURL:       gitLabURL,
-		OAuthToken:   gitLabToken,
-		UserName:     os.Getenv("GITLAB_USER_NAME"),
-		UserUsername: os.Getenv("GITLAB_USER_USERNAME"),
-		UserEmail:    os.Getenv("GITLAB_USER_EMAIL"),
+	if cronjobCfg.GetGroup() == "" {
+		return errors.NewValidationError("no group to analyze: set --group or --group-project-id")
 	}
 
-	// Set defaults for GitLab user info
-	if gitLabCfg.UserName == "" {
-		gitLabCfg.UserName = "AI Analyzer"
-	}
-	if gitLabCfg.UserUsername == "" {
-		gitLabCfg.UserUsername = "agent_doc"
+	forgeSettings, err := loadForgeSettings(".", cronjobCfg.GetForge())
+	if err != nil {
+		return err
 	}
 
 	cliOverrides := map[string]interface{}{
@@ -90,24 +107,114 @@ func runCronjobAnalyze(cmd *cobra.Command, args []string) error {
 	analyzerCfg := *analyzerCfgPtr
 
 	// Initialize logger (verbose=true to enable console output for cronjob)
-	logger, err := InitLogger(cronjobWorkingPath, debugFlag, true)
+	logger, err := InitLogger(cronjobCfg.WorkingPath, debugFlag, true)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = logger.Sync() }()
 
 	logger.Info("Starting cronjob analysis",
-		logging.Int("group_id", cronjobGroupID),
-		logging.Int("max_days", cronjobMaxDays),
+		logging.String("forge", cronjobCfg.GetForge()),
+		logging.String("group", cronjobCfg.GetGroup()),
+		logging.Int("max_days", cronjobCfg.MaxDaysSinceLastCommit),
+		logging.Int("concurrency", cronjobCfg.GetConcurrency()),
+		logging.String("mr_policy", cronjobCfg.GetMRPolicy()),
 	)
 
-	// Create and run CronjobHandler
-	handler := handlers.NewCronjobHandler(cronjobCfg, gitLabCfg, analyzerCfg, logger)
+	f, err := forge.New(cronjobCfg.GetForge(), forgeSettings, logger)
+	if err != nil {
+		return err
+	}
 
-	if err := handler.Handle(cmd.Context()); err != nil {
+	// Create and run CronjobHandler
+	handler := handlers.NewCronjobHandler(*cronjobCfg, f, analyzerCfg, logger)
+
+	result, err := handler.Handle(cmd.Context())
+	if result != nil {
+		for _, project := range result.Projects {
+			if project.Error != nil {
+				continue
+			}
+			if project.Unchanged && project.MRURL == "" {
+				logger.Info(fmt.Sprintf("%s: documentation unchanged", project.Name))
+				continue
+			}
+			if project.Unchanged {
+				logger.Info(fmt.Sprintf("%s: documentation unchanged, MR %s", project.Name, project.MRURL))
+				continue
+			}
+			agentsRun := 0
+			if project.Analysis != nil {
+				agentsRun = len(project.Analysis.Successful)
+			}
+			logger.Info(fmt.Sprintf("%s: %d analyses, MR %s", project.Name, agentsRun, project.MRURL))
+		}
+	}
+	if err != nil {
 		return HandleCommandError(err, nil, false)
 	}
 
 	logger.Info("Cronjob analysis complete")
 	return nil
 }
+
+// loadForgeSettings reads the credentials of the selected forge from the
+// environment, falling back to the forge section of the config files
+func loadForgeSettings(repoPath, kind string) (forge.Settings, error) {
+	var settings forge.Settings
+
+	switch kind {
+	case forge.KindGitLab:
+		cfg, err := config.LoadGitLabConfig(repoPath)
+		if err != nil {
+			return settings, err
+		}
+		token := config.GetEnvVarOrDefault("GITLAB_OAUTH_TOKEN", cfg.OAuthToken)
+		if token == "" {
+			return settings, errors.NewMissingEnvVarError("GITLAB_OAUTH_TOKEN", "GitLab API authentication token (or gitlab.oauth_token in config)")
+		}
+		settings.GitLab = config.GitLabConfig{
+			APIURL:       config.GetEnvVarOrDefault("GITLAB_API_URL", cfg.APIURL),
+			OAuthToken:   token,
+			UserName:     config.GetEnvVarOrDefault("GITLAB_USER_NAME", cfg.UserName),
+			UserUsername: config.GetEnvVarOrDefault("GITLAB_USER_USERNAME", cfg.UserUsername),
+			UserEmail:    config.GetEnvVarOrDefault("GITLAB_USER_EMAIL", cfg.UserEmail),
+		}
+	case forge.KindGitHub:
+		cfg, err := config.LoadForgeConfig(repoPath, "github")
+		if err != nil {
+			return settings, err
+		}
+		token := config.GetEnvVarOrDefault("GITHUB_TOKEN", cfg.Token)
+		if token == "" {
+			return settings, errors.NewMissingEnvVarError("GITHUB_TOKEN", "GitHub API token (or github.token in config)")
+		}
+		settings.GitHub = config.ForgeConfig{
+			APIURL:    config.GetEnvVarOrDefault("GITHUB_API_URL", cfg.APIURL),
+			Token:     token,
+			UserName:  config.GetEnvVarOrDefault("GITHUB_USER_NAME", cfg.UserName),
+			UserEmail: config.GetEnvVarOrDefault("GITHUB_USER_EMAIL", cfg.UserEmail),
+		}
+	case forge.KindGitea:
+		cfg, err := config.LoadForgeConfig(repoPath, "gitea")
+		if err != nil {
+			return settings, err
+		}
+		token := config.GetEnvVarOrDefault("GITEA_TOKEN", cfg.Token)
+		if token == "" {
+			return settings, errors.NewMissingEnvVarError("GITEA_TOKEN", "Gitea API token (or gitea.token in config)")
+		}
+		apiURL := config.GetEnvVarOrDefault("GITEA_API_URL", cfg.APIURL)
+		if apiURL == "" {
+			return settings, errors.NewMissingEnvVarError("GITEA_API_URL", "Gitea instance URL (or gitea.api_url in config)")
+		}
+		settings.Gitea = config.ForgeConfig{
+			APIURL:    apiURL,
+			Token:     token,
+			UserName:  config.GetEnvVarOrDefault("GITEA_USER_NAME", cfg.UserName),
+			UserEmail: config.GetEnvVarOrDefault("GITEA_USER_EMAIL", cfg.UserEmail),
+		}
+	}
+
+	return settings, nil
+}
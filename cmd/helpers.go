@@ -178,8 +178,8 @@ func getSuggestionForExitCode(code errors.ExitCode) string {
 		return "Suggestion: Check your repository structure and try again. Run with --debug for details"
 	case errors.ExitIOError:
 		return "Suggestion: Check file permissions and disk space. Ensure the target directory exists"
-	case errors.ExitGitLabError:
-		return "Suggestion: Verify your GITLAB_OAUTH_TOKEN and GitLab API URL are correct"
+	case errors.ExitForgeError:
+		return "Suggestion: Verify the token and API URL of your forge (GITLAB_OAUTH_TOKEN, GITHUB_TOKEN or GITEA_TOKEN) are correct"
 	default:
 		return "Suggestion: Run with --debug for more details or check logs in .ai/logs/"
 	}
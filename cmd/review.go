@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/forge"
+	"github.com/user/gendocs/internal/handlers"
+)
+
+type reviewOptions struct {
+	repoPath     string
+	outputFormat string
+	forge        string
+	project      string
+	mr           int
+	dryRun       bool
+	noPatch      bool
+}
+
+func newReviewCmd() *cobra.Command {
+	opts := &reviewOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Comment documentation drift on a merge/pull request",
+		Long: `Check which .ai/docs sections a merge/pull request makes stale and post
+a summary comment on it.
+
+The files changed by the merge request are compared against the analysis
+cache committed on its target branch. Only the agents whose file patterns
+match the changes are re-run, on the local checkout in --repo-path (which
+should be the merge request's source branch), and the regenerated sections
+are attached to the comment as suggested patches.
+
+In CI, --mr and --project default to CI_MERGE_REQUEST_IID and
+CI_PROJECT_ID (GitLab) or GITHUB_REPOSITORY (GitHub, Gitea).
+Forge credentials are read from the same variables as the cronjob command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReview(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.repoPath, "repo-path", ".", "Path to the checked-out merge request")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output", "o", "text", "Output format (text, json)")
+	cmd.Flags().StringVar(&opts.forge, "forge", "gitlab", "Forge hosting the project: gitlab, github, gitea")
+	cmd.Flags().StringVar(&opts.project, "project", "", "Project ID or full path (owner/repo)")
+	cmd.Flags().IntVar(&opts.mr, "mr", 0, "Merge request IID or pull request number")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the comment instead of posting it")
+	cmd.Flags().BoolVar(&opts.noPatch, "no-patch", false, "List stale sections without regenerating them")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newReviewCmd())
+}
+
+func runReview(cmd *cobra.Command, opts *reviewOptions) error {
+	cliOverrides := map[string]interface{}{
+		"repo_path":     opts.repoPath,
+		"debug":         debugFlag,
+		"output_format": opts.outputFormat,
+	}
+	if cmd.Flags().Changed("forge") {
+		cliOverrides["forge"] = opts.forge
+	}
+	if cmd.Flags().Changed("dry-run") {
+		cliOverrides["dry_run"] = opts.dryRun
+	}
+	if cmd.Flags().Changed("no-patch") {
+		cliOverrides["no_patch"] = opts.noPatch
+	}
+
+	// CI variables fill in the merge request when the flags are not set
+	if cmd.Flags().Changed("mr") {
+		cliOverrides["mr"] = opts.mr
+	} else if iid := os.Getenv("CI_MERGE_REQUEST_IID"); iid != "" {
+		cliOverrides["mr"] = iid
+	}
+	if cmd.Flags().Changed("project") {
+		cliOverrides["project"] = opts.project
+	} else if project := config.GetEnvVarOrDefault("CI_PROJECT_ID", os.Getenv("GITHUB_REPOSITORY")); project != "" {
+		cliOverrides["project"] = project
+	}
+
+	cfg, err := config.LoadReviewConfig(opts.repoPath, cliOverrides)
+	if err != nil {
+		return err
+	}
+
+	forgeSettings, err := loadForgeSettings(opts.repoPath, cfg.Forge)
+	if err != nil {
+		return err
+	}
+
+	// The analyzer (and its LLM credentials) is only needed to suggest patches
+	var analyzerCfg config.AnalyzerConfig
+	if !cfg.NoPatch {
+		loaded, err := config.LoadAnalyzerConfig(cfg.RepoPath, map[string]interface{}{
+			"repo_path": cfg.RepoPath,
+			"debug":     debugFlag,
+		})
+		if err != nil {
+			return err
+		}
+		analyzerCfg = *loaded
+	}
+
+	logger, err := InitLogger(cfg.RepoPath, debugFlag, verboseFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logger.Sync() }()
+
+	f, err := forge.New(cfg.Forge, forgeSettings, logger)
+	if err != nil {
+		return err
+	}
+
+	handler := handlers.NewReviewHandler(*cfg, f, analyzerCfg, logger)
+	result, err := handler.Handle(cmd.Context())
+	if err != nil {
+		if docErr, ok := err.(*errors.AIDocGenError); ok {
+			fmt.Fprintf(os.Stderr, "%s\n", docErr.GetUserMessage())
+			return docErr
+		}
+		return err
+	}
+
+	switch cfg.OutputFormat {
+	case "json":
+		output, err := handler.FormatJSONReport(result)
+		if err != nil {
+			return err
+		}
+		fmt.Println(output)
+	default:
+		fmt.Print(result.Comment)
+		if result.Posted {
+			fmt.Printf("\nComment posted on %s\n", result.WebURL)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/user/gendocs/internal/tokenizer"
+)
+
+// tokenizerFetchCmd represents the tokenizer-fetch command
+var tokenizerFetchCmd = &cobra.Command{
+	Use:   "tokenizer-fetch",
+	Short: "Download the tokenizer vocabularies used to count tokens",
+	Long: `Download the cl100k_base and o200k_base BPE vocabularies published by OpenAI.
+
+No vocabulary ships with gendocs. With them, prompts for OpenAI models are
+budgeted with exact token counts and Claude counts are estimated more closely;
+without them, counts are estimated. The files are saved to
+$GENDOCS_TOKENIZER_DIR, or gendocs/tokenizers in the user cache directory.`,
+	RunE: runTokenizerFetch,
+}
+
+func init() {
+	rootCmd.AddCommand(tokenizerFetchCmd)
+}
+
+func runTokenizerFetch(cmd *cobra.Command, args []string) error {
+	dir := tokenizer.Dir()
+	if dir == "" {
+		return fmt.Errorf("no user cache directory: set %s", tokenizer.TokenizerDirEnv)
+	}
+
+	for _, name := range tokenizer.Encodings {
+		path, err := tokenizer.Fetch(cmd.Context(), name, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Saved %s\n", path)
+	}
+	return nil
+}
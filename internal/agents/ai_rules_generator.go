@@ -3,7 +3,6 @@ package agents
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/user/gendocs/internal/config"
@@ -30,27 +29,12 @@ func NewAIRulesGeneratorAgent(cfg config.AIRulesConfig, promptManager *prompts.M
 
 // Run generates AI rules files
 func (aa *AIRulesGeneratorAgent) Run(ctx context.Context) error {
-	// Pre-load all analysis documents
-	analysisFiles := []string{
-		"structure_analysis.md",
-		"dependency_analysis.md",
-		"data_flow_analysis.md",
-		"request_flow_analysis.md",
-		"api_analysis.md",
-	}
-
-	analysisContent := make(map[string]string)
-	docsDir := filepath.Join(aa.config.RepoPath, ".ai/docs")
-
-	for _, filename := range analysisFiles {
-		filePath := filepath.Join(docsDir, filename)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			aa.logger.Warn(fmt.Sprintf("Could not read %s: %v", filename, err))
-			continue
-		}
-		analysisContent[filename] = string(content)
+	// Pre-load the analysis documents of all registry agents
+	registry, err := LoadRegistry(aa.config.RepoPath)
+	if err != nil {
+		return err
 	}
+	analysisContent, customAnalyses := readAnalysisDocuments(registry, filepath.Join(aa.config.RepoPath, ".ai/docs"), aa.logger)
 
 	// Setup LLM response caches
 	memoryCache, diskCache, cacheCleanup, err := setupCaches(aa.config.LLM, aa.logger)
@@ -74,6 +58,7 @@ func (aa *AIRulesGeneratorAgent) Run(ctx context.Context) error {
 	promptData := map[string]interface{}{
 		"RepoPath":        aa.config.RepoPath,
 		"AnalysisContent": analysisContent,
+		"CustomAnalyses":  customAnalyses,
 	}
 	userPrompt, err := aa.promptManager.Render("ai_rules_user", promptData)
 	if err != nil {
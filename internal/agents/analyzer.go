@@ -3,7 +3,12 @@ package agents
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/user/gendocs/internal/cache"
 	"github.com/user/gendocs/internal/config"
@@ -31,6 +36,19 @@ type AnalyzerAgent struct {
 
 	progress     ProgressReporter
 	cacheCleanup func() // Cleanup function for LLM cache
+
+	usageMu     sync.Mutex
+	usage       map[string]llm.TokenUsage // Token usage per analysis name
+	grounding   map[string]GroundingReport
+	filesRead   map[string][]string // Repository files each agent read, per agent name
+	usageLedger *llm.UsageLedger
+	prices      llm.PriceTable
+	budget      *runBudget   // nil when no budget is configured
+	budgetTotal *budgetTotal // Shared with the other analyses of a monorepo; nil for a run of its own
+
+	checkpoints *CheckpointStore // nil outside of Run
+
+	updates map[string]*sectionUpdate // Agents updating sections of their previous document
 }
 
 // NewAnalyzerAgent creates a new analyzer agent
@@ -47,6 +65,9 @@ func NewAnalyzerAgent(cfg config.AnalyzerConfig, promptManager *prompts.Manager,
 
 	// Create LLM factory with cache support
 	factory := llm.NewFactory(retryClient, memoryCache, diskCache, cfg.LLM.Cache.IsEnabled(), cfg.LLM.Cache.GetTTL())
+	prices := llm.NewPriceTable(cfg.LLM.Pricing)
+	usageLedger := llm.NewUsageLedger(prices)
+	factory.SetUsageLedger(usageLedger)
 
 	return &AnalyzerAgent{
 		config:        cfg,
@@ -55,6 +76,11 @@ func NewAnalyzerAgent(cfg config.AnalyzerConfig, promptManager *prompts.Manager,
 		logger:        logger,
 		workerPool:    worker_pool.NewWorkerPool(cfg.MaxWorkers),
 		cacheCleanup:  cacheCleanup,
+		usage:         make(map[string]llm.TokenUsage),
+		grounding:     make(map[string]GroundingReport),
+		filesRead:     make(map[string][]string),
+		usageLedger:   usageLedger,
+		prices:        prices,
 	}
 }
 
@@ -72,6 +98,14 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		logging.Int("max_workers", aa.config.MaxWorkers),
 	)
 
+	registry, err := LoadRegistry(aa.config.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	if custom := registry.Custom(); len(custom) > 0 {
+		aa.logger.Info(fmt.Sprintf("Loaded %d custom agent(s) from project config", len(custom)))
+	}
+
 	// Load cache and detect changes (unless force mode)
 	var analysisCache *cache.AnalysisCache
 	var changeReport *cache.ChangeReport
@@ -86,7 +120,7 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 	}
 
 	// Always scan files for cache update (with cache for selective hashing and metrics tracking)
-	currentFiles, scanErr = cache.ScanFiles(aa.config.RepoPath, nil, analysisCache, &scanMetrics, aa.config.GetMaxHashWorkers())
+	currentFiles, scanErr = cache.ScanFiles(aa.config.RepoPath, aa.config.ExcludePaths, analysisCache, &scanMetrics, aa.config.GetMaxHashWorkers())
 	if scanErr != nil {
 		aa.logger.Warn(fmt.Sprintf("Failed to scan files: %v", scanErr))
 	}
@@ -105,15 +139,17 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 
 	if !aa.config.Force && scanErr == nil {
 		// Detect changes
-		changeReport = analysisCache.DetectChanges(aa.config.RepoPath, currentFiles)
+		changeReport = analysisCache.DetectChangesForAgents(aa.config.RepoPath, currentFiles, registry.FilePatterns())
 
 		if !changeReport.HasChanges {
 			aa.logger.Info("No changes detected since last analysis",
 				logging.String("last_analysis", analysisCache.LastAnalysis.Format("2006-01-02 15:04:05")),
 			)
 			return &AnalysisResult{
-				Successful: []string{"No changes - using cached results"},
+				Successful: []string{},
 				Failed:     []FailedAnalysis{},
+				Skipped:    analysisNames(changeReport.AgentsToSkip),
+				Unchanged:  true,
 			}, nil
 		}
 
@@ -132,13 +168,25 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		aa.logger.Info("Force mode enabled - running full analysis")
 	}
 
+	// A fresh run discards the checkpoints of an interrupted one; a resumed run
+	// skips the agents that completed and continues the others' conversations
+	aa.checkpoints = NewCheckpointStore(aa.config.RepoPath)
+	completed := make(map[string][]string)
+	if aa.config.Resume {
+		completed = aa.checkpoints.Completed()
+		aa.logger.Info(fmt.Sprintf("Resuming interrupted analysis (%d agent(s) already completed)", len(completed)))
+	} else if err := aa.checkpoints.Clear(); err != nil {
+		aa.logger.Warn(fmt.Sprintf("Failed to clear checkpoints: %v", err))
+	}
+	var resumed []string
+
 	// Use the existing factory
 	factory := aa.llmFactory
 
 	// Build task list based on configuration and change report
 	var tasks []worker_pool.Task
-	var outputPaths []string
 	var agentNames []string
+	var specs []AgentSpec
 
 	docsDir := filepath.Join(aa.config.RepoPath, ".ai", "docs")
 
@@ -155,96 +203,105 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		return false
 	}
 
-	agentDisplayNames := map[string]string{
-		"structure_analyzer":    "Structure Analysis",
-		"dependency_analyzer":   "Dependency Analysis",
-		"data_flow_analyzer":    "Data Flow Analysis",
-		"request_flow_analyzer": "Request Flow Analysis",
-		"api_analyzer":          "API Analysis",
+	// Built-in agents can be excluded from the command line
+	excluded := map[string]bool{
+		"structure_analyzer":      aa.config.ExcludeStructure,
+		"dependency_analyzer":     aa.config.ExcludeDeps,
+		"data_flow_analyzer":      aa.config.ExcludeDataFlow,
+		"request_flow_analyzer":   aa.config.ExcludeReqFlow,
+		"api_analyzer":            aa.config.ExcludeAPI,
+		"security_analyzer":       aa.config.ExcludeSecurity,
+		"testing_analyzer":        aa.config.ExcludeTesting,
+		"infrastructure_analyzer": aa.config.ExcludeInfra,
 	}
 
-	if !aa.config.ExcludeStructure && shouldRunAgent("structure_analyzer") {
-		task, outputPath := aa.createTaskWithProgress(ctx, factory, "structure_analyzer", CreateStructureAnalyzer,
-			filepath.Join(docsDir, "structure_analysis.md"))
-		tasks = append(tasks, task)
-		outputPaths = append(outputPaths, outputPath)
-		agentNames = append(agentNames, "structure_analyzer")
-		if aa.progress != nil {
-			aa.progress.AddTask("structure_analyzer", agentDisplayNames["structure_analyzer"], "Analyzing code structure")
+	for _, spec := range registry.Agents() {
+		if excluded[spec.Name] || !shouldRunAgent(spec.Name) {
+			continue
 		}
-	}
-
-	if !aa.config.ExcludeDeps && shouldRunAgent("dependency_analyzer") {
-		task, outputPath := aa.createTaskWithProgress(ctx, factory, "dependency_analyzer", CreateDependencyAnalyzer,
-			filepath.Join(docsDir, "dependency_analysis.md"))
-		tasks = append(tasks, task)
-		outputPaths = append(outputPaths, outputPath)
-		agentNames = append(agentNames, "dependency_analyzer")
-		if aa.progress != nil {
-			aa.progress.AddTask("dependency_analyzer", agentDisplayNames["dependency_analyzer"], "Analyzing dependencies")
+		if len(aa.config.OnlyAgents) > 0 && !slices.Contains(aa.config.OnlyAgents, spec.Name) {
+			continue
 		}
-	}
-
-	if !aa.config.ExcludeDataFlow && shouldRunAgent("data_flow_analyzer") {
-		task, outputPath := aa.createTaskWithProgress(ctx, factory, "data_flow_analyzer", CreateDataFlowAnalyzer,
-			filepath.Join(docsDir, "data_flow_analysis.md"))
-		tasks = append(tasks, task)
-		outputPaths = append(outputPaths, outputPath)
-		agentNames = append(agentNames, "data_flow_analyzer")
-		if aa.progress != nil {
-			aa.progress.AddTask("data_flow_analyzer", agentDisplayNames["data_flow_analyzer"], "Analyzing data flow")
+		if files, done := completed[spec.Name]; done && outputExists(filepath.Join(docsDir, spec.OutputFile)) {
+			resumed = append(resumed, spec.Name)
+			aa.filesRead[spec.Name] = files
+			if aa.progress != nil {
+				aa.progress.AddTask(spec.Name, spec.DisplayName, "Completed before the interruption")
+				aa.progress.CompleteTask(spec.Name)
+			}
+			continue
 		}
-	}
-
-	if !aa.config.ExcludeReqFlow && shouldRunAgent("request_flow_analyzer") {
-		task, outputPath := aa.createTaskWithProgress(ctx, factory, "request_flow_analyzer", CreateRequestFlowAnalyzer,
-			filepath.Join(docsDir, "request_flow_analysis.md"))
-		tasks = append(tasks, task)
-		outputPaths = append(outputPaths, outputPath)
-		agentNames = append(agentNames, "request_flow_analyzer")
-		if aa.progress != nil {
-			aa.progress.AddTask("request_flow_analyzer", agentDisplayNames["request_flow_analyzer"], "Analyzing request flow")
+		if aa.config.SectionUpdates && !aa.config.Force && changeReport != nil && !changeReport.IsFirstRun {
+			if update := aa.sectionUpdateFor(spec, analysisCache, changeReport, docsDir); update != nil {
+				if aa.updates == nil {
+					aa.updates = make(map[string]*sectionUpdate)
+				}
+				aa.updates[spec.Name] = update
+			}
 		}
-	}
-
-	if !aa.config.ExcludeAPI && shouldRunAgent("api_analyzer") {
-		task, outputPath := aa.createTaskWithProgress(ctx, factory, "api_analyzer", CreateAPIAnalyzer,
-			filepath.Join(docsDir, "api_analysis.md"))
+		var upstream func() []AnalysisDocument
+		if len(spec.DependsOn) > 0 {
+			upstream = func() []AnalysisDocument { return upstreamDocuments(registry, spec, docsDir) }
+		}
+		task, _ := aa.createTaskWithProgress(ctx, factory, spec.Name, NewAgentCreator(spec),
+			filepath.Join(docsDir, spec.OutputFile), upstream)
 		tasks = append(tasks, task)
-		outputPaths = append(outputPaths, outputPath)
-		agentNames = append(agentNames, "api_analyzer")
+		agentNames = append(agentNames, spec.Name)
+		specs = append(specs, spec)
 		if aa.progress != nil {
-			aa.progress.AddTask("api_analyzer", agentDisplayNames["api_analyzer"], "Analyzing APIs")
+			aa.progress.AddTask(spec.Name, spec.DisplayName, spec.Description)
 		}
 	}
 
 	if changeReport != nil && aa.progress != nil {
 		for _, skipped := range changeReport.AgentsToSkip {
-			if displayName, ok := agentDisplayNames[skipped]; ok {
-				aa.progress.AddTask(skipped, displayName, "")
+			if spec, ok := registry.Get(skipped); ok {
+				aa.progress.AddTask(skipped, spec.DisplayName, "")
 				aa.progress.SkipTask(skipped)
 			}
 		}
 	}
 
-	if len(tasks) == 0 {
+	if len(tasks) == 0 && len(resumed) == 0 {
 		if changeReport != nil && len(changeReport.AgentsToSkip) > 0 {
 			aa.logger.Info("All required agents already up-to-date")
 			return &AnalysisResult{
 				Successful: changeReport.AgentsToSkip,
 				Failed:     []FailedAnalysis{},
+				Skipped:    analysisNames(changeReport.AgentsToSkip),
 			}, nil
 		}
 		return nil, fmt.Errorf("no analysis tasks to run (all agents excluded)")
 	}
 
+	if aa.config.HasBudget() && len(tasks) > 0 {
+		total := aa.budgetTotal
+		if total == nil {
+			total = newBudgetTotal(aa.config)
+		}
+		aa.budget = total.share(aa.prices, len(tasks))
+		aa.logger.Info("Run budget enabled",
+			logging.Int("max_tokens_per_agent", aa.budget.maxTokens),
+			logging.String("max_cost_per_agent", fmt.Sprintf("%.4f", aa.budget.maxCost)),
+		)
+		if _, priced := aa.prices.Lookup(aa.config.LLM.Model); aa.config.MaxCostPerRun > 0 && !priced {
+			aa.logger.Warn(fmt.Sprintf("No price configured for model %s: max_cost_per_run cannot be enforced", aa.config.LLM.Model))
+		}
+	}
+
 	aa.logger.Info(fmt.Sprintf("Running %d analysis tasks concurrently", len(tasks)))
 
-	// Execute all tasks concurrently
-	results := aa.workerPool.Run(ctx, tasks)
+	// Execute the tasks as a DAG: agents start once the agents they depend on
+	// have finished, independent agents run in parallel
+	results := aa.workerPool.RunDAG(ctx, tasks, taskDependencies(specs))
 
 	// Process results
-	analysisResult := aa.processResults(outputPaths, results)
+	analysisResult := aa.processResults(agentNames, results)
+	if changeReport != nil {
+		analysisResult.Skipped = analysisNames(changeReport.AgentsToSkip)
+	}
+	analysisResult.Successful = append(analysisResult.Successful, analysisNames(resumed)...)
+	aa.recordUsageSummary(analysisResult)
 
 	// Update cache with results
 	if analysisCache != nil && len(currentFiles) > 0 {
@@ -252,6 +309,9 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		for i, name := range agentNames {
 			agentResults[name] = results[i].Error == nil
 		}
+		for _, name := range resumed {
+			agentResults[name] = true
+		}
 		// Also mark skipped agents as successful (they were already cached)
 		if changeReport != nil {
 			for _, skipped := range changeReport.AgentsToSkip {
@@ -260,7 +320,7 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		}
 		// In force mode, mark all agents as successful
 		if aa.config.Force {
-			for _, name := range []string{"structure_analyzer", "dependency_analyzer", "data_flow_analyzer", "request_flow_analyzer", "api_analyzer"} {
+			for _, name := range registry.Names() {
 				if _, exists := agentResults[name]; !exists {
 					agentResults[name] = true
 				}
@@ -268,6 +328,13 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		}
 
 		analysisCache.UpdateAfterAnalysis(aa.config.RepoPath, currentFiles, agentResults)
+		for name, files := range aa.filesRead {
+			// A section update leaves the rest of the document based on earlier reads
+			if aa.updates[name] != nil {
+				files = mergeFileLists(analysisCache.Agents[name].FilesAnalyzed, files)
+			}
+			analysisCache.SetFilesAnalyzed(name, files)
+		}
 		if err := analysisCache.Save(aa.config.RepoPath); err != nil {
 			aa.logger.Warn(fmt.Sprintf("Failed to save cache: %v", err))
 		} else {
@@ -275,10 +342,19 @@ func (aa *AnalyzerAgent) Run(ctx context.Context) (*AnalysisResult, error) {
 		}
 	}
 
+	// Checkpoints are kept until every agent has completed, for --resume
+	if len(analysisResult.Failed) == 0 {
+		if err := aa.checkpoints.Clear(); err != nil {
+			aa.logger.Warn(fmt.Sprintf("Failed to clear checkpoints: %v", err))
+		}
+	}
+
 	return analysisResult, nil
 }
 
-func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *llm.Factory, name string, creator AgentCreator, outputPath string) (worker_pool.Task, string) {
+// createTaskWithProgress builds the task running one agent. upstream, when set, is
+// called once the agent's dependencies have finished and returns their documents.
+func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *llm.Factory, name string, creator AgentCreator, outputPath string, upstream func() []AnalysisDocument) (worker_pool.Task, string) {
 	task := func(ctx context.Context) (interface{}, error) {
 		if aa.progress != nil {
 			aa.progress.StartTask(name)
@@ -286,8 +362,15 @@ func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *ll
 
 		aa.logger.Info(fmt.Sprintf("Creating %s", name))
 
+		// Agents started after the budget was hit fall back to the cheaper model
+		llmCfg := aa.config.LLM
+		if aa.budget != nil && aa.budget.Exceeded() && aa.config.BudgetFallbackModel != "" {
+			llmCfg.Model = aa.config.BudgetFallbackModel
+			aa.logger.Warn(fmt.Sprintf("Budget exceeded, running %s with fallback model %s", name, llmCfg.Model))
+		}
+
 		// Create agent
-		agent, err := creator(aa.config.LLM, aa.config.RepoPath, factory, aa.promptManager, aa.logger)
+		agent, err := creator(llmCfg, aa.config.RepoPath, factory, aa.promptManager, aa.logger)
 		if err != nil {
 			if aa.progress != nil {
 				aa.progress.FailTask(name, err)
@@ -295,6 +378,26 @@ func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *ll
 			return nil, fmt.Errorf("failed to create %s: %w", name, err)
 		}
 
+		agent.update = aa.updates[name]
+		if upstream != nil {
+			agent.upstream = upstream()
+			aa.logger.Debug(fmt.Sprintf("%s received %d upstream document(s)", name, len(agent.upstream)))
+		}
+
+		if aa.budget != nil {
+			var cancel context.CancelCauseFunc
+			ctx, cancel = context.WithCancelCause(ctx)
+			defer cancel(nil)
+			agent.llmClient = newBudgetedClient(agent.llmClient, aa.budget, name, llmCfg.Model, cancel)
+		}
+
+		if aa.checkpoints != nil {
+			agent.EnableCheckpoints(aa.checkpoints, name)
+		}
+
+		// Tokens spent count even when the agent fails
+		defer func() { aa.recordUsage(name, agent.Usage()) }()
+
 		// Run agent
 		output, err := agent.Run(ctx)
 		if err != nil {
@@ -304,6 +407,10 @@ func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *ll
 			return nil, fmt.Errorf("%s failed: %w", name, err)
 		}
 
+		if aa.config.VerificationEnabled() {
+			output = aa.verifyOutput(ctx, agent, name, output)
+		}
+
 		// Save output
 		if err := agent.SaveOutput(output, outputPath); err != nil {
 			if aa.progress != nil {
@@ -311,6 +418,15 @@ func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *ll
 			}
 			return nil, fmt.Errorf("failed to save %s output: %w", name, err)
 		}
+		filesRead := agent.FilesAnalyzed()
+		aa.usageMu.Lock()
+		aa.filesRead[name] = filesRead
+		aa.usageMu.Unlock()
+		if aa.checkpoints != nil {
+			if err := aa.checkpoints.MarkCompleted(name, filesRead); err != nil {
+				aa.logger.Warn(fmt.Sprintf("Failed to checkpoint %s: %v", name, err))
+			}
+		}
 
 		if aa.progress != nil {
 			aa.progress.CompleteTask(name)
@@ -323,17 +439,88 @@ func (aa *AnalyzerAgent) createTaskWithProgress(ctx context.Context, factory *ll
 	return task, outputPath
 }
 
+// sectionUpdateFor prepares the update of an agent's previous document with
+// the changes of the files it cares about. It returns nil when the document
+// must be written from scratch: no previous document, no matching change (the
+// last run failed) or a change too large to describe as a diff.
+func (aa *AnalyzerAgent) sectionUpdateFor(spec AgentSpec, analysisCache *cache.AnalysisCache, report *cache.ChangeReport, docsDir string) *sectionUpdate {
+	files := analysisCache.AffectedFiles(spec.Name, report, spec.FilePatterns)
+	if len(files) == 0 {
+		return nil
+	}
+	previous, err := os.ReadFile(filepath.Join(docsDir, spec.OutputFile))
+	if err != nil {
+		return nil
+	}
+
+	diff := changeDiff(aa.config.RepoPath, report, files)
+	if diff == "" || len(diff) > maxSectionUpdateDiff {
+		aa.logger.Info(fmt.Sprintf("Rewriting %s: the changes are too large for a section update", spec.OutputFile),
+			logging.Int("diff_bytes", len(diff)))
+		return nil
+	}
+	return &sectionUpdate{
+		Document: stripGroundingFooter(string(previous)),
+		Files:    files,
+		Diff:     diff,
+	}
+}
+
+// outputExists reports whether an agent's document was written
+func outputExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// mergeFileLists returns the sorted union of two file lists
+func mergeFileLists(a, b []string) []string {
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// taskDependencies maps the dependencies of each task to task indexes. Dependencies
+// that are not part of the run (excluded or unchanged) are ignored; their
+// documents from earlier runs are used instead.
+func taskDependencies(specs []AgentSpec) [][]int {
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		index[spec.Name] = i
+	}
+
+	deps := make([][]int, len(specs))
+	for i, spec := range specs {
+		for _, dep := range spec.DependsOn {
+			if j, ok := index[dep]; ok {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+	return deps
+}
+
 // processResults processes worker pool results
-func (aa *AnalyzerAgent) processResults(outputPaths []string, results []worker_pool.Result) *AnalysisResult {
+func (aa *AnalyzerAgent) processResults(agentNames []string, results []worker_pool.Result) *AnalysisResult {
 	result := &AnalysisResult{
 		Successful: []string{},
 		Failed:     []FailedAnalysis{},
+		Usage:      make(map[string]llm.TokenUsage),
+	}
+
+	aa.usageMu.Lock()
+	for name, usage := range aa.usage {
+		result.Usage[name] = usage
 	}
+	if len(aa.grounding) > 0 {
+		result.Grounding = make(map[string]GroundingReport, len(aa.grounding))
+		for name, report := range aa.grounding {
+			result.Grounding[name] = report
+		}
+	}
+	aa.usageMu.Unlock()
 
 	for i, r := range results {
-		// Get agent name from output path
-		name := filepath.Base(outputPaths[i])
-		name = name[:len(name)-11] // Remove "_analysis.md"
+		name := strings.TrimSuffix(agentNames[i], "_analyzer")
 
 		if r.Error != nil {
 			result.Failed = append(result.Failed, FailedAnalysis{
@@ -352,3 +539,101 @@ func (aa *AnalyzerAgent) processResults(outputPaths []string, results []worker_p
 
 	return result
 }
+
+// recordUsage stores the token usage of an agent under its analysis name
+func (aa *AnalyzerAgent) recordUsage(agentName string, usage llm.TokenUsage) {
+	aa.usageMu.Lock()
+	defer aa.usageMu.Unlock()
+	aa.usage[strings.TrimSuffix(agentName, "_analyzer")] = usage
+}
+
+// verifyOutput checks the file paths and symbols of a document against the
+// repository. In repair mode the agent is asked once to fix the references
+// that were not found; the remaining ones are flagged inline and the grounding
+// score is recorded at the end of the document.
+func (aa *AnalyzerAgent) verifyOutput(ctx context.Context, agent *SubAgent, name, output string) string {
+	verifier := newVerifier(aa.config.RepoPath)
+	document := cleanLLMOutput(output)
+	report := verifier.Verify(ctx, document)
+
+	if len(report.Unverified) > 0 && aa.config.Verify == config.VerifyRepair && aa.promptManager.HasPrompt(verificationRepairPrompt) {
+		aa.logger.Info(fmt.Sprintf("Repairing %d unverified reference(s) in %s", len(report.Unverified), name))
+		repaired, err := aa.repairOutput(ctx, agent, document, report.Unverified)
+		if err != nil {
+			aa.logger.Warn(fmt.Sprintf("Repair of %s failed, keeping the original document: %v", name, err))
+		} else {
+			document = repaired
+			report = verifier.Verify(ctx, document)
+			report.Repaired = true
+		}
+	}
+
+	aa.logger.Info("Grounding score",
+		logging.String("analysis", strings.TrimSuffix(name, "_analyzer")),
+		logging.String("score", fmt.Sprintf("%.2f", report.Score())),
+		logging.Int("references", report.References),
+		logging.Int("verified", report.Verified),
+	)
+	aa.usageMu.Lock()
+	aa.grounding[strings.TrimSuffix(name, "_analyzer")] = report
+	aa.usageMu.Unlock()
+
+	return markUnverified(document, report.Unverified) + groundingFooter(report)
+}
+
+// repairOutput asks the agent to correct the unverified references of a document
+func (aa *AnalyzerAgent) repairOutput(ctx context.Context, agent *SubAgent, document string, unverified []string) (string, error) {
+	prompt, err := aa.promptManager.Render(verificationRepairPrompt, map[string]interface{}{
+		"RepoPath":   aa.config.RepoPath,
+		"Document":   document,
+		"Unverified": unverified,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render repair prompt: %w", err)
+	}
+	// The repair is a separate conversation: checkpointing it under the agent's
+	// key would overwrite the checkpoint of the analysis itself
+	agent.DisableCheckpoints()
+	repaired, err := agent.RunOnce(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return cleanLLMOutput(repaired), nil
+}
+
+// recordUsageSummary attaches the ledger summary to the result and appends it to the usage history
+func (aa *AnalyzerAgent) recordUsageSummary(result *AnalysisResult) {
+	summary := aa.usageLedger.Summary()
+	result.UsageSummary = &summary
+
+	aa.logger.Info("Token usage",
+		logging.Int("calls", summary.Calls),
+		logging.Int("cache_hits", summary.CacheHits),
+		logging.Int("input_tokens", summary.InputTokens),
+		logging.Int("cache_read_input_tokens", summary.CacheReadInputTokens),
+		logging.Int("cache_creation_input_tokens", summary.CacheCreationInputTokens),
+		logging.Int("output_tokens", summary.OutputTokens),
+		logging.String("estimated_cost_usd", fmt.Sprintf("%.4f", summary.Cost)),
+	)
+	if len(summary.Unpriced) > 0 {
+		aa.logger.Debug(fmt.Sprintf("No price configured for: %v", summary.Unpriced))
+	}
+
+	entry := llm.UsageHistoryEntry{
+		Timestamp:    time.Now(),
+		Command:      "analyze",
+		UsageSummary: summary,
+	}
+	if err := llm.AppendUsageHistory(aa.config.RepoPath, entry); err != nil {
+		aa.logger.Warn(fmt.Sprintf("Failed to save usage history: %v", err))
+	}
+}
+
+// analysisNames converts agent names (e.g. "data_flow_analyzer") to analysis names ("data_flow")
+func analysisNames(agents []string) []string {
+	names := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		names = append(names, strings.TrimSuffix(agent, "_analyzer"))
+	}
+	return names
+}
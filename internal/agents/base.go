@@ -4,23 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
 	"github.com/user/gendocs/internal/llm"
 	"github.com/user/gendocs/internal/logging"
 	"github.com/user/gendocs/internal/prompts"
+	"github.com/user/gendocs/internal/tokenizer"
 	"github.com/user/gendocs/internal/tools"
 )
 
 // Context management constants
 const (
-	// MaxConversationTokens is the maximum estimated tokens allowed in conversation history
+	// MaxConversationTokens is the conversation history budget of agents without a configured model
 	MaxConversationTokens = 100000
 
 	// MaxToolResponseTokens is the maximum tokens for a single tool response
 	MaxToolResponseTokens = 15000
 
-	// TokenEstimateRatio is the approximate characters per token (for estimation)
-	TokenEstimateRatio = 4
+	// messageOverheadTokens approximates the tokens a provider adds around each message
+	messageOverheadTokens = 4
 )
 
 // Agent is the interface that all agents must implement
@@ -43,6 +48,13 @@ type BaseAgent struct {
 	maxRetries    int
 	maxTokens     int
 	temperature   float64
+	tokenizer     *tokenizer.Tokenizer
+	contextBudget int             // Tokens the conversation history may use
+	usage         llm.TokenUsage  // Accumulated over all LLM calls
+	filesRead     map[string]bool // file_path of every successful read_file call
+
+	checkpoints   *CheckpointStore // nil when the conversation is not checkpointed
+	checkpointKey string
 }
 
 // NewBaseAgent creates a new base agent
@@ -65,7 +77,68 @@ func NewBaseAgent(
 		maxRetries:    maxRetries,
 		maxTokens:     8192,
 		temperature:   0.0,
+		tokenizer:     tokenizer.Heuristic(),
+		contextBudget: MaxConversationTokens,
+	}
+}
+
+// estimatedEncodings records the encodings whose missing vocabulary, and the
+// models whose approximate counts, were already reported, so each is logged once
+// per process rather than once per agent
+var estimatedEncodings sync.Map
+
+// SetModel budgets the conversation against the context window of the model
+// and its fallbacks: the smallest window, less the response, the system prompt,
+// the tool definitions and a margin for counting error, doubled when the counts
+// are estimates. Responses are capped
+// at the smallest output limit of the known models and half the window.
+// It returns a configuration error when the window leaves no room for the
+// conversation.
+func (ba *BaseAgent) SetModel(cfg config.LLMConfig) error {
+	ba.tokenizer = tokenizer.ForModel(cfg.Model)
+	if info, _ := tokenizer.LookupModel(cfg.Model); info.Approximate {
+		if _, warned := estimatedEncodings.LoadOrStore(cfg.Model, true); !warned {
+			ba.logger.Info(fmt.Sprintf("Token counts for %s are estimates: its tokenizer is not public, so it is counted with %s",
+				cfg.Model, info.Encoding))
+		}
+	} else if info.Encoding != "" && !ba.tokenizer.Exact() {
+		if _, warned := estimatedEncodings.LoadOrStore(info.Encoding, true); !warned {
+			ba.logger.Warn(fmt.Sprintf("Token counts for %s are estimated: the %s vocabulary is not in %s. Run 'gendocs tokenizer-fetch' to count them exactly",
+				cfg.Model, info.Encoding, tokenizer.Dir()))
+		}
+	}
+
+	window, output := 0, cfg.GetMaxTokens()
+	for _, backend := range cfg.Backends() {
+		info, known := tokenizer.LookupModel(backend.Model)
+		if cfg.ContextWindow > 0 {
+			info.ContextWindow = cfg.ContextWindow
+		}
+		if window == 0 || info.ContextWindow < window {
+			window = info.ContextWindow
+		}
+		if known {
+			output = min(output, info.MaxOutputTokens)
+		}
+	}
+	ba.maxTokens = min(output, window/2)
+
+	prompt := ba.tokenizer.Count(ba.systemPrompt)
+	for _, tool := range ba.convertTools() {
+		prompt += ba.tokenizer.Count(tool.Name) + ba.tokenizer.Count(tool.Description) + ba.tokenizer.Count(formatToolResult(tool.Parameters))
+	}
+	margin := window / 20
+	if !ba.tokenizer.Exact() {
+		margin = window / 10
 	}
+	budget := window - ba.maxTokens - prompt - margin
+	if budget <= 0 {
+		return errors.NewConfigurationError(fmt.Sprintf(
+			"%s: the %d-token context window of %s leaves no room for the conversation after %d tokens of response and %d tokens of system prompt and tools; set llm.context_window if the model supports a larger window, or lower llm.max_tokens",
+			ba.name, window, cfg.Model, ba.maxTokens, prompt))
+	}
+	ba.contextBudget = budget
+	return nil
 }
 
 // SetMaxTokens sets the maximum tokens for LLM responses
@@ -78,6 +151,52 @@ func (ba *BaseAgent) SetTemperature(temperature float64) {
 	ba.temperature = temperature
 }
 
+// Usage returns the token usage accumulated over all LLM calls made by the agent
+func (ba *BaseAgent) Usage() llm.TokenUsage {
+	return ba.usage
+}
+
+// FilesRead returns the paths the agent read with the read_file tool, as passed to it
+func (ba *BaseAgent) FilesRead() []string {
+	files := make([]string, 0, len(ba.filesRead))
+	for file := range ba.filesRead {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// recordFileRead remembers the file of a successful read_file call
+func (ba *BaseAgent) recordFileRead(toolCall llm.ToolCall, result interface{}) {
+	if toolCall.Name != "read_file" {
+		return
+	}
+	if response, ok := result.(map[string]interface{}); ok {
+		if _, failed := response["error"]; failed {
+			return
+		}
+	}
+	if filePath, ok := toolCall.Arguments["file_path"].(string); ok && filePath != "" {
+		if ba.filesRead == nil {
+			ba.filesRead = make(map[string]bool)
+		}
+		ba.filesRead[filePath] = true
+	}
+}
+
+// EnableCheckpoints saves the conversation under key after every tool-loop
+// iteration, and resumes from the saved conversation when the prompts match
+func (ba *BaseAgent) EnableCheckpoints(store *CheckpointStore, key string) {
+	ba.checkpoints = store
+	ba.checkpointKey = key
+}
+
+// DisableCheckpoints stops saving the conversation of the following runs
+func (ba *BaseAgent) DisableCheckpoints() {
+	ba.checkpoints = nil
+	ba.checkpointKey = ""
+}
+
 // RunOnce executes the agent once with the given user prompt
 func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, error) {
 	// Initialize conversation history with the user prompt
@@ -90,6 +209,26 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 	const maxIterations = 100
 	iterations := 0
 
+	var hash string
+	if ba.checkpoints != nil {
+		hash = promptHash(ba.systemPrompt, userPrompt)
+		checkpoint, err := ba.checkpoints.Load(ba.checkpointKey)
+		if err != nil {
+			ba.logger.Warn(fmt.Sprintf("Ignoring checkpoint: %v", err), logging.String("agent", ba.name))
+		} else if checkpoint != nil && !checkpoint.Completed && checkpoint.PromptHash == hash && len(checkpoint.Messages) > 0 {
+			conversationHistory = checkpoint.Messages
+			iterations = checkpoint.Iteration
+			for _, file := range checkpoint.FilesRead {
+				ba.recordFileRead(llm.ToolCall{Name: "read_file", Arguments: map[string]interface{}{"file_path": file}}, nil)
+			}
+			ba.logger.Info("Resuming from checkpoint",
+				logging.String("agent", ba.name),
+				logging.Int("iteration", iterations),
+				logging.Int("history_messages", len(conversationHistory)),
+			)
+		}
+	}
+
 	// Tool calling loop
 	for {
 		iterations++
@@ -98,19 +237,23 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 				logging.String("agent", ba.name),
 				logging.Int("iterations", iterations),
 			)
+			if ba.checkpoints != nil {
+				ba.checkpoints.Remove(ba.checkpointKey)
+			}
 			return "", fmt.Errorf("agent exceeded maximum iterations (%d)", maxIterations)
 		}
 
 		// Trim conversation history to prevent context overflow
-		conversationHistory = trimConversationHistory(conversationHistory, MaxConversationTokens)
+		conversationHistory = trimConversationHistory(conversationHistory, ba.contextBudget, ba.tokenizer)
 
 		// Log current context size
-		currentTokens := estimateHistoryTokens(conversationHistory)
+		currentTokens := estimateHistoryTokens(conversationHistory, ba.tokenizer)
 		ba.logger.Info("Calling LLM",
 			logging.String("agent", ba.name),
 			logging.Int("tool_count", len(ba.tools)),
 			logging.Int("history_messages", len(conversationHistory)),
 			logging.Int("estimated_tokens", currentTokens),
+			logging.Int("context_budget", ba.contextBudget),
 		)
 
 		req := llm.CompletionRequest{
@@ -122,7 +265,13 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 		}
 
 		// Call LLM
-		resp, err := ba.llmClient.GenerateCompletion(ctx, req)
+		// Usage is counted before the error check: a failed call may still have been billed
+		resp, err := ba.llmClient.GenerateCompletion(llm.WithUsageScope(ctx, ba.name, iterations), req)
+		ba.usage.InputTokens += resp.Usage.InputTokens
+		ba.usage.OutputTokens += resp.Usage.OutputTokens
+		ba.usage.TotalTokens += resp.Usage.TotalTokens
+		ba.usage.CacheCreationInputTokens += resp.Usage.CacheCreationInputTokens
+		ba.usage.CacheReadInputTokens += resp.Usage.CacheReadInputTokens
 		if err != nil {
 			return "", fmt.Errorf("LLM call failed: %w", err)
 		}
@@ -130,6 +279,8 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 		ba.logger.Info("LLM response received",
 			logging.String("agent", ba.name),
 			logging.Int("input_tokens", resp.Usage.InputTokens),
+			logging.Int("cache_read_input_tokens", resp.Usage.CacheReadInputTokens),
+			logging.Int("cache_creation_input_tokens", resp.Usage.CacheCreationInputTokens),
 			logging.Int("output_tokens", resp.Usage.OutputTokens),
 			logging.Int("tool_calls", len(resp.ToolCalls)),
 		)
@@ -178,9 +329,11 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 					ToolID:  toolCall.Name,
 				})
 			} else {
+				ba.recordFileRead(toolCall, result)
+
 				// Format and truncate tool response
 				formattedResult := formatToolResult(result)
-				truncatedResult := truncateToolResponse(formattedResult, MaxToolResponseTokens)
+				truncatedResult := truncateToolResponse(formattedResult, toolResponseLimit(ba.contextBudget), ba.tokenizer)
 
 				conversationHistory = append(conversationHistory, llm.Message{
 					Role:    "tool",
@@ -190,6 +343,19 @@ func (ba *BaseAgent) RunOnce(ctx context.Context, userPrompt string) (string, er
 			}
 		}
 
+		if ba.checkpoints != nil {
+			checkpoint := &Checkpoint{
+				Agent:      ba.checkpointKey,
+				PromptHash: hash,
+				Iteration:  iterations,
+				Messages:   conversationHistory,
+				FilesRead:  ba.FilesRead(),
+			}
+			if err := ba.checkpoints.Save(checkpoint); err != nil {
+				ba.logger.Warn(fmt.Sprintf("Failed to save checkpoint: %v", err), logging.String("agent", ba.name))
+			}
+		}
+
 		// Continue loop to get final response from LLM
 	}
 }
@@ -222,28 +388,44 @@ func (ba *BaseAgent) Name() string {
 	return ba.name
 }
 
-// estimateTokens estimates the number of tokens in a string
-func estimateTokens(text string) int {
-	return len(text) / TokenEstimateRatio
-}
-
 // estimateHistoryTokens estimates total tokens in conversation history
-func estimateHistoryTokens(history []llm.Message) int {
+func estimateHistoryTokens(history []llm.Message, tok *tokenizer.Tokenizer) int {
 	total := 0
 	for _, msg := range history {
-		total += estimateTokens(msg.Content)
+		total += estimateMessageTokens(msg, tok)
+	}
+	return total
+}
+
+// estimateMessageTokens estimates the tokens of one message, including its tool calls
+func estimateMessageTokens(msg llm.Message, tok *tokenizer.Tokenizer) int {
+	total := messageOverheadTokens + tok.Count(msg.Content)
+	for _, toolCall := range msg.ToolCalls {
+		total += tok.Count(toolCall.Name) + tok.Count(formatToolResult(toolCall.Arguments))
 	}
 	return total
 }
 
+// toolResponseLimit caps tool responses at MaxToolResponseTokens, or a quarter
+// of the conversation budget for small context windows
+func toolResponseLimit(budget int) int {
+	return min(MaxToolResponseTokens, budget/4)
+}
+
 // trimConversationHistory keeps conversation history within token limits
 // It removes older messages while preserving the most recent context
-func trimConversationHistory(history []llm.Message, maxTokens int) []llm.Message {
+func trimConversationHistory(history []llm.Message, maxTokens int, tok *tokenizer.Tokenizer) []llm.Message {
 	if len(history) == 0 {
 		return history
 	}
 
-	totalTokens := estimateHistoryTokens(history)
+	// Count each message once; dropping a message subtracts its count
+	counts := make([]int, len(history))
+	totalTokens := 0
+	for i, msg := range history {
+		counts[i] = estimateMessageTokens(msg, tok)
+		totalTokens += counts[i]
+	}
 
 	// If within limits, return as is
 	if totalTokens <= maxTokens {
@@ -258,17 +440,21 @@ func trimConversationHistory(history []llm.Message, maxTokens int) []llm.Message
 	}
 
 	trimmed := history
-	for len(trimmed) > minKeep && estimateHistoryTokens(trimmed) > maxTokens {
+	for len(trimmed) > minKeep && totalTokens > maxTokens {
+		totalTokens -= counts[len(history)-len(trimmed)]
 		trimmed = trimmed[1:]
 	}
 
 	// If still too large, truncate individual messages
-	if estimateHistoryTokens(trimmed) > maxTokens {
+	if totalTokens > maxTokens {
+		limit := toolResponseLimit(maxTokens)
 		for i := range trimmed {
-			if trimmed[i].Role == "tool" && len(trimmed[i].Content) > MaxToolResponseTokens*TokenEstimateRatio {
-				// Truncate tool responses that are too large
-				maxChars := MaxToolResponseTokens * TokenEstimateRatio
-				trimmed[i].Content = trimmed[i].Content[:maxChars] + "\n[TRUNCATED - response exceeded token limit]"
+			if trimmed[i].Role != "tool" {
+				continue
+			}
+			// Truncate tool responses that are too large
+			if content, truncated := tok.Truncate(trimmed[i].Content, limit); truncated {
+				trimmed[i].Content = content + "\n[TRUNCATED - response exceeded token limit]"
 			}
 		}
 	}
@@ -277,13 +463,13 @@ func trimConversationHistory(history []llm.Message, maxTokens int) []llm.Message
 }
 
 // truncateToolResponse truncates a tool response if it exceeds the limit
-func truncateToolResponse(response string, maxTokens int) string {
-	maxChars := maxTokens * TokenEstimateRatio
-	if len(response) <= maxChars {
+func truncateToolResponse(response string, maxTokens int, tok *tokenizer.Tokenizer) string {
+	truncated, cut := tok.Truncate(response, maxTokens)
+	if !cut {
 		return response
 	}
 
-	return response[:maxChars] + "\n\n[TRUNCATED - Tool response exceeded " + fmt.Sprintf("%d", maxTokens) + " token limit]"
+	return truncated + "\n\n[TRUNCATED - Tool response exceeded " + fmt.Sprintf("%d", maxTokens) + " token limit]"
 }
 
 // formatToolResult formats a tool result for inclusion in conversation history
@@ -0,0 +1,132 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	"github.com/user/gendocs/internal/tokenizer"
+	"github.com/user/gendocs/internal/tools"
+)
+
+func newTestBaseAgent() *BaseAgent {
+	return NewBaseAgent("StructureAnalyzer", nil, []tools.Tool{tools.NewFileReadTool(1)},
+		prompts.NewManagerFromMap(nil), logging.NewNopLogger(), strings.Repeat("Analyze the code. ", 100), 1)
+}
+
+func TestBaseAgent_SetModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.LLMConfig
+		maxTokens int
+		minBudget int
+		maxBudget int
+	}{
+		{
+			name:      "small context window",
+			cfg:       config.LLMConfig{Model: "gpt-4", MaxTokens: 8192},
+			maxTokens: 4096,
+			minBudget: 2000,
+			maxBudget: 4096 - 400,
+		},
+		{
+			name:      "large context window",
+			cfg:       config.LLMConfig{Model: "gemini-2.5-pro", MaxTokens: 8192},
+			maxTokens: 8192,
+			minBudget: 900_000,
+			maxBudget: 1_048_576 - 8192,
+		},
+		{
+			name:      "wider margin for estimated Claude counts",
+			cfg:       config.LLMConfig{Model: "claude-3-5-sonnet", MaxTokens: 8192},
+			maxTokens: 8192,
+			minBudget: 150_000,
+			maxBudget: 200_000 - 8192 - 20_000,
+		},
+		{
+			name:      "smallest window of the fallbacks",
+			cfg:       config.LLMConfig{Provider: "gemini", Model: "gemini-2.5-pro", MaxTokens: 8192, Fallbacks: []config.LLMFallback{{Provider: "openai", Model: "gpt-4o"}}},
+			maxTokens: 8192,
+			minBudget: 100_000,
+			maxBudget: 128_000 - 8192,
+		},
+		{
+			name:      "context window override for a local model",
+			cfg:       config.LLMConfig{Model: "llama3.1:8b", MaxTokens: 16_000, ContextWindow: 64_000},
+			maxTokens: 16_000,
+			minBudget: 40_000,
+			maxBudget: 64_000 - 16_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newTestBaseAgent()
+			if err := agent.SetModel(tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if agent.maxTokens != tt.maxTokens {
+				t.Errorf("maxTokens = %d, want %d", agent.maxTokens, tt.maxTokens)
+			}
+			if agent.contextBudget < tt.minBudget || agent.contextBudget > tt.maxBudget {
+				t.Errorf("contextBudget = %d, want between %d and %d", agent.contextBudget, tt.minBudget, tt.maxBudget)
+			}
+			if limit := toolResponseLimit(agent.contextBudget); limit > MaxToolResponseTokens || limit > agent.contextBudget/4 {
+				t.Errorf("tool response limit %d exceeds a quarter of the budget %d", limit, agent.contextBudget)
+			}
+		})
+	}
+}
+
+func TestBaseAgent_SetModel_NoRoomForConversation(t *testing.T) {
+	agent := newTestBaseAgent()
+	err := agent.SetModel(config.LLMConfig{Model: "llama3.1:8b", MaxTokens: 2048, ContextWindow: 800})
+	if err == nil || !strings.Contains(err.Error(), "context_window") {
+		t.Fatalf("expected a configuration error naming context_window, got %v", err)
+	}
+}
+
+func TestTrimConversationHistory(t *testing.T) {
+	tok := tokenizer.Heuristic()
+	toolResult := strings.Repeat("func main() { fmt.Println(\"hello\") }\n", 500)
+	history := []llm.Message{{Role: "user", Content: "analyze"}}
+	for i := 0; i < 10; i++ {
+		history = append(history,
+			llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{Name: "read_file", Arguments: map[string]interface{}{"file_path": "main.go"}}}},
+			llm.Message{Role: "tool", Content: toolResult, ToolID: "read_file"},
+		)
+	}
+
+	// Within the budget the history is kept as is
+	total := estimateHistoryTokens(history, tok)
+	if got := trimConversationHistory(history, total, tok); len(got) != len(history) {
+		t.Errorf("expected no trimming within the budget, got %d of %d messages", len(got), len(history))
+	}
+
+	// Older messages are dropped first
+	budget := total / 2
+	trimmed := trimConversationHistory(history, budget, tok)
+	if len(trimmed) >= len(history) || estimateHistoryTokens(trimmed, tok) > budget {
+		t.Errorf("expected the history trimmed to %d tokens, got %d messages with %d tokens", budget, len(trimmed), estimateHistoryTokens(trimmed, tok))
+	}
+	if trimmed[len(trimmed)-1].Content != toolResult {
+		t.Error("expected the most recent messages to be kept")
+	}
+	if dropped := len(history) - len(trimmed); estimateHistoryTokens(history[dropped-1:], tok) <= budget {
+		t.Errorf("expected only as many messages dropped as needed, dropped %d", dropped)
+	}
+
+	// When the last messages alone exceed the budget, tool results are truncated
+	budget = tok.Count(toolResult)
+	trimmed = trimConversationHistory(append([]llm.Message(nil), history...), budget, tok)
+	if len(trimmed) != 4 || !strings.HasSuffix(trimmed[3].Content, "[TRUNCATED - response exceeded token limit]") {
+		t.Fatalf("expected the last 4 messages with truncated tool results, got %d messages", len(trimmed))
+	}
+	if got := tok.Count(strings.TrimSuffix(trimmed[3].Content, "\n[TRUNCATED - response exceeded token limit]")); got > toolResponseLimit(budget) {
+		t.Errorf("expected the tool result truncated to %d tokens, got %d", toolResponseLimit(budget), got)
+	}
+}
@@ -0,0 +1,166 @@
+package agents
+
+import (
+	"context"
+	"sync"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/tokenizer"
+)
+
+// budgetTotal tracks the spend of a whole run against its token and cost
+// limits. The analyses of every module of a monorepo share one.
+type budgetTotal struct {
+	maxTokens int
+	maxCost   float64
+
+	mu       sync.Mutex
+	tokens   int
+	cost     float64
+	exceeded bool // Set once any agent was stopped
+}
+
+func newBudgetTotal(cfg config.AnalyzerConfig) *budgetTotal {
+	return &budgetTotal{
+		maxTokens: cfg.MaxTokensPerRun,
+		maxCost:   cfg.MaxCostPerRun,
+	}
+}
+
+// share splits the limits of the run evenly between agentCount agents
+func (t *budgetTotal) share(prices llm.PriceTable, agentCount int) *runBudget {
+	if agentCount < 1 {
+		agentCount = 1
+	}
+	return &runBudget{
+		maxTokens: t.maxTokens / agentCount,
+		maxCost:   t.maxCost / float64(agentCount),
+		prices:    prices,
+		total:     t,
+	}
+}
+
+// spent returns the spend of the run so far
+func (t *budgetTotal) spent() (int, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tokens, t.cost
+}
+
+// add records the spend of a call
+func (t *budgetTotal) add(tokens int, cost float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens += tokens
+	t.cost += cost
+}
+
+// runBudget is the share of each agent of one analyzer run. Agents are also
+// stopped once the run as a whole reaches its limits.
+type runBudget struct {
+	maxTokens int
+	maxCost   float64
+	prices    llm.PriceTable
+	total     *budgetTotal
+}
+
+func newRunBudget(cfg config.AnalyzerConfig, prices llm.PriceTable, agentCount int) *runBudget {
+	return newBudgetTotal(cfg).share(prices, agentCount)
+}
+
+// Exceeded returns whether an agent of the run was stopped by the budget
+func (b *runBudget) Exceeded() bool {
+	b.total.mu.Lock()
+	defer b.total.mu.Unlock()
+	return b.total.exceeded
+}
+
+func (b *runBudget) markExceeded() {
+	b.total.mu.Lock()
+	defer b.total.mu.Unlock()
+	b.total.exceeded = true
+}
+
+// budgetedClient enforces the share of one agent. Before each call it checks that
+// the spend so far plus the estimated prompt stays within the share; when it
+// does not, the agent's context is cancelled and the call fails.
+type budgetedClient struct {
+	client llm.LLMClient
+	budget *runBudget
+	agent  string
+	model  string
+	tok    *tokenizer.Tokenizer
+	cancel context.CancelCauseFunc
+
+	tokens int
+	cost   float64
+}
+
+func newBudgetedClient(client llm.LLMClient, budget *runBudget, agent, model string, cancel context.CancelCauseFunc) *budgetedClient {
+	return &budgetedClient{
+		client: client,
+		budget: budget,
+		agent:  agent,
+		model:  model,
+		tok:    tokenizer.ForModel(model),
+		cancel: cancel,
+	}
+}
+
+// GenerateCompletion implements llm.LLMClient
+func (c *budgetedClient) GenerateCompletion(ctx context.Context, req llm.CompletionRequest) (llm.CompletionResponse, error) {
+	promptTokens := c.tok.Count(req.SystemPrompt) + estimateHistoryTokens(req.Messages, c.tok)
+	promptCost, _ := c.budget.prices.Cost(c.model, promptTokens, 0)
+
+	runTokens, runCost := c.budget.total.spent()
+	if err := c.check(c.tokens+promptTokens, c.cost+promptCost, runTokens+promptTokens, runCost+promptCost); err != nil {
+		return llm.CompletionResponse{}, err
+	}
+
+	resp, err := c.client.GenerateCompletion(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	// The response is paid for, so it is always returned; a call that crossed
+	// the limit stops the agent at its next call
+	c.tokens += resp.Usage.TotalTokens
+	cost, _ := c.budget.prices.UsageCost(c.model, resp.Usage)
+	c.cost += cost
+	c.budget.total.add(resp.Usage.TotalTokens, cost)
+	return resp, nil
+}
+
+// check stops the agent when the given spend exceeds its share, or the spend
+// of the run exceeds the run limits
+func (c *budgetedClient) check(tokens int, cost float64, runTokens int, runCost float64) error {
+	var err error
+	switch {
+	case c.budget.maxTokens > 0 && tokens > c.budget.maxTokens:
+		err = errors.NewBudgetExceededError(c.agent, "tokens", float64(tokens), float64(c.budget.maxTokens))
+	case c.budget.maxCost > 0 && cost > c.budget.maxCost:
+		err = errors.NewBudgetExceededError(c.agent, "cost", cost, c.budget.maxCost)
+	case c.budget.total.maxTokens > 0 && runTokens > c.budget.total.maxTokens:
+		err = errors.NewBudgetExceededError(c.agent, "tokens", float64(runTokens), float64(c.budget.total.maxTokens))
+	case c.budget.total.maxCost > 0 && runCost > c.budget.total.maxCost:
+		err = errors.NewBudgetExceededError(c.agent, "cost", runCost, c.budget.total.maxCost)
+	default:
+		return nil
+	}
+
+	c.budget.markExceeded()
+	c.cancel(err)
+	return err
+}
+
+// SupportsTools delegates to the wrapped client
+func (c *budgetedClient) SupportsTools() bool {
+	return c.client.SupportsTools()
+}
+
+// GetProvider delegates to the wrapped client
+func (c *budgetedClient) GetProvider() string {
+	return c.client.GetProvider()
+}
@@ -0,0 +1,151 @@
+package agents
+
+import (
+	"context"
+	stderrors "errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestBudgetedClient_StopsBeforeExceedingTokenShare(t *testing.T) {
+	mock := testHelpers.NewMockLLMClient(llm.CompletionResponse{
+		Content: "ok",
+		Usage:   llm.TokenUsage{InputTokens: 90, OutputTokens: 10, TotalTokens: 100},
+	})
+	budget := newRunBudget(config.AnalyzerConfig{MaxTokensPerRun: 300}, llm.NewPriceTable(nil), 2)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	client := newBudgetedClient(mock, budget, "structure_analyzer", "gpt-4o", cancel)
+
+	// A 40 token prompt stays within the 150 token share
+	req := llm.CompletionRequest{Messages: []llm.Message{{Role: "user", Content: strings.Repeat("x", 160)}}}
+	if _, err := client.GenerateCompletion(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first call: %v", err)
+	}
+
+	// 100 tokens spent plus a 100 token prompt does not
+	req.Messages[0].Content = strings.Repeat("x", 400)
+	_, err := client.GenerateCompletion(ctx, req)
+
+	var budgetErr *errors.BudgetExceededError
+	if !stderrors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if mock.CallCount != 1 {
+		t.Errorf("expected the second call to be blocked before reaching the LLM, got %d calls", mock.CallCount)
+	}
+	if !stderrors.As(context.Cause(ctx), &budgetErr) {
+		t.Errorf("expected the agent context to be cancelled with the budget error, got %v", context.Cause(ctx))
+	}
+	if !budget.Exceeded() {
+		t.Error("expected the run budget to be marked as exceeded")
+	}
+	if errors.Category(err) != errors.CategoryBudget {
+		t.Errorf("expected budget category, got %s", errors.Category(err))
+	}
+}
+
+func TestBudgetedClient_CostShare(t *testing.T) {
+	mock := testHelpers.NewMockLLMClient(llm.CompletionResponse{
+		Usage: llm.TokenUsage{InputTokens: 1000, OutputTokens: 1000, TotalTokens: 2000},
+	})
+	prices := llm.NewPriceTable(map[string]config.ModelPrice{"pricey": {Input: 10, Output: 40}})
+	budget := newRunBudget(config.AnalyzerConfig{MaxCostPerRun: 0.04}, prices, 1)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	client := newBudgetedClient(mock, budget, "api_analyzer", "pricey", cancel)
+
+	// The first call costs $0.05: it crosses the limit, but its response is kept
+	resp, err := client.GenerateCompletion(ctx, llm.CompletionRequest{})
+	if err != nil || resp.Usage.TotalTokens != 2000 {
+		t.Fatalf("expected the completed response to be returned, got %+v, %v", resp, err)
+	}
+
+	// The next call is stopped before reaching the LLM
+	_, err = client.GenerateCompletion(ctx, llm.CompletionRequest{})
+	if mock.CallCount != 1 {
+		t.Errorf("expected one LLM call, got %d", mock.CallCount)
+	}
+
+	var budgetErr *errors.BudgetExceededError
+	if !stderrors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if !strings.Contains(budgetErr.Message, "cost budget exceeded ($0.0500 of $0.0400)") {
+		t.Errorf("unexpected message: %s", budgetErr.Message)
+	}
+}
+
+func TestBaseAgent_RunOnce_KeepsAnswerCrossingBudget(t *testing.T) {
+	mock := testHelpers.NewMockLLMClient(llm.CompletionResponse{
+		Content: "# Analysis",
+		Usage:   llm.TokenUsage{InputTokens: 900, OutputTokens: 200, TotalTokens: 1100},
+	})
+	budget := newRunBudget(config.AnalyzerConfig{MaxTokensPerRun: 1000}, llm.NewPriceTable(nil), 1)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	agent := newTestBaseAgent()
+	agent.llmClient = newBudgetedClient(mock, budget, "structure_analyzer", "gpt-4o", cancel)
+
+	output, err := agent.RunOnce(ctx, "analyze")
+	if err != nil || output != "# Analysis" {
+		t.Fatalf("expected the final answer to be kept, got %q, %v", output, err)
+	}
+	if usage := agent.Usage(); usage.TotalTokens != 1100 {
+		t.Errorf("expected the call to be counted in the agent usage, got %+v", usage)
+	}
+}
+
+func TestAnalyzerAgent_Run_BudgetExceeded(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Analysis\\n\\nGenerated content"))
+	t.Cleanup(llmServer.Close)
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+		},
+		MaxWorkers:      1,
+		ExcludeDataFlow: true,
+		ExcludeReqFlow:  true,
+		ExcludeAPI:      true,
+		ExcludeDeps:     true,
+		ExcludeSecurity: true,
+		ExcludeTesting:  true,
+		ExcludeInfra:    true,
+		MaxTokensPerRun: 10, // Smaller than any prompt
+	}
+
+	result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Failed) != 1 || !result.BudgetExceeded() {
+		t.Fatalf("expected the structure analysis to be stopped by the budget, got %+v", result)
+	}
+	if result.UsageSummary == nil || result.UsageSummary.Calls != 0 {
+		t.Errorf("expected no LLM call to be made, got %+v", result.UsageSummary)
+	}
+}
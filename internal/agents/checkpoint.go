@@ -0,0 +1,121 @@
+package agents
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/llm"
+)
+
+// CheckpointDir holds, relative to the repository root, the state of an
+// interrupted analysis
+const CheckpointDir = ".ai/checkpoints"
+
+// Checkpoint is the state of one agent of an interrupted analysis: its
+// conversation, including tool results, after the last tool-loop iteration
+type Checkpoint struct {
+	Agent      string        `json:"agent"`
+	PromptHash string        `json:"prompt_hash"` // The conversation only resumes with the same prompts
+	Iteration  int           `json:"iteration"`
+	Messages   []llm.Message `json:"messages,omitempty"`
+	FilesRead  []string      `json:"files_read,omitempty"` // As passed to read_file; repository-relative once completed
+	Completed  bool          `json:"completed"`            // The agent's document was written
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// CheckpointStore reads and writes checkpoints, one file per agent
+type CheckpointStore struct {
+	dir string
+}
+
+// NewCheckpointStore creates a checkpoint store for the repository
+func NewCheckpointStore(repoPath string) *CheckpointStore {
+	return &CheckpointStore{dir: filepath.Join(repoPath, filepath.FromSlash(CheckpointDir))}
+}
+
+func (s *CheckpointStore) path(agent string) string {
+	return filepath.Join(s.dir, agent+".json")
+}
+
+// Load returns the checkpoint of an agent, or nil if there is none
+func (s *CheckpointStore) Load(agent string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(agent))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint of %s: %w", agent, err)
+	}
+	return &checkpoint, nil
+}
+
+// Save writes a checkpoint atomically, so an interruption never leaves a
+// truncated file behind
+func (s *CheckpointStore) Save(checkpoint *Checkpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	tmpPath := s.path(checkpoint.Agent) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmpPath, s.path(checkpoint.Agent))
+}
+
+// MarkCompleted records that an agent's document was written, with the
+// repository files it read; its conversation is no longer needed
+func (s *CheckpointStore) MarkCompleted(agent string, filesAnalyzed []string) error {
+	return s.Save(&Checkpoint{Agent: agent, FilesRead: filesAnalyzed, Completed: true})
+}
+
+// Completed returns the agents whose document was written before the
+// interruption, with the repository files each read
+func (s *CheckpointStore) Completed() map[string][]string {
+	completed := make(map[string][]string)
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return completed
+	}
+	for _, entry := range entries {
+		agent, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if checkpoint, err := s.Load(agent); err == nil && checkpoint != nil && checkpoint.Completed {
+			completed[agent] = checkpoint.FilesRead
+		}
+	}
+	return completed
+}
+
+// Remove deletes the checkpoint of an agent
+func (s *CheckpointStore) Remove(agent string) {
+	_ = os.Remove(s.path(agent))
+}
+
+// Clear deletes every checkpoint
+func (s *CheckpointStore) Clear() error {
+	return os.RemoveAll(s.dir)
+}
+
+// promptHash identifies the prompts a conversation started from
+func promptHash(systemPrompt, userPrompt string) string {
+	sum := sha256.Sum256([]byte(systemPrompt + "\x00" + userPrompt))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,179 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+	"github.com/user/gendocs/internal/tools"
+)
+
+// interruptedClient answers with a tool call, then fails as if the run was interrupted
+type interruptedClient struct {
+	calls int
+}
+
+func (c *interruptedClient) GenerateCompletion(ctx context.Context, req llm.CompletionRequest) (llm.CompletionResponse, error) {
+	c.calls++
+	if c.calls > 1 {
+		return llm.CompletionResponse{}, fmt.Errorf("interrupted")
+	}
+	return llm.CompletionResponse{
+		ToolCalls: []llm.ToolCall{{Name: "read_file", Arguments: map[string]interface{}{"file_path": "main.go"}}},
+	}, nil
+}
+
+func (c *interruptedClient) SupportsTools() bool { return true }
+func (c *interruptedClient) GetProvider() string { return "test" }
+
+func TestBaseAgent_RunOnce_ResumesFromCheckpoint(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{"main.go": "package main"})
+	t.Chdir(repoPath)
+	store := NewCheckpointStore(repoPath)
+
+	agent := NewBaseAgent("StructureAnalyzer", &interruptedClient{}, []tools.Tool{tools.NewFileReadTool(1)},
+		prompts.NewManagerFromMap(nil), logging.NewNopLogger(), "system", 1)
+	agent.EnableCheckpoints(store, "structure_analyzer")
+	if _, err := agent.RunOnce(context.Background(), "analyze"); err == nil {
+		t.Fatal("expected the interrupted run to fail")
+	}
+
+	checkpoint, err := store.Load("structure_analyzer")
+	if err != nil || checkpoint == nil {
+		t.Fatalf("expected a checkpoint after the first iteration, got %v, %v", checkpoint, err)
+	}
+	if checkpoint.Iteration != 1 || len(checkpoint.Messages) != 3 {
+		t.Fatalf("expected the prompt, the tool call and its result, got %+v", checkpoint)
+	}
+
+	// The resumed conversation continues with the tool result instead of starting over
+	mock := testHelpers.NewMockLLMClient(llm.CompletionResponse{Content: "# Structure"})
+	agent.llmClient = mock
+	output, err := agent.RunOnce(context.Background(), "analyze")
+	if err != nil || output != "# Structure" {
+		t.Fatalf("unexpected result %q, %v", output, err)
+	}
+	if mock.CallCount != 1 || len(mock.LastRequest.Messages) != 3 || mock.LastRequest.Messages[2].Role != "tool" {
+		t.Errorf("expected one call with the restored history, got %d call(s) with %+v", mock.CallCount, mock.LastRequest.Messages)
+	}
+
+	// Different prompts start a new conversation
+	mock = testHelpers.NewMockLLMClient(llm.CompletionResponse{Content: "# Other"})
+	agent.llmClient = mock
+	if _, err := agent.RunOnce(context.Background(), "another prompt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.LastRequest.Messages) != 1 {
+		t.Errorf("expected a fresh conversation, got %+v", mock.LastRequest.Messages)
+	}
+}
+
+func TestAnalyzerAgent_Run_Resume(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Dependencies\\n\\nNone"))
+	t.Cleanup(llmServer.Close)
+
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go":                        "package main\nfunc main() {}",
+		".ai/docs/structure_analysis.md": "# Structure\n\nWritten before the interruption",
+		".ai/checkpoints/unrelated.json": "{}",
+	})
+	store := NewCheckpointStore(repoPath)
+	if err := store.MarkCompleted("structure_analyzer", []string{"main.go"}); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers: 1,
+		OnlyAgents: []string{"structure_analyzer", "dependency_analyzer"},
+		Resume:     true,
+	}
+
+	result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"structure", "dependency"} {
+		if !slices.Contains(result.Successful, name) {
+			t.Errorf("expected %s to succeed, got %+v", name, result)
+		}
+	}
+	_, structureRan := result.Usage["structure"]
+	_, dependencyRan := result.Usage["dependency"]
+	if structureRan || !dependencyRan {
+		t.Errorf("expected only the dependency analysis to run, got usage %+v", result.Usage)
+	}
+
+	structure, _ := os.ReadFile(filepath.Join(repoPath, ".ai", "docs", "structure_analysis.md"))
+	if string(structure) != "# Structure\n\nWritten before the interruption" {
+		t.Errorf("expected the completed document to be kept, got:\n%s", structure)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, ".ai", "docs", "dependency_analysis.md")); err != nil {
+		t.Errorf("expected the dependency analysis to be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, CheckpointDir)); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoints to be cleared after a complete run, got %v", err)
+	}
+}
+
+func TestAnalyzerAgent_RepairOutput_KeepsCheckpoint(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{"main.go": "package main"})
+	t.Chdir(repoPath)
+	store := NewCheckpointStore(repoPath)
+	saved := &Checkpoint{Agent: "structure_analyzer", PromptHash: "analysis", Iteration: 2,
+		Messages: []llm.Message{{Role: "user", Content: "analyze"}}}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+
+	promptManager := prompts.NewManagerFromMap(map[string]string{
+		"test_system":            "Test system",
+		"test_user":              "Test user",
+		verificationRepairPrompt: "Fix {{.Unverified}} in {{.Document}}",
+	})
+	agent, err := NewSubAgent(SubAgentConfig{
+		Name:         "StructureAnalyzer",
+		LLMConfig:    config.LLMConfig{Provider: "openai", Model: "gpt-4", APIKey: "test-key"},
+		RepoPath:     repoPath,
+		PromptSuffix: "test",
+	}, &llm.Factory{}, promptManager, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	agent.llmClient = &interruptedClient{}
+	agent.tools = []tools.Tool{tools.NewFileReadTool(1)}
+	agent.EnableCheckpoints(store, "structure_analyzer")
+
+	aa := &AnalyzerAgent{config: config.AnalyzerConfig{BaseConfig: config.BaseConfig{RepoPath: repoPath}}, promptManager: promptManager}
+	if _, err := aa.repairOutput(context.Background(), agent, "# Structure", []string{"missing.go"}); err == nil {
+		t.Fatal("expected the interrupted repair to fail")
+	}
+
+	checkpoint, err := store.Load("structure_analyzer")
+	if err != nil || checkpoint == nil || checkpoint.PromptHash != "analysis" || checkpoint.Iteration != 2 {
+		t.Errorf("expected the repair to leave the analysis checkpoint alone, got %+v, %v", checkpoint, err)
+	}
+}
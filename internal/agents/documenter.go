@@ -3,7 +3,6 @@ package agents
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/user/gendocs/internal/config"
@@ -30,27 +29,12 @@ func NewDocumenterAgent(cfg config.DocumenterConfig, promptManager *prompts.Mana
 
 // Run generates the README
 func (da *DocumenterAgent) Run(ctx context.Context) error {
-	// Pre-load all analysis documents
-	analysisFiles := []string{
-		"structure_analysis.md",
-		"dependency_analysis.md",
-		"data_flow_analysis.md",
-		"request_flow_analysis.md",
-		"api_analysis.md",
-	}
-
-	analysisContent := make(map[string]string)
-	docsDir := filepath.Join(da.config.RepoPath, ".ai/docs")
-
-	for _, filename := range analysisFiles {
-		filePath := filepath.Join(docsDir, filename)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			da.logger.Warn(fmt.Sprintf("Could not read %s: %v", filename, err))
-			continue
-		}
-		analysisContent[filename] = string(content)
+	// Pre-load the analysis documents of all registry agents
+	registry, err := LoadRegistry(da.config.RepoPath)
+	if err != nil {
+		return err
 	}
+	analysisContent, customAnalyses := readAnalysisDocuments(registry, filepath.Join(da.config.RepoPath, ".ai/docs"), da.logger)
 
 	// Setup LLM response caches
 	memoryCache, diskCache, cacheCleanup, err := setupCaches(da.config.LLM, da.logger)
@@ -74,6 +58,7 @@ func (da *DocumenterAgent) Run(ctx context.Context) error {
 	promptData := map[string]interface{}{
 		"RepoPath":        da.config.RepoPath,
 		"AnalysisContent": analysisContent,
+		"CustomAnalyses":  customAnalyses,
 	}
 	userPrompt, err := da.promptManager.Render("documenter_user", promptData)
 	if err != nil {
@@ -62,6 +62,74 @@ func CreateAPIAnalyzer(llmCfg config.LLMConfig, repoPath string, llmFactory *llm
 	return NewSubAgent(cfg, llmFactory, promptManager, logger)
 }
 
+// CreateSecurityAnalyzer creates the security and threat-model analyzer sub-agent
+func CreateSecurityAnalyzer(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
+	cfg := SubAgentConfig{
+		Name:         "SecurityAnalyzer",
+		LLMConfig:    llmCfg,
+		RepoPath:     repoPath,
+		PromptSuffix: "security_analyzer",
+	}
+	return NewSubAgent(cfg, llmFactory, promptManager, logger)
+}
+
+// CreateTestingAnalyzer creates the test suite and quality analyzer sub-agent.
+// The test commands declared in the repository's build files are passed to its prompt.
+func CreateTestingAnalyzer(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
+	cfg := SubAgentConfig{
+		Name:         "TestingAnalyzer",
+		LLMConfig:    llmCfg,
+		RepoPath:     repoPath,
+		PromptSuffix: "testing_analyzer",
+		PromptData: map[string]interface{}{
+			"TestCommands": detectTestCommands(repoPath),
+		},
+	}
+	return NewSubAgent(cfg, llmFactory, promptManager, logger)
+}
+
+// CreateInfrastructureAnalyzer creates the deployment and infrastructure analyzer sub-agent
+func CreateInfrastructureAnalyzer(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
+	cfg := SubAgentConfig{
+		Name:         "InfrastructureAnalyzer",
+		LLMConfig:    llmCfg,
+		RepoPath:     repoPath,
+		PromptSuffix: "infrastructure_analyzer",
+	}
+	return NewSubAgent(cfg, llmFactory, promptManager, logger)
+}
+
+// builtinCreators maps the built-in analysis agents to their constructors
+var builtinCreators = map[string]AgentCreator{
+	"structure_analyzer":      CreateStructureAnalyzer,
+	"dependency_analyzer":     CreateDependencyAnalyzer,
+	"data_flow_analyzer":      CreateDataFlowAnalyzer,
+	"request_flow_analyzer":   CreateRequestFlowAnalyzer,
+	"api_analyzer":            CreateAPIAnalyzer,
+	"security_analyzer":       CreateSecurityAnalyzer,
+	"testing_analyzer":        CreateTestingAnalyzer,
+	"infrastructure_analyzer": CreateInfrastructureAnalyzer,
+}
+
+// NewAgentCreator returns the constructor of a registry agent
+func NewAgentCreator(spec AgentSpec) AgentCreator {
+	if creator, ok := builtinCreators[spec.Name]; ok && spec.BuiltIn {
+		return creator
+	}
+	return func(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
+		cfg := SubAgentConfig{
+			Name:            spec.Name,
+			LLMConfig:       llmCfg,
+			RepoPath:        repoPath,
+			PromptSuffix:    spec.Name,
+			SystemPromptKey: spec.SystemPrompt,
+			UserPromptKey:   spec.UserPrompt,
+			Tools:           spec.Tools,
+		}
+		return NewSubAgent(cfg, llmFactory, promptManager, logger)
+	}
+}
+
 // CreateDocumenterAgent creates the documenter agent (README generator)
 func CreateDocumenterAgent(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
 	cfg := SubAgentConfig{
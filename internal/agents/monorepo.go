@@ -0,0 +1,307 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/modules"
+	"github.com/user/gendocs/internal/prompts"
+)
+
+const (
+	// OverviewAgentName is the aggregation pass of a monorepo analysis
+	OverviewAgentName = "architecture_overview"
+	// OverviewFile is the top-level document written to .ai/docs by the aggregation pass
+	OverviewFile = "architecture_overview.md"
+
+	// maxStructureExcerpt bounds the structure analysis of each module passed to
+	// the overview prompt, so large monorepos still fit the context window
+	maxStructureExcerpt = 4000
+)
+
+// ModuleOverview describes a module to the architecture overview prompt
+type ModuleOverview struct {
+	Name      string
+	Kind      string
+	Structure string             // Excerpt of the module's structure analysis, if any
+	Documents []AnalysisDocument // File is the link from the top-level .ai/docs
+}
+
+// MonorepoAnalyzer runs the analysis once per module of a monorepo, each with
+// its own .ai/docs and cache, then writes a top-level architecture overview
+// that links the module documents
+type MonorepoAnalyzer struct {
+	config        config.AnalyzerConfig
+	promptManager *prompts.Manager
+	logger        *logging.Logger
+	progress      ProgressReporter
+}
+
+// NewMonorepoAnalyzer creates a new monorepo analyzer
+func NewMonorepoAnalyzer(cfg config.AnalyzerConfig, promptManager *prompts.Manager, logger *logging.Logger) *MonorepoAnalyzer {
+	return &MonorepoAnalyzer{
+		config:        cfg,
+		promptManager: promptManager,
+		logger:        logger,
+	}
+}
+
+func (ma *MonorepoAnalyzer) SetProgressReporter(p ProgressReporter) {
+	ma.progress = p
+}
+
+// Run analyzes every module, then the overview. Analysis names in the result
+// are prefixed with their module (e.g. "services/api/structure"); a module
+// that could not be analyzed at all is reported under its own name.
+func (ma *MonorepoAnalyzer) Run(ctx context.Context) (*AnalysisResult, error) {
+	mods, err := modules.Discover(ma.config.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(mods) == 0 {
+		return nil, errors.NewValidationError("no modules found: expected go.mod files, package.json workspaces, a Cargo workspace or pom.xml modules")
+	}
+	ma.logger.Info(fmt.Sprintf("Analyzing %d module(s)", len(mods)))
+
+	result := &AnalysisResult{
+		Successful:   []string{},
+		Failed:       []FailedAnalysis{},
+		Usage:        make(map[string]llm.TokenUsage),
+		UsageSummary: &llm.UsageSummary{},
+	}
+	changed := false
+
+	// The run limits cover every module and the overview together
+	var budget *budgetTotal
+	if ma.config.HasBudget() {
+		budget = newBudgetTotal(ma.config)
+	}
+
+	for _, module := range mods {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		moduleCfg := ma.config
+		moduleCfg.RepoPath = filepath.Join(ma.config.RepoPath, module.Path)
+		moduleCfg.Monorepo = false
+		moduleCfg.ExcludePaths = append(slices.Clone(ma.config.ExcludePaths), modules.NestedPaths(mods, module)...)
+
+		ma.logger.Info(fmt.Sprintf("Analyzing module %s", module.Name), logging.String("kind", module.Kind))
+		analyzer := NewAnalyzerAgent(moduleCfg, ma.promptManager, ma.logger.Named(module.Name))
+		analyzer.budgetTotal = budget
+		if ma.progress != nil {
+			analyzer.SetProgressReporter(moduleProgress{ProgressReporter: ma.progress, module: module.Name})
+		}
+
+		moduleResult, err := analyzer.Run(ctx)
+		if err != nil {
+			ma.logger.Error(fmt.Sprintf("Module %s failed", module.Name), logging.Error(err))
+			result.Failed = append(result.Failed, FailedAnalysis{Name: module.Name, Error: err})
+			continue
+		}
+		result.merge(moduleResult, modulePrefix(module))
+		if len(moduleResult.Usage) > 0 {
+			changed = true
+		}
+	}
+
+	if err := modules.SaveManifest(ma.config.RepoPath, mods); err != nil {
+		ma.logger.Warn(fmt.Sprintf("Failed to save module manifest: %v", err))
+	}
+
+	outputPath := filepath.Join(ma.config.RepoPath, ".ai", "docs", OverviewFile)
+	if _, err := os.Stat(outputPath); err == nil && !changed && !ma.config.Force {
+		ma.logger.Info("No module changed, keeping the architecture overview")
+		result.Skipped = append(result.Skipped, OverviewAgentName)
+		result.Unchanged = len(result.Successful) == 0 && len(result.Failed) == 0
+		return result, nil
+	}
+
+	if err := ma.runOverview(ctx, mods, outputPath, budget, result); err != nil {
+		ma.logger.Error("Architecture overview failed", logging.Error(err))
+		result.Failed = append(result.Failed, FailedAnalysis{Name: OverviewAgentName, Error: err})
+	} else {
+		result.Successful = append(result.Successful, OverviewAgentName)
+	}
+
+	return result, nil
+}
+
+// runOverview writes the architecture overview from the module documents and
+// appends an index linking them
+func (ma *MonorepoAnalyzer) runOverview(ctx context.Context, mods []modules.Module, outputPath string, budget *budgetTotal, result *AnalysisResult) error {
+	overviews := ma.moduleOverviews(mods)
+
+	// The overview runs through an analyzer of the root so it shares the
+	// LLM cache, budget and usage accounting of a regular agent
+	root := NewAnalyzerAgent(ma.config, ma.promptManager, ma.logger)
+	defer root.cacheCleanup()
+	if budget != nil {
+		root.budget = budget.share(root.prices, 1)
+	}
+	if ma.progress != nil {
+		root.SetProgressReporter(ma.progress)
+		ma.progress.AddTask(OverviewAgentName, "Architecture Overview", "Aggregating module analyses")
+	}
+
+	task, _ := root.createTaskWithProgress(ctx, root.llmFactory, OverviewAgentName, newOverviewCreator(overviews), outputPath, nil)
+	_, err := task(ctx)
+
+	summary := root.usageLedger.Summary()
+	result.UsageSummary.Merge(summary, "")
+	for name, usage := range root.usage {
+		result.Usage[name] = usage
+	}
+	for name, report := range root.grounding {
+		if result.Grounding == nil {
+			result.Grounding = make(map[string]GroundingReport)
+		}
+		result.Grounding[name] = report
+	}
+	if err != nil {
+		return err
+	}
+
+	return appendModuleIndex(outputPath, overviews)
+}
+
+// moduleOverviews collects the documents each module has in its .ai/docs
+func (ma *MonorepoAnalyzer) moduleOverviews(mods []modules.Module) []ModuleOverview {
+	var overviews []ModuleOverview
+	for _, module := range mods {
+		modulePath := filepath.Join(ma.config.RepoPath, module.Path)
+		registry, err := LoadRegistry(modulePath)
+		if err != nil {
+			ma.logger.Warn(fmt.Sprintf("Skipping module %s in the overview: %v", module.Name, err))
+			continue
+		}
+
+		overview := ModuleOverview{Name: module.Name, Kind: module.Kind}
+		for _, spec := range registry.Agents() {
+			content, err := os.ReadFile(filepath.Join(modulePath, ".ai", "docs", spec.OutputFile))
+			if err != nil {
+				continue
+			}
+			link := filepath.ToSlash(filepath.Join("..", "..", module.Path, ".ai", "docs", spec.OutputFile))
+			overview.Documents = append(overview.Documents, AnalysisDocument{Title: spec.DisplayName, File: link})
+			if spec.Name == "structure_analyzer" {
+				overview.Structure = excerpt(string(content), maxStructureExcerpt)
+			}
+		}
+		overviews = append(overviews, overview)
+	}
+	return overviews
+}
+
+// newOverviewCreator returns the constructor of the architecture overview agent
+func newOverviewCreator(overviews []ModuleOverview) AgentCreator {
+	return func(llmCfg config.LLMConfig, repoPath string, llmFactory *llm.Factory, promptManager *prompts.Manager, logger *logging.Logger) (*SubAgent, error) {
+		cfg := SubAgentConfig{
+			Name:         "ArchitectureOverview",
+			LLMConfig:    llmCfg,
+			RepoPath:     repoPath,
+			PromptSuffix: "monorepo_overview",
+			PromptData: map[string]interface{}{
+				"Modules": overviews,
+			},
+		}
+		return NewSubAgent(cfg, llmFactory, promptManager, logger)
+	}
+}
+
+// appendModuleIndex appends links to every module document to the overview
+func appendModuleIndex(outputPath string, overviews []ModuleOverview) error {
+	var sb strings.Builder
+	sb.WriteString("\n\n## Module Documentation\n")
+	for _, overview := range overviews {
+		sb.WriteString(fmt.Sprintf("\n### %s\n", overview.Name))
+		if len(overview.Documents) == 0 {
+			sb.WriteString("- No analysis documents yet\n")
+		}
+		for _, doc := range overview.Documents {
+			sb.WriteString(fmt.Sprintf("- [%s](%s)\n", doc.Title, doc.File))
+		}
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open overview: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("failed to write module index: %w", err)
+	}
+	return nil
+}
+
+// excerpt truncates content to about limit bytes at a line boundary
+func excerpt(content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+	cut := content[:limit]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "\n[...]"
+}
+
+// modulePrefix is the prefix of a module's analysis names; the root module's are not prefixed
+func modulePrefix(module modules.Module) string {
+	if module.Name == "." {
+		return ""
+	}
+	return module.Name + "/"
+}
+
+// merge adds the analyses of a module run to the result
+func (r *AnalysisResult) merge(other *AnalysisResult, prefix string) {
+	for _, name := range other.Successful {
+		r.Successful = append(r.Successful, prefix+name)
+	}
+	for _, failed := range other.Failed {
+		r.Failed = append(r.Failed, FailedAnalysis{Name: prefix + failed.Name, Error: failed.Error})
+	}
+	for _, name := range other.Skipped {
+		r.Skipped = append(r.Skipped, prefix+name)
+	}
+	for name, usage := range other.Usage {
+		r.Usage[prefix+name] = usage
+	}
+	if other.UsageSummary != nil {
+		r.UsageSummary.Merge(*other.UsageSummary, prefix)
+	}
+	for name, report := range other.Grounding {
+		if r.Grounding == nil {
+			r.Grounding = make(map[string]GroundingReport)
+		}
+		r.Grounding[prefix+name] = report
+	}
+}
+
+// moduleProgress reports the tasks of one module, prefixing their IDs and names
+// so that agents of different modules stay apart
+type moduleProgress struct {
+	ProgressReporter
+	module string
+}
+
+func (p moduleProgress) AddTask(id, name, description string) {
+	p.ProgressReporter.AddTask(p.module+"/"+id, fmt.Sprintf("[%s] %s", p.module, name), description)
+}
+
+func (p moduleProgress) StartTask(id string)    { p.ProgressReporter.StartTask(p.module + "/" + id) }
+func (p moduleProgress) CompleteTask(id string) { p.ProgressReporter.CompleteTask(p.module + "/" + id) }
+func (p moduleProgress) FailTask(id string, err error) {
+	p.ProgressReporter.FailTask(p.module+"/"+id, err)
+}
+func (p moduleProgress) SkipTask(id string) { p.ProgressReporter.SkipTask(p.module + "/" + id) }
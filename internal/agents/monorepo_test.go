@@ -0,0 +1,156 @@
+package agents
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/modules"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestMonorepoAnalyzer_Run(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Structure\\n\\nOne package"))
+	t.Cleanup(llmServer.Close)
+
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"services/api/go.mod":     "module example.com/api\n",
+		"services/api/handler.go": "package api",
+		"services/web/go.mod":     "module example.com/web\n",
+		"services/web/main.go":    "package main\nfunc main() {}",
+	})
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers: 1,
+		Monorepo:   true,
+		OnlyAgents: []string{"structure_analyzer"},
+	}
+
+	result, err := NewMonorepoAnalyzer(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"services/api/structure", "services/web/structure", OverviewAgentName} {
+		if !slices.Contains(result.Successful, name) {
+			t.Errorf("expected %s to succeed, got %+v", name, result)
+		}
+	}
+	if result.UsageSummary == nil || result.UsageSummary.ByAgent["services/api/StructureAnalyzer"].Calls == 0 {
+		t.Errorf("expected module usage under prefixed agent names, got %+v", result.UsageSummary)
+	}
+
+	// Each module has its own documents and cache
+	for _, module := range []string{"api", "web"} {
+		modulePath := filepath.Join(repoPath, "services", module)
+		if _, err := os.Stat(filepath.Join(modulePath, ".ai", "docs", "structure_analysis.md")); err != nil {
+			t.Errorf("expected the structure analysis of %s: %v", module, err)
+		}
+		if _, err := os.Stat(filepath.Join(modulePath, ".ai", "analysis_cache.json")); err != nil {
+			t.Errorf("expected the cache of %s: %v", module, err)
+		}
+	}
+
+	overview, err := os.ReadFile(filepath.Join(repoPath, ".ai", "docs", OverviewFile))
+	if err != nil {
+		t.Fatalf("expected the architecture overview: %v", err)
+	}
+	if !strings.Contains(string(overview), "- [Structure Analysis](../../services/api/.ai/docs/structure_analysis.md)") {
+		t.Errorf("expected links to the module documents, got:\n%s", overview)
+	}
+	if !modules.HasManifest(repoPath) {
+		t.Error("expected the module manifest to be written")
+	}
+
+	// Nothing changed: the modules and the overview are skipped
+	result, err = NewMonorepoAnalyzer(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Successful) != 0 || !slices.Contains(result.Skipped, OverviewAgentName) || !result.Unchanged {
+		t.Errorf("expected an unchanged monorepo to be skipped, got %+v", result)
+	}
+}
+
+func TestMonorepoAnalyzer_Run_SharesBudget(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	// Each call reports 99,900 input tokens: one module fits the 100,000 token
+	// budget, a second module's prompt on top of it does not
+	var calls atomic.Int32
+	llmServer := testHelpers.NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		testHelpers.SetSSEHeaders(w)
+		testHelpers.WriteSSE(w, "message_start", testHelpers.AnthropicMessageStart(99_900))
+		testHelpers.WriteSSE(w, "content_block_start", testHelpers.AnthropicContentBlockStart(0, "text"))
+		testHelpers.WriteSSE(w, "content_block_delta", testHelpers.AnthropicTextDelta(0, "# Structure"))
+		testHelpers.WriteSSE(w, "content_block_stop", testHelpers.AnthropicContentBlockStop(0))
+		testHelpers.WriteSSE(w, "message_delta", testHelpers.AnthropicMessageDelta("end_turn", 5))
+		testHelpers.WriteSSE(w, "message_stop", testHelpers.AnthropicMessageStop())
+	})
+	t.Cleanup(llmServer.Close)
+
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"services/api/go.mod":     "module example.com/api\n",
+		"services/api/handler.go": "package api",
+		"services/web/go.mod":     "module example.com/web\n",
+		"services/web/main.go":    "package main\nfunc main() {}",
+	})
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		LLM: config.LLMConfig{
+			Provider: "anthropic",
+			Model:    "claude-sonnet-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+		},
+		MaxWorkers:      1,
+		Monorepo:        true,
+		OnlyAgents:      []string{"structure_analyzer"},
+		MaxTokensPerRun: 100_000,
+	}
+
+	result, err := NewMonorepoAnalyzer(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Successful) != 1 || !result.BudgetExceeded() {
+		t.Fatalf("expected the second module and the overview to be stopped by the run budget, got %+v", result)
+	}
+	if len(result.Failed) != 2 || result.Failed[1].Name != OverviewAgentName {
+		t.Errorf("expected one module and the overview to fail, got %+v", result.Failed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected only the first module to reach the LLM, got %d calls", calls.Load())
+	}
+}
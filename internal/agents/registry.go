@@ -0,0 +1,272 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/gendocs/internal/cache"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+// AgentSpec describes an analysis agent: the prompts it runs, the files whose
+// changes trigger a re-run and the document it writes to .ai/docs
+type AgentSpec struct {
+	Name         string // e.g. "structure_analyzer"
+	DisplayName  string
+	Description  string
+	SystemPrompt string
+	UserPrompt   string
+	FilePatterns []string
+	OutputFile   string
+	Tools        []string
+	DependsOn    []string // Agents that run first; their documents are passed to this agent
+	BuiltIn      bool
+}
+
+// AnalysisName returns the name of the agent's analysis (e.g. "structure")
+func (s AgentSpec) AnalysisName() string {
+	return strings.TrimSuffix(s.Name, "_analyzer")
+}
+
+// builtinAgents are the analysis agents shipped with gendocs, in run order.
+// The request flow, API, security and testing analyses build on the repository layout
+// found by the structure analysis instead of re-discovering it.
+var builtinAgents = []AgentSpec{
+	{Name: "structure_analyzer", DisplayName: "Structure Analysis", Description: "Analyzing code structure"},
+	{Name: "dependency_analyzer", DisplayName: "Dependency Analysis", Description: "Analyzing dependencies"},
+	{Name: "data_flow_analyzer", DisplayName: "Data Flow Analysis", Description: "Analyzing data flow"},
+	{Name: "request_flow_analyzer", DisplayName: "Request Flow Analysis", Description: "Analyzing request flow",
+		DependsOn: []string{"structure_analyzer"}},
+	{Name: "api_analyzer", DisplayName: "API Analysis", Description: "Analyzing APIs",
+		DependsOn: []string{"structure_analyzer"}},
+	{Name: "security_analyzer", DisplayName: "Security Analysis", Description: "Analyzing security posture",
+		DependsOn: []string{"structure_analyzer"}},
+	{Name: "testing_analyzer", DisplayName: "Testing Analysis", Description: "Analyzing test suite",
+		DependsOn: []string{"structure_analyzer"}},
+	{Name: "infrastructure_analyzer", DisplayName: "Infrastructure Analysis", Description: "Analyzing build and deployment"},
+}
+
+// Registry holds the built-in agents followed by the custom agents of a project
+type Registry struct {
+	agents []AgentSpec
+}
+
+// NewRegistry returns the built-in agents plus the given custom definitions.
+// Custom agents may not reuse the name or output file of another agent.
+func NewRegistry(defs map[string]config.AgentDefinition) (*Registry, error) {
+	r := &Registry{}
+	outputs := make(map[string]string)
+	for _, spec := range builtinAgents {
+		spec.SystemPrompt = spec.Name + "_system"
+		spec.UserPrompt = spec.Name + "_user"
+		spec.FilePatterns = cache.AgentFilePatterns[spec.Name]
+		spec.OutputFile = spec.AnalysisName() + "_analysis.md"
+		spec.Tools = config.AgentTools
+		spec.BuiltIn = true
+		r.agents = append(r.agents, spec)
+		outputs[spec.OutputFile] = spec.Name
+	}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def := defs[name]
+		if _, exists := r.Get(name); exists {
+			return nil, errors.NewValidationError(fmt.Sprintf("agent %s is built in and cannot be redefined", name))
+		}
+		if other, exists := outputs[def.OutputFile]; exists {
+			return nil, errors.NewValidationError(fmt.Sprintf("agent %s: output_file %s is already written by %s", name, def.OutputFile, other))
+		}
+		outputs[def.OutputFile] = name
+
+		description := def.Description
+		if description == "" {
+			description = "Running " + def.DisplayName
+		}
+		r.agents = append(r.agents, AgentSpec{
+			Name:         name,
+			DisplayName:  def.DisplayName,
+			Description:  description,
+			SystemPrompt: def.SystemPrompt,
+			UserPrompt:   def.UserPrompt,
+			FilePatterns: def.FilePatterns,
+			OutputFile:   def.OutputFile,
+			Tools:        def.Tools,
+			DependsOn:    def.DependsOn,
+		})
+	}
+
+	if err := r.validateDependencies(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// validateDependencies checks that dependencies exist and do not form a cycle
+func (r *Registry) validateDependencies() error {
+	for _, spec := range r.agents {
+		for _, dep := range spec.DependsOn {
+			if _, exists := r.Get(dep); !exists {
+				return errors.NewValidationError(fmt.Sprintf("agent %s depends on unknown agent %s", spec.Name, dep))
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(r.agents))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return errors.NewValidationError(fmt.Sprintf("agent dependency cycle: %s", strings.Join(append(path, name), " -> ")))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		spec, _ := r.Get(name)
+		for _, dep := range spec.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, spec := range r.agents {
+		if err := visit(spec.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadRegistry builds the registry of a repository from its .ai/config.yaml
+func LoadRegistry(repoPath string) (*Registry, error) {
+	defs, err := config.LoadAgentDefinitions(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(defs)
+}
+
+// DefaultRegistry returns a registry with the built-in agents only
+func DefaultRegistry() *Registry {
+	r, _ := NewRegistry(nil)
+	return r
+}
+
+// Agents returns all agents, built-in first
+func (r *Registry) Agents() []AgentSpec {
+	return r.agents
+}
+
+// Custom returns the agents declared in the project configuration
+func (r *Registry) Custom() []AgentSpec {
+	var custom []AgentSpec
+	for _, spec := range r.agents {
+		if !spec.BuiltIn {
+			custom = append(custom, spec)
+		}
+	}
+	return custom
+}
+
+// Get returns the agent with the given name
+func (r *Registry) Get(name string) (AgentSpec, bool) {
+	for _, spec := range r.agents {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return AgentSpec{}, false
+}
+
+// ForAnalysis returns the agent writing an analysis, by analysis name ("data_flow")
+// or agent name ("data_flow_analyzer")
+func (r *Registry) ForAnalysis(name string) (AgentSpec, bool) {
+	for _, spec := range r.agents {
+		if spec.Name == name || spec.AnalysisName() == name {
+			return spec, true
+		}
+	}
+	return AgentSpec{}, false
+}
+
+// Names returns the names of all agents
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.agents))
+	for _, spec := range r.agents {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+// FilePatterns maps each agent to the file patterns that trigger a re-run
+func (r *Registry) FilePatterns() map[string][]string {
+	patterns := make(map[string][]string, len(r.agents))
+	for _, spec := range r.agents {
+		patterns[spec.Name] = spec.FilePatterns
+	}
+	return patterns
+}
+
+// AnalysisDocument is the document of a custom agent, passed to the README and
+// AI rules prompts as CustomAnalyses
+type AnalysisDocument struct {
+	Title   string
+	File    string
+	Content string
+}
+
+// readAnalysisDocuments reads the documents of all registry agents from docsDir.
+// It returns the contents keyed by file name (AnalysisContent) and the documents
+// of custom agents. Missing documents are logged and left out.
+func readAnalysisDocuments(registry *Registry, docsDir string, logger *logging.Logger) (map[string]string, []AnalysisDocument) {
+	analysisContent := make(map[string]string)
+	var custom []AnalysisDocument
+
+	for _, spec := range registry.Agents() {
+		content, err := os.ReadFile(filepath.Join(docsDir, spec.OutputFile))
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Could not read %s: %v", spec.OutputFile, err))
+			continue
+		}
+		analysisContent[spec.OutputFile] = string(content)
+		if !spec.BuiltIn {
+			custom = append(custom, AnalysisDocument{Title: spec.DisplayName, File: spec.OutputFile, Content: string(content)})
+		}
+	}
+
+	return analysisContent, custom
+}
+
+// upstreamDocuments reads the documents of the agents spec depends on. Documents
+// from earlier runs are used when an upstream agent did not run or failed.
+func upstreamDocuments(registry *Registry, spec AgentSpec, docsDir string) []AnalysisDocument {
+	var docs []AnalysisDocument
+	for _, dep := range spec.DependsOn {
+		upstream, ok := registry.Get(dep)
+		if !ok {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(docsDir, upstream.OutputFile))
+		if err != nil {
+			continue
+		}
+		docs = append(docs, AnalysisDocument{Title: upstream.DisplayName, File: upstream.OutputFile, Content: string(content)})
+	}
+	return docs
+}
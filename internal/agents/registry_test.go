@@ -0,0 +1,238 @@
+package agents
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/gendocs/internal/cache"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestNewRegistry_CustomAgents(t *testing.T) {
+	registry, err := NewRegistry(map[string]config.AgentDefinition{
+		"license_analyzer": {
+			DisplayName:  "License Analysis",
+			SystemPrompt: "license_analyzer_system",
+			UserPrompt:   "license_analyzer_user",
+			FilePatterns: []string{"LICENSE*"},
+			OutputFile:   "license_analysis.md",
+			Tools:        []string{"read_file"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := registry.Names()
+	if len(names) != 9 || names[0] != "structure_analyzer" || names[8] != "license_analyzer" {
+		t.Fatalf("expected the built-in agents followed by the custom one, got %v", names)
+	}
+	if custom := registry.Custom(); len(custom) != 1 || custom[0].AnalysisName() != "license" {
+		t.Errorf("unexpected custom agents: %+v", custom)
+	}
+	if patterns := registry.FilePatterns(); len(patterns["license_analyzer"]) != 1 || len(patterns["api_analyzer"]) == 0 {
+		t.Errorf("unexpected file patterns: %v", patterns)
+	}
+}
+
+func TestNewRegistry_RejectsConflicts(t *testing.T) {
+	tests := map[string]config.AgentDefinition{
+		"api_analyzer":    {FilePatterns: []string{"*.go"}, OutputFile: "custom_api.md"},
+		"second_analyzer": {FilePatterns: []string{"*.go"}, OutputFile: "structure_analysis.md"},
+	}
+
+	for name, def := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewRegistry(map[string]config.AgentDefinition{name: def}); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestNewRegistry_Dependencies(t *testing.T) {
+	api, _ := DefaultRegistry().Get("api_analyzer")
+	if len(api.DependsOn) != 1 || api.DependsOn[0] != "structure_analyzer" {
+		t.Errorf("expected the API analysis to depend on the structure analysis, got %v", api.DependsOn)
+	}
+
+	tests := map[string]map[string]config.AgentDefinition{
+		"unknown agent": {
+			"first_analyzer": {FilePatterns: []string{"*.go"}, OutputFile: "first.md", DependsOn: []string{"missing_analyzer"}},
+		},
+		"cycle": {
+			"first_analyzer":  {FilePatterns: []string{"*.go"}, OutputFile: "first.md", DependsOn: []string{"second_analyzer"}},
+			"second_analyzer": {FilePatterns: []string{"*.go"}, OutputFile: "second.md", DependsOn: []string{"first_analyzer"}},
+		},
+	}
+
+	for name, defs := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewRegistry(defs); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestAnalyzerAgent_Run_InjectsUpstreamDocuments(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	var mu sync.Mutex
+	var downstreamBodies []string
+	llmServer := testHelpers.NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "CONTEXT FROM EARLIER ANALYSES") {
+			mu.Lock()
+			downstreamBodies = append(downstreamBodies, string(body))
+			mu.Unlock()
+			testHelpers.OpenAIStreamHandler("# API\\n\\nEndpoints")(w, r)
+			return
+		}
+		testHelpers.OpenAIStreamHandler("# Structure\\n\\nLayout: cmd and internal")(w, r)
+	})
+	t.Cleanup(llmServer.Close)
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers:      2,
+		ExcludeDeps:     true,
+		ExcludeDataFlow: true,
+		ExcludeReqFlow:  true,
+		ExcludeSecurity: true,
+		ExcludeTesting:  true,
+		ExcludeInfra:    true,
+	}
+
+	result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Successful) != 2 {
+		t.Fatalf("expected both analyses to succeed, got %+v", result)
+	}
+
+	// The API analysis starts after the structure analysis and sees its document
+	if len(downstreamBodies) != 1 || !strings.Contains(downstreamBodies[0], "Layout: cmd and internal") {
+		t.Errorf("expected the structure document in the API analysis prompt, got %v", downstreamBodies)
+	}
+}
+
+func TestAnalyzerAgent_Run_CustomAgent(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Licenses\\n\\nMIT only"))
+	t.Cleanup(llmServer.Close)
+
+	repoPath := testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())
+	writeFile(t, filepath.Join(repoPath, ".ai", "config.yaml"), `
+agents:
+  license_analyzer:
+    display_name: License Analysis
+    file_patterns: ["*.go"]
+    output_file: license_report.md
+    tools: [read_file, list_files]
+`)
+	writeFile(t, filepath.Join(repoPath, ".ai", "prompts", "license.yaml"), `
+license_analyzer_system: You review the licenses of a project and its dependencies.
+license_analyzer_user: Review the project at {{ .RepoPath }}.
+`)
+
+	promptManager, err := prompts.NewManagerWithOverrides("./prompts", filepath.Join(repoPath, ".ai", "prompts"))
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers:       1,
+		ExcludeStructure: true,
+		ExcludeDeps:      true,
+		ExcludeDataFlow:  true,
+		ExcludeReqFlow:   true,
+		ExcludeAPI:       true,
+		ExcludeSecurity:  true,
+		ExcludeTesting:   true,
+		ExcludeInfra:     true,
+	}
+
+	result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Successful) != 1 || result.Successful[0] != "license" {
+		t.Fatalf("expected only the license analysis to run, got %+v", result)
+	}
+
+	output, err := os.ReadFile(filepath.Join(repoPath, ".ai", "docs", "license_report.md"))
+	if err != nil || !strings.Contains(string(output), "MIT only") {
+		t.Errorf("expected the custom agent's document to be written, got %q (%v)", output, err)
+	}
+
+	analysisCache, err := cache.LoadCache(repoPath)
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	if status := analysisCache.Agents["license_analyzer"]; !status.Success {
+		t.Errorf("expected the custom agent to be recorded in the cache, got %+v", status)
+	}
+}
+
+func TestDocumenterPrompt_CustomAnalyses(t *testing.T) {
+	promptManager, err := prompts.NewManager(filepath.Join("..", "..", "prompts"))
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	rendered, err := promptManager.Render("documenter_user", map[string]interface{}{
+		"RepoPath":        "/repo",
+		"AnalysisContent": map[string]string{"api_analysis.md": "API content"},
+		"CustomAnalyses":  []AnalysisDocument{{Title: "License Analysis", Content: "License content"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to render prompt: %v", err)
+	}
+	if !strings.Contains(rendered, "## License Analysis\nLicense content") {
+		t.Errorf("expected the custom analysis in the prompt, got:\n%s", rendered)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,167 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/gendocs/internal/cache"
+)
+
+// sectionUpdatePrompt is appended to the user prompt of an agent updating its previous document
+const sectionUpdatePrompt = "analyzer_section_update"
+
+// noSectionChanges is the answer of an agent when no section of its document needs to change
+const noSectionChanges = "NO_CHANGES"
+
+// maxSectionUpdateDiff bounds the diff passed to a section update; larger
+// changes regenerate the whole document
+const maxSectionUpdateDiff = 40000
+
+// sectionUpdate is what an agent needs to regenerate only the sections of its
+// previous document affected by a change
+type sectionUpdate struct {
+	Document string   // Previous document, without the grounding footer
+	Files    []string // Changed files the agent cares about
+	Diff     string
+}
+
+// apply merges the sections returned by the agent into the previous document
+// and returns the headings that changed
+func (u *sectionUpdate) apply(output string) (string, []string, error) {
+	output = strings.TrimSpace(output)
+	if output == noSectionChanges {
+		return u.Document, nil, nil
+	}
+	output = cleanLLMOutput(output)
+	if strings.TrimSpace(output) == noSectionChanges {
+		return u.Document, nil, nil
+	}
+	return mergeSections(u.Document, output)
+}
+
+// section is a "## " heading and its content up to the next one
+type section struct {
+	heading string // Empty for the text before the first heading
+	content string // Exact bytes, heading line included
+}
+
+// splitSections splits a markdown document at its "## " headings outside
+// fenced code blocks. Joining the contents gives back the document.
+func splitSections(markdown string) []section {
+	sections := []section{{}}
+	inFence := false
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "## ") {
+			sections = append(sections, section{heading: trimmed})
+		}
+		sections[len(sections)-1].content += line
+	}
+	if sections[0].content == "" {
+		sections = sections[1:]
+	}
+	return sections
+}
+
+// headingKey compares headings regardless of case and spacing
+func headingKey(heading string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.TrimLeft(heading, "#")), " "))
+}
+
+// mergeSections replaces the sections of previous that update rewrites, keeping
+// the exact bytes of every other section, and appends sections with new
+// headings. Text of update before its first heading (e.g. the title) is ignored.
+func mergeSections(previous, update string) (string, []string, error) {
+	sections := splitSections(previous)
+	index := make(map[string]int, len(sections))
+	for i, s := range sections {
+		if s.heading != "" {
+			index[headingKey(s.heading)] = i
+		}
+	}
+
+	var changed []string
+	var added []string
+	found := false
+	for _, s := range splitSections(update) {
+		if s.heading == "" {
+			continue
+		}
+		found = true
+		body := strings.TrimRight(s.content, "\n")
+
+		i, ok := index[headingKey(s.heading)]
+		if !ok {
+			added = append(added, body)
+			changed = append(changed, s.heading)
+			continue
+		}
+		current := sections[i].content
+		trailing := current[len(strings.TrimRight(current, "\n")):]
+		if body+trailing != current {
+			sections[i].content = body + trailing
+			changed = append(changed, s.heading)
+		}
+	}
+	if !found {
+		return "", nil, fmt.Errorf("section update returned no sections")
+	}
+
+	var sb strings.Builder
+	for _, s := range sections {
+		sb.WriteString(s.content)
+	}
+	merged := sb.String()
+	if len(added) > 0 {
+		merged = strings.TrimRight(merged, "\n") + "\n\n" + strings.Join(added, "\n\n") + "\n"
+	}
+	return merged, changed, nil
+}
+
+// stripGroundingFooter removes the grounding score the verification pass appended
+func stripGroundingFooter(markdown string) string {
+	i := strings.LastIndex(markdown, "\n\n"+groundingFooterPrefix)
+	if i < 0 || !strings.HasSuffix(strings.TrimSpace(markdown), "-->") || strings.Contains(markdown[i+2:], "\n\n") {
+		return markdown
+	}
+	return markdown[:i]
+}
+
+// changeDiff describes the changes of files since the last analysis: the git
+// diff from the analyzed commit when available, and the current content of
+// the files it does not cover (untracked, or changed without a commit)
+func changeDiff(repoPath string, report *cache.ChangeReport, files []string) string {
+	var sb strings.Builder
+	covered := make(map[string]bool)
+	if report.PreviousCommit != "" {
+		if diff, err := cache.GitDiff(repoPath, report.PreviousCommit, files); err == nil {
+			sb.WriteString(diff)
+			for _, line := range strings.Split(diff, "\n") {
+				if rest, ok := strings.CutPrefix(line, "diff --git a/"); ok {
+					if i := strings.Index(rest, " b/"); i >= 0 {
+						covered[rest[:i]] = true
+						covered[rest[i+3:]] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		if covered[filepath.ToSlash(file)] {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			fmt.Fprintf(&sb, "--- %s\n(deleted)\n", filepath.ToSlash(file))
+			continue
+		}
+		fmt.Fprintf(&sb, "--- %s (current content)\n%s\n", filepath.ToSlash(file), content)
+	}
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package agents
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+const previousAPIDocument = `# API Analysis
+
+Overview paragraph.
+
+## Endpoints
+
+| Method | Path |
+|--------|------|
+| GET | /users |
+
+## Authentication
+
+Bearer tokens.
+
+` + "```go" + `
+## Not a heading inside a fence
+` + "```" + `
+
+## Error Handling
+
+Errors are JSON.
+`
+
+func TestSplitSections(t *testing.T) {
+	sections := splitSections(previousAPIDocument)
+
+	var headings []string
+	var joined strings.Builder
+	for _, s := range sections {
+		headings = append(headings, s.heading)
+		joined.WriteString(s.content)
+	}
+	want := []string{"", "## Endpoints", "## Authentication", "## Error Handling"}
+	if strings.Join(headings, "|") != strings.Join(want, "|") {
+		t.Errorf("headings = %q, want %q", headings, want)
+	}
+	if joined.String() != previousAPIDocument {
+		t.Error("expected the sections to join back into the exact document")
+	}
+}
+
+func TestMergeSections(t *testing.T) {
+	update := "# API Analysis\n\n## endpoints\n\n| Method | Path |\n|--------|------|\n| GET | /users |\n| POST | /users |\n\n## Rate Limiting\n\nNone.\n"
+
+	merged, changed, err := mergeSections(previousAPIDocument, update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(changed, "|") != "## endpoints|## Rate Limiting" {
+		t.Errorf("unexpected changed headings %q", changed)
+	}
+
+	// Only the rewritten section differs; the new one is appended
+	want := strings.Replace(previousAPIDocument,
+		"## Endpoints\n\n| Method | Path |\n|--------|------|\n| GET | /users |\n",
+		"## endpoints\n\n| Method | Path |\n|--------|------|\n| GET | /users |\n| POST | /users |\n", 1) +
+		"\n## Rate Limiting\n\nNone.\n"
+	if merged != want {
+		t.Errorf("unexpected merge:\n%s\nwant:\n%s", merged, want)
+	}
+
+	// A section returned unchanged is not reported
+	_, changed, err = mergeSections(previousAPIDocument, "## Error Handling\n\nErrors are JSON.")
+	if err != nil || len(changed) != 0 {
+		t.Errorf("expected no changes, got %q, %v", changed, err)
+	}
+
+	if _, _, err := mergeSections(previousAPIDocument, "Nothing to see"); err == nil {
+		t.Error("expected an error for an answer without sections")
+	}
+}
+
+func TestSectionUpdate_Apply(t *testing.T) {
+	update := &sectionUpdate{Document: previousAPIDocument}
+	for _, output := range []string{"NO_CHANGES", "  NO_CHANGES\n"} {
+		doc, changed, err := update.apply(output)
+		if err != nil || doc != previousAPIDocument || len(changed) != 0 {
+			t.Errorf("apply(%q) = %d changes, %v; expected the previous document", output, len(changed), err)
+		}
+	}
+}
+
+func TestStripGroundingFooter(t *testing.T) {
+	doc := "# Doc\n\nText"
+	footer := groundingFooter(GroundingReport{References: 2, Verified: 2})
+	if got := stripGroundingFooter(doc + footer); got != doc {
+		t.Errorf("expected the footer to be removed, got %q", got)
+	}
+	if got := stripGroundingFooter(doc); got != doc {
+		t.Errorf("expected a document without footer to be unchanged, got %q", got)
+	}
+}
+
+func TestAnalyzerAgent_Run_SectionUpdates(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"user_handler.go": "package api\n\nfunc ListUsers() {}\n",
+	})
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	run := func(answer string) {
+		t.Helper()
+		llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler(answer))
+		defer llmServer.Close()
+
+		cfg := config.AnalyzerConfig{
+			BaseConfig: config.BaseConfig{RepoPath: repoPath},
+			LLM: config.LLMConfig{
+				Provider: "openai",
+				Model:    "gpt-4",
+				APIKey:   "test-key",
+				BaseURL:  llmServer.URL,
+				Retries:  1,
+			},
+			MaxWorkers:     1,
+			OnlyAgents:     []string{"api_analyzer"},
+			SectionUpdates: true,
+		}
+		result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+		if err != nil || len(result.Failed) > 0 {
+			t.Fatalf("unexpected failure: %v %+v", err, result)
+		}
+	}
+
+	// The first run writes the whole document
+	run(strings.ReplaceAll(previousAPIDocument, "\n", "\\n"))
+	docPath := filepath.Join(repoPath, ".ai", "docs", "api_analysis.md")
+	first, err := os.ReadFile(docPath)
+	if err != nil {
+		t.Fatalf("expected the API analysis: %v", err)
+	}
+
+	// A handler change only rewrites the Endpoints section
+	if err := os.WriteFile(filepath.Join(repoPath, "user_handler.go"), []byte("package api\n\nfunc ListUsers() {}\nfunc CreateUser() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to modify handler: %v", err)
+	}
+	run("## Endpoints\\n\\n| Method | Path |\\n|--------|------|\\n| GET | /users |\\n| POST | /users |")
+
+	second, err := os.ReadFile(docPath)
+	if err != nil {
+		t.Fatalf("failed to read document: %v", err)
+	}
+	want := strings.Replace(string(first), "| GET | /users |\n", "| GET | /users |\n| POST | /users |\n", 1)
+	if string(second) != want {
+		t.Errorf("expected only the Endpoints section to change, got:\n%s\nwant:\n%s", second, want)
+	}
+}
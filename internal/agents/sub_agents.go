@@ -2,13 +2,16 @@ package agents
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
 	"github.com/user/gendocs/internal/llm"
 	"github.com/user/gendocs/internal/llmcache"
 	"github.com/user/gendocs/internal/logging"
@@ -22,12 +25,60 @@ type SubAgentConfig struct {
 	LLMConfig    config.LLMConfig
 	RepoPath     string
 	PromptSuffix string // e.g., "structure_analyzer"
+
+	// Custom agents name their prompts and tools explicitly. Empty values
+	// default to PromptSuffix+"_system", PromptSuffix+"_user" and all tools.
+	SystemPromptKey string
+	UserPromptKey   string
+	Tools           []string
+
+	// PromptData holds extra variables for the user prompt besides RepoPath
+	PromptData map[string]interface{}
+}
+
+func (c SubAgentConfig) systemPromptKey() string {
+	if c.SystemPromptKey != "" {
+		return c.SystemPromptKey
+	}
+	return c.PromptSuffix + "_system"
+}
+
+func (c SubAgentConfig) userPromptKey() string {
+	if c.UserPromptKey != "" {
+		return c.UserPromptKey
+	}
+	return c.PromptSuffix + "_user"
+}
+
+// newAgentTools creates the named tools, or all of them when names is empty
+func newAgentTools(names []string, repoPath string) []tools.Tool {
+	all := []tools.Tool{
+		tools.NewFileReadTool(2),
+		tools.NewListFilesTool(2),
+		tools.NewSearchFilesTool(repoPath, 2),
+	}
+	if len(names) == 0 {
+		return all
+	}
+
+	var selected []tools.Tool
+	for _, tool := range all {
+		if slices.Contains(names, tool.Name()) {
+			selected = append(selected, tool)
+		}
+	}
+	return selected
 }
 
+// upstreamContextPrompt renders the documents of upstream agents into a user prompt
+const upstreamContextPrompt = "analyzer_upstream_context"
+
 // SubAgent is a specialized analysis agent
 type SubAgent struct {
 	*BaseAgent
-	config SubAgentConfig
+	config   SubAgentConfig
+	upstream []AnalysisDocument // Documents of the agents this one depends on
+	update   *sectionUpdate     // Set to update the previous document instead of rewriting it
 }
 
 // NewSubAgent creates a new sub-agent
@@ -39,14 +90,10 @@ func NewSubAgent(cfg SubAgentConfig, llmFactory *llm.Factory, promptManager *pro
 	}
 
 	// Create tools
-	toolList := []tools.Tool{
-		tools.NewFileReadTool(2),
-		tools.NewListFilesTool(2),
-		tools.NewSearchFilesTool(cfg.RepoPath, 2),
-	}
+	toolList := newAgentTools(cfg.Tools, cfg.RepoPath)
 
 	// Load system prompt
-	systemPrompt, err := promptManager.Get(cfg.PromptSuffix + "_system")
+	systemPrompt, err := promptManager.Get(cfg.systemPromptKey())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load system prompt: %w", err)
 	}
@@ -60,6 +107,9 @@ func NewSubAgent(cfg SubAgentConfig, llmFactory *llm.Factory, promptManager *pro
 		systemPrompt,
 		cfg.LLMConfig.GetRetries(),
 	)
+	if err := baseAgent.SetModel(cfg.LLMConfig); err != nil {
+		return nil, err
+	}
 
 	return &SubAgent{
 		BaseAgent: baseAgent,
@@ -112,12 +162,40 @@ func setupCaches(llmCfg config.LLMConfig, logger *logging.Logger) (*llmcache.LRU
 // Run executes the sub-agent
 func (sa *SubAgent) Run(ctx context.Context) (string, error) {
 	// Render user prompt with variables
-	userPrompt, err := sa.promptManager.Render(sa.config.PromptSuffix+"_user", map[string]interface{}{
+	data := map[string]interface{}{
 		"RepoPath": sa.config.RepoPath,
-	})
+	}
+	for key, value := range sa.config.PromptData {
+		data[key] = value
+	}
+	userPrompt, err := sa.promptManager.Render(sa.config.userPromptKey(), data)
 	if err != nil {
 		return "", fmt.Errorf("failed to render user prompt: %w", err)
 	}
+	if len(sa.upstream) > 0 && sa.promptManager.HasPrompt(upstreamContextPrompt) {
+		upstreamContext, err := sa.promptManager.Render(upstreamContextPrompt, map[string]interface{}{
+			"Analyses": sa.upstream,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render upstream context: %w", err)
+		}
+		userPrompt += "\n\n" + upstreamContext
+	}
+	update := sa.update
+	if update != nil && !sa.promptManager.HasPrompt(sectionUpdatePrompt) {
+		update = nil
+	}
+	if update != nil {
+		updateContext, err := sa.promptManager.Render(sectionUpdatePrompt, map[string]interface{}{
+			"Document": update.Document,
+			"Files":    update.Files,
+			"Diff":     update.Diff,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render section update: %w", err)
+		}
+		userPrompt += "\n\n" + updateContext
+	}
 
 	// Run with retry logic and exponential backoff
 	var lastErr error
@@ -125,6 +203,13 @@ func (sa *SubAgent) Run(ctx context.Context) (string, error) {
 		sa.logger.Info(fmt.Sprintf("Running sub-agent %s (attempt %d/%d)", sa.config.Name, attempt+1, sa.maxRetries))
 
 		result, err := sa.RunOnce(ctx, userPrompt)
+		if err == nil && update != nil {
+			var changed []string
+			if result, changed, err = update.apply(result); err == nil {
+				sa.logger.Info(fmt.Sprintf("Sub-agent %s updated %d section(s)", sa.config.Name, len(changed)),
+					logging.String("sections", strings.Join(changed, ", ")))
+			}
+		}
 		if err == nil {
 			sa.logger.Info(fmt.Sprintf("Sub-agent %s completed successfully", sa.config.Name))
 			return result, nil
@@ -133,6 +218,15 @@ func (sa *SubAgent) Run(ctx context.Context) (string, error) {
 		lastErr = err
 		sa.logger.Warn(fmt.Sprintf("Sub-agent %s attempt %d failed: %v", sa.config.Name, attempt+1, err))
 
+		// A stopped budget or a cancelled run is not retried
+		var budgetErr *errors.BudgetExceededError
+		if stderrors.As(err, &budgetErr) {
+			return "", err
+		}
+		if ctx.Err() != nil {
+			return "", context.Cause(ctx)
+		}
+
 		// Exponential backoff before next attempt: 30s, 60s, 120s...
 		if attempt < sa.maxRetries-1 {
 			backoff := time.Duration(30<<attempt) * time.Second
@@ -143,7 +237,7 @@ func (sa *SubAgent) Run(ctx context.Context) (string, error) {
 			select {
 			case <-time.After(backoff):
 			case <-ctx.Done():
-				return "", ctx.Err()
+				return "", context.Cause(ctx)
 			}
 		}
 	}
@@ -151,6 +245,29 @@ func (sa *SubAgent) Run(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("sub-agent %s failed after %d retries: %w", sa.config.Name, sa.maxRetries, lastErr)
 }
 
+// FilesAnalyzed returns the repository files the agent read through its tools,
+// as slash-separated paths relative to the repository root
+func (sa *SubAgent) FilesAnalyzed() []string {
+	root, err := filepath.Abs(sa.config.RepoPath)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range sa.FilesRead() {
+		// Relative paths are resolved like read_file does, from the working directory
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files
+}
+
 // SaveOutput saves the agent output to a file
 func (sa *SubAgent) SaveOutput(output, outputPath string) error {
 	// Clean the output to remove unwanted preambles and code fences
@@ -0,0 +1,55 @@
+package agents
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/llm"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+	"github.com/user/gendocs/internal/tools"
+)
+
+// TestSubAgent_FilesAnalyzed tests that files read through tools are recorded relative to the repository
+func TestSubAgent_FilesAnalyzed(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"internal/server.go": "package internal",
+	})
+
+	mockClient := testHelpers.NewMockLLMClient(
+		llm.CompletionResponse{ToolCalls: []llm.ToolCall{
+			{Name: "read_file", Arguments: map[string]interface{}{"file_path": filepath.Join(repoPath, "internal", "server.go")}},
+			{Name: "read_file", Arguments: map[string]interface{}{"file_path": filepath.Join(repoPath, "missing.go")}},
+			{Name: "read_file", Arguments: map[string]interface{}{"file_path": "/etc/hostname"}},
+		}},
+		llm.CompletionResponse{Content: "# Analysis"},
+	)
+
+	promptManager := prompts.NewManagerFromMap(map[string]string{
+		"test_system": "Test system",
+		"test_user":   "Test user",
+	})
+	subAgent, err := NewSubAgent(SubAgentConfig{
+		Name:         "TestAgent",
+		LLMConfig:    config.LLMConfig{Provider: "openai", Model: "gpt-4", APIKey: "test-key"},
+		RepoPath:     repoPath,
+		PromptSuffix: "test",
+	}, &llm.Factory{}, promptManager, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	subAgent.BaseAgent.llmClient = mockClient
+	subAgent.BaseAgent.tools = []tools.Tool{tools.NewFileReadTool(1)}
+
+	if _, err := subAgent.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	files := subAgent.FilesAnalyzed()
+	if len(files) != 1 || files[0] != "internal/server.go" {
+		t.Errorf("Expected only internal/server.go to be recorded, got %v", files)
+	}
+}
@@ -0,0 +1,100 @@
+package agents
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TestCommand is a way of running a project's tests, found in its build metadata
+type TestCommand struct {
+	Command string // e.g. "go test ./..."
+	Source  string // File the command was derived from, e.g. "go.mod"
+}
+
+// makeTestTarget matches Makefile targets such as "test:", "test-integration:" or "coverage:"
+var makeTestTarget = regexp.MustCompile(`^([A-Za-z0-9_.-]*(?:test|coverage|bench)[A-Za-z0-9_.-]*)\s*:([^=]|$)`)
+
+// detectTestCommands reads the test commands a repository declares in its root
+// build files (go.mod, package.json scripts, Makefile targets, Python and Rust
+// configuration), so the testing analysis documents the project's own commands
+// rather than guessing them.
+func detectTestCommands(repoPath string) []TestCommand {
+	var commands []TestCommand
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(repoPath, name))
+		return err == nil
+	}
+
+	if exists("go.mod") {
+		commands = append(commands, TestCommand{Command: "go test ./...", Source: "go.mod"})
+	}
+	commands = append(commands, packageJSONTestCommands(filepath.Join(repoPath, "package.json"))...)
+	commands = append(commands, makefileTestCommands(filepath.Join(repoPath, "Makefile"))...)
+	for _, name := range []string{"pytest.ini", "conftest.py", "tox.ini"} {
+		if exists(name) {
+			commands = append(commands, TestCommand{Command: "pytest", Source: name})
+			break
+		}
+	}
+	if exists("Cargo.toml") {
+		commands = append(commands, TestCommand{Command: "cargo test", Source: "Cargo.toml"})
+	}
+
+	return commands
+}
+
+// packageJSONTestCommands returns the npm scripts that run tests or coverage
+func packageJSONTestCommands(path string) []TestCommand {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "test") || strings.Contains(lower, "coverage") || strings.Contains(lower, "e2e") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	commands := make([]TestCommand, 0, len(names))
+	for _, name := range names {
+		command := "npm run " + name
+		if name == "test" {
+			command = "npm test"
+		}
+		commands = append(commands, TestCommand{Command: command + " (" + pkg.Scripts[name] + ")", Source: "package.json"})
+	}
+	return commands
+}
+
+// makefileTestCommands returns the Makefile targets that run tests, benchmarks or coverage
+func makefileTestCommands(path string) []TestCommand {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var commands []TestCommand
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if match := makeTestTarget.FindStringSubmatch(scanner.Text()); match != nil {
+			commands = append(commands, TestCommand{Command: "make " + match[1], Source: "Makefile"})
+		}
+	}
+	return commands
+}
@@ -0,0 +1,62 @@
+package agents
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/gendocs/internal/prompts"
+)
+
+func TestDetectTestCommands(t *testing.T) {
+	repoPath := t.TempDir()
+	writeFile(t, filepath.Join(repoPath, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(repoPath, "package.json"), `{
+  "scripts": {"build": "tsc", "test": "jest", "test:e2e": "playwright test"}
+}`)
+	writeFile(t, filepath.Join(repoPath, "Makefile"), `.PHONY: test
+GOTEST := go test
+build:
+	go build ./...
+test:
+	$(GOTEST) ./...
+test-integration: build
+	$(GOTEST) -tags=integration ./...
+`)
+	writeFile(t, filepath.Join(repoPath, "pytest.ini"), "[pytest]\n")
+
+	want := []TestCommand{
+		{Command: "go test ./...", Source: "go.mod"},
+		{Command: "npm test (jest)", Source: "package.json"},
+		{Command: "npm run test:e2e (playwright test)", Source: "package.json"},
+		{Command: "make test", Source: "Makefile"},
+		{Command: "make test-integration", Source: "Makefile"},
+		{Command: "pytest", Source: "pytest.ini"},
+	}
+	if got := detectTestCommands(repoPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("detectTestCommands() = %+v, want %+v", got, want)
+	}
+
+	if got := detectTestCommands(t.TempDir()); len(got) != 0 {
+		t.Errorf("expected no commands for an empty repository, got %+v", got)
+	}
+}
+
+func TestTestingAnalyzerPrompt_TestCommands(t *testing.T) {
+	promptManager, err := prompts.NewManager(filepath.Join("..", "..", "prompts"))
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	rendered, err := promptManager.Render("testing_analyzer_user", map[string]interface{}{
+		"RepoPath":     "/repo",
+		"TestCommands": []TestCommand{{Command: "go test ./...", Source: "go.mod"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to render prompt: %v", err)
+	}
+	if !strings.Contains(rendered, "- `go test ./...` (go.mod)") {
+		t.Errorf("expected the detected command in the prompt, got:\n%s", rendered)
+	}
+}
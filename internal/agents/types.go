@@ -1,7 +1,10 @@
 package agents
 
 import (
+	stderrors "errors"
+
 	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
 	"github.com/user/gendocs/internal/llm"
 	"github.com/user/gendocs/internal/logging"
 	"github.com/user/gendocs/internal/prompts"
@@ -9,8 +12,37 @@ import (
 
 // AnalysisResult represents the result of an analysis
 type AnalysisResult struct {
-	Successful []string
-	Failed     []FailedAnalysis
+	Successful   []string
+	Failed       []FailedAnalysis
+	Skipped      []string                   // Analyses skipped because their inputs did not change
+	Unchanged    bool                       // Nothing changed since the last run, so no analysis ran
+	Usage        map[string]llm.TokenUsage  // Token usage per analysis that ran, including failed ones
+	UsageSummary *llm.UsageSummary          // Usage per agent, model and iteration, with estimated cost
+	Grounding    map[string]GroundingReport // Per analysis, when verification is enabled
+}
+
+// TotalUsage returns the token usage summed over all analyses
+func (r *AnalysisResult) TotalUsage() llm.TokenUsage {
+	var total llm.TokenUsage
+	for _, usage := range r.Usage {
+		total.InputTokens += usage.InputTokens
+		total.OutputTokens += usage.OutputTokens
+		total.TotalTokens += usage.TotalTokens
+		total.CacheCreationInputTokens += usage.CacheCreationInputTokens
+		total.CacheReadInputTokens += usage.CacheReadInputTokens
+	}
+	return total
+}
+
+// BudgetExceeded returns whether any analysis was stopped by the run budget
+func (r *AnalysisResult) BudgetExceeded() bool {
+	for _, failed := range r.Failed {
+		var budgetErr *errors.BudgetExceededError
+		if stderrors.As(failed.Error, &budgetErr) {
+			return true
+		}
+	}
+	return false
 }
 
 // FailedAnalysis represents a failed analysis
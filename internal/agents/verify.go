@@ -0,0 +1,296 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/user/gendocs/internal/tools"
+)
+
+// verificationRepairPrompt asks an agent to fix the references the verifier could not find
+const verificationRepairPrompt = "verification_repair"
+
+// maxVerifiedReferences bounds the references checked per document; every
+// symbol costs a search over the repository
+const maxVerifiedReferences = 150
+
+// unverifiedMarker is appended to references that were not found in the repository
+const unverifiedMarker = " _(unverified)_"
+
+// GroundingReport is the result of checking the file paths and symbols a
+// document mentions against the repository
+type GroundingReport struct {
+	References int      `json:"references"`
+	Verified   int      `json:"verified"`
+	Unverified []string `json:"unverified,omitempty"`
+	Repaired   bool     `json:"repaired"` // The agent was asked to fix unverified references
+}
+
+// Score returns the share of references found in the repository, 1 for a
+// document without references
+func (r GroundingReport) Score() float64 {
+	if r.References == 0 {
+		return 1
+	}
+	return float64(r.Verified) / float64(r.References)
+}
+
+// String renders the report on one line, e.g. "0.92 (23/25 references verified)"
+func (r GroundingReport) String() string {
+	return fmt.Sprintf("%.2f (%d/%d references verified)", r.Score(), r.Verified, r.References)
+}
+
+type referenceKind int
+
+const (
+	pathReference referenceKind = iota
+	symbolReference
+)
+
+type reference struct {
+	text string // As written in the document, e.g. "internal/llm/client.go:42"
+	name string // What is looked up, e.g. "internal/llm/client.go"
+	kind referenceKind
+}
+
+var (
+	inlineCode     = regexp.MustCompile("`([^`\n]+)`")
+	markdownLink   = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+	lineSuffix     = regexp.MustCompile(`(:\d+(-\d+)?|#L\d+(-L\d+)?)$`)
+	symbolPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*(\(\))?$`)
+	fileExtensions = map[string]bool{
+		".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+		".java": true, ".kt": true, ".rs": true, ".rb": true, ".php": true, ".cs": true,
+		".c": true, ".h": true, ".cpp": true, ".swift": true, ".scala": true,
+		".yaml": true, ".yml": true, ".json": true, ".toml": true, ".xml": true, ".ini": true,
+		".md": true, ".sql": true, ".proto": true, ".sh": true, ".tf": true,
+		".mod": true, ".sum": true, ".lock": true, ".gradle": true, ".html": true, ".css": true,
+	}
+	fileNames = map[string]bool{"Dockerfile": true, "Makefile": true, "Jenkinsfile": true, "Procfile": true}
+)
+
+// extractReferences returns the distinct file paths and code symbols a markdown
+// document mentions in inline code spans and link targets. Fenced code blocks,
+// URLs, routes and import paths are not references to check.
+func extractReferences(markdown string) []reference {
+	seen := make(map[string]bool)
+	var refs []reference
+
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		var candidates []string
+		for _, match := range inlineCode.FindAllStringSubmatch(line, -1) {
+			candidates = append(candidates, match[1])
+		}
+		for _, match := range markdownLink.FindAllStringSubmatch(line, -1) {
+			candidates = append(candidates, match[1])
+		}
+
+		for _, text := range candidates {
+			ref, ok := classifyReference(strings.TrimSpace(text))
+			if !ok || seen[ref.text] {
+				continue
+			}
+			seen[ref.text] = true
+			refs = append(refs, ref)
+			if len(refs) == maxVerifiedReferences {
+				return refs
+			}
+		}
+	}
+	return refs
+}
+
+// classifyReference decides whether text refers to a file or a symbol
+func classifyReference(text string) (reference, bool) {
+	if text == "" || strings.ContainsAny(text, " *{}<>$=,;'\"") || strings.Contains(text, "://") ||
+		strings.HasPrefix(text, "/") || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") {
+		return reference{}, false
+	}
+
+	name := lineSuffix.ReplaceAllString(text, "")
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "./"), "/")
+	if name == "" || name == "." || strings.HasPrefix(name, "..") {
+		return reference{}, false
+	}
+
+	if strings.Contains(text, "/") {
+		// Import paths start with a domain (github.com/...) and are not files
+		if first := strings.SplitN(name, "/", 2)[0]; strings.Contains(first, ".") && first != ".ai" && first != ".github" {
+			return reference{}, false
+		}
+		return reference{text: text, name: name, kind: pathReference}, true
+	}
+	if fileExtensions[path.Ext(name)] || fileNames[name] {
+		return reference{text: text, name: name, kind: pathReference}, true
+	}
+
+	// Symbols must look like code: mixed case, an underscore, a selector or a call
+	symbol := strings.TrimSuffix(name, "()")
+	if len(symbol) < 4 || !symbolPattern.MatchString(name) {
+		return reference{}, false
+	}
+	if symbol == strings.ToLower(symbol) && !strings.ContainsAny(symbol, "_.") && !strings.HasSuffix(name, "()") {
+		return reference{}, false
+	}
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	return reference{text: text, name: symbol, kind: symbolReference}, true
+}
+
+// verifier checks references against a repository with the read_file and
+// search_files tools
+type verifier struct {
+	repoPath string
+	readFile tools.Tool
+	search   tools.Tool
+	files    []string // Slash-separated paths of the repository, listed on first use
+	results  map[string]bool
+}
+
+func newVerifier(repoPath string) *verifier {
+	return &verifier{
+		repoPath: repoPath,
+		readFile: tools.NewFileReadTool(1),
+		search:   tools.NewSearchFilesTool(repoPath, 1),
+		results:  make(map[string]bool),
+	}
+}
+
+// Verify checks every reference of a document
+func (v *verifier) Verify(ctx context.Context, markdown string) GroundingReport {
+	var report GroundingReport
+	for _, ref := range extractReferences(markdown) {
+		report.References++
+		if v.check(ctx, ref) {
+			report.Verified++
+		} else {
+			report.Unverified = append(report.Unverified, ref.text)
+		}
+	}
+	return report
+}
+
+func (v *verifier) check(ctx context.Context, ref reference) bool {
+	key := fmt.Sprintf("%d:%s", ref.kind, ref.name)
+	if found, ok := v.results[key]; ok {
+		return found
+	}
+
+	var found bool
+	if ref.kind == pathReference {
+		found = v.fileExists(ctx, ref.name) || v.pathSuffixExists(ref.name)
+	} else {
+		result, err := v.search.Execute(ctx, map[string]interface{}{"pattern": ref.name})
+		if response, ok := result.(map[string]interface{}); err == nil && ok {
+			count, _ := response["matches_count"].(int)
+			found = count > 0
+		}
+	}
+
+	v.results[key] = found
+	return found
+}
+
+// fileExists reads the path from the repository root; directories count as found
+func (v *verifier) fileExists(ctx context.Context, name string) bool {
+	fullPath := filepath.Join(v.repoPath, filepath.FromSlash(name))
+	if info, err := os.Stat(fullPath); err == nil && info.IsDir() {
+		return true
+	}
+	result, err := v.readFile.Execute(ctx, map[string]interface{}{"file_path": fullPath, "line_count": 1})
+	if err != nil {
+		return false
+	}
+	response, ok := result.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	_, failed := response["error"]
+	return !failed
+}
+
+// pathSuffixExists matches paths written relative to a subdirectory (e.g.
+// "llm/client.go" for internal/llm/client.go) or bare file names
+func (v *verifier) pathSuffixExists(name string) bool {
+	if v.files == nil {
+		v.files = listRepositoryFiles(v.repoPath)
+	}
+	for _, file := range v.files {
+		if file == name || strings.HasSuffix(file, "/"+name) || strings.Contains(file, "/"+name+"/") || strings.HasPrefix(file, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// listRepositoryFiles lists the files of a repository, skipping ignored paths
+func listRepositoryFiles(repoPath string) []string {
+	ignorePatterns := tools.LoadGitignorePatterns(repoPath)
+	files := []string{}
+	_ = filepath.WalkDir(repoPath, func(fullPath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		relPath, err := filepath.Rel(repoPath, fullPath)
+		if err != nil || relPath == "." {
+			return nil
+		}
+		if tools.ShouldIgnore(relPath, ignorePatterns) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			files = append(files, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	return files
+}
+
+// markUnverified flags unverified references inline, outside fenced code blocks
+func markUnverified(markdown string, unverified []string) string {
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		// Markers of an earlier verification are replaced, not stacked
+		line = strings.ReplaceAll(line, unverifiedMarker, "")
+		for _, text := range unverified {
+			line = strings.ReplaceAll(line, "`"+text+"`", "`"+text+"`"+unverifiedMarker)
+			line = strings.ReplaceAll(line, "]("+text+")", "]("+text+")"+unverifiedMarker)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// groundingFooterPrefix starts the HTML comment recording the grounding score of a document
+const groundingFooterPrefix = "<!-- gendocs grounding score:"
+
+// groundingFooter records the grounding score in the document as an HTML comment
+func groundingFooter(report GroundingReport) string {
+	return fmt.Sprintf("\n\n%s %s -->\n", groundingFooterPrefix, report)
+}
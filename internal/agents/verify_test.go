@@ -0,0 +1,152 @@
+package agents
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/prompts"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestClassifyReference(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // Looked-up name, empty if not a reference
+		kind referenceKind
+	}{
+		{"internal/llm/client.go", "internal/llm/client.go", pathReference},
+		{"internal/llm/client.go:42", "internal/llm/client.go", pathReference},
+		{"./cmd/", "cmd", pathReference},
+		{"main.go", "main.go", pathReference},
+		{"Dockerfile", "Dockerfile", pathReference},
+		{"NewAnalyzerAgent", "NewAnalyzerAgent", symbolReference},
+		{"llm.Factory", "Factory", symbolReference},
+		{"cleanLLMOutput()", "cleanLLMOutput", symbolReference},
+		{"max_workers", "max_workers", symbolReference},
+		{"github.com/spf13/cobra", "", 0},
+		{"https://example.com/docs", "", 0},
+		{"/api/v1/users", "", 0},
+		{"go test ./...", "", 0},
+		{"--force", "", 0},
+		{"true", "", 0},
+		{"string", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			ref, ok := classifyReference(tt.text)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("expected %q not to be a reference, got %+v", tt.text, ref)
+				}
+				return
+			}
+			if !ok || ref.name != tt.want || ref.kind != tt.kind {
+				t.Errorf("classifyReference(%q) = %+v, %v; want name %q kind %d", tt.text, ref, ok, tt.want, tt.kind)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"internal/server/server.go": "package server\n\nfunc NewServer() *Server { return nil }\n",
+		"cmd/main.go":               "package main",
+	})
+
+	markdown := strings.Join([]string{
+		"# Structure",
+		"",
+		"The entry point is `cmd/main.go` and `NewServer` lives in [server.go](internal/server/server.go).",
+		"Handlers are in `internal/handlers/routes.go` and use `RegisterRoutes`.",
+		"Paths relative to a package also resolve: `server/server.go`.",
+		"",
+		"```go",
+		"srv := `internal/missing.go`",
+		"```",
+	}, "\n")
+
+	report := newVerifier(repoPath).Verify(context.Background(), markdown)
+	if report.References != 6 || report.Verified != 4 {
+		t.Errorf("expected 4/6 references verified, got %+v", report)
+	}
+	want := []string{"internal/handlers/routes.go", "RegisterRoutes"}
+	if !slices.Equal(report.Unverified, want) {
+		t.Errorf("unverified = %v, want %v", report.Unverified, want)
+	}
+
+	marked := markUnverified(markdown, report.Unverified)
+	if !strings.Contains(marked, "`RegisterRoutes`"+unverifiedMarker) {
+		t.Errorf("expected unverified symbols to be flagged, got:\n%s", marked)
+	}
+	if strings.Contains(marked, "`cmd/main.go`"+unverifiedMarker) {
+		t.Error("verified references must not be flagged")
+	}
+}
+
+func TestGroundingReport_Score(t *testing.T) {
+	if score := (GroundingReport{}).Score(); score != 1 {
+		t.Errorf("expected a document without references to score 1, got %v", score)
+	}
+	report := GroundingReport{References: 4, Verified: 3}
+	if report.String() != "0.75 (3/4 references verified)" {
+		t.Errorf("unexpected report string %q", report.String())
+	}
+}
+
+func TestAnalyzerAgent_Verify(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Structure\\n\\nSee `main.go` and `parseFlags()`."))
+	t.Cleanup(llmServer.Close)
+
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go": "package main\nfunc main() {}",
+	})
+
+	promptManager, err := prompts.NewManager("./prompts")
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	cfg := config.AnalyzerConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers: 1,
+		OnlyAgents: []string{"structure_analyzer"},
+		Verify:     config.VerifyMark,
+	}
+
+	result, err := NewAnalyzerAgent(cfg, promptManager, logging.NewNopLogger()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, ok := result.Grounding["structure"]
+	if !ok || report.References != 2 || report.Verified != 1 {
+		t.Fatalf("expected 1/2 references verified for structure, got %+v", result.Grounding)
+	}
+
+	doc, err := os.ReadFile(filepath.Join(repoPath, ".ai", "docs", "structure_analysis.md"))
+	if err != nil {
+		t.Fatalf("failed to read document: %v", err)
+	}
+	if !strings.Contains(string(doc), "`parseFlags()`"+unverifiedMarker) {
+		t.Errorf("expected the unverified symbol to be flagged, got:\n%s", doc)
+	}
+	if !strings.Contains(string(doc), "<!-- gendocs grounding score: 0.50 (1/2 references verified) -->") {
+		t.Errorf("expected the grounding score in the document, got:\n%s", doc)
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -327,6 +329,24 @@ type ChangeReport struct {
 	Reason           string
 	IsFirstRun       bool
 	GitCommitChanged bool
+	PreviousCommit   string // Git commit of the last analysis, if known
+}
+
+// FilesMatching returns the new, modified and deleted files matching any of the patterns
+func (r *ChangeReport) FilesMatching(patterns []string) []string {
+	var files []string
+	for _, group := range [][]string{r.NewFiles, r.ModifiedFiles, r.DeletedFiles} {
+		for _, file := range group {
+			for _, pattern := range patterns {
+				if matchPattern(file, pattern) {
+					files = append(files, file)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
 }
 
 // AgentFilePatterns maps agents to file patterns they care about
@@ -358,6 +378,25 @@ var AgentFilePatterns = map[string][]string{
 		"*controller*.ts", "*route*.ts", "*api*.ts",
 		"openapi*.yaml", "swagger*.yaml", "*.proto",
 	},
+	"security_analyzer": {
+		"*auth*", "*middleware*", "*login*", "*session*", "*secret*", "*crypt*",
+		"auth/*", "authn/*", "authz/*", "middleware/*", "security/*", "crypto/*",
+		"*.pem", "*.key", "*.crt", ".env*",
+		"Dockerfile*", "*.dockerfile", "docker-compose*",
+	},
+	"testing_analyzer": {
+		"*_test.go", "*.test.ts", "*.spec.ts", "*.test.tsx", "*.spec.tsx",
+		"*.test.js", "*.spec.js", "test_*", "*_test.py", "conftest.py",
+		"pytest.ini", "tox.ini", "jest.config*", "vitest.config*",
+		"package.json", "Makefile", "*Test.java",
+	},
+	"infrastructure_analyzer": {
+		"Dockerfile*", "*.dockerfile", "docker-compose*", "compose.yaml", "compose.yml",
+		"Chart.yaml", "values.yaml", "charts/*", "helm/*", "k8s/*", "kustomization.yaml",
+		"*.tf", "*.tfvars", "*.hcl",
+		".github/workflows/*", ".gitlab-ci.yml", ".circleci/*", "Jenkinsfile", "azure-pipelines.yml",
+		"Makefile", "Procfile", ".env.example", "skaffold.yaml", "fly.toml", "serverless.yml",
+	},
 }
 
 // NewCache creates a new empty cache
@@ -432,6 +471,19 @@ func GetCurrentGitCommit(repoPath string) string {
 	return strings.TrimSpace(string(output))
 }
 
+// GitDiff returns the diff of files between a commit and the working tree.
+// Untracked files are not part of it.
+func GitDiff(repoPath, commit string, files []string) (string, error) {
+	args := append([]string{"diff", "--no-color", "--no-ext-diff", commit, "--"}, files...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(output), nil
+}
+
 // HashFile calculates SHA256 hash of a file
 func HashFile(path string) (string, error) {
 	file, err := os.Open(path)
@@ -546,6 +598,11 @@ func ScanFiles(repoPath string, ignorePatterns []string, cache *AnalysisCache, m
 
 // DetectChanges compares current files with cached files
 func (c *AnalysisCache) DetectChanges(repoPath string, currentFiles map[string]FileInfo) *ChangeReport {
+	return c.DetectChangesForAgents(repoPath, currentFiles, AgentFilePatterns)
+}
+
+// DetectChangesForAgents is DetectChanges for the given agents and their file patterns
+func (c *AnalysisCache) DetectChangesForAgents(repoPath string, currentFiles map[string]FileInfo, agentPatterns map[string][]string) *ChangeReport {
 	report := &ChangeReport{
 		NewFiles:      []string{},
 		ModifiedFiles: []string{},
@@ -559,7 +616,7 @@ func (c *AnalysisCache) DetectChanges(repoPath string, currentFiles map[string]F
 		report.IsFirstRun = true
 		report.HasChanges = true
 		report.Reason = "First analysis run"
-		report.AgentsToRun = getAllAgents()
+		report.AgentsToRun = agentNames(agentPatterns)
 		for path := range currentFiles {
 			report.NewFiles = append(report.NewFiles, path)
 		}
@@ -567,6 +624,7 @@ func (c *AnalysisCache) DetectChanges(repoPath string, currentFiles map[string]F
 	}
 
 	// Check git commit
+	report.PreviousCommit = c.GitCommit
 	currentCommit := GetCurrentGitCommit(repoPath)
 	if currentCommit != "" && c.GitCommit != "" && currentCommit != c.GitCommit {
 		report.GitCommitChanged = true
@@ -597,22 +655,29 @@ func (c *AnalysisCache) DetectChanges(repoPath string, currentFiles map[string]F
 
 	if !report.HasChanges {
 		report.Reason = "No files changed since last analysis"
-		report.AgentsToSkip = getAllAgents()
+		report.AgentsToSkip = agentNames(agentPatterns)
 		return report
 	}
 
 	// Determine which agents are affected by the changes
-	for agent, patterns := range AgentFilePatterns {
-		if agentNeedsRun(changedFiles, patterns, c.Agents[agent]) {
+	ownership := false
+	for _, agent := range agentNames(agentPatterns) {
+		if len(c.Agents[agent].FilesAnalyzed) > 0 {
+			ownership = true
+		}
+		if agentNeedsRun(c.AffectedFiles(agent, report, agentPatterns[agent]), c.Agents[agent]) {
 			report.AgentsToRun = append(report.AgentsToRun, agent)
 		} else {
 			report.AgentsToSkip = append(report.AgentsToSkip, agent)
 		}
 	}
 
-	// If no specific agents matched, run all (safety fallback)
-	if len(report.AgentsToRun) == 0 && report.HasChanges {
-		report.AgentsToRun = getAllAgents()
+	// If no specific agents matched, run all (safety fallback). Agents that
+	// recorded the files they read are trusted not to depend on other files.
+	if len(report.AgentsToRun) == 0 && ownership {
+		report.Reason = fmt.Sprintf("%d files changed, no agent depends on them", len(changedFiles))
+	} else if len(report.AgentsToRun) == 0 && report.HasChanges {
+		report.AgentsToRun = agentNames(agentPatterns)
 		report.AgentsToSkip = []string{}
 		report.Reason = "Changes detected but no specific agent patterns matched"
 	} else {
@@ -631,43 +696,89 @@ func (c *AnalysisCache) UpdateAfterAnalysis(repoPath string, currentFiles map[st
 
 	for agent, success := range agentResults {
 		c.Agents[agent] = AgentStatus{
-			LastRun: time.Now(),
-			Success: success,
+			LastRun:       time.Now(),
+			Success:       success,
+			FilesAnalyzed: c.Agents[agent].FilesAnalyzed,
 		}
 	}
 }
 
-// Helper functions
-
-func getAllAgents() []string {
-	return []string{
-		"structure_analyzer",
-		"dependency_analyzer",
-		"data_flow_analyzer",
-		"request_flow_analyzer",
-		"api_analyzer",
-	}
+// SetFilesAnalyzed records the files an agent read during its last run
+func (c *AnalysisCache) SetFilesAnalyzed(agent string, files []string) {
+	status := c.Agents[agent]
+	status.FilesAnalyzed = files
+	c.Agents[agent] = status
 }
 
-func agentNeedsRun(changedFiles []string, patterns []string, lastStatus AgentStatus) bool {
-	// If agent never ran successfully, it needs to run
-	if lastStatus.LastRun.IsZero() || !lastStatus.Success {
-		return true
+// AffectedFiles returns the changed files an agent depends on. Modified and
+// deleted files count when the agent read them in its last run; new files, and
+// every change for agents that recorded no files, are matched against the
+// agent's file patterns.
+func (c *AnalysisCache) AffectedFiles(agent string, report *ChangeReport, patterns []string) []string {
+	analyzed := c.Agents[agent].FilesAnalyzed
+	if len(analyzed) == 0 {
+		return report.FilesMatching(patterns)
 	}
 
-	// Check if any changed file matches agent's patterns
-	for _, file := range changedFiles {
+	owned := make(map[string]bool, len(analyzed))
+	for _, file := range analyzed {
+		owned[file] = true
+	}
+	var files []string
+	for _, file := range report.NewFiles {
 		for _, pattern := range patterns {
 			if matchPattern(file, pattern) {
-				return true
+				files = append(files, file)
+				break
 			}
 		}
 	}
+	for _, group := range [][]string{report.ModifiedFiles, report.DeletedFiles} {
+		for _, file := range group {
+			if owned[filepath.ToSlash(file)] {
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
 
-	return false
+// Helper functions
+
+// agentNames returns the agents of agentPatterns in a stable order
+func agentNames(agentPatterns map[string][]string) []string {
+	names := make([]string, 0, len(agentPatterns))
+	for agent := range agentPatterns {
+		names = append(names, agent)
+	}
+	sort.Strings(names)
+	return names
 }
 
+func agentNeedsRun(affectedFiles []string, lastStatus AgentStatus) bool {
+	// If agent never ran successfully, it needs to run
+	if lastStatus.LastRun.IsZero() || !lastStatus.Success {
+		return true
+	}
+
+	return len(affectedFiles) > 0
+}
+
+// matchPattern reports whether a repository-relative path matches an agent file
+// pattern, case-insensitively. Patterns without a slash match the base name
+// anywhere in the tree; patterns with a slash (e.g. "k8s/*.yaml") match the
+// trailing directories as well. "*keyword*.ext" patterns use path.Match.
 func matchPattern(filename, pattern string) bool {
+	// Handle directory patterns (e.g. ".github/workflows/*") against the relative path
+	if dir, base := path.Split(strings.ToLower(pattern)); dir != "" {
+		file := strings.ToLower(filepath.ToSlash(filename))
+		if !strings.HasPrefix(file, dir) && !strings.Contains(file, "/"+dir) {
+			return false
+		}
+		return matchPattern(file, base)
+	}
+
 	// Handle patterns like "*handler*.go", "*_test.go", etc.
 	if strings.Contains(pattern, "*") {
 		// Simple glob matching
@@ -681,10 +792,9 @@ func matchPattern(filename, pattern string) bool {
 		}
 
 		// Handle *keyword*.ext patterns (e.g., "*handler*.go")
-		if strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") && strings.Contains(pattern[1:], "*") {
-			// Extract the middle part
-			middle := strings.Trim(pattern, "*")
-			return strings.Contains(filename, middle)
+		if strings.HasPrefix(pattern, "*") && !strings.HasSuffix(pattern, "*") && strings.Contains(pattern[1:], "*") {
+			matched, _ := path.Match(pattern, filename)
+			return matched
 		}
 
 		// Handle *suffix patterns (e.g., "*_test.go", "_test.go")
@@ -718,8 +828,8 @@ func shouldIgnore(relPath, name string, patterns []string) bool {
 	}
 
 	for _, pattern := range append(defaultIgnore, patterns...) {
-		// Exact name match
-		if name == pattern {
+		// Exact name or path match
+		if name == pattern || relPath == pattern {
 			return true
 		}
 
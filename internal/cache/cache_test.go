@@ -3,6 +3,7 @@ package cache
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -524,3 +525,181 @@ func TestHashFile_NonExistent(t *testing.T) {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 }
+
+// TestChangeReport_FilesMatching verifies that changed files are filtered by agent patterns
+func TestChangeReport_FilesMatching(t *testing.T) {
+	report := &ChangeReport{
+		NewFiles:      []string{"internal/api/routes.go"},
+		ModifiedFiles: []string{"main.go", "internal/api/user_handler.go"},
+		DeletedFiles:  []string{"old_handler.go"},
+	}
+
+	got := report.FilesMatching([]string{"*handler*.go", "*route*.go"})
+	want := []string{"internal/api/routes.go", "internal/api/user_handler.go", "old_handler.go"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		filename string
+		pattern  string
+		expected bool
+	}{
+		{"main.go", "*.go", true},
+		{"main.py", "*.go", false},
+		{"handler.go", "*handler*", true},
+		{"api_handler.go", "*handler*", true},
+		{"main.go", "*handler*", false},
+		{"internal/api/user_handler.go", "*handler*.go", true},
+		{"handler.py", "*handler*.go", false},
+		{"go.mod", "go.mod", true},
+		{"go.sum", "go.mod", false},
+		{"test_file.go", "*_test.go", false},
+		{"file_test.go", "*_test.go", true},
+		{"Dockerfile.prod", "Dockerfile*", true},
+		{"main.go", "Dockerfile*", false},
+		{".github/workflows/ci.yml", ".github/workflows/*", true},
+		{"services/api/.github/workflows/ci.yml", ".github/workflows/*", true},
+		{"docs/ci.yml", ".github/workflows/*", false},
+		{"deploy/k8s/service.yaml", "k8s/*.yaml", true},
+		{"deploy/service.yaml", "k8s/*.yaml", false},
+		{"k8s.yaml", "k8s/*", false},
+		{"main.go", "*handler*.go", false},
+		{"handler_test.go", "*handler*.go", true},
+		{"handler.go.bak", "*handler*.go", false},
+
+		// Patterns of the built-in agents keep matching as before directory
+		// and "*keyword*.ext" support were added
+		{"internal/app/main.go", "*.go", true},
+		{"Main.GO", "*.go", true},
+		{"pkg/store/store_test.go", "*_test.go", true},
+		{"tests/test_api.py", "test_*", true},
+		{"latest_api.py", "test_*", false},
+		{"internal/oauth_client.go", "*auth*", true},
+		{"internal/auth/token.go", "*auth*", false},
+		{"internal/auth/token.go", "auth/*", true},
+		{"internal/auth/jwt/claims.go", "auth/*", true},
+		{"internal/oauth/token.go", "auth/*", false},
+		{"config/.env.local", ".env*", true},
+		{"src/UserServiceTest.java", "*Test.java", true},
+		{"web/jest.config.ts", "jest.config*", true},
+		{"services/api/Makefile", "Makefile", true},
+		{"services/api/go.mod", "go.mod", true},
+		{"Makefile.old", "Makefile", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename+"_"+tt.pattern, func(t *testing.T) {
+			got := matchPattern(tt.filename, tt.pattern)
+			if got != tt.expected {
+				t.Errorf("matchPattern(%q, %q) = %v, expected %v",
+					tt.filename, tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAgentFilePatterns_NewFiles verifies that new files re-run the agents
+// whose built-in patterns cover them, and only those
+func TestAgentFilePatterns_NewFiles(t *testing.T) {
+	tests := []struct {
+		file     string
+		agent    string
+		expected bool
+	}{
+		{"internal/auth/token.go", "security_analyzer", true},
+		{"pkg/middleware/cors.go", "security_analyzer", true},
+		{"internal/session_store.go", "security_analyzer", true},
+		{"deploy/tls/server.pem", "security_analyzer", true},
+		{"internal/store/user.go", "security_analyzer", false},
+		{"pkg/store/store_test.go", "testing_analyzer", true},
+		{"web/src/app.spec.ts", "testing_analyzer", true},
+		{"internal/store/user.go", "testing_analyzer", false},
+		{".github/workflows/ci.yml", "infrastructure_analyzer", true},
+		{"deploy/k8s/service.yaml", "infrastructure_analyzer", true},
+		{"infra/main.tf", "infrastructure_analyzer", true},
+		{"Dockerfile.prod", "infrastructure_analyzer", true},
+		{".github/workflows/ci.yml", "security_analyzer", false},
+		{"internal/store/user.go", "infrastructure_analyzer", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+"_"+tt.agent, func(t *testing.T) {
+			cache := NewCache()
+			cache.LastAnalysis = time.Now().Add(-time.Hour)
+			cache.Files = map[string]FileInfo{"main.go": {Hash: "a"}}
+			ran := make(map[string]bool)
+			for agent := range AgentFilePatterns {
+				ran[agent] = true
+			}
+			cache.UpdateAfterAnalysis(t.TempDir(), cache.Files, ran)
+
+			current := map[string]FileInfo{"main.go": {Hash: "a"}, tt.file: {Hash: "b"}}
+			report := cache.DetectChangesForAgents(t.TempDir(), current, AgentFilePatterns)
+			if got := slices.Contains(report.AgentsToRun, tt.agent); got != tt.expected {
+				t.Errorf("%s runs for new %s = %v, expected %v (agents to run: %v)", tt.agent, tt.file, got, tt.expected, report.AgentsToRun)
+			}
+		})
+	}
+}
+
+// TestDetectChangesForAgents_FilesAnalyzed verifies that agents re-run for the files
+// they read, with their patterns only deciding on new files
+func TestDetectChangesForAgents_FilesAnalyzed(t *testing.T) {
+	lastRun := time.Now().Add(-time.Hour)
+	cache := NewCache()
+	cache.LastAnalysis = lastRun
+	cache.Files = map[string]FileInfo{
+		"server.go":  {Hash: "a"},
+		"main.go":    {Hash: "b"},
+		"handler.go": {Hash: "c"},
+	}
+	cache.UpdateAfterAnalysis(t.TempDir(), cache.Files, map[string]bool{"api_analyzer": true, "structure_analyzer": true})
+	cache.SetFilesAnalyzed("api_analyzer", []string{"server.go"})
+	cache.SetFilesAnalyzed("structure_analyzer", []string{"handler.go"})
+
+	patterns := map[string][]string{
+		"api_analyzer":       {"*handler*.go"},
+		"structure_analyzer": {"*.go"},
+	}
+
+	// The router in server.go is owned by the API agent although no pattern matches it
+	current := map[string]FileInfo{
+		"server.go":  {Hash: "a2"},
+		"main.go":    {Hash: "b2"},
+		"handler.go": {Hash: "c"},
+	}
+	report := cache.DetectChangesForAgents(t.TempDir(), current, patterns)
+	if len(report.AgentsToRun) != 1 || report.AgentsToRun[0] != "api_analyzer" {
+		t.Errorf("Expected only api_analyzer to run, got %v", report.AgentsToRun)
+	}
+
+	// New files fall back to the patterns
+	current["new_handler.go"] = FileInfo{Hash: "d"}
+	current["server.go"] = FileInfo{Hash: "a"}
+	report = cache.DetectChangesForAgents(t.TempDir(), current, patterns)
+	if len(report.AgentsToRun) != 2 {
+		t.Errorf("Expected both agents to run for a new handler, got %v", report.AgentsToRun)
+	}
+
+	// Changes no agent depends on run nothing
+	delete(current, "new_handler.go")
+	report = cache.DetectChangesForAgents(t.TempDir(), current, patterns)
+	if len(report.AgentsToRun) != 0 || !report.HasChanges {
+		t.Errorf("Expected no agent to run for main.go, got %v (%s)", report.AgentsToRun, report.Reason)
+	}
+
+	// A later run keeps the recorded files
+	cache.UpdateAfterAnalysis(t.TempDir(), current, map[string]bool{"api_analyzer": true})
+	if files := cache.Agents["api_analyzer"].FilesAnalyzed; len(files) != 1 || files[0] != "server.go" {
+		t.Errorf("Expected FilesAnalyzed to be kept, got %v", files)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -177,10 +179,21 @@ func LoadAnalyzerConfig(repoPath string, cliOverrides map[string]interface{}) (*
 
 	applyAnalyzerDefaults(cfg)
 	applyAnalyzerEnvOverrides(cfg)
+	if err := applyGeminiConfig(repoPath, &cfg.LLM); err != nil {
+		return nil, err
+	}
 
 	if err := validateLLMConfig(&cfg.LLM, "ANALYZER"); err != nil {
 		return nil, err
 	}
+	if cfg.MaxTokensPerRun < 0 || cfg.MaxCostPerRun < 0 {
+		return nil, errors.NewValidationError("max_tokens_per_run and max_cost_per_run must not be negative")
+	}
+	switch cfg.Verify {
+	case "", VerifyOff, VerifyMark, VerifyRepair:
+	default:
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid verify mode %q (expected off, mark or repair)", cfg.Verify))
+	}
 
 	return cfg, nil
 }
@@ -307,6 +320,9 @@ func LoadDocumenterConfig(repoPath string, cliOverrides map[string]interface{})
 
 	applyDocumenterDefaults(cfg)
 	applyDocumenterEnvOverrides(cfg)
+	if err := applyGeminiConfig(repoPath, &cfg.LLM); err != nil {
+		return nil, err
+	}
 
 	if err := validateLLMConfig(&cfg.LLM, "DOCUMENTER"); err != nil {
 		return nil, err
@@ -365,6 +381,9 @@ func LoadAIRulesConfig(repoPath string, cliOverrides map[string]interface{}) (*A
 
 	applyAIRulesDefaults(cfg)
 	applyAIRulesEnvOverrides(cfg)
+	if err := applyGeminiConfig(repoPath, &cfg.LLM); err != nil {
+		return nil, err
+	}
 
 	if err := validateLLMConfig(&cfg.LLM, "AI_RULES"); err != nil {
 		return nil, err
@@ -449,9 +468,25 @@ func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// applyGeminiConfig fills the LLM Gemini settings from the top-level gemini
+// section, which the config TUI edits, unless the LLM section sets its own
+func applyGeminiConfig(repoPath string, llm *LLMConfig) error {
+	if llm.Gemini != (GeminiConfig{}) {
+		return nil
+	}
+	section, err := MergeConfigs(repoPath, "gemini", &GeminiConfig{}, nil)
+	if err != nil {
+		return err
+	}
+	if err := mapstructure.WeakDecode(section, &llm.Gemini); err != nil {
+		return fmt.Errorf("failed to decode gemini config: %w", err)
+	}
+	return nil
+}
+
 // validateLLMConfig validates LLM configuration
 func validateLLMConfig(cfg *LLMConfig, prefix string) error {
-	if cfg.APIKey == "" {
+	if cfg.APIKey == "" && cfg.Provider != "bedrock" && !(cfg.Provider == "gemini" && cfg.Gemini.UseVertexAI) {
 		return errors.NewMissingEnvVarError(prefix+"_LLM_API_KEY", "API key for LLM provider")
 	}
 
@@ -459,12 +494,75 @@ func validateLLMConfig(cfg *LLMConfig, prefix string) error {
 		"openai":    true,
 		"anthropic": true,
 		"gemini":    true,
+		"azure":     true,
+		"bedrock":   true,
 	}
 
 	if !validProviders[cfg.Provider] {
-		return errors.NewInvalidEnvVarError(prefix+"_LLM_PROVIDER", cfg.Provider, "Must be one of: openai, anthropic, gemini")
+		return errors.NewInvalidEnvVarError(prefix+"_LLM_PROVIDER", cfg.Provider, "Must be one of: openai, anthropic, gemini, azure, bedrock")
+	}
+	if cfg.ContextWindow < 0 {
+		return errors.NewValidationError("context_window must not be negative")
+	}
+	if err := validateProviderSettings(*cfg); err != nil {
+		return err
+	}
+
+	return validateLLMFallbacks(*cfg)
+}
+
+// validateProviderSettings checks what Azure and Bedrock need beyond an API key
+func validateProviderSettings(cfg LLMConfig) error {
+	switch cfg.Provider {
+	case "azure":
+		if cfg.BaseURL == "" {
+			return errors.NewValidationError("azure provider requires base_url, the resource endpoint (https://<resource>.openai.azure.com)")
+		}
+	case "bedrock":
+		bedrock := cfg.Bedrock.WithEnvDefaults()
+		if bedrock.Region == "" {
+			return errors.NewValidationError("bedrock provider requires a region (llm.bedrock.region or AWS_REGION)")
+		}
+		if cfg.APIKey == "" && (bedrock.AccessKeyID == "" || bedrock.SecretAccessKey == "") {
+			return errors.NewValidationError("bedrock provider requires AWS credentials (llm.bedrock access keys, AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY or a Bedrock API key)")
+		}
+	case "gemini":
+		if !cfg.Gemini.UseVertexAI {
+			break
+		}
+		gemini := cfg.Gemini.WithEnvDefaults()
+		if gemini.CredentialsFile == "" {
+			return errors.NewValidationError("vertex AI requires credentials (gemini.credentials_file, GOOGLE_APPLICATION_CREDENTIALS or `gcloud auth application-default login`)")
+		}
+		if gemini.ProjectID == "" {
+			return errors.NewValidationError("vertex AI requires a project (gemini.project_id or GOOGLE_CLOUD_PROJECT)")
+		}
 	}
+	return nil
+}
 
+// validateLLMFallbacks validates the fallback providers; local providers need no API key
+func validateLLMFallbacks(cfg LLMConfig) error {
+	validProviders := map[string]bool{
+		"openai":    true,
+		"anthropic": true,
+		"gemini":    true,
+		"azure":     true,
+		"bedrock":   true,
+		"ollama":    true,
+		"lmstudio":  true,
+	}
+	for i, backend := range cfg.Backends()[1:] {
+		if !validProviders[backend.Provider] {
+			return errors.NewValidationError(fmt.Sprintf("fallback %d: unsupported provider %q (expected openai, anthropic, gemini, azure, bedrock, ollama or lmstudio)", i+1, backend.Provider))
+		}
+		if backend.APIKey == "" && backend.Provider != "bedrock" && backend.Provider != "ollama" && backend.Provider != "lmstudio" {
+			return errors.NewValidationError(fmt.Sprintf("fallback %d: api_key is required for provider %s", i+1, backend.Provider))
+		}
+		if err := validateProviderSettings(backend); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("fallback %d: %v", i+1, err))
+		}
+	}
 	return nil
 }
 
@@ -550,3 +648,236 @@ func applyCheckDefaults(cfg *CheckConfig) {
 		cfg.OutputFormat = "text"
 	}
 }
+
+// LoadReviewConfig loads review configuration from the "review" section
+func LoadReviewConfig(repoPath string, cliOverrides map[string]interface{}) (*ReviewConfig, error) {
+	configMap, err := MergeConfigs(repoPath, "review", &ReviewConfig{}, cliOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &ReviewConfig{}
+	decoderConfig := &mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           cfg,
+		TagName:          "mapstructure",
+		Squash:           true,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config decoder: %w", err)
+	}
+
+	if err := decoder.Decode(configMap); err != nil {
+		return nil, fmt.Errorf("failed to decode review config: %w", err)
+	}
+
+	applyReviewDefaults(cfg)
+
+	if cfg.Forge != "gitlab" && cfg.Forge != "github" && cfg.Forge != "gitea" {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("invalid review forge %q: must be one of: gitlab, github, gitea", cfg.Forge))
+	}
+	if cfg.MRNumber <= 0 {
+		return nil, errors.NewValidationError("review requires a merge request number (--mr)")
+	}
+	if cfg.Project == "" {
+		return nil, errors.NewValidationError("review requires a project (--project, CI_PROJECT_ID or GITHUB_REPOSITORY)")
+	}
+
+	return cfg, nil
+}
+
+func applyReviewDefaults(cfg *ReviewConfig) {
+	if cfg.RepoPath == "" {
+		cfg.RepoPath = "."
+	}
+	if cfg.Forge == "" {
+		cfg.Forge = "gitlab"
+	}
+	if cfg.OutputFormat == "" {
+		cfg.OutputFormat = "text"
+	}
+}
+
+// AgentTools are the tools a custom agent may use
+var AgentTools = []string{"read_file", "list_files", "search_files"}
+
+var agentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// LoadAgentDefinitions loads the custom agents declared in the "agents" section
+func LoadAgentDefinitions(repoPath string) (map[string]AgentDefinition, error) {
+	configMap, err := MergeConfigs(repoPath, "agents", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]AgentDefinition, len(configMap))
+	decoderConfig := &mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           &defs,
+		TagName:          "mapstructure",
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config decoder: %w", err)
+	}
+
+	if err := decoder.Decode(configMap); err != nil {
+		return nil, fmt.Errorf("failed to decode agents config: %w", err)
+	}
+
+	for name, def := range defs {
+		applyAgentDefaults(name, &def)
+		if err := validateAgentDefinition(name, def); err != nil {
+			return nil, err
+		}
+		defs[name] = def
+	}
+
+	return defs, nil
+}
+
+func applyAgentDefaults(name string, def *AgentDefinition) {
+	base := strings.TrimSuffix(name, "_analyzer")
+	if def.DisplayName == "" {
+		words := strings.Split(base, "_")
+		for i, word := range words {
+			if word != "" {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		def.DisplayName = strings.Join(words, " ") + " Analysis"
+	}
+	if def.SystemPrompt == "" {
+		def.SystemPrompt = name + "_system"
+	}
+	if def.UserPrompt == "" {
+		def.UserPrompt = name + "_user"
+	}
+	if def.OutputFile == "" {
+		def.OutputFile = base + "_analysis.md"
+	}
+	if len(def.Tools) == 0 {
+		def.Tools = append([]string(nil), AgentTools...)
+	}
+}
+
+func validateAgentDefinition(name string, def AgentDefinition) error {
+	if !agentNamePattern.MatchString(name) {
+		return errors.NewValidationError(fmt.Sprintf("invalid agent name %q: use lowercase letters, digits and underscores", name))
+	}
+	if len(def.FilePatterns) == 0 {
+		return errors.NewValidationError(fmt.Sprintf("agent %s must declare at least one file pattern", name))
+	}
+	if filepath.Base(def.OutputFile) != def.OutputFile || filepath.Ext(def.OutputFile) != ".md" {
+		return errors.NewValidationError(fmt.Sprintf("agent %s: output_file %q must be a markdown file name without directories", name, def.OutputFile))
+	}
+	for _, tool := range def.Tools {
+		if !slices.Contains(AgentTools, tool) {
+			return errors.NewValidationError(fmt.Sprintf("agent %s: unknown tool %q: must be one of: %s", name, tool, strings.Join(AgentTools, ", ")))
+		}
+	}
+	return nil
+}
+
+// LoadCronjobConfig loads cronjob configuration from the "cronjob" section
+func LoadCronjobConfig(repoPath string, cliOverrides map[string]interface{}) (*CronjobConfig, error) {
+	configMap, err := MergeConfigs(repoPath, "cronjob", &CronjobConfig{}, cliOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &CronjobConfig{}
+	decoderConfig := &mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           cfg,
+		TagName:          "mapstructure",
+		Squash:           true,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config decoder: %w", err)
+	}
+
+	if err := decoder.Decode(configMap); err != nil {
+		return nil, fmt.Errorf("failed to decode cronjob config: %w", err)
+	}
+
+	applyCronjobDefaults(cfg)
+
+	validForges := map[string]bool{
+		"gitlab": true,
+		"github": true,
+		"gitea":  true,
+	}
+	if !validForges[cfg.Forge] {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("invalid cronjob forge %q: must be one of: gitlab, github, gitea", cfg.Forge))
+	}
+	if cfg.MRPolicy != "create" && cfg.MRPolicy != "update" {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("invalid cronjob mr_policy %q: must be one of: create, update", cfg.MRPolicy))
+	}
+
+	return cfg, nil
+}
+
+func applyCronjobDefaults(cfg *CronjobConfig) {
+	if cfg.Forge == "" {
+		cfg.Forge = "gitlab"
+	}
+	if cfg.MRPolicy == "" {
+		cfg.MRPolicy = "create"
+	}
+	if cfg.WorkingPath == "" {
+		cfg.WorkingPath = "./work"
+	}
+	if cfg.MaxDaysSinceLastCommit == 0 {
+		cfg.MaxDaysSinceLastCommit = 14
+	}
+}
+
+// LoadGitLabConfig loads the "gitlab" section. Environment variables are
+// applied by the caller and take precedence over these values.
+func LoadGitLabConfig(repoPath string) (*GitLabConfig, error) {
+	section, err := MergeConfigs(repoPath, "gitlab", &GitLabConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &GitLabConfig{}
+	if err := mapstructure.WeakDecode(section, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode gitlab config: %w", err)
+	}
+	applyGitLabDefaults(cfg)
+	return cfg, nil
+}
+
+func applyGitLabDefaults(cfg *GitLabConfig) {
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://gitlab.com"
+	}
+	if cfg.UserName == "" {
+		cfg.UserName = "AI Analyzer"
+	}
+	if cfg.UserUsername == "" {
+		cfg.UserUsername = "agent_doc"
+	}
+}
+
+// LoadForgeConfig loads the "github" or "gitea" section. Environment variables
+// are applied by the caller and take precedence over these values.
+func LoadForgeConfig(repoPath, section string) (*ForgeConfig, error) {
+	configMap, err := MergeConfigs(repoPath, section, &ForgeConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ForgeConfig{}
+	if err := mapstructure.WeakDecode(configMap, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s config: %w", section, err)
+	}
+	if cfg.UserName == "" {
+		cfg.UserName = "AI Analyzer"
+	}
+	return cfg, nil
+}
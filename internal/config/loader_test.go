@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -152,6 +153,78 @@ func TestLoadAnalyzerConfig_InvalidProvider(t *testing.T) {
 	}
 }
 
+func TestLoadAnalyzerConfig_InvalidVerifyMode(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("ANALYZER_LLM_PROVIDER", "openai")
+	_ = os.Setenv("ANALYZER_LLM_MODEL", "gpt-4")
+	_ = os.Setenv("ANALYZER_LLM_API_KEY", "test-key")
+
+	_, err := LoadAnalyzerConfig(".", map[string]interface{}{"verify": "fix"})
+	if err == nil {
+		t.Fatal("Expected error for invalid verify mode, got nil")
+	}
+
+	cfg, err := LoadAnalyzerConfig(".", map[string]interface{}{"verify": VerifyRepair})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.VerificationEnabled() {
+		t.Error("Expected verification to be enabled in repair mode")
+	}
+}
+
+func TestLoadAnalyzerConfig_Fallbacks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	projectConfigContent := `
+analyzer:
+  llm:
+    provider: anthropic
+    model: claude-sonnet-4
+    api_key: anthropic-key
+    fallbacks:
+      - model: claude-3-5-haiku
+      - provider: openai
+        model: gpt-4o
+        api_key: openai-key
+`
+	_ = os.WriteFile(projectConfig, []byte(projectConfigContent), 0644)
+	os.Clearenv()
+
+	cfg, err := LoadAnalyzerConfig(tmpDir, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	backends := cfg.LLM.Backends()
+	if len(backends) != 3 {
+		t.Fatalf("Expected 3 backends, got %+v", backends)
+	}
+	if backends[1].Provider != "anthropic" || backends[1].Model != "claude-3-5-haiku" || backends[1].APIKey != "anthropic-key" {
+		t.Errorf("Expected the same-provider fallback to inherit the API key, got %+v", backends[1])
+	}
+	if backends[2].Provider != "openai" || backends[2].APIKey != "openai-key" || backends[2].MaxTokens != cfg.LLM.MaxTokens {
+		t.Errorf("Expected the openai fallback with its own key and inherited settings, got %+v", backends[2])
+	}
+
+	// Another provider does not inherit the API key
+	projectConfigContent = `
+analyzer:
+  llm:
+    provider: anthropic
+    api_key: anthropic-key
+    fallbacks:
+      - provider: gemini
+`
+	_ = os.WriteFile(projectConfig, []byte(projectConfigContent), 0644)
+	_, err = LoadAnalyzerConfig(tmpDir, map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "fallback 1") {
+		t.Errorf("Expected error for a fallback provider without API key, got %v", err)
+	}
+}
+
 func TestLoadAnalyzerConfig_ExclusionFlags(t *testing.T) {
 	os.Clearenv()
 	_ = os.Setenv("ANALYZER_LLM_PROVIDER", "openai")
@@ -212,6 +285,10 @@ analyzer:
     timeout: 240
     max_tokens: 16384
     temperature: 0.5
+    pricing:
+      claude-3-sonnet:
+        input: 3
+        output: 15
 `
 	_ = os.WriteFile(projectConfig, []byte(projectConfigContent), 0644)
 
@@ -247,6 +324,10 @@ analyzer:
 	if cfg.ExcludeDataFlow {
 		t.Error("Expected ExcludeDataFlow to be false")
 	}
+
+	if price := cfg.LLM.Pricing["claude-3-sonnet"]; price.Input != 3 || price.Output != 15 {
+		t.Errorf("Expected claude-3-sonnet pricing 3/15, got %+v", cfg.LLM.Pricing)
+	}
 }
 
 func TestLoadAnalyzerConfig_InvalidYAML(t *testing.T) {
@@ -352,6 +433,110 @@ func TestLoadAnalyzerConfig_AllProviders(t *testing.T) {
 	}
 }
 
+func TestLoadAnalyzerConfig_CloudProviders(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("ANALYZER_LLM_PROVIDER", "azure")
+	_ = os.Setenv("ANALYZER_LLM_API_KEY", "test-key")
+
+	if _, err := LoadAnalyzerConfig(".", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for azure without base_url")
+	}
+	_ = os.Setenv("ANALYZER_LLM_BASE_URL", "https://example.openai.azure.com")
+	if _, err := LoadAnalyzerConfig(".", map[string]interface{}{}); err != nil {
+		t.Errorf("Expected no error for azure with base_url, got %v", err)
+	}
+
+	// Bedrock signs with AWS credentials instead of an API key
+	os.Clearenv()
+	_ = os.Setenv("ANALYZER_LLM_PROVIDER", "bedrock")
+	_ = os.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	_ = os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	if _, err := LoadAnalyzerConfig(".", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for bedrock without region")
+	}
+	_ = os.Setenv("AWS_REGION", "us-east-1")
+	if _, err := LoadAnalyzerConfig(".", map[string]interface{}{}); err != nil {
+		t.Errorf("Expected no error for bedrock with environment credentials, got %v", err)
+	}
+
+	_ = os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	if _, err := LoadAnalyzerConfig(".", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for bedrock without credentials")
+	}
+}
+
+func TestLoadAnalyzerConfig_VertexAI(t *testing.T) {
+	tmpDir := t.TempDir()
+	credentials := filepath.Join(tmpDir, "service-account.json")
+	_ = os.WriteFile(credentials, []byte(`{"type":"service_account","project_id":"sa-project"}`), 0600)
+
+	// The top-level gemini section, as saved by the config TUI
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	_ = os.WriteFile(projectConfig, []byte(`
+gemini:
+  use_vertex_ai: true
+  location: europe-west4
+analyzer:
+  llm:
+    provider: gemini
+    model: gemini-2.5-pro
+`), 0644)
+
+	os.Clearenv()
+	if _, err := LoadAnalyzerConfig(tmpDir, map[string]interface{}{}); err == nil {
+		t.Error("Expected error for Vertex AI without credentials")
+	}
+
+	// Vertex AI needs no API key; the project comes from the credentials
+	_ = os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentials)
+	cfg, err := LoadAnalyzerConfig(tmpDir, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !cfg.LLM.Gemini.UseVertexAI || cfg.LLM.Gemini.Location != "europe-west4" {
+		t.Errorf("Expected the gemini section to apply, got %+v", cfg.LLM.Gemini)
+	}
+	if project := cfg.LLM.Gemini.WithEnvDefaults().ProjectID; project != "sa-project" {
+		t.Errorf("Expected the credentials' project, got %q", project)
+	}
+}
+
+func TestLoadAnalyzerConfig_ContextWindow(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	_ = os.WriteFile(projectConfig, []byte(`
+analyzer:
+  llm:
+    provider: openai
+    model: llama3.1:8b
+    base_url: http://localhost:11434/v1
+    context_window: 32768
+`), 0644)
+
+	os.Clearenv()
+	_ = os.Setenv("ANALYZER_LLM_API_KEY", "test-key")
+	cfg, err := LoadAnalyzerConfig(tmpDir, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.LLM.ContextWindow != 32768 {
+		t.Errorf("Expected context_window 32768, got %d", cfg.LLM.ContextWindow)
+	}
+
+	_ = os.WriteFile(projectConfig, []byte(`
+analyzer:
+  llm:
+    provider: openai
+    context_window: -1
+`), 0644)
+	if _, err := LoadAnalyzerConfig(tmpDir, map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "context_window") {
+		t.Errorf("Expected error for a negative context_window, got %v", err)
+	}
+}
+
 func TestLoadAnalyzerConfig_RepoPath(t *testing.T) {
 	os.Clearenv()
 	_ = os.Setenv("ANALYZER_LLM_PROVIDER", "openai")
@@ -941,3 +1126,178 @@ check:
 		t.Error("Expected verbose=true")
 	}
 }
+
+func TestLoadCronjobConfig_YAMLAndOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	projectConfigContent := `
+cronjob:
+  forge: github
+  group: acme
+  concurrency: 2
+  ignore_projects:
+    - acme/legacy-*
+  ignore_subgroups: [acme/archive]
+  topics: [service, api]
+  min_repo_size_kb: 50
+`
+	_ = os.WriteFile(projectConfig, []byte(projectConfigContent), 0644)
+
+	os.Clearenv()
+
+	cfg, err := LoadCronjobConfig(tmpDir, map[string]interface{}{"concurrency": "4"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.GetForge() != "github" {
+		t.Errorf("Expected forge='github', got '%s'", cfg.GetForge())
+	}
+	if cfg.GetGroup() != "acme" {
+		t.Errorf("Expected group='acme', got '%s'", cfg.GetGroup())
+	}
+	if cfg.GetConcurrency() != 4 {
+		t.Errorf("Expected CLI concurrency=4, got %d", cfg.GetConcurrency())
+	}
+	if cfg.MaxDaysSinceLastCommit != 14 {
+		t.Errorf("Expected default max_days_since_last_commit=14, got %d", cfg.MaxDaysSinceLastCommit)
+	}
+	if len(cfg.IgnoreProjects) != 1 || cfg.IgnoreProjects[0] != "acme/legacy-*" {
+		t.Errorf("Expected ignore_projects=[acme/legacy-*], got %v", cfg.IgnoreProjects)
+	}
+	if len(cfg.IgnoreSubgroups) != 1 || len(cfg.Topics) != 2 || cfg.MinRepoSizeKB != 50 {
+		t.Errorf("Unexpected filters: subgroups=%v topics=%v min_size=%d", cfg.IgnoreSubgroups, cfg.Topics, cfg.MinRepoSizeKB)
+	}
+}
+
+func TestLoadCronjobConfig_InvalidForge(t *testing.T) {
+	os.Clearenv()
+
+	_, err := LoadCronjobConfig(t.TempDir(), map[string]interface{}{"forge": "bitbucket"})
+	if err == nil {
+		t.Fatal("Expected error for unsupported forge")
+	}
+}
+
+func TestLoadReviewConfig(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadReviewConfig(t.TempDir(), map[string]interface{}{
+		"project": "group/app",
+		"mr":      "12",
+	})
+	if err != nil {
+		t.Fatalf("LoadReviewConfig failed: %v", err)
+	}
+	if cfg.MRNumber != 12 || cfg.Project != "group/app" {
+		t.Errorf("unexpected merge request: %q !%d", cfg.Project, cfg.MRNumber)
+	}
+	if cfg.Forge != "gitlab" || cfg.OutputFormat != "text" || cfg.RepoPath != "." {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+
+	if _, err := LoadReviewConfig(t.TempDir(), map[string]interface{}{"project": "group/app"}); err == nil {
+		t.Error("Expected error when the merge request number is missing")
+	}
+}
+
+func TestLoadAgentDefinitions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	projectConfigContent := `
+agents:
+  security_analyzer:
+    file_patterns: ["*auth*.go", "*.pem"]
+    tools: [read_file]
+`
+	_ = os.WriteFile(projectConfig, []byte(projectConfigContent), 0644)
+
+	os.Clearenv()
+
+	defs, err := LoadAgentDefinitions(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	def, ok := defs["security_analyzer"]
+	if !ok {
+		t.Fatalf("Expected security_analyzer to be loaded, got %v", defs)
+	}
+	if def.DisplayName != "Security Analysis" || def.OutputFile != "security_analysis.md" {
+		t.Errorf("Unexpected defaults: display_name=%q output_file=%q", def.DisplayName, def.OutputFile)
+	}
+	if def.SystemPrompt != "security_analyzer_system" || def.UserPrompt != "security_analyzer_user" {
+		t.Errorf("Unexpected prompt keys: %q, %q", def.SystemPrompt, def.UserPrompt)
+	}
+	if len(def.FilePatterns) != 2 || len(def.Tools) != 1 {
+		t.Errorf("Unexpected patterns or tools: %v %v", def.FilePatterns, def.Tools)
+	}
+}
+
+func TestLoadAgentDefinitions_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no patterns":  "agents:\n  custom:\n    output_file: custom.md\n",
+		"output path":  "agents:\n  custom:\n    file_patterns: ['*.go']\n    output_file: ../custom.md\n",
+		"unknown tool": "agents:\n  custom:\n    file_patterns: ['*.go']\n    tools: [shell]\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+			_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+			_ = os.WriteFile(projectConfig, []byte(content), 0644)
+
+			os.Clearenv()
+
+			if _, err := LoadAgentDefinitions(tmpDir); err == nil {
+				t.Error("Expected a validation error")
+			}
+		})
+	}
+}
+
+func TestLoadForgeConfig(t *testing.T) {
+	os.Clearenv()
+	tmpDir := t.TempDir()
+	projectConfig := filepath.Join(tmpDir, ".ai", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(projectConfig), 0755)
+	_ = os.WriteFile(projectConfig, []byte(`
+gitea:
+  api_url: https://gitea.example.com
+  token: secret
+gitlab:
+  user_username: docs_bot
+`), 0644)
+
+	gitea, err := LoadForgeConfig(tmpDir, "gitea")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gitea.APIURL != "https://gitea.example.com" || gitea.Token != "secret" {
+		t.Errorf("Expected gitea settings from config, got %+v", gitea)
+	}
+	if gitea.UserName != "AI Analyzer" {
+		t.Errorf("Expected default user name, got %q", gitea.UserName)
+	}
+
+	github, err := LoadForgeConfig(tmpDir, "github")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if github.Token != "" || github.APIURL != "" {
+		t.Errorf("Expected empty github settings, got %+v", github)
+	}
+
+	gitlab, err := LoadGitLabConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gitlab.UserUsername != "docs_bot" || gitlab.APIURL != "https://gitlab.com" {
+		t.Errorf("Expected gitlab settings from config with defaults, got %+v", gitlab)
+	}
+}
@@ -1,6 +1,12 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,7 +18,7 @@ type BaseConfig struct {
 
 // LLMConfig holds LLM provider configuration
 type LLMConfig struct {
-	Provider    string         `mapstructure:"provider" yaml:"provider"` // openai, anthropic, gemini, ollama, lmstudio
+	Provider    string         `mapstructure:"provider" yaml:"provider"` // openai, anthropic, gemini, azure, bedrock, ollama, lmstudio
 	Model       string         `mapstructure:"model" yaml:"model"`
 	APIKey      string         `mapstructure:"api_key" yaml:"api_key"`
 	BaseURL     string         `mapstructure:"base_url" yaml:"base_url"` // Optional, for OpenAI-compatible APIs
@@ -21,6 +27,107 @@ type LLMConfig struct {
 	MaxTokens   int            `mapstructure:"max_tokens" yaml:"max_tokens"`
 	Temperature float64        `mapstructure:"temperature" yaml:"temperature"`
 	Cache       LLMCacheConfig `mapstructure:"cache" yaml:"cache"` // Cache configuration
+
+	// ContextWindow overrides the model's context window from the built-in
+	// registry, e.g. for local models. 0 uses the registry.
+	ContextWindow int `mapstructure:"context_window" yaml:"context_window,omitempty"`
+
+	// Pricing overrides the built-in price table, keyed by model name (or name prefix)
+	Pricing map[string]ModelPrice `mapstructure:"pricing" yaml:"pricing,omitempty"`
+
+	// Azure and Bedrock hold the settings of those providers
+	Azure   AzureConfig   `mapstructure:"azure" yaml:"azure,omitempty"`
+	Bedrock BedrockConfig `mapstructure:"bedrock" yaml:"bedrock,omitempty"`
+
+	// Gemini selects Vertex AI for the gemini provider. Defaults to the top-level gemini section.
+	Gemini GeminiConfig `mapstructure:"gemini" yaml:"gemini,omitempty"`
+
+	// Fallbacks are tried in order when the provider is overloaded, rate limited or out of quota
+	Fallbacks []LLMFallback `mapstructure:"fallbacks" yaml:"fallbacks,omitempty"`
+}
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when none is configured
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// AzureConfig holds Azure OpenAI configuration. The resource endpoint
+// (https://<resource>.openai.azure.com) is the LLM base URL.
+type AzureConfig struct {
+	Deployment string `mapstructure:"deployment" yaml:"deployment,omitempty"`   // Defaults to the model name
+	APIVersion string `mapstructure:"api_version" yaml:"api_version,omitempty"` // Defaults to DefaultAzureAPIVersion
+}
+
+// BedrockConfig holds AWS Bedrock configuration. Unset fields fall back to the
+// standard AWS environment variables; an LLM API key is used as a Bedrock API
+// key instead of signing with access keys.
+type BedrockConfig struct {
+	Region          string `mapstructure:"region" yaml:"region,omitempty"`
+	AccessKeyID     string `mapstructure:"access_key_id" yaml:"access_key_id,omitempty"`
+	SecretAccessKey string `mapstructure:"secret_access_key" yaml:"secret_access_key,omitempty"`
+	SessionToken    string `mapstructure:"session_token" yaml:"session_token,omitempty"`
+}
+
+// WithEnvDefaults fills unset fields from AWS_REGION (or AWS_DEFAULT_REGION),
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+func (c BedrockConfig) WithEnvDefaults() BedrockConfig {
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_REGION")
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if c.AccessKeyID == "" && c.SecretAccessKey == "" {
+		c.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		c.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if c.SessionToken == "" {
+			c.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	return c
+}
+
+// LLMFallback is a provider/model to fail over to. Unset fields are inherited
+// from the primary provider; the API key and base URL only when the provider is the same.
+type LLMFallback struct {
+	Provider string `mapstructure:"provider" yaml:"provider"`
+	Model    string `mapstructure:"model" yaml:"model"`
+	APIKey   string `mapstructure:"api_key" yaml:"api_key"`
+	BaseURL  string `mapstructure:"base_url" yaml:"base_url"`
+}
+
+// Backends returns the provider configuration followed by the configuration
+// of each fallback, none of them with fallbacks of their own
+func (c LLMConfig) Backends() []LLMConfig {
+	primary := c
+	primary.Fallbacks = nil
+	backends := []LLMConfig{primary}
+	for _, fallback := range c.Fallbacks {
+		backend := primary
+		if fallback.Provider != "" && fallback.Provider != primary.Provider {
+			backend.Provider = fallback.Provider
+			backend.APIKey = ""
+			backend.BaseURL = ""
+		}
+		if fallback.Model != "" {
+			backend.Model = fallback.Model
+		}
+		if fallback.APIKey != "" {
+			backend.APIKey = fallback.APIKey
+		}
+		if fallback.BaseURL != "" {
+			backend.BaseURL = fallback.BaseURL
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// ModelPrice is the price of a model in USD per million tokens. Prompt cache
+// prices default to 1.25x (writes) and 0.1x (reads) the input price.
+type ModelPrice struct {
+	Input      float64 `mapstructure:"input" yaml:"input"`
+	Output     float64 `mapstructure:"output" yaml:"output"`
+	CacheWrite float64 `mapstructure:"cache_write" yaml:"cache_write,omitempty"`
+	CacheRead  float64 `mapstructure:"cache_read" yaml:"cache_read,omitempty"`
 }
 
 // LLMCacheConfig holds LLM response cache configuration
@@ -31,11 +138,85 @@ type LLMCacheConfig struct {
 	CachePath string `mapstructure:"cache_path" yaml:"cache_path"` // Path to disk cache file
 }
 
-// GeminiConfig holds Gemini-specific configuration
+// DefaultVertexLocation is the Vertex AI location used when none is configured
+const DefaultVertexLocation = "us-central1"
+
+// GeminiConfig holds Gemini-specific configuration. With UseVertexAI the
+// gemini provider calls Vertex AI with OAuth tokens from CredentialsFile, a
+// service account key or an application default credentials file.
 type GeminiConfig struct {
-	UseVertexAI bool   `mapstructure:"use_vertex_ai" yaml:"use_vertex_ai"`
-	ProjectID   string `mapstructure:"project_id" yaml:"project_id"`
-	Location    string `mapstructure:"location" yaml:"location"`
+	UseVertexAI     bool   `mapstructure:"use_vertex_ai" yaml:"use_vertex_ai"`
+	ProjectID       string `mapstructure:"project_id" yaml:"project_id"`
+	Location        string `mapstructure:"location" yaml:"location"`
+	CredentialsFile string `mapstructure:"credentials_file" yaml:"credentials_file,omitempty"`
+}
+
+// WithEnvDefaults fills unset fields from GOOGLE_APPLICATION_CREDENTIALS (or
+// the gcloud application default credentials file), GOOGLE_CLOUD_PROJECT (or
+// the project of the credentials) and GOOGLE_CLOUD_LOCATION
+func (c GeminiConfig) WithEnvDefaults() GeminiConfig {
+	if c.CredentialsFile == "" {
+		c.CredentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if rest, ok := strings.CutPrefix(c.CredentialsFile, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			c.CredentialsFile = filepath.Join(home, rest)
+		}
+	}
+	if c.CredentialsFile == "" {
+		if path := wellKnownADCFile(); path != "" {
+			if _, err := os.Stat(path); err == nil {
+				c.CredentialsFile = path
+			}
+		}
+	}
+	if c.ProjectID == "" {
+		c.ProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if c.ProjectID == "" && c.CredentialsFile != "" {
+		c.ProjectID = credentialsProject(c.CredentialsFile)
+	}
+	if c.Location == "" {
+		c.Location = os.Getenv("GOOGLE_CLOUD_LOCATION")
+	}
+	if c.Location == "" {
+		c.Location = DefaultVertexLocation
+	}
+	return c
+}
+
+// wellKnownADCFile is where `gcloud auth application-default login` writes credentials
+func wellKnownADCFile() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "gcloud", "application_default_credentials.json")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// credentialsProject returns the project a credentials file belongs to, if any
+func credentialsProject(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var creds struct {
+		ProjectID      string `json:"project_id"`
+		QuotaProjectID string `json:"quota_project_id"`
+	}
+	if json.Unmarshal(data, &creds) != nil {
+		return ""
+	}
+	if creds.ProjectID != "" {
+		return creds.ProjectID
+	}
+	return creds.QuotaProjectID
 }
 
 // RetryConfig holds HTTP retry configuration
@@ -55,11 +236,42 @@ type AnalyzerConfig struct {
 	ExcludeDeps      bool        `mapstructure:"exclude_dependencies" yaml:"exclude_dependencies"`
 	ExcludeReqFlow   bool        `mapstructure:"exclude_request_flow" yaml:"exclude_request_flow"`
 	ExcludeAPI       bool        `mapstructure:"exclude_api_analysis" yaml:"exclude_api_analysis"`
+	ExcludeSecurity  bool        `mapstructure:"exclude_security_analysis" yaml:"exclude_security_analysis"`
+	ExcludeTesting   bool        `mapstructure:"exclude_testing_analysis" yaml:"exclude_testing_analysis"`
+	ExcludeInfra     bool        `mapstructure:"exclude_infrastructure_analysis" yaml:"exclude_infrastructure_analysis"`
+	Monorepo         bool        `mapstructure:"monorepo" yaml:"monorepo"` // Analyze each module separately, then write an overview
+	ExcludePaths     []string    `mapstructure:"-" yaml:"-"`               // Directories left out of the file scan (nested modules)
 	MaxWorkers       int         `mapstructure:"max_workers" yaml:"max_workers"`
 	MaxHashWorkers   int         `mapstructure:"max_hash_workers" yaml:"max_hash_workers"`
 	RetryConfig      RetryConfig `mapstructure:"retry" yaml:"retry"`
-	Force            bool        `mapstructure:"force" yaml:"force"`             // Force full re-analysis, ignore cache
-	Incremental      bool        `mapstructure:"incremental" yaml:"incremental"` // Enable incremental analysis (default: true)
+	Force            bool        `mapstructure:"force" yaml:"force"`                               // Force full re-analysis, ignore cache
+	Incremental      bool        `mapstructure:"incremental" yaml:"incremental"`                   // Enable incremental analysis (default: true)
+	OnlyAgents       []string    `mapstructure:"only_agents" yaml:"-"`                             // Restrict the run to these agents (empty = all)
+	Verify           string      `mapstructure:"verify" yaml:"verify,omitempty"`                   // Check generated documents against the repository: off, mark or repair
+	Resume           bool        `mapstructure:"resume" yaml:"-"`                                  // Continue an interrupted run from .ai/checkpoints
+	SectionUpdates   bool        `mapstructure:"section_updates" yaml:"section_updates,omitempty"` // Incremental runs regenerate only the affected sections
+
+	// Run budget, split evenly between the agents of a run (0 = unlimited)
+	MaxTokensPerRun     int     `mapstructure:"max_tokens_per_run" yaml:"max_tokens_per_run,omitempty"`
+	MaxCostPerRun       float64 `mapstructure:"max_cost_per_run" yaml:"max_cost_per_run,omitempty"`           // USD, priced with llm.pricing
+	BudgetFallbackModel string  `mapstructure:"budget_fallback_model" yaml:"budget_fallback_model,omitempty"` // Used for agents started after the budget was hit
+}
+
+// Verification modes of generated documents (analyzer.verify)
+const (
+	VerifyOff    = "off"
+	VerifyMark   = "mark"   // Flag references not found in the repository inline
+	VerifyRepair = "repair" // Ask the agent to fix them first, then flag what remains
+)
+
+// VerificationEnabled returns whether generated documents are checked against the repository
+func (c *AnalyzerConfig) VerificationEnabled() bool {
+	return c.Verify == VerifyMark || c.Verify == VerifyRepair
+}
+
+// HasBudget returns whether a token or cost limit is configured
+func (c *AnalyzerConfig) HasBudget() bool {
+	return c.MaxTokensPerRun > 0 || c.MaxCostPerRun > 0
 }
 
 // DocumenterConfig holds configuration for readme generation
@@ -83,6 +295,20 @@ type CronjobConfig struct {
 	MaxDaysSinceLastCommit int    `mapstructure:"max_days_since_last_commit" yaml:"max_days_since_last_commit"`
 	WorkingPath            string `mapstructure:"working_path" yaml:"working_path"`
 	GroupProjectID         int    `mapstructure:"group_project_id" yaml:"group_project_id"`
+	Concurrency            int    `mapstructure:"concurrency" yaml:"concurrency"`         // Number of projects processed in parallel
+	ProjectTimeout         int    `mapstructure:"project_timeout" yaml:"project_timeout"` // Per-project timeout in minutes
+	Forge                  string `mapstructure:"forge" yaml:"forge"`                     // gitlab, github, gitea
+	Group                  string `mapstructure:"group" yaml:"group"`                     // Organization/group name (GitHub, Gitea); overrides group_project_id
+	MRPolicy               string `mapstructure:"mr_policy" yaml:"mr_policy"`             // create (one branch per day) or update (reuse one open MR)
+	BranchName             string `mapstructure:"branch_name" yaml:"branch_name"`         // Stable branch used by the update policy
+
+	// Project selection. Glob lists use path.Match syntax against the full project path.
+	IgnoreProjects  []string `mapstructure:"ignore_projects" yaml:"ignore_projects,omitempty"`   // e.g. group/legacy-*
+	IgnoreSubgroups []string `mapstructure:"ignore_subgroups" yaml:"ignore_subgroups,omitempty"` // e.g. group/archive (nested subgroups included)
+	Topics          []string `mapstructure:"topics" yaml:"topics,omitempty"`                     // Require at least one of these topics/labels
+	Visibility      []string `mapstructure:"visibility" yaml:"visibility,omitempty"`             // Allowed visibilities: public, internal, private
+	Languages       []string `mapstructure:"languages" yaml:"languages,omitempty"`               // Allowed primary languages (case-insensitive)
+	MinRepoSizeKB   int      `mapstructure:"min_repo_size_kb" yaml:"min_repo_size_kb,omitempty"` // Skip repositories smaller than this; repositories of unknown size are kept
 }
 
 // GitLabConfig holds GitLab integration configuration
@@ -94,6 +320,14 @@ type GitLabConfig struct {
 	OAuthToken   string `mapstructure:"oauth_token" yaml:"oauth_token"`
 }
 
+// ForgeConfig holds GitHub or Gitea integration configuration
+type ForgeConfig struct {
+	APIURL    string `mapstructure:"api_url" yaml:"api_url"`
+	Token     string `mapstructure:"token" yaml:"token"`
+	UserName  string `mapstructure:"user_name" yaml:"user_name"`
+	UserEmail string `mapstructure:"user_email" yaml:"user_email"`
+}
+
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	LogDir       string `mapstructure:"log_dir" yaml:"log_dir"`
@@ -101,19 +335,36 @@ type LoggingConfig struct {
 	ConsoleLevel string `mapstructure:"console_level" yaml:"console_level"` // debug, info, warn, error
 }
 
+// AgentDefinition declares a custom analysis agent in the "agents" section of
+// .ai/config.yaml. Its prompts are looked up in the prompt files, including the
+// project overrides in .ai/prompts.
+type AgentDefinition struct {
+	DisplayName  string   `mapstructure:"display_name" yaml:"display_name,omitempty"`
+	Description  string   `mapstructure:"description" yaml:"description,omitempty"`
+	SystemPrompt string   `mapstructure:"system_prompt" yaml:"system_prompt,omitempty"` // Default: <name>_system
+	UserPrompt   string   `mapstructure:"user_prompt" yaml:"user_prompt,omitempty"`     // Default: <name>_user
+	FilePatterns []string `mapstructure:"file_patterns" yaml:"file_patterns,omitempty"` // Changes to matching files trigger a re-run
+	OutputFile   string   `mapstructure:"output_file" yaml:"output_file,omitempty"`     // File name in .ai/docs
+	Tools        []string `mapstructure:"tools" yaml:"tools,omitempty"`                 // read_file, list_files, search_files; default all
+	DependsOn    []string `mapstructure:"depends_on" yaml:"depends_on,omitempty"`       // Agents whose documents are passed to this agent's prompt
+}
+
 // CurrentConfigVersion is the current schema version for config files
 const CurrentConfigVersion = 1
 
 // GlobalConfig holds top-level configuration from .ai/config.yaml
 type GlobalConfig struct {
-	Version    int              `mapstructure:"version" yaml:"version"`
-	Analyzer   AnalyzerConfig   `mapstructure:"analyzer" yaml:"analyzer"`
-	Documenter DocumenterConfig `mapstructure:"documenter" yaml:"documenter"`
-	AIRules    AIRulesConfig    `mapstructure:"ai_rules" yaml:"ai_rules"`
-	Cronjob    CronjobConfig    `mapstructure:"cronjob" yaml:"cronjob"`
-	GitLab     GitLabConfig     `mapstructure:"gitlab" yaml:"gitlab"`
-	Gemini     GeminiConfig     `mapstructure:"gemini" yaml:"gemini"`
-	Logging    LoggingConfig    `mapstructure:"logging" yaml:"logging"`
+	Version    int                        `mapstructure:"version" yaml:"version"`
+	Analyzer   AnalyzerConfig             `mapstructure:"analyzer" yaml:"analyzer"`
+	Documenter DocumenterConfig           `mapstructure:"documenter" yaml:"documenter"`
+	AIRules    AIRulesConfig              `mapstructure:"ai_rules" yaml:"ai_rules"`
+	Cronjob    CronjobConfig              `mapstructure:"cronjob" yaml:"cronjob"`
+	GitLab     GitLabConfig               `mapstructure:"gitlab" yaml:"gitlab"`
+	GitHub     ForgeConfig                `mapstructure:"github" yaml:"github"`
+	Gitea      ForgeConfig                `mapstructure:"gitea" yaml:"gitea"`
+	Gemini     GeminiConfig               `mapstructure:"gemini" yaml:"gemini"`
+	Logging    LoggingConfig              `mapstructure:"logging" yaml:"logging"`
+	Agents     map[string]AgentDefinition `mapstructure:"agents" yaml:"agents,omitempty"`
 }
 
 // GetTimeout returns the timeout as a time.Duration
@@ -182,15 +433,78 @@ func (c *AnalyzerConfig) GetMaxHashWorkers() int {
 	return c.MaxHashWorkers
 }
 
+// GetConcurrency returns the project-level concurrency with a default
+func (c *CronjobConfig) GetConcurrency() int {
+	if c.Concurrency <= 0 {
+		return 1 // Default: one project at a time
+	}
+	return c.Concurrency
+}
+
+// GetForge returns the forge implementation name with a default
+func (c *CronjobConfig) GetForge() string {
+	if c.Forge == "" {
+		return "gitlab"
+	}
+	return c.Forge
+}
+
+// GetMRPolicy returns the merge request policy with a default
+func (c *CronjobConfig) GetMRPolicy() string {
+	if c.MRPolicy == "" {
+		return "create"
+	}
+	return c.MRPolicy
+}
+
+// GetBranchName returns the stable documentation branch used by the update policy
+func (c *CronjobConfig) GetBranchName() string {
+	if c.BranchName == "" {
+		return "ai-analyzer"
+	}
+	return c.BranchName
+}
+
+// GetGroup returns the group or organization to scan, falling back to the numeric GitLab group ID
+func (c *CronjobConfig) GetGroup() string {
+	if c.Group != "" {
+		return c.Group
+	}
+	if c.GroupProjectID > 0 {
+		return strconv.Itoa(c.GroupProjectID)
+	}
+	return ""
+}
+
+// GetProjectTimeout returns the per-project timeout as a time.Duration with a default
+func (c *CronjobConfig) GetProjectTimeout() time.Duration {
+	if c.ProjectTimeout <= 0 {
+		return 60 * time.Minute // Default timeout
+	}
+	return time.Duration(c.ProjectTimeout) * time.Minute
+}
+
 // CheckConfig holds configuration for the check command (drift detection)
 type CheckConfig struct {
 	BaseConfig     `yaml:",inline"`
 	MaxHashWorkers int    `mapstructure:"max_hash_workers" yaml:"max_hash_workers"`
 	OutputFormat   string `mapstructure:"output_format" yaml:"output_format"` // text, json
 	Verbose        bool   `mapstructure:"verbose" yaml:"verbose"`
+	Monorepo       bool   `mapstructure:"monorepo" yaml:"monorepo"` // Report drift per module
 }
 
 // GetMaxHashWorkers returns the max hash workers with a default (0 = use CPU count with max of 8)
 func (c *CheckConfig) GetMaxHashWorkers() int {
 	return c.MaxHashWorkers
 }
+
+// ReviewConfig holds configuration for the review command (doc drift on a pull/merge request)
+type ReviewConfig struct {
+	BaseConfig   `yaml:",inline"`
+	Forge        string `mapstructure:"forge" yaml:"forge"`                 // gitlab, github or gitea
+	Project      string `mapstructure:"project" yaml:"project"`             // Project ID or full path (owner/repo)
+	MRNumber     int    `mapstructure:"mr" yaml:"mr"`                       // MR IID or PR number
+	OutputFormat string `mapstructure:"output_format" yaml:"output_format"` // text, json
+	DryRun       bool   `mapstructure:"dry_run" yaml:"dry_run"`             // Print the comment instead of posting it
+	NoPatch      bool   `mapstructure:"no_patch" yaml:"no_patch"`           // Skip regenerating stale sections
+}
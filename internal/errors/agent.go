@@ -106,6 +106,44 @@ func NewAgentTimeoutError(agentName string, timeoutSeconds int) *AgentTimeoutErr
 	}
 }
 
+// BudgetExceededError is raised when an agent is stopped for exceeding its share of the run budget
+type BudgetExceededError struct {
+	*AIDocGenError
+}
+
+// NewBudgetExceededError creates a new budget exceeded error.
+// resource is "tokens" or "cost"; cost amounts are in USD.
+func NewBudgetExceededError(agentName, resource string, used, limit float64) *BudgetExceededError {
+	amount := func(v float64) string {
+		if resource == "cost" {
+			return fmt.Sprintf("$%.4f", v)
+		}
+		return fmt.Sprintf("%.0f tokens", v)
+	}
+
+	return &BudgetExceededError{
+		AIDocGenError: &AIDocGenError{
+			Message: fmt.Sprintf("Agent '%s' stopped: %s budget exceeded (%s of %s)", agentName, resource, amount(used), amount(limit)),
+			Context: &ErrorContext{
+				Operation: "Agent Execution",
+				Component: agentName,
+				Details: map[string]interface{}{
+					"resource": resource,
+					"used":     used,
+					"limit":    limit,
+				},
+				Suggestions: []string{
+					"Increase max_tokens_per_run or max_cost_per_run",
+					"Set budget_fallback_model to a cheaper model",
+					"Exclude analyses you do not need",
+				},
+				Recoverable: true,
+			},
+			ExitCode: ExitPartialSuccess,
+		},
+	}
+}
+
 // ToolExecutionError is raised when a tool execution fails
 type ToolExecutionError struct {
 	*AIDocGenError
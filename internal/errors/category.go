@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+)
+
+// Error categories used in machine-readable reports
+const (
+	CategoryConfig     = "config"
+	CategoryValidation = "validation"
+	CategoryLLM        = "llm"
+	CategoryAgent      = "agent"
+	CategoryTimeout    = "timeout"
+	CategoryBudget     = "budget"
+	CategoryForgeAuth  = "forge_auth"
+	CategoryForgeAPI   = "forge_api"
+	CategoryGitClone   = "git_clone"
+	CategoryAnalysis   = "analysis"
+	CategoryCronjob    = "cronjob"
+	CategoryUnknown    = "unknown"
+)
+
+// Category classifies an error chain by the most specific application error it
+// contains. Errors without an application error are reported as CategoryUnknown.
+func Category(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// Ordered from most to least specific: an analysis error wrapping an LLM
+	// connection failure is reported as llm
+	switch {
+	case as[*BudgetExceededError](err):
+		return CategoryBudget
+	case stderrors.Is(err, context.DeadlineExceeded), as[*AgentTimeoutError](err):
+		return CategoryTimeout
+	case as[*GitLabAuthError](err), as[*ForgeAuthError](err):
+		return CategoryForgeAuth
+	case as[*GitLabAPIError](err), as[*ForgeAPIError](err), as[*GitLabError](err):
+		return CategoryForgeAPI
+	case as[*GitCloneError](err):
+		return CategoryGitClone
+	case as[*LLMConnectionError](err), as[*LLMResponseError](err):
+		return CategoryLLM
+	case as[*AgentError](err), as[*ToolExecutionError](err):
+		return CategoryAgent
+	case as[*ConfigurationError](err), as[*MissingEnvVarError](err), as[*InvalidEnvVarError](err), as[*ConfigFileError](err):
+		return CategoryConfig
+	case as[*ValidationError](err), as[*MissingFileError](err), as[*InvalidPathError](err), as[*OutputValidationError](err):
+		return CategoryValidation
+	case as[*AnalysisError](err), as[*DocumentationError](err):
+		return CategoryAnalysis
+	case as[*CronjobError](err), as[*HandlerError](err):
+		return CategoryCronjob
+	default:
+		return CategoryUnknown
+	}
+}
+
+func as[T error](err error) bool {
+	var target T
+	return stderrors.As(err, &target)
+}
@@ -10,7 +10,7 @@ const (
 	ExitLLMError        ExitCode = 4
 	ExitAgentError      ExitCode = 5
 	ExitIOError         ExitCode = 6
-	ExitGitLabError     ExitCode = 7
+	ExitForgeError      ExitCode = 7 // GitLab, GitHub or Gitea API failure
 	ExitPartialSuccess  ExitCode = 10
 )
 
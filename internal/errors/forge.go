@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// ForgeAPIError is raised when a GitHub or Gitea API call fails
+type ForgeAPIError struct {
+	*AIDocGenError
+}
+
+// NewForgeAPIError creates a new forge API error
+func NewForgeAPIError(forge, operation, statusCode string, cause error) *ForgeAPIError {
+	return &ForgeAPIError{
+		AIDocGenError: &AIDocGenError{
+			Message: fmt.Sprintf("%s API error during %s", forge, operation),
+			Cause:   cause,
+			Context: &ErrorContext{
+				Operation: "Forge API Call",
+				Component: forge + " Client",
+				Details: map[string]interface{}{
+					"forge":       forge,
+					"operation":   operation,
+					"status_code": statusCode,
+				},
+				Suggestions: []string{
+					fmt.Sprintf("Check the %s API URL is correct", forge),
+					"Verify the organization/repository exists",
+					"Check API rate limits",
+					"Try again later",
+				},
+				Recoverable: true,
+			},
+			ExitCode: ExitForgeError,
+		},
+	}
+}
+
+// ForgeAuthError is raised when GitHub or Gitea authentication fails
+type ForgeAuthError struct {
+	*AIDocGenError
+}
+
+// NewForgeAuthError creates a new forge authentication error
+func NewForgeAuthError(forge, tokenEnvVar string, cause error) *ForgeAuthError {
+	return &ForgeAuthError{
+		AIDocGenError: &AIDocGenError{
+			Message: fmt.Sprintf("%s authentication failed", forge),
+			Cause:   cause,
+			Context: &ErrorContext{
+				Operation: fmt.Sprintf("%s Authentication", forge),
+				Component: forge + " Client",
+				Suggestions: []string{
+					fmt.Sprintf("Verify %s is set correctly", tokenEnvVar),
+					"Check if the token has not expired",
+					"Ensure token has repository read/write and pull request permissions",
+				},
+				Recoverable: false,
+			},
+			ExitCode: ExitForgeError,
+		},
+	}
+}
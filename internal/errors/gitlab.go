@@ -14,7 +14,7 @@ func NewGitLabError(message string) *GitLabError {
 	return &GitLabError{
 		AIDocGenError: &AIDocGenError{
 			Message:  message,
-			ExitCode: ExitGitLabError,
+			ExitCode: ExitForgeError,
 		},
 	}
 }
@@ -41,7 +41,7 @@ func NewGitLabAuthError(cause error) *GitLabAuthError {
 				},
 				Recoverable: false,
 			},
-			ExitCode: ExitGitLabError,
+			ExitCode: ExitForgeError,
 		},
 	}
 }
@@ -72,7 +72,7 @@ func NewGitLabAPIError(operation, statusCode string, cause error) *GitLabAPIErro
 				},
 				Recoverable: true,
 			},
-			ExitCode: ExitGitLabError,
+			ExitCode: ExitForgeError,
 		},
 	}
 }
@@ -103,7 +103,7 @@ func NewGitCloneError(repoURL, reason string, cause error) *GitCloneError {
 				},
 				Recoverable: false,
 			},
-			ExitCode: ExitGitLabError,
+			ExitCode: ExitForgeError,
 		},
 	}
 }
@@ -0,0 +1,143 @@
+package forge
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// ProjectFilter determines if a project should be analyzed.
+// Empty lists and zero values disable the corresponding check.
+type ProjectFilter struct {
+	MaxDaysSinceLastCommit int
+	// IgnoreProjects holds glob patterns matched against the full project path
+	IgnoreProjects []string
+	// IgnoreSubgroups holds glob patterns matched against every parent namespace
+	// of a project, so ignoring a subgroup also ignores its nested subgroups
+	IgnoreSubgroups []string
+	// Topics requires at least one matching topic (case-insensitive)
+	Topics []string
+	// Visibility lists the allowed visibilities
+	Visibility []string
+	// Languages lists the allowed primary languages (case-insensitive)
+	Languages []string
+	// MinSizeKB skips projects known to be smaller; projects of unknown size are kept
+	MinSizeKB int
+	// UpdateExisting keeps projects whose documentation branch or open PR
+	// already exists, so the update policy can push onto them
+	UpdateExisting bool
+}
+
+// NewProjectFilter builds a filter from the cronjob configuration
+func NewProjectFilter(cfg config.CronjobConfig) ProjectFilter {
+	return ProjectFilter{
+		MaxDaysSinceLastCommit: cfg.MaxDaysSinceLastCommit,
+		IgnoreProjects:         cfg.IgnoreProjects,
+		IgnoreSubgroups:        cfg.IgnoreSubgroups,
+		Topics:                 cfg.Topics,
+		Visibility:             cfg.Visibility,
+		Languages:              cfg.Languages,
+		MinSizeKB:              cfg.MinRepoSizeKB,
+		UpdateExisting:         cfg.GetMRPolicy() == "update",
+	}
+}
+
+// ShouldAnalyze determines if a project should be analyzed
+func ShouldAnalyze(ctx context.Context, f Forge, project Project, filter ProjectFilter, branchName string) (bool, error) {
+	// Skip archived projects
+	if project.Archived {
+		return false, nil
+	}
+
+	// Skip ignored projects and subgroups
+	if matchesAny(filter.IgnoreProjects, project.FullName) || inIgnoredSubgroup(filter.IgnoreSubgroups, project.FullName) {
+		return false, nil
+	}
+
+	// Check if last commit is too old
+	if filter.MaxDaysSinceLastCommit > 0 {
+		daysSince := time.Since(project.LastActivityAt).Hours() / 24
+		if int(daysSince) > filter.MaxDaysSinceLastCommit {
+			return false, nil
+		}
+	}
+
+	// Check project metadata
+	if len(filter.Topics) > 0 && !containsAnyFold(project.Topics, filter.Topics) {
+		return false, nil
+	}
+	if len(filter.Visibility) > 0 && !containsAnyFold([]string{project.Visibility}, filter.Visibility) {
+		return false, nil
+	}
+	// A size of 0 is unknown (e.g. GitLab without statistics access) and never filtered
+	if filter.MinSizeKB > 0 && project.SizeKB > 0 && project.SizeKB < filter.MinSizeKB {
+		return false, nil
+	}
+	if len(filter.Languages) > 0 {
+		language := project.Language
+		if resolver, ok := f.(LanguageResolver); ok && language == "" {
+			var err error
+			if language, err = resolver.PrimaryLanguage(ctx, project); err != nil {
+				return false, err
+			}
+		}
+		if !containsAnyFold([]string{language}, filter.Languages) {
+			return false, nil
+		}
+	}
+
+	if filter.UpdateExisting {
+		return true, nil
+	}
+
+	// Check if branch already exists
+	if branchExists, err := f.BranchExists(ctx, project, branchName); err == nil && branchExists {
+		return false, nil
+	}
+
+	// Check if open PR exists
+	if pr, err := f.FindOpenPR(ctx, project, branchName); err == nil && pr != nil {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// matchesAny reports whether name matches one of the glob patterns.
+// Malformed patterns never match.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(strings.Trim(pattern, "/"), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inIgnoredSubgroup reports whether any parent namespace of fullName matches a pattern
+func inIgnoredSubgroup(patterns []string, fullName string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	for namespace := path.Dir(fullName); namespace != "." && namespace != "/"; namespace = path.Dir(namespace) {
+		if matchesAny(patterns, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAnyFold reports whether values and wanted share an element, ignoring case
+func containsAnyFold(values, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
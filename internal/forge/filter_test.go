@@ -0,0 +1,156 @@
+package forge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestShouldAnalyze_IgnoreGlobs(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	f := NewGitLab(config.GitLabConfig{APIURL: fake.URL()}, logging.NewNopLogger())
+	filter := ProjectFilter{
+		IgnoreProjects:  []string{"group/legacy-*", "group/sandbox"},
+		IgnoreSubgroups: []string{"group/archive", "group/*/experiments"},
+	}
+
+	tests := []struct {
+		fullName string
+		expected bool
+	}{
+		{"group/app", true},
+		{"group/legacy-billing", false},
+		{"group/sandbox", false},
+		{"group/sandbox-two", true},
+		{"group/archive/old-app", false},
+		{"group/archive/deep/old-app", false},
+		{"group/archived-app", true},
+		{"group/team-a/experiments/spike", false},
+		{"group/team-a/app", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullName, func(t *testing.T) {
+			project := Project{ID: 1, FullName: tt.fullName, LastActivityAt: time.Now()}
+			ok, err := ShouldAnalyze(context.Background(), f, project, filter, "ai-analyzer")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestShouldAnalyze_Metadata(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	f := NewGitLab(config.GitLabConfig{APIURL: fake.URL()}, logging.NewNopLogger())
+	filter := ProjectFilter{
+		Topics:     []string{"service", "API"},
+		Visibility: []string{"internal", "private"},
+		Languages:  []string{"go", "python"},
+		MinSizeKB:  100,
+	}
+	base := Project{
+		ID:             1,
+		FullName:       "group/app",
+		LastActivityAt: time.Now(),
+		Topics:         []string{"api"},
+		Visibility:     "internal",
+		Language:       "Go",
+		SizeKB:         500,
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(p *Project)
+		expected bool
+	}{
+		{"matches all", func(p *Project) {}, true},
+		{"no matching topic", func(p *Project) { p.Topics = []string{"library"} }, false},
+		{"no topics", func(p *Project) { p.Topics = nil }, false},
+		{"public", func(p *Project) { p.Visibility = "public" }, false},
+		{"other language", func(p *Project) { p.Language = "Ruby" }, false},
+		{"too small", func(p *Project) { p.SizeKB = 10 }, false},
+		{"unknown size without statistics", func(p *Project) { p.SizeKB = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := base
+			tt.mutate(&project)
+			ok, err := ShouldAnalyze(context.Background(), f, project, filter, "ai-analyzer")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestShouldAnalyze_ResolvesGitLabLanguage(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1, Languages: map[string]float64{"Go": 80.5, "Shell": 19.5}})
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 2, Languages: map[string]float64{"Java": 100}})
+	f := NewGitLab(config.GitLabConfig{APIURL: fake.URL()}, logging.NewNopLogger())
+	filter := ProjectFilter{Languages: []string{"go"}}
+
+	for id, expected := range map[int]bool{1: true, 2: false} {
+		project := Project{ID: id, LastActivityAt: time.Now()}
+		ok, err := ShouldAnalyze(context.Background(), f, project, filter, "ai-analyzer")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok != expected {
+			t.Errorf("project %d: expected %v, got %v", id, expected, ok)
+		}
+	}
+}
+
+func TestNewProjectFilter(t *testing.T) {
+	filter := NewProjectFilter(config.CronjobConfig{
+		MaxDaysSinceLastCommit: 7,
+		IgnoreProjects:         []string{"a/*"},
+		IgnoreSubgroups:        []string{"a/b"},
+		Topics:                 []string{"docs"},
+		MinRepoSizeKB:          10,
+		MRPolicy:               "update",
+	})
+
+	if filter.MaxDaysSinceLastCommit != 7 || filter.MinSizeKB != 10 || !filter.UpdateExisting {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if len(filter.IgnoreProjects) != 1 || len(filter.IgnoreSubgroups) != 1 || len(filter.Topics) != 1 {
+		t.Errorf("expected lists to be copied, got %+v", filter)
+	}
+}
+
+func TestShouldAnalyze_GitLabProjectWithoutStatistics(t *testing.T) {
+	// Tokens below Reporter get no statistics, so the size is unknown
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1, PathWithNamespace: "group/app", LastActivityAt: time.Now()})
+	f := NewGitLab(config.GitLabConfig{APIURL: fake.URL()}, logging.NewNopLogger())
+
+	projects, err := f.ListProjects(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 1 || projects[0].SizeKB != 0 {
+		t.Fatalf("expected one project of unknown size, got %+v", projects)
+	}
+
+	ok, err := ShouldAnalyze(context.Background(), f, projects[0], ProjectFilter{MinSizeKB: 100}, "ai-analyzer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Error("expected a project of unknown size not to be skipped by min_repo_size_kb")
+	}
+}
@@ -0,0 +1,141 @@
+// Package forge abstracts the code hosting platforms (GitLab, GitHub, Gitea)
+// used by the cronjob to discover repositories and open documentation PRs, and
+// by the review command to inspect and comment on pull/merge requests.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+// Supported forge names for the cronjob "forge" setting
+const (
+	KindGitLab = "gitlab"
+	KindGitHub = "github"
+	KindGitea  = "gitea"
+)
+
+// Forge is the set of operations gendocs needs from a code hosting platform
+type Forge interface {
+	// Name returns the forge kind (gitlab, github, gitea)
+	Name() string
+	// ListProjects returns all repositories in a group or organization
+	ListProjects(ctx context.Context, group string) ([]Project, error)
+	// GetProject returns a single repository by full path (or numeric ID on GitLab)
+	GetProject(ctx context.Context, ref string) (*Project, error)
+	// BranchExists checks if a branch exists in a project
+	BranchExists(ctx context.Context, project Project, branchName string) (bool, error)
+	// FindOpenPR returns the open pull/merge request for a source branch, or nil if none exists
+	FindOpenPR(ctx context.Context, project Project, sourceBranch string) (*PullRequest, error)
+	// CreateBranch creates a new branch from an existing one
+	CreateBranch(ctx context.Context, project Project, branchName, fromBranch string) error
+	// CommitFiles commits files (keyed by repository-relative path) to a branch,
+	// creating or updating each file as needed
+	CommitFiles(ctx context.Context, project Project, branchName, message string, files map[string]string) error
+	// GetFileContent returns a file's content on a branch; the boolean is false when it does not exist
+	GetFileContent(ctx context.Context, project Project, branchName, filePath string) (string, bool, error)
+	// CreatePR opens a pull/merge request
+	CreatePR(ctx context.Context, project Project, sourceBranch, targetBranch, title, description string) (*PullRequest, error)
+	// UpdatePRDescription replaces the description of an open pull/merge request
+	UpdatePRDescription(ctx context.Context, project Project, number int, description string) error
+	// GetPR returns a pull/merge request by number (IID on GitLab)
+	GetPR(ctx context.Context, project Project, number int) (*PullRequest, error)
+	// ListPRFiles returns the files changed by a pull/merge request
+	ListPRFiles(ctx context.Context, project Project, number int) ([]ChangedFile, error)
+	// CommentOnPR posts a comment on a pull/merge request
+	CommentOnPR(ctx context.Context, project Project, number int, body string) error
+	// FindPRComment returns the ID of the first comment of a pull/merge request containing marker, or 0 if none does
+	FindPRComment(ctx context.Context, project Project, number int, marker string) (int, error)
+	// UpdatePRComment replaces the body of a comment of a pull/merge request
+	UpdatePRComment(ctx context.Context, project Project, number, commentID int, body string) error
+	// AuthenticatedCloneURL returns the clone URL with credentials injected
+	AuthenticatedCloneURL(project Project) string
+}
+
+// Project represents a repository on any forge
+type Project struct {
+	ID             int
+	Name           string
+	FullName       string // Namespaced path, e.g. group/subgroup/repo or owner/repo
+	CloneURL       string
+	DefaultBranch  string
+	LastActivityAt time.Time
+	CreatedAt      time.Time
+	Archived       bool
+	Topics         []string // Topics (GitHub, Gitea) or topics/tags (GitLab)
+	Visibility     string   // public, internal or private
+	Language       string   // Primary language; empty when the forge does not report it in listings
+	SizeKB         int      // Repository size; 0 when unknown
+}
+
+// PullRequest represents a GitHub/Gitea pull request or a GitLab merge request
+type PullRequest struct {
+	Number       int // PR number, or MR IID on GitLab
+	Title        string
+	Description  string
+	SourceBranch string
+	TargetBranch string
+	WebURL       string
+}
+
+// Change statuses of a ChangedFile
+const (
+	FileAdded    = "added"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+	FileRenamed  = "renamed"
+)
+
+// ChangedFile is a file touched by a pull/merge request
+type ChangedFile struct {
+	Path         string // Path after the change (the old path for deletions)
+	PreviousPath string // Path before a rename; empty otherwise
+	Status       string // One of FileAdded, FileModified, FileDeleted, FileRenamed
+}
+
+// LanguageResolver is implemented by forges whose project listings do not include
+// the primary language, which then has to be fetched per project
+type LanguageResolver interface {
+	PrimaryLanguage(ctx context.Context, project Project) (string, error)
+}
+
+// Settings holds the per-forge connection configuration
+type Settings struct {
+	GitLab config.GitLabConfig
+	GitHub config.ForgeConfig
+	Gitea  config.ForgeConfig
+}
+
+// New creates the forge implementation selected by kind
+func New(kind string, settings Settings, logger *logging.Logger) (Forge, error) {
+	switch kind {
+	case "", KindGitLab:
+		return NewGitLab(settings.GitLab, logger), nil
+	case KindGitHub:
+		return NewGitHub(settings.GitHub, logger), nil
+	case KindGitea:
+		return NewGitea(settings.Gitea, logger), nil
+	default:
+		return nil, errors.NewConfigurationError(fmt.Sprintf("unsupported forge %q: must be one of: %s, %s, %s", kind, KindGitLab, KindGitHub, KindGitea))
+	}
+}
+
+// cloneURLWithToken injects basic-auth credentials into http(s) clone URLs.
+// Other URLs (ssh, local paths) are returned unchanged.
+func cloneURLWithToken(rawURL, username, token string) string {
+	if token == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return rawURL
+	}
+	u.User = url.UserPassword(username, token)
+	return u.String()
+}
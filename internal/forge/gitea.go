@@ -0,0 +1,419 @@
+package forge
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+const (
+	giteaAPIPrefix = "/api/v1"
+	giteaPageLimit = 50
+)
+
+// Gitea implements Forge using the Gitea REST API (v1)
+type Gitea struct {
+	rest      *restClient
+	token     string
+	userName  string
+	userEmail string
+	logger    *logging.Logger
+}
+
+// giteaRepo is the subset of the repository payload used by gendocs
+type giteaRepo struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	CloneURL      string    `json:"clone_url"`
+	DefaultBranch string    `json:"default_branch"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	Archived      bool      `json:"archived"`
+	Topics        []string  `json:"topics"`
+	Language      string    `json:"language"`
+	Size          int       `json:"size"` // KB
+	Private       bool      `json:"private"`
+	Internal      bool      `json:"internal"`
+}
+
+// visibility maps Gitea's private/internal flags to a visibility name
+func (r *giteaRepo) visibility() string {
+	switch {
+	case r.Internal:
+		return "internal"
+	case r.Private:
+		return "private"
+	default:
+		return "public"
+	}
+}
+
+// giteaPull is the subset of the pull request payload used by gendocs
+type giteaPull struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// giteaFile is an entry of the pull request files payload
+type giteaFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+}
+
+// giteaFileOperation is a single entry of the change-files API payload
+type giteaFileOperation struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	SHA       string `json:"sha,omitempty"`
+}
+
+// NewGitea creates a Gitea forge. APIURL may be the instance root or include /api/v1.
+func NewGitea(cfg config.ForgeConfig, logger *logging.Logger) *Gitea {
+	apiURL := strings.TrimRight(cfg.APIURL, "/")
+	if !strings.HasSuffix(apiURL, giteaAPIPrefix) {
+		apiURL += giteaAPIPrefix
+	}
+
+	headers := map[string]string{
+		"Accept": "application/json",
+	}
+	if cfg.Token != "" {
+		headers["Authorization"] = "token " + cfg.Token
+	}
+
+	return &Gitea{
+		rest:      newRESTClient("Gitea", "GITEA_TOKEN", apiURL, headers, logger),
+		token:     cfg.Token,
+		userName:  cfg.UserName,
+		userEmail: cfg.UserEmail,
+		logger:    logger,
+	}
+}
+
+// Name returns the forge kind
+func (g *Gitea) Name() string { return KindGitea }
+
+// ListProjects lists the repositories of an organization, falling back to a user account
+func (g *Gitea) ListProjects(ctx context.Context, group string) ([]Project, error) {
+	g.logger.Info(fmt.Sprintf("Fetching repositories for %s", group))
+
+	repos, err := g.listRepos(ctx, fmt.Sprintf("/orgs/%s/repos", url.PathEscape(group)))
+	if isNotFoundErr(err) {
+		// Not an organization: try a user account
+		repos, err = g.listRepos(ctx, fmt.Sprintf("/users/%s/repos", url.PathEscape(group)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]Project, 0, len(repos))
+	for i := range repos {
+		projects = append(projects, repos[i].toProject())
+	}
+	return projects, nil
+}
+
+// GetProject fetches a repository by its owner/name path
+func (g *Gitea) GetProject(ctx context.Context, ref string) (*Project, error) {
+	var repo giteaRepo
+	if _, err := g.rest.do(ctx, "get repository", http.MethodGet, "/repos/"+ref, nil, nil, &repo); err != nil {
+		return nil, err
+	}
+	project := repo.toProject()
+	return &project, nil
+}
+
+func (g *Gitea) listRepos(ctx context.Context, path string) ([]giteaRepo, error) {
+	var repos []giteaRepo
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(giteaPageLimit))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []giteaRepo
+		if _, err := g.rest.do(ctx, "list repositories", http.MethodGet, path, query, nil, &batch); err != nil {
+			return nil, err
+		}
+		repos = append(repos, batch...)
+		if len(batch) < giteaPageLimit {
+			return repos, nil
+		}
+	}
+}
+
+// BranchExists checks if a branch exists in a repository
+func (g *Gitea) BranchExists(ctx context.Context, project Project, branchName string) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/branches/%s", project.FullName, url.PathEscape(branchName))
+	resp, err := g.rest.do(ctx, "get branch", http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		if isNotFound(resp) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// FindOpenPR returns the open pull request for a head branch, or nil.
+// Gitea cannot filter by head branch, so open PRs are paged through.
+func (g *Gitea) FindOpenPR(ctx context.Context, project Project, sourceBranch string) (*PullRequest, error) {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("state", "open")
+		query.Set("limit", strconv.Itoa(giteaPageLimit))
+		query.Set("page", strconv.Itoa(page))
+
+		var pulls []giteaPull
+		if _, err := g.rest.do(ctx, "list pull requests", http.MethodGet, fmt.Sprintf("/repos/%s/pulls", project.FullName), query, nil, &pulls); err != nil {
+			return nil, err
+		}
+		for i := range pulls {
+			if pulls[i].Head.Ref == sourceBranch {
+				return pulls[i].toPullRequest(), nil
+			}
+		}
+		if len(pulls) < giteaPageLimit {
+			return nil, nil
+		}
+	}
+}
+
+// CreateBranch creates a new branch from fromBranch
+func (g *Gitea) CreateBranch(ctx context.Context, project Project, branchName, fromBranch string) error {
+	g.logger.Info(fmt.Sprintf("Creating branch '%s' in %s", branchName, project.FullName))
+
+	payload := map[string]string{
+		"new_branch_name": branchName,
+		"old_branch_name": fromBranch,
+	}
+	_, err := g.rest.do(ctx, "create branch", http.MethodPost, fmt.Sprintf("/repos/%s/branches", project.FullName), nil, payload, nil)
+	return err
+}
+
+// CommitFiles creates a single commit with all files using the change-files API
+func (g *Gitea) CommitFiles(ctx context.Context, project Project, branchName, message string, files map[string]string) error {
+	if len(files) == 0 {
+		return errors.NewForgeAPIError("Gitea", "create commit", "", fmt.Errorf("no files to commit in %s", project.FullName))
+	}
+
+	g.logger.Info(fmt.Sprintf("Creating commit in %s on branch %s (%d files)", project.FullName, branchName, len(files)))
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	operations := make([]giteaFileOperation, 0, len(paths))
+	for _, path := range paths {
+		sha, err := g.fileSHA(ctx, project, branchName, path)
+		if err != nil {
+			return err
+		}
+		op := giteaFileOperation{
+			Operation: "create",
+			Path:      path,
+			Content:   base64.StdEncoding.EncodeToString([]byte(files[path])),
+		}
+		if sha != "" {
+			op.Operation = "update"
+			op.SHA = sha
+		}
+		operations = append(operations, op)
+	}
+
+	payload := map[string]interface{}{
+		"branch":  branchName,
+		"message": message,
+		"files":   operations,
+	}
+	if g.userName != "" && g.userEmail != "" {
+		payload["author"] = map[string]string{"name": g.userName, "email": g.userEmail}
+	}
+
+	_, err := g.rest.do(ctx, "create commit", http.MethodPost, fmt.Sprintf("/repos/%s/contents", project.FullName), nil, payload, nil)
+	return err
+}
+
+// fileSHA returns the blob SHA of a file on a branch, or "" if it does not exist
+func (g *Gitea) fileSHA(ctx context.Context, project Project, branchName, filePath string) (string, error) {
+	query := url.Values{}
+	query.Set("ref", branchName)
+
+	var content struct {
+		SHA string `json:"sha"`
+	}
+	path := fmt.Sprintf("/repos/%s/contents/%s", project.FullName, escapePath(filePath))
+	resp, err := g.rest.do(ctx, "get file", http.MethodGet, path, query, nil, &content)
+	if err != nil {
+		if isNotFound(resp) {
+			return "", nil
+		}
+		return "", err
+	}
+	return content.SHA, nil
+}
+
+// GetFileContent returns a file's content on a branch
+func (g *Gitea) GetFileContent(ctx context.Context, project Project, branchName, filePath string) (string, bool, error) {
+	return g.rest.getContents(ctx, project.FullName, branchName, filePath)
+}
+
+// CreatePR opens a pull request
+func (g *Gitea) CreatePR(ctx context.Context, project Project, sourceBranch, targetBranch, title, description string) (*PullRequest, error) {
+	g.logger.Info(fmt.Sprintf("Creating PR in %s: %s -> %s", project.FullName, sourceBranch, targetBranch))
+
+	payload := map[string]string{
+		"title": title,
+		"head":  sourceBranch,
+		"base":  targetBranch,
+		"body":  description,
+	}
+
+	var pull giteaPull
+	if _, err := g.rest.do(ctx, "create pull request", http.MethodPost, fmt.Sprintf("/repos/%s/pulls", project.FullName), nil, payload, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// UpdatePRDescription replaces the body of a pull request
+func (g *Gitea) UpdatePRDescription(ctx context.Context, project Project, number int, description string) error {
+	g.logger.Info(fmt.Sprintf("Updating PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": description}
+	_, err := g.rest.do(ctx, "update pull request", http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", project.FullName, number), nil, payload, nil)
+	return err
+}
+
+// GetPR fetches a pull request by number
+func (g *Gitea) GetPR(ctx context.Context, project Project, number int) (*PullRequest, error) {
+	var pull giteaPull
+	if _, err := g.rest.do(ctx, "get pull request", http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", project.FullName, number), nil, nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// ListPRFiles returns the files changed by a pull request
+func (g *Gitea) ListPRFiles(ctx context.Context, project Project, number int) ([]ChangedFile, error) {
+	var files []ChangedFile
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(giteaPageLimit))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []giteaFile
+		if _, err := g.rest.do(ctx, "list pull request files", http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d/files", project.FullName, number), query, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, f := range batch {
+			file := ChangedFile{Path: f.Filename, PreviousPath: f.PreviousFilename, Status: FileModified}
+			switch f.Status {
+			case "added", "copied":
+				file.Status = FileAdded
+			case "deleted":
+				file.Status = FileDeleted
+			case "renamed":
+				file.Status = FileRenamed
+			}
+			files = append(files, file)
+		}
+		if len(batch) < giteaPageLimit {
+			return files, nil
+		}
+	}
+}
+
+// CommentOnPR posts a comment on a pull request through the issues API
+func (g *Gitea) CommentOnPR(ctx context.Context, project Project, number int, body string) error {
+	g.logger.Info(fmt.Sprintf("Commenting on PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": body}
+	_, err := g.rest.do(ctx, "create comment", http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", project.FullName, number), nil, payload, nil)
+	return err
+}
+
+// FindPRComment returns the ID of the first pull request comment containing marker
+func (g *Gitea) FindPRComment(ctx context.Context, project Project, number int, marker string) (int, error) {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(giteaPageLimit))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []issueComment
+		if _, err := g.rest.do(ctx, "list comments", http.MethodGet, fmt.Sprintf("/repos/%s/issues/%d/comments", project.FullName, number), query, nil, &batch); err != nil {
+			return 0, err
+		}
+		if id := findComment(batch, marker); id != 0 {
+			return id, nil
+		}
+		if len(batch) < giteaPageLimit {
+			return 0, nil
+		}
+	}
+}
+
+// UpdatePRComment replaces the body of a pull request comment
+func (g *Gitea) UpdatePRComment(ctx context.Context, project Project, number, commentID int, body string) error {
+	g.logger.Info(fmt.Sprintf("Updating comment on PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": body}
+	_, err := g.rest.do(ctx, "update comment", http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", project.FullName, commentID), nil, payload, nil)
+	return err
+}
+
+// AuthenticatedCloneURL injects the token into the clone URL.
+// Gitea authenticates by the token alone, so the username is a placeholder.
+func (g *Gitea) AuthenticatedCloneURL(project Project) string {
+	return cloneURLWithToken(project.CloneURL, "oauth2", g.token)
+}
+
+func (r *giteaRepo) toProject() Project {
+	return Project{
+		ID:             r.ID,
+		Name:           r.Name,
+		FullName:       r.FullName,
+		CloneURL:       r.CloneURL,
+		DefaultBranch:  r.DefaultBranch,
+		LastActivityAt: r.UpdatedAt,
+		CreatedAt:      r.CreatedAt,
+		Archived:       r.Archived,
+		Topics:         r.Topics,
+		Visibility:     r.visibility(),
+		Language:       r.Language,
+		SizeKB:         r.Size,
+	}
+}
+
+func (p *giteaPull) toPullRequest() *PullRequest {
+	return &PullRequest{
+		Number:       p.Number,
+		Title:        p.Title,
+		Description:  p.Body,
+		SourceBranch: p.Head.Ref,
+		TargetBranch: p.Base.Ref,
+		WebURL:       p.HTMLURL,
+	}
+}
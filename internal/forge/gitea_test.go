@@ -0,0 +1,217 @@
+package forge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/logging"
+)
+
+func newTestGitea(t *testing.T, handler http.Handler) *Gitea {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewGitea(config.ForgeConfig{
+		APIURL:    server.URL,
+		Token:     "secret",
+		UserName:  "AI Analyzer",
+		UserEmail: "ai@example.com",
+	}, logging.NewNopLogger())
+}
+
+func TestGitea_ListProjects_FallsBackToUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"org not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /api/v1/users/{user}/repos", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaRepo{{ID: 4, Name: "app", FullName: r.PathValue("user") + "/app", DefaultBranch: "main"}})
+	})
+	g := newTestGitea(t, mux)
+
+	projects, err := g.ListProjects(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 1 || projects[0].FullName != "alice/app" {
+		t.Errorf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestGitea_FindOpenPR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/app/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = fmt.Fprint(w, `[{"number":1,"head":{"ref":"feature"}},{"number":2,"html_url":"https://gitea.example.com/org/app/pulls/2","head":{"ref":"docs"},"base":{"ref":"main"}}]`)
+	})
+	g := newTestGitea(t, mux)
+	project := Project{FullName: "org/app"}
+
+	pr, err := g.FindOpenPR(context.Background(), project, "docs")
+	if err != nil || pr == nil || pr.Number != 2 || pr.TargetBranch != "main" {
+		t.Errorf("expected PR #2, got %+v (err: %v)", pr, err)
+	}
+	if pr, err := g.FindOpenPR(context.Background(), project, "other"); err != nil || pr != nil {
+		t.Errorf("expected no PR, got %+v (err: %v)", pr, err)
+	}
+}
+
+func TestGitea_CommitFiles(t *testing.T) {
+	var payload struct {
+		Branch string               `json:"branch"`
+		Files  []giteaFileOperation `json:"files"`
+		Author map[string]string    `json:"author"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/app/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "README.md" || r.URL.Query().Get("ref") != "docs" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sha":"readme-sha"}`))
+	})
+	mux.HandleFunc("POST /api/v1/repos/org/app/contents", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	g := newTestGitea(t, mux)
+
+	files := map[string]string{
+		"README.md":                      "new readme",
+		".ai/docs/structure_analysis.md": "# Structure",
+	}
+	if err := g.CommitFiles(context.Background(), Project{FullName: "org/app"}, "docs", "[skip ci] docs", files); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+
+	if payload.Branch != "docs" || len(payload.Files) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	ops := make(map[string]giteaFileOperation)
+	for _, op := range payload.Files {
+		ops[op.Path] = op
+	}
+	if op := ops["README.md"]; op.Operation != "update" || op.SHA != "readme-sha" {
+		t.Errorf("expected README update with sha, got %+v", op)
+	}
+	if op := ops[".ai/docs/structure_analysis.md"]; op.Operation != "create" || op.SHA != "" {
+		t.Errorf("expected analysis file create, got %+v", op)
+	}
+	if decoded, _ := base64.StdEncoding.DecodeString(ops["README.md"].Content); string(decoded) != "new readme" {
+		t.Errorf("expected base64 content, got %q", ops["README.md"].Content)
+	}
+	if payload.Author["email"] != "ai@example.com" {
+		t.Errorf("unexpected author: %v", payload.Author)
+	}
+}
+
+func TestGitea_APIURL(t *testing.T) {
+	tests := []string{"https://gitea.example.com", "https://gitea.example.com/", "https://gitea.example.com/api/v1"}
+	for _, apiURL := range tests {
+		g := NewGitea(config.ForgeConfig{APIURL: apiURL}, logging.NewNopLogger())
+		if g.rest.baseURL != "https://gitea.example.com/api/v1" {
+			t.Errorf("NewGitea(%q) base URL = %q", apiURL, g.rest.baseURL)
+		}
+	}
+}
+
+func TestGitea_GetFileContentAndUpdatePR(t *testing.T) {
+	var body map[string]string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/app/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "README.md" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"sha":"abc","encoding":"base64","content":%q}`, base64.StdEncoding.EncodeToString([]byte("hello")))
+	})
+	mux.HandleFunc("PATCH /api/v1/repos/org/app/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	g := newTestGitea(t, mux)
+	project := Project{FullName: "org/app"}
+
+	content, exists, err := g.GetFileContent(context.Background(), project, "docs", "README.md")
+	if err != nil || !exists || content != "hello" {
+		t.Errorf("unexpected file: %q exists=%v err=%v", content, exists, err)
+	}
+	if _, exists, err := g.GetFileContent(context.Background(), project, "docs", "missing.md"); err != nil || exists {
+		t.Errorf("expected missing file, got exists=%v err=%v", exists, err)
+	}
+
+	if err := g.UpdatePRDescription(context.Background(), project, 7, "updated"); err != nil {
+		t.Fatalf("UpdatePRDescription failed: %v", err)
+	}
+	if body["body"] != "updated" {
+		t.Errorf("expected body to be sent, got %v", body)
+	}
+}
+
+func TestGitea_ListPRFilesAndComment(t *testing.T) {
+	var comment map[string]string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/app/pulls/4/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"filename":"go.mod","status":"changed"},{"filename":"gone.go","status":"deleted"}]`))
+	})
+	mux.HandleFunc("POST /api/v1/repos/org/app/issues/4/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/org/app/issues/4/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":8,"body":"<!-- marker -->\nstale docs"}]`))
+	})
+	mux.HandleFunc("PATCH /api/v1/repos/org/app/issues/comments/8", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	g := newTestGitea(t, mux)
+	project := Project{FullName: "org/app"}
+
+	files, err := g.ListPRFiles(context.Background(), project, 4)
+	if err != nil {
+		t.Fatalf("ListPRFiles failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Status != FileModified || files[1].Status != FileDeleted {
+		t.Errorf("unexpected files: %+v", files)
+	}
+
+	if err := g.CommentOnPR(context.Background(), project, 4, "stale docs"); err != nil {
+		t.Fatalf("CommentOnPR failed: %v", err)
+	}
+	if comment["body"] != "stale docs" {
+		t.Errorf("expected comment body to be sent, got %v", comment)
+	}
+
+	id, err := g.FindPRComment(context.Background(), project, 4, "<!-- marker -->")
+	if err != nil || id != 8 {
+		t.Fatalf("expected the comment with the marker, got %d (err: %v)", id, err)
+	}
+	if err := g.UpdatePRComment(context.Background(), project, 4, id, "fresh docs"); err != nil {
+		t.Fatalf("UpdatePRComment failed: %v", err)
+	}
+	if comment["body"] != "fresh docs" {
+		t.Errorf("expected the updated body to be sent, got %v", comment)
+	}
+}
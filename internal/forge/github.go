@@ -0,0 +1,419 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+const (
+	defaultGitHubAPIURL = "https://api.github.com"
+	githubPerPage       = 100
+)
+
+// GitHub implements Forge using the GitHub REST API (github.com or GitHub Enterprise)
+type GitHub struct {
+	rest      *restClient
+	token     string
+	userName  string
+	userEmail string
+	logger    *logging.Logger
+}
+
+// githubRepo is the subset of the repository payload used by gendocs
+type githubRepo struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	CloneURL      string    `json:"clone_url"`
+	DefaultBranch string    `json:"default_branch"`
+	PushedAt      time.Time `json:"pushed_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	Archived      bool      `json:"archived"`
+	Topics        []string  `json:"topics"`
+	Language      string    `json:"language"`
+	Size          int       `json:"size"` // KB
+	Visibility    string    `json:"visibility"`
+}
+
+// githubPull is the subset of the pull request payload used by gendocs
+type githubPull struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// githubFile is an entry of the pull request files payload
+type githubFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+}
+
+// githubRef is a git reference payload
+type githubRef struct {
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+// NewGitHub creates a GitHub forge. For GitHub Enterprise, set APIURL to https://<host>/api/v3.
+func NewGitHub(cfg config.ForgeConfig, logger *logging.Logger) *GitHub {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	if cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + cfg.Token
+	}
+
+	return &GitHub{
+		rest:      newRESTClient("GitHub", "GITHUB_TOKEN", apiURL, headers, logger),
+		token:     cfg.Token,
+		userName:  cfg.UserName,
+		userEmail: cfg.UserEmail,
+		logger:    logger,
+	}
+}
+
+// Name returns the forge kind
+func (g *GitHub) Name() string { return KindGitHub }
+
+// ListProjects lists the repositories of an organization, falling back to a user account
+func (g *GitHub) ListProjects(ctx context.Context, group string) ([]Project, error) {
+	g.logger.Info(fmt.Sprintf("Fetching repositories for %s", group))
+
+	repos, err := g.listRepos(ctx, fmt.Sprintf("/orgs/%s/repos", url.PathEscape(group)))
+	if isNotFoundErr(err) {
+		// Not an organization: try a user account
+		repos, err = g.listRepos(ctx, fmt.Sprintf("/users/%s/repos", url.PathEscape(group)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]Project, 0, len(repos))
+	for i := range repos {
+		projects = append(projects, repos[i].toProject())
+	}
+	return projects, nil
+}
+
+// GetProject fetches a repository by its owner/name path
+func (g *GitHub) GetProject(ctx context.Context, ref string) (*Project, error) {
+	var repo githubRepo
+	if _, err := g.rest.do(ctx, "get repository", http.MethodGet, "/repos/"+ref, nil, nil, &repo); err != nil {
+		return nil, err
+	}
+	project := repo.toProject()
+	return &project, nil
+}
+
+func (g *GitHub) listRepos(ctx context.Context, path string) ([]githubRepo, error) {
+	var repos []githubRepo
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(githubPerPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []githubRepo
+		if _, err := g.rest.do(ctx, "list repositories", http.MethodGet, path, query, nil, &batch); err != nil {
+			return nil, err
+		}
+		repos = append(repos, batch...)
+		if len(batch) < githubPerPage {
+			return repos, nil
+		}
+	}
+}
+
+// BranchExists checks if a branch exists in a repository
+func (g *GitHub) BranchExists(ctx context.Context, project Project, branchName string) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/branches/%s", project.FullName, url.PathEscape(branchName))
+	resp, err := g.rest.do(ctx, "get branch", http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		if isNotFound(resp) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// FindOpenPR returns the open pull request for a head branch, or nil
+func (g *GitHub) FindOpenPR(ctx context.Context, project Project, sourceBranch string) (*PullRequest, error) {
+	owner := strings.SplitN(project.FullName, "/", 2)[0]
+
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", owner+":"+sourceBranch)
+
+	var pulls []githubPull
+	if _, err := g.rest.do(ctx, "list pull requests", http.MethodGet, fmt.Sprintf("/repos/%s/pulls", project.FullName), query, nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+// CreateBranch creates a branch pointing at the head of fromBranch
+func (g *GitHub) CreateBranch(ctx context.Context, project Project, branchName, fromBranch string) error {
+	g.logger.Info(fmt.Sprintf("Creating branch '%s' in %s", branchName, project.FullName))
+
+	sha, err := g.branchHead(ctx, project, fromBranch)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]string{
+		"ref": "refs/heads/" + branchName,
+		"sha": sha,
+	}
+	_, err = g.rest.do(ctx, "create branch", http.MethodPost, fmt.Sprintf("/repos/%s/git/refs", project.FullName), nil, payload, nil)
+	return err
+}
+
+// CommitFiles creates a single commit with all files using the Git data API
+func (g *GitHub) CommitFiles(ctx context.Context, project Project, branchName, message string, files map[string]string) error {
+	if len(files) == 0 {
+		return errors.NewForgeAPIError("GitHub", "create commit", "", fmt.Errorf("no files to commit in %s", project.FullName))
+	}
+
+	g.logger.Info(fmt.Sprintf("Creating commit in %s on branch %s (%d files)", project.FullName, branchName, len(files)))
+
+	parentSHA, err := g.branchHead(ctx, project, branchName)
+	if err != nil {
+		return err
+	}
+
+	var parent struct {
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	if _, err := g.rest.do(ctx, "get commit", http.MethodGet, fmt.Sprintf("/repos/%s/git/commits/%s", project.FullName, parentSHA), nil, nil, &parent); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := make([]map[string]string, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, map[string]string{
+			"path":    path,
+			"mode":    "100644",
+			"type":    "blob",
+			"content": files[path],
+		})
+	}
+
+	var tree struct {
+		SHA string `json:"sha"`
+	}
+	treePayload := map[string]interface{}{
+		"base_tree": parent.Tree.SHA,
+		"tree":      entries,
+	}
+	if _, err := g.rest.do(ctx, "create tree", http.MethodPost, fmt.Sprintf("/repos/%s/git/trees", project.FullName), nil, treePayload, &tree); err != nil {
+		return err
+	}
+
+	commitPayload := map[string]interface{}{
+		"message": message,
+		"tree":    tree.SHA,
+		"parents": []string{parentSHA},
+	}
+	if g.userName != "" && g.userEmail != "" {
+		commitPayload["author"] = map[string]string{"name": g.userName, "email": g.userEmail}
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := g.rest.do(ctx, "create commit", http.MethodPost, fmt.Sprintf("/repos/%s/git/commits", project.FullName), nil, commitPayload, &commit); err != nil {
+		return err
+	}
+
+	refPayload := map[string]string{"sha": commit.SHA}
+	_, err = g.rest.do(ctx, "update branch", http.MethodPatch, fmt.Sprintf("/repos/%s/git/refs/heads/%s", project.FullName, escapePath(branchName)), nil, refPayload, nil)
+	return err
+}
+
+// GetFileContent returns a file's content on a branch
+func (g *GitHub) GetFileContent(ctx context.Context, project Project, branchName, filePath string) (string, bool, error) {
+	return g.rest.getContents(ctx, project.FullName, branchName, filePath)
+}
+
+// CreatePR opens a pull request
+func (g *GitHub) CreatePR(ctx context.Context, project Project, sourceBranch, targetBranch, title, description string) (*PullRequest, error) {
+	g.logger.Info(fmt.Sprintf("Creating PR in %s: %s -> %s", project.FullName, sourceBranch, targetBranch))
+
+	payload := map[string]string{
+		"title": title,
+		"head":  sourceBranch,
+		"base":  targetBranch,
+		"body":  description,
+	}
+
+	var pull githubPull
+	if _, err := g.rest.do(ctx, "create pull request", http.MethodPost, fmt.Sprintf("/repos/%s/pulls", project.FullName), nil, payload, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// UpdatePRDescription replaces the body of a pull request
+func (g *GitHub) UpdatePRDescription(ctx context.Context, project Project, number int, description string) error {
+	g.logger.Info(fmt.Sprintf("Updating PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": description}
+	_, err := g.rest.do(ctx, "update pull request", http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", project.FullName, number), nil, payload, nil)
+	return err
+}
+
+// GetPR fetches a pull request by number
+func (g *GitHub) GetPR(ctx context.Context, project Project, number int) (*PullRequest, error) {
+	var pull githubPull
+	if _, err := g.rest.do(ctx, "get pull request", http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", project.FullName, number), nil, nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// ListPRFiles returns the files changed by a pull request
+func (g *GitHub) ListPRFiles(ctx context.Context, project Project, number int) ([]ChangedFile, error) {
+	var files []ChangedFile
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(githubPerPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []githubFile
+		if _, err := g.rest.do(ctx, "list pull request files", http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d/files", project.FullName, number), query, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, f := range batch {
+			file := ChangedFile{Path: f.Filename, PreviousPath: f.PreviousFilename, Status: FileModified}
+			switch f.Status {
+			case "added", "copied":
+				file.Status = FileAdded
+			case "removed":
+				file.Status = FileDeleted
+			case "renamed":
+				file.Status = FileRenamed
+			}
+			files = append(files, file)
+		}
+		if len(batch) < githubPerPage {
+			return files, nil
+		}
+	}
+}
+
+// CommentOnPR posts a comment on a pull request through the issues API
+func (g *GitHub) CommentOnPR(ctx context.Context, project Project, number int, body string) error {
+	g.logger.Info(fmt.Sprintf("Commenting on PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": body}
+	_, err := g.rest.do(ctx, "create comment", http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", project.FullName, number), nil, payload, nil)
+	return err
+}
+
+// FindPRComment returns the ID of the first pull request comment containing marker
+func (g *GitHub) FindPRComment(ctx context.Context, project Project, number int, marker string) (int, error) {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(githubPerPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []issueComment
+		if _, err := g.rest.do(ctx, "list comments", http.MethodGet, fmt.Sprintf("/repos/%s/issues/%d/comments", project.FullName, number), query, nil, &batch); err != nil {
+			return 0, err
+		}
+		if id := findComment(batch, marker); id != 0 {
+			return id, nil
+		}
+		if len(batch) < githubPerPage {
+			return 0, nil
+		}
+	}
+}
+
+// UpdatePRComment replaces the body of a pull request comment
+func (g *GitHub) UpdatePRComment(ctx context.Context, project Project, number, commentID int, body string) error {
+	g.logger.Info(fmt.Sprintf("Updating comment on PR #%d in %s", number, project.FullName))
+
+	payload := map[string]string{"body": body}
+	_, err := g.rest.do(ctx, "update comment", http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", project.FullName, commentID), nil, payload, nil)
+	return err
+}
+
+// AuthenticatedCloneURL injects the token into the clone URL
+func (g *GitHub) AuthenticatedCloneURL(project Project) string {
+	return cloneURLWithToken(project.CloneURL, "x-access-token", g.token)
+}
+
+// branchHead returns the commit SHA a branch points to
+func (g *GitHub) branchHead(ctx context.Context, project Project, branchName string) (string, error) {
+	var ref githubRef
+	path := fmt.Sprintf("/repos/%s/git/ref/heads/%s", project.FullName, escapePath(branchName))
+	if _, err := g.rest.do(ctx, "get branch ref", http.MethodGet, path, nil, nil, &ref); err != nil {
+		return "", err
+	}
+	return ref.Object.SHA, nil
+}
+
+func (r *githubRepo) toProject() Project {
+	return Project{
+		ID:             r.ID,
+		Name:           r.Name,
+		FullName:       r.FullName,
+		CloneURL:       r.CloneURL,
+		DefaultBranch:  r.DefaultBranch,
+		LastActivityAt: r.PushedAt,
+		CreatedAt:      r.CreatedAt,
+		Archived:       r.Archived,
+		Topics:         r.Topics,
+		Visibility:     r.Visibility,
+		Language:       r.Language,
+		SizeKB:         r.Size,
+	}
+}
+
+func (p *githubPull) toPullRequest() *PullRequest {
+	return &PullRequest{
+		Number:       p.Number,
+		Title:        p.Title,
+		Description:  p.Body,
+		SourceBranch: p.Head.Ref,
+		TargetBranch: p.Base.Ref,
+		WebURL:       p.HTMLURL,
+	}
+}
@@ -0,0 +1,309 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+// fakeGitHub records requests made against a minimal GitHub API
+type fakeGitHub struct {
+	mu       sync.Mutex
+	requests []string
+	payloads map[string]map[string]interface{}
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *GitHub) {
+	t.Helper()
+	fake := &fakeGitHub{payloads: make(map[string]map[string]interface{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("org") != "acme" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		count := githubPerPage
+		if page == 2 {
+			count = 5
+		}
+		repos := make([]githubRepo, 0, count)
+		for i := 0; i < count; i++ {
+			id := (page-1)*githubPerPage + i + 1
+			repos = append(repos, githubRepo{ID: id, Name: fmt.Sprintf("repo-%d", id), FullName: fmt.Sprintf("acme/repo-%d", id), DefaultBranch: "main"})
+		}
+		_ = json.NewEncoder(w).Encode(repos)
+	})
+	mux.HandleFunc("GET /users/{user}/repos", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]githubRepo{{ID: 1, FullName: r.PathValue("user") + "/dotfiles", DefaultBranch: "main"}})
+	})
+	mux.HandleFunc("GET /repos/acme/app/branches/{branch}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("branch") != "main" {
+			http.Error(w, `{"message":"Branch not found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"main"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("head") != "acme:docs" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number":3,"html_url":"https://github.com/acme/app/pull/3","head":{"ref":"docs"},"base":{"ref":"main"}}]`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/git/ref/heads/{branch}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"object":{"sha":"sha-%s"}}`, r.PathValue("branch"))
+	})
+	mux.HandleFunc("GET /repos/acme/app/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tree":{"sha":"base-tree"}}`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		_, _ = w.Write([]byte(`{"sha":"new-tree"}`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		_, _ = w.Write([]byte(`{"sha":"new-commit"}`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("PATCH /repos/acme/app/git/refs/heads/{branch}", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":9,"html_url":"https://github.com/acme/app/pull/9","head":{"ref":"docs"},"base":{"ref":"main"}}`))
+	})
+
+	mux.HandleFunc("GET /repos/acme/app", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":5,"name":"app","full_name":"acme/app","default_branch":"main","visibility":"private"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"number":%s,"title":"Add users","head":{"ref":"feature"},"base":{"ref":"main"}}`, r.PathValue("number"))
+	})
+	mux.HandleFunc("GET /repos/acme/app/pulls/{number}/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"filename":"users.go","status":"added"},
+			{"filename":"main.go","status":"modified"},
+			{"filename":"old.go","status":"removed"},
+			{"filename":"api/routes.go","previous_filename":"routes.go","status":"renamed"}
+		]`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":70,"body":"LGTM"},{"id":71,"body":"<!-- marker -->\nstale docs"}]`))
+	})
+	mux.HandleFunc("PATCH /repos/acme/app/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fake.record(r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	gh := NewGitHub(config.ForgeConfig{
+		APIURL:    server.URL,
+		Token:     "secret",
+		UserName:  "AI Analyzer",
+		UserEmail: "ai@example.com",
+	}, logging.NewNopLogger())
+	return fake, gh
+}
+
+func (f *fakeGitHub) record(r *http.Request) {
+	var payload map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, key)
+	f.payloads[key] = payload
+}
+
+func TestGitHub_ListProjects(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+
+	projects, err := gh.ListProjects(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != githubPerPage+5 {
+		t.Fatalf("expected %d projects, got %d", githubPerPage+5, len(projects))
+	}
+	if projects[0].FullName != "acme/repo-1" || projects[0].DefaultBranch != "main" {
+		t.Errorf("unexpected first project: %+v", projects[0])
+	}
+
+	projects, err = gh.ListProjects(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("unexpected error for user account: %v", err)
+	}
+	if len(projects) != 1 || projects[0].FullName != "octocat/dotfiles" {
+		t.Errorf("expected fallback to user repositories, got %+v", projects)
+	}
+}
+
+func TestGitHub_BranchesAndPullRequests(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+	ctx := context.Background()
+	project := Project{FullName: "acme/app", DefaultBranch: "main"}
+
+	if exists, err := gh.BranchExists(ctx, project, "main"); err != nil || !exists {
+		t.Errorf("expected main to exist, got %v (err: %v)", exists, err)
+	}
+	if exists, err := gh.BranchExists(ctx, project, "missing"); err != nil || exists {
+		t.Errorf("expected missing branch to not exist, got %v (err: %v)", exists, err)
+	}
+
+	pr, err := gh.FindOpenPR(ctx, project, "docs")
+	if err != nil || pr == nil || pr.Number != 3 {
+		t.Errorf("expected open PR #3, got %+v (err: %v)", pr, err)
+	}
+	if pr, err := gh.FindOpenPR(ctx, project, "other"); err != nil || pr != nil {
+		t.Errorf("expected no open PR, got %+v (err: %v)", pr, err)
+	}
+}
+
+func TestGitHub_CommitFlow(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	ctx := context.Background()
+	project := Project{FullName: "acme/app", DefaultBranch: "main"}
+
+	if err := gh.CreateBranch(ctx, project, "docs", "main"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	ref := fake.payloads["POST /repos/acme/app/git/refs"]
+	if ref["ref"] != "refs/heads/docs" || ref["sha"] != "sha-main" {
+		t.Errorf("unexpected ref payload: %v", ref)
+	}
+
+	files := map[string]string{"README.md": "readme", ".ai/docs/api_analysis.md": "# API"}
+	if err := gh.CommitFiles(ctx, project, "docs", "[skip ci] docs", files); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+
+	tree := fake.payloads["POST /repos/acme/app/git/trees"]
+	if tree["base_tree"] != "base-tree" {
+		t.Errorf("expected base_tree base-tree, got %v", tree["base_tree"])
+	}
+	if entries, _ := tree["tree"].([]interface{}); len(entries) != 2 {
+		t.Errorf("expected 2 tree entries, got %v", tree["tree"])
+	}
+
+	commit := fake.payloads["POST /repos/acme/app/git/commits"]
+	if commit["tree"] != "new-tree" {
+		t.Errorf("expected commit on new-tree, got %v", commit["tree"])
+	}
+	if author, _ := commit["author"].(map[string]interface{}); author["email"] != "ai@example.com" {
+		t.Errorf("unexpected commit author: %v", commit["author"])
+	}
+
+	update := fake.payloads["PATCH /repos/acme/app/git/refs/heads/docs"]
+	if update["sha"] != "new-commit" {
+		t.Errorf("expected branch to move to new-commit, got %v", update)
+	}
+
+	pr, err := gh.CreatePR(ctx, project, "docs", "main", "AI Analysis", "body")
+	if err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+	if pr.Number != 9 || pr.WebURL == "" {
+		t.Errorf("unexpected pull request: %+v", pr)
+	}
+}
+
+func TestGitHub_ReviewEndpoints(t *testing.T) {
+	fake, gh := newFakeGitHub(t)
+	ctx := context.Background()
+
+	project, err := gh.GetProject(ctx, "acme/app")
+	if err != nil {
+		t.Fatalf("GetProject failed: %v", err)
+	}
+	if project.ID != 5 || project.FullName != "acme/app" || project.Visibility != "private" {
+		t.Errorf("unexpected project: %+v", project)
+	}
+
+	pr, err := gh.GetPR(ctx, *project, 12)
+	if err != nil || pr.Number != 12 || pr.SourceBranch != "feature" || pr.TargetBranch != "main" {
+		t.Errorf("unexpected pull request: %+v (err: %v)", pr, err)
+	}
+
+	files, err := gh.ListPRFiles(ctx, *project, 12)
+	if err != nil {
+		t.Fatalf("ListPRFiles failed: %v", err)
+	}
+	expected := []ChangedFile{
+		{Path: "users.go", Status: FileAdded},
+		{Path: "main.go", Status: FileModified},
+		{Path: "old.go", Status: FileDeleted},
+		{Path: "api/routes.go", PreviousPath: "routes.go", Status: FileRenamed},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %+v", len(expected), files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("file %d: expected %+v, got %+v", i, expected[i], files[i])
+		}
+	}
+
+	if err := gh.CommentOnPR(ctx, *project, 12, "stale docs"); err != nil {
+		t.Fatalf("CommentOnPR failed: %v", err)
+	}
+	if comment := fake.payloads["POST /repos/acme/app/issues/12/comments"]; comment["body"] != "stale docs" {
+		t.Errorf("unexpected comment payload: %v", comment)
+	}
+
+	id, err := gh.FindPRComment(ctx, *project, 12, "<!-- marker -->")
+	if err != nil || id != 71 {
+		t.Fatalf("expected the comment with the marker, got %d (err: %v)", id, err)
+	}
+	if err := gh.UpdatePRComment(ctx, *project, 12, id, "fresh docs"); err != nil {
+		t.Fatalf("UpdatePRComment failed: %v", err)
+	}
+	if comment := fake.payloads["PATCH /repos/acme/app/issues/comments/71"]; comment["body"] != "fresh docs" {
+		t.Errorf("unexpected comment update payload: %v", comment)
+	}
+	if id, err := gh.FindPRComment(ctx, *project, 12, "<!-- other -->"); err != nil || id != 0 {
+		t.Errorf("expected no comment for an unknown marker, got %d (err: %v)", id, err)
+	}
+}
+
+func TestGitHub_AuthError(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+	gh.rest.headers["Authorization"] = "Bearer wrong"
+
+	_, err := gh.ListProjects(context.Background(), "acme")
+	var authErr *errors.ForgeAuthError
+	if !stderrors.As(err, &authErr) {
+		t.Fatalf("expected ForgeAuthError, got %v", err)
+	}
+}
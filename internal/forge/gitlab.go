@@ -0,0 +1,229 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/gitlab"
+	"github.com/user/gendocs/internal/logging"
+)
+
+// GitLab adapts gitlab.Client to the Forge interface
+type GitLab struct {
+	client *gitlab.Client
+}
+
+// NewGitLab creates a GitLab forge
+func NewGitLab(cfg config.GitLabConfig, logger *logging.Logger) *GitLab {
+	return &GitLab{client: gitlab.NewClient(cfg, logger)}
+}
+
+// Name returns the forge kind
+func (g *GitLab) Name() string { return KindGitLab }
+
+// ListProjects fetches all projects in a group (including subgroups). The group must be a numeric ID.
+func (g *GitLab) ListProjects(ctx context.Context, group string) ([]Project, error) {
+	groupID, err := strconv.Atoi(group)
+	if err != nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("GitLab group must be a numeric ID, got %q", group))
+	}
+
+	glProjects, err := g.client.FetchProjectsInGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]Project, 0, len(glProjects))
+	for i := range glProjects {
+		projects = append(projects, fromGitLabProject(&glProjects[i]))
+	}
+	return projects, nil
+}
+
+// GetProject fetches a project by numeric ID or full path
+func (g *GitLab) GetProject(ctx context.Context, ref string) (*Project, error) {
+	p, err := g.client.GetProject(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	project := fromGitLabProject(p)
+	return &project, nil
+}
+
+// PrimaryLanguage returns the language with the largest share of the project.
+// GitLab project listings do not include languages, so they are fetched on demand.
+func (g *GitLab) PrimaryLanguage(ctx context.Context, project Project) (string, error) {
+	languages, err := g.client.FetchProjectLanguages(ctx, toGitLabProject(project))
+	if err != nil {
+		return "", err
+	}
+
+	var primary string
+	var share float64
+	for language, percent := range languages {
+		if percent > share || (percent == share && language < primary) {
+			primary, share = language, percent
+		}
+	}
+	return primary, nil
+}
+
+// BranchExists checks if a branch exists in a project
+func (g *GitLab) BranchExists(ctx context.Context, project Project, branchName string) (bool, error) {
+	return g.client.BranchExists(ctx, toGitLabProject(project), branchName)
+}
+
+// FindOpenPR returns the open merge request for a source branch, or nil
+func (g *GitLab) FindOpenPR(ctx context.Context, project Project, sourceBranch string) (*PullRequest, error) {
+	mr, err := g.client.FindOpenMR(ctx, toGitLabProject(project), sourceBranch)
+	if err != nil || mr == nil {
+		return nil, err
+	}
+	return fromMergeRequest(mr), nil
+}
+
+// CreateBranch creates a new branch in a project
+func (g *GitLab) CreateBranch(ctx context.Context, project Project, branchName, fromBranch string) error {
+	return g.client.CreateBranch(ctx, toGitLabProject(project), branchName, fromBranch)
+}
+
+// CommitFiles commits files to a branch using the commit actions API
+func (g *GitLab) CommitFiles(ctx context.Context, project Project, branchName, message string, files map[string]string) error {
+	return g.client.CreateCommit(ctx, toGitLabProject(project), branchName, message, files)
+}
+
+// GetFileContent returns a file's content on a branch
+func (g *GitLab) GetFileContent(ctx context.Context, project Project, branchName, filePath string) (string, bool, error) {
+	return g.client.GetFileContent(ctx, toGitLabProject(project), branchName, filePath)
+}
+
+// CreatePR creates a merge request
+func (g *GitLab) CreatePR(ctx context.Context, project Project, sourceBranch, targetBranch, title, description string) (*PullRequest, error) {
+	mr, err := g.client.CreateMR(ctx, toGitLabProject(project), sourceBranch, targetBranch, title, description)
+	if err != nil {
+		return nil, err
+	}
+	return fromMergeRequest(mr), nil
+}
+
+// UpdatePRDescription replaces the description of a merge request
+func (g *GitLab) UpdatePRDescription(ctx context.Context, project Project, number int, description string) error {
+	return g.client.UpdateMRDescription(ctx, toGitLabProject(project), number, description)
+}
+
+// GetPR fetches a merge request by IID
+func (g *GitLab) GetPR(ctx context.Context, project Project, number int) (*PullRequest, error) {
+	mr, err := g.client.GetMR(ctx, toGitLabProject(project), number)
+	if err != nil {
+		return nil, err
+	}
+	return fromMergeRequest(mr), nil
+}
+
+// ListPRFiles returns the files changed by a merge request
+func (g *GitLab) ListPRFiles(ctx context.Context, project Project, number int) ([]ChangedFile, error) {
+	diffs, err := g.client.ListMRDiffs(ctx, toGitLabProject(project), number)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ChangedFile, 0, len(diffs))
+	for _, d := range diffs {
+		file := ChangedFile{Path: d.NewPath, Status: FileModified}
+		switch {
+		case d.NewFile:
+			file.Status = FileAdded
+		case d.DeletedFile:
+			file.Path = d.OldPath
+			file.Status = FileDeleted
+		case d.RenamedFile:
+			file.PreviousPath = d.OldPath
+			file.Status = FileRenamed
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// CommentOnPR posts a note on a merge request
+func (g *GitLab) CommentOnPR(ctx context.Context, project Project, number int, body string) error {
+	return g.client.CreateMRNote(ctx, toGitLabProject(project), number, body)
+}
+
+// FindPRComment returns the ID of the first merge request note containing marker
+func (g *GitLab) FindPRComment(ctx context.Context, project Project, number int, marker string) (int, error) {
+	notes, err := g.client.ListMRNotes(ctx, toGitLabProject(project), number)
+	if err != nil {
+		return 0, err
+	}
+	for _, note := range notes {
+		if strings.Contains(note.Body, marker) {
+			return note.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// UpdatePRComment replaces the body of a merge request note
+func (g *GitLab) UpdatePRComment(ctx context.Context, project Project, number, commentID int, body string) error {
+	return g.client.UpdateMRNote(ctx, toGitLabProject(project), number, commentID, body)
+}
+
+// AuthenticatedCloneURL injects the OAuth token into the clone URL
+func (g *GitLab) AuthenticatedCloneURL(project Project) string {
+	return cloneURLWithToken(project.CloneURL, "oauth2", g.client.OAuthToken)
+}
+
+func toGitLabProject(p Project) gitlab.Project {
+	return gitlab.Project{
+		ID:                p.ID,
+		Name:              p.Name,
+		PathWithNamespace: p.FullName,
+		HTTPURL:           p.CloneURL,
+		DefaultBranch:     p.DefaultBranch,
+		LastActivityAt:    p.LastActivityAt,
+		CreatedAt:         p.CreatedAt,
+		Archived:          p.Archived,
+		Topics:            p.Topics,
+		Visibility:        p.Visibility,
+	}
+}
+
+func fromGitLabProject(p *gitlab.Project) Project {
+	topics := p.Topics
+	if len(topics) == 0 {
+		topics = p.TagList
+	}
+	var sizeKB int
+	if p.Statistics != nil {
+		sizeKB = int(p.Statistics.RepositorySize / 1024)
+	}
+	return Project{
+		ID:             p.ID,
+		Name:           p.Name,
+		FullName:       p.PathWithNamespace,
+		CloneURL:       p.HTTPURL,
+		DefaultBranch:  p.DefaultBranch,
+		LastActivityAt: p.LastActivityAt,
+		CreatedAt:      p.CreatedAt,
+		Archived:       p.Archived,
+		Topics:         topics,
+		Visibility:     p.Visibility,
+		SizeKB:         sizeKB,
+	}
+}
+
+func fromMergeRequest(mr *gitlab.MergeRequest) *PullRequest {
+	return &PullRequest{
+		Number:       mr.IID,
+		Title:        mr.Title,
+		Description:  mr.Description,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		WebURL:       mr.WebURL,
+	}
+}
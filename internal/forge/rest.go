@@ -0,0 +1,166 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+)
+
+// restClient is the JSON-over-HTTP transport shared by the GitHub and Gitea forges
+type restClient struct {
+	forge       string // Display name used in errors, e.g. "GitHub"
+	tokenEnvVar string // Environment variable suggested on auth failures
+	httpClient  *http.Client
+	baseURL     string
+	headers     map[string]string
+	logger      *logging.Logger
+}
+
+func newRESTClient(forge, tokenEnvVar, baseURL string, headers map[string]string, logger *logging.Logger) *restClient {
+	return &restClient{
+		forge:       forge,
+		tokenEnvVar: tokenEnvVar,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		headers: headers,
+		logger:  logger,
+	}
+}
+
+// do performs an API request, decoding a JSON response into out when non-nil.
+// The response is returned even on error so callers can inspect the status code.
+func (c *restClient) do(ctx context.Context, operation, method, path string, query url.Values, payload, out interface{}) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.NewForgeAPIError(c.forge, operation, "", fmt.Errorf("failed to marshal request: %w", err))
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, errors.NewForgeAPIError(c.forge, operation, "", err)
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.logger.Debug(fmt.Sprintf("%s API %s %s", c.forge, method, path))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewForgeAPIError(c.forge, operation, "", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return resp, errors.NewForgeAuthError(c.forge, c.tokenEnvVar, fmt.Errorf("%s: %s", resp.Status, readErrorBody(resp.Body)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, errors.NewForgeAPIError(c.forge, operation, strconv.Itoa(resp.StatusCode), fmt.Errorf("%s: %s", resp.Status, readErrorBody(resp.Body)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, errors.NewForgeAPIError(c.forge, operation, strconv.Itoa(resp.StatusCode), fmt.Errorf("failed to decode response: %w", err))
+		}
+	}
+
+	return resp, nil
+}
+
+// getContents fetches a file through the GitHub-style contents API, which both
+// GitHub and Gitea implement. The boolean is false when the file does not exist.
+func (c *restClient) getContents(ctx context.Context, fullName, branchName, filePath string) (string, bool, error) {
+	query := url.Values{}
+	query.Set("ref", branchName)
+
+	var file struct {
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	path := fmt.Sprintf("/repos/%s/contents/%s", fullName, escapePath(filePath))
+	resp, err := c.do(ctx, "get file", http.MethodGet, path, query, nil, &file)
+	if err != nil {
+		if isNotFound(resp) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if file.Encoding != "base64" {
+		return file.Content, true, nil
+	}
+	// GitHub wraps base64 content at 60 characters
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return "", false, errors.NewForgeAPIError(c.forge, "get file", "", fmt.Errorf("failed to decode %s: %w", filePath, err))
+	}
+	return string(content), true, nil
+}
+
+// issueComment is a pull request comment of the GitHub-style issues API
+type issueComment struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+// findComment returns the ID of the first comment containing marker, or 0
+func findComment(comments []issueComment, marker string) int {
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			return comment.ID
+		}
+	}
+	return 0
+}
+
+// isNotFound reports whether a failed request returned 404
+func isNotFound(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
+
+// isNotFoundErr reports whether err is a forge API error for a 404 response
+func isNotFoundErr(err error) bool {
+	apiErr, ok := err.(*errors.ForgeAPIError)
+	return ok && apiErr.Context.Details["status_code"] == strconv.Itoa(http.StatusNotFound)
+}
+
+// escapePath escapes each segment of a slash-separated path (file paths, branch names)
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// readErrorBody reads a bounded amount of an error response for diagnostics
+func readErrorBody(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, 4096))
+	return strings.TrimSpace(string(data))
+}
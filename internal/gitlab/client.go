@@ -1,15 +1,30 @@
 package gitlab
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
 	"github.com/user/gendocs/internal/logging"
 )
 
+const (
+	defaultAPIURL   = "https://gitlab.com"
+	apiPrefix       = "/api/v4"
+	projectsPerPage = 100
+)
+
 // Client represents a GitLab API client
 type Client struct {
 	httpClient   *http.Client
@@ -32,6 +47,31 @@ type Project struct {
 	LastActivityAt    time.Time `json:"last_activity_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	Archived          bool      `json:"archived"`
+	Topics            []string  `json:"topics"`
+	TagList           []string  `json:"tag_list"` // Deprecated alias of topics on older GitLab versions
+	Visibility        string    `json:"visibility"`
+	// Statistics is only returned to members with at least Reporter access
+	Statistics *ProjectStatistics `json:"statistics,omitempty"`
+}
+
+// MRDiff describes a file changed by a merge request
+type MRDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// MRNote is a comment on a merge request
+type MRNote struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+// ProjectStatistics holds the storage statistics of a project
+type ProjectStatistics struct {
+	RepositorySize int64 `json:"repository_size"` // Bytes
 }
 
 // MergeRequest represents a GitLab merge request
@@ -64,86 +104,359 @@ func NewClient(cfg config.GitLabConfig, logger *logging.Logger) *Client {
 func (c *Client) FetchProjectsInGroup(ctx context.Context, groupID int) ([]Project, error) {
 	c.logger.Info(fmt.Sprintf("Fetching projects in group %d", groupID))
 
-	// For now, return empty list - would need full GitLab API implementation
-	// This would require using xanzy/go-gitlab or implementing the API calls
-	return []Project{}, nil
-}
-
-// ProjectFilter determines if a project should be analyzed
-type ProjectFilter struct {
-	MaxDaysSinceLastCommit int
-	IgnoreProjects         map[string]bool
-	IgnoreSubgroups        map[string]bool
-}
+	var projects []Project
+	page := "1"
+	for page != "" {
+		query := url.Values{}
+		query.Set("include_subgroups", "true")
+		query.Set("statistics", "true")
+		query.Set("per_page", strconv.Itoa(projectsPerPage))
+		query.Set("page", page)
 
-// ShouldAnalyze determines if a project should be analyzed
-func (c *Client) ShouldAnalyze(ctx context.Context, project Project, filter ProjectFilter) (bool, error) {
-	// Skip archived projects
-	if project.Archived {
-		return false, nil
-	}
+		var batch []Project
+		resp, err := c.do(ctx, "fetch projects", http.MethodGet, fmt.Sprintf("/groups/%d/projects", groupID), query, nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, batch...)
 
-	// Skip ignored projects
-	if filter.IgnoreProjects[project.PathWithNamespace] {
-		return false, nil
+		// GitLab leaves X-Next-Page empty on the last page
+		page = resp.Header.Get("X-Next-Page")
 	}
 
-	// Check if last commit is too old
-	if filter.MaxDaysSinceLastCommit > 0 {
-		daysSince := time.Since(project.LastActivityAt).Hours() / 24
-		if int(daysSince) > filter.MaxDaysSinceLastCommit {
-			return false, nil
-		}
-	}
+	c.logger.Debug(fmt.Sprintf("Fetched %d projects in group %d", len(projects), groupID))
+	return projects, nil
+}
 
-	// Check if branch already exists
-	branchName := fmt.Sprintf("ai-analyzer-%s", time.Now().Format("2006-01-02"))
-	if branchExists, err := c.BranchExists(ctx, project, branchName); err == nil && branchExists {
-		return false, nil
+// GetProject fetches a project by numeric ID or full path (e.g. group/subgroup/app)
+func (c *Client) GetProject(ctx context.Context, ref string) (*Project, error) {
+	var project Project
+	if _, err := c.do(ctx, "get project", http.MethodGet, "/projects/"+url.PathEscape(ref), nil, nil, &project); err != nil {
+		return nil, err
 	}
+	return &project, nil
+}
 
-	// Check if open MR exists
-	if hasMR, err := c.HasOpenMR(ctx, project, branchName); err == nil && hasMR {
-		return false, nil
+// FetchProjectLanguages returns the language breakdown of a project as percentages
+func (c *Client) FetchProjectLanguages(ctx context.Context, project Project) (map[string]float64, error) {
+	languages := make(map[string]float64)
+	if _, err := c.do(ctx, "fetch languages", http.MethodGet, fmt.Sprintf("/projects/%d/languages", project.ID), nil, nil, &languages); err != nil {
+		return nil, err
 	}
-
-	return true, nil
+	return languages, nil
 }
 
 // BranchExists checks if a branch exists in a project
 func (c *Client) BranchExists(ctx context.Context, project Project, branchName string) (bool, error) {
-	// Placeholder - would implement GitLab API call
-	return false, nil
+	path := fmt.Sprintf("/projects/%d/repository/branches/%s", project.ID, url.PathEscape(branchName))
+	resp, err := c.do(ctx, "get branch", http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // HasOpenMR checks if an open MR exists for a branch
 func (c *Client) HasOpenMR(ctx context.Context, project Project, branchName string) (bool, error) {
-	// Placeholder - would implement GitLab API call
-	return false, nil
+	mr, err := c.FindOpenMR(ctx, project, branchName)
+	if err != nil {
+		return false, err
+	}
+	return mr != nil, nil
+}
+
+// FindOpenMR returns the open MR for a source branch, or nil if there is none
+func (c *Client) FindOpenMR(ctx context.Context, project Project, branchName string) (*MergeRequest, error) {
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("source_branch", branchName)
+
+	var mrs []MergeRequest
+	if _, err := c.do(ctx, "list merge requests", http.MethodGet, fmt.Sprintf("/projects/%d/merge_requests", project.ID), query, nil, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return &mrs[0], nil
 }
 
 // CreateBranch creates a new branch in a project
 func (c *Client) CreateBranch(ctx context.Context, project Project, branchName, fromBranch string) error {
-	// Placeholder - would implement GitLab API call
-	c.logger.Info(fmt.Sprintf("Would create branch '%s' in %s", branchName, project.PathWithNamespace))
-	return nil
+	c.logger.Info(fmt.Sprintf("Creating branch '%s' in %s", branchName, project.PathWithNamespace))
+
+	payload := map[string]string{
+		"branch": branchName,
+		"ref":    fromBranch,
+	}
+	_, err := c.do(ctx, "create branch", http.MethodPost, fmt.Sprintf("/projects/%d/repository/branches", project.ID), nil, payload, nil)
+	return err
+}
+
+// commitAction is a single file operation in the commits API payload
+type commitAction struct {
+	Action   string `json:"action"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
 }
 
-// CreateCommit creates a commit with the given files
+// CreateCommit creates a commit with the given files, keyed by repository-relative path.
+// Files that already exist on the branch are updated, the rest are created.
 func (c *Client) CreateCommit(ctx context.Context, project Project, branchName, message string, files map[string]string) error {
-	// Placeholder - would implement GitLab API call
-	c.logger.Info(fmt.Sprintf("Would create commit in %s on branch %s", project.PathWithNamespace, branchName))
-	return nil
+	if len(files) == 0 {
+		return errors.NewGitLabError(fmt.Sprintf("no files to commit in %s", project.PathWithNamespace))
+	}
+
+	c.logger.Info(fmt.Sprintf("Creating commit in %s on branch %s (%d files)", project.PathWithNamespace, branchName, len(files)))
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	actions := make([]commitAction, 0, len(paths))
+	for _, path := range paths {
+		exists, err := c.fileExists(ctx, project, branchName, path)
+		if err != nil {
+			return err
+		}
+		action := "create"
+		if exists {
+			action = "update"
+		}
+		actions = append(actions, commitAction{
+			Action:   action,
+			FilePath: path,
+			Content:  files[path],
+		})
+	}
+
+	payload := map[string]interface{}{
+		"branch":         branchName,
+		"commit_message": message,
+		"actions":        actions,
+	}
+	if c.UserName != "" {
+		payload["author_name"] = c.UserName
+	}
+	if c.UserEmail != "" {
+		payload["author_email"] = c.UserEmail
+	}
+
+	_, err := c.do(ctx, "create commit", http.MethodPost, fmt.Sprintf("/projects/%d/repository/commits", project.ID), nil, payload, nil)
+	return err
+}
+
+// fileExists checks if a file exists on the given branch
+func (c *Client) fileExists(ctx context.Context, project Project, branchName, filePath string) (bool, error) {
+	query := url.Values{}
+	query.Set("ref", branchName)
+
+	path := fmt.Sprintf("/projects/%d/repository/files/%s", project.ID, url.PathEscape(filePath))
+	resp, err := c.do(ctx, "get file", http.MethodHead, path, query, nil, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // CreateMR creates a merge request
 func (c *Client) CreateMR(ctx context.Context, project Project, sourceBranch, targetBranch, title, description string) (*MergeRequest, error) {
-	// Placeholder - would implement GitLab API call
-	c.logger.Info(fmt.Sprintf("Would create MR in %s: %s -> %s", project.PathWithNamespace, sourceBranch, targetBranch))
-	return &MergeRequest{
-		Title:        title,
-		Description:  description,
-		SourceBranch: sourceBranch,
-		TargetBranch: targetBranch,
-	}, nil
+	c.logger.Info(fmt.Sprintf("Creating MR in %s: %s -> %s", project.PathWithNamespace, sourceBranch, targetBranch))
+
+	payload := map[string]interface{}{
+		"source_branch":        sourceBranch,
+		"target_branch":        targetBranch,
+		"title":                title,
+		"description":          description,
+		"remove_source_branch": true,
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, "create merge request", http.MethodPost, fmt.Sprintf("/projects/%d/merge_requests", project.ID), nil, payload, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// GetMR fetches a merge request by IID
+func (c *Client) GetMR(ctx context.Context, project Project, iid int) (*MergeRequest, error) {
+	var mr MergeRequest
+	if _, err := c.do(ctx, "get merge request", http.MethodGet, fmt.Sprintf("/projects/%d/merge_requests/%d", project.ID, iid), nil, nil, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// ListMRDiffs returns the files changed by a merge request
+func (c *Client) ListMRDiffs(ctx context.Context, project Project, iid int) ([]MRDiff, error) {
+	var diffs []MRDiff
+	page := "1"
+	for page != "" {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(projectsPerPage))
+		query.Set("page", page)
+
+		var batch []MRDiff
+		resp, err := c.do(ctx, "list merge request diffs", http.MethodGet, fmt.Sprintf("/projects/%d/merge_requests/%d/diffs", project.ID, iid), query, nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, batch...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return diffs, nil
+}
+
+// CreateMRNote posts a comment on a merge request
+func (c *Client) CreateMRNote(ctx context.Context, project Project, iid int, body string) error {
+	c.logger.Info(fmt.Sprintf("Commenting on MR !%d in %s", iid, project.PathWithNamespace))
+
+	payload := map[string]string{"body": body}
+	_, err := c.do(ctx, "create merge request note", http.MethodPost, fmt.Sprintf("/projects/%d/merge_requests/%d/notes", project.ID, iid), nil, payload, nil)
+	return err
+}
+
+// ListMRNotes returns the comments of a merge request
+func (c *Client) ListMRNotes(ctx context.Context, project Project, iid int) ([]MRNote, error) {
+	var notes []MRNote
+	page := "1"
+	for page != "" {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(projectsPerPage))
+		query.Set("page", page)
+
+		var batch []MRNote
+		resp, err := c.do(ctx, "list merge request notes", http.MethodGet, fmt.Sprintf("/projects/%d/merge_requests/%d/notes", project.ID, iid), query, nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, batch...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return notes, nil
+}
+
+// UpdateMRNote replaces the body of a merge request comment
+func (c *Client) UpdateMRNote(ctx context.Context, project Project, iid, noteID int, body string) error {
+	c.logger.Info(fmt.Sprintf("Updating comment on MR !%d in %s", iid, project.PathWithNamespace))
+
+	payload := map[string]string{"body": body}
+	_, err := c.do(ctx, "update merge request note", http.MethodPut, fmt.Sprintf("/projects/%d/merge_requests/%d/notes/%d", project.ID, iid, noteID), nil, payload, nil)
+	return err
+}
+
+// UpdateMRDescription replaces the description of a merge request
+func (c *Client) UpdateMRDescription(ctx context.Context, project Project, iid int, description string) error {
+	c.logger.Info(fmt.Sprintf("Updating MR !%d in %s", iid, project.PathWithNamespace))
+
+	payload := map[string]string{"description": description}
+	_, err := c.do(ctx, "update merge request", http.MethodPut, fmt.Sprintf("/projects/%d/merge_requests/%d", project.ID, iid), nil, payload, nil)
+	return err
+}
+
+// GetFileContent returns the content of a file on a branch. The boolean is false when the file does not exist.
+func (c *Client) GetFileContent(ctx context.Context, project Project, branchName, filePath string) (string, bool, error) {
+	query := url.Values{}
+	query.Set("ref", branchName)
+
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	path := fmt.Sprintf("/projects/%d/repository/files/%s", project.ID, url.PathEscape(filePath))
+	resp, err := c.do(ctx, "get file", http.MethodGet, path, query, nil, &file)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if file.Encoding != "base64" {
+		return file.Content, true, nil
+	}
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return "", false, errors.NewGitLabAPIError("get file", "", fmt.Errorf("failed to decode %s: %w", filePath, err))
+	}
+	return string(content), true, nil
+}
+
+// baseURL returns the REST API root, appending /api/v4 when the configured URL is the instance root
+func (c *Client) baseURL() string {
+	base := strings.TrimRight(c.apiURL, "/")
+	if base == "" {
+		base = defaultAPIURL
+	}
+	if !strings.HasSuffix(base, apiPrefix) {
+		base += apiPrefix
+	}
+	return base
+}
+
+// do performs an API request, decoding a JSON response into out when non-nil.
+// The response is returned even on error so callers can inspect the status code.
+func (c *Client) do(ctx context.Context, operation, method, path string, query url.Values, payload, out interface{}) (*http.Response, error) {
+	endpoint := c.baseURL() + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.NewGitLabAPIError(operation, "", fmt.Errorf("failed to marshal request: %w", err))
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, errors.NewGitLabAPIError(operation, "", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.OAuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.OAuthToken)
+	}
+
+	c.logger.Debug(fmt.Sprintf("GitLab API %s %s", method, path))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewGitLabAPIError(operation, "", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return resp, errors.NewGitLabAuthError(fmt.Errorf("%s: %s", resp.Status, readErrorBody(resp.Body)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, errors.NewGitLabAPIError(operation, strconv.Itoa(resp.StatusCode), fmt.Errorf("%s: %s", resp.Status, readErrorBody(resp.Body)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, errors.NewGitLabAPIError(operation, strconv.Itoa(resp.StatusCode), fmt.Errorf("failed to decode response: %w", err))
+		}
+	}
+
+	return resp, nil
+}
+
+// readErrorBody reads a bounded amount of an error response for diagnostics
+func readErrorBody(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, 4096))
+	return strings.TrimSpace(string(data))
 }
@@ -0,0 +1,328 @@
+package gitlab
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/logging"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func newTestClient(t *testing.T, fake *testHelpers.FakeGitLab, token string) *Client {
+	t.Helper()
+	return NewClient(config.GitLabConfig{
+		APIURL:     fake.URL(),
+		OAuthToken: token,
+		UserName:   "AI Analyzer",
+		UserEmail:  "ai@example.com",
+	}, logging.NewNopLogger())
+}
+
+func TestClient_FetchProjectsInGroup_Paginates(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	for i := 1; i <= 150; i++ {
+		fake.AddProject(42, testHelpers.FakeGitLabProject{
+			ID:                i,
+			Name:              fmt.Sprintf("project-%d", i),
+			PathWithNamespace: fmt.Sprintf("group/sub/project-%d", i),
+		})
+	}
+
+	client := newTestClient(t, fake, "secret")
+	projects, err := client.FetchProjectsInGroup(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(projects) != 150 {
+		t.Fatalf("expected 150 projects, got %d", len(projects))
+	}
+	if projects[149].PathWithNamespace != "group/sub/project-150" {
+		t.Errorf("unexpected last project: %s", projects[149].PathWithNamespace)
+	}
+	if projects[0].DefaultBranch != "main" {
+		t.Errorf("expected default branch main, got %q", projects[0].DefaultBranch)
+	}
+}
+
+func TestClient_FetchProjectsInGroup_AuthError(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1})
+
+	client := newTestClient(t, fake, "wrong")
+	_, err := client.FetchProjectsInGroup(context.Background(), 1)
+	if err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+
+	var authErr *errors.GitLabAuthError
+	if !stderrors.As(err, &authErr) {
+		t.Errorf("expected GitLabAuthError, got %T", err)
+	}
+}
+
+func TestClient_FetchProjectsInGroup_UnknownGroup(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	client := newTestClient(t, fake, "")
+
+	_, err := client.FetchProjectsInGroup(context.Background(), 999)
+	var apiErr *errors.GitLabAPIError
+	if !stderrors.As(err, &apiErr) {
+		t.Fatalf("expected GitLabAPIError, got %v", err)
+	}
+	if apiErr.Context.Details["status_code"] != "404" {
+		t.Errorf("expected status_code 404, got %v", apiErr.Context.Details["status_code"])
+	}
+}
+
+func TestClient_BranchExists(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1})
+	fake.AddBranch(1, "feature/docs", nil)
+
+	client := newTestClient(t, fake, "")
+	project := Project{ID: 1}
+
+	exists, err := client.BranchExists(context.Background(), project, "feature/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists {
+		t.Error("expected branch with slash to exist")
+	}
+
+	exists, err = client.BranchExists(context.Background(), project, "missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists {
+		t.Error("expected missing branch to not exist")
+	}
+}
+
+func TestClient_HasOpenMR(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1})
+	fake.AddMergeRequest(1, testHelpers.FakeGitLabMR{SourceBranch: "open-branch"})
+	fake.AddMergeRequest(1, testHelpers.FakeGitLabMR{SourceBranch: "merged-branch", State: "merged"})
+
+	client := newTestClient(t, fake, "")
+	project := Project{ID: 1}
+
+	tests := []struct {
+		branch   string
+		expected bool
+	}{
+		{"open-branch", true},
+		{"merged-branch", false},
+		{"other", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			hasMR, err := client.HasOpenMR(context.Background(), project, tt.branch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if hasMR != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, hasMR)
+			}
+		})
+	}
+}
+
+func TestClient_CreateBranchCommitAndMR(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	fake.AddProject(7, testHelpers.FakeGitLabProject{ID: 7, PathWithNamespace: "group/app"})
+	fake.AddBranch(7, "main", map[string]string{"README.md": "old readme"})
+
+	client := newTestClient(t, fake, "secret")
+	project := Project{ID: 7, PathWithNamespace: "group/app", DefaultBranch: "main"}
+	ctx := context.Background()
+
+	if err := client.CreateBranch(ctx, project, "ai-analyzer-2024-01-01", "main"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if !fake.HasBranch(7, "ai-analyzer-2024-01-01") {
+		t.Fatal("expected branch to be created")
+	}
+
+	files := map[string]string{
+		".ai/docs/structure_analysis.md": "# Structure",
+		"README.md":                      "new readme",
+		"CLAUDE.md":                      "# Rules",
+	}
+	if err := client.CreateCommit(ctx, project, "ai-analyzer-2024-01-01", "[skip ci] AI analysis", files); err != nil {
+		t.Fatalf("CreateCommit failed: %v", err)
+	}
+
+	commits := fake.Commits()
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit, got %d", len(commits))
+	}
+	commit := commits[0]
+	if commit.AuthorName != "AI Analyzer" || commit.AuthorEmail != "ai@example.com" {
+		t.Errorf("unexpected author: %s <%s>", commit.AuthorName, commit.AuthorEmail)
+	}
+
+	actions := make(map[string]string)
+	for _, a := range commit.Actions {
+		actions[a.FilePath] = a.Action
+	}
+	expected := map[string]string{
+		".ai/docs/structure_analysis.md": "create",
+		"README.md":                      "update",
+		"CLAUDE.md":                      "create",
+	}
+	for path, action := range expected {
+		if actions[path] != action {
+			t.Errorf("expected %s action for %s, got %q", action, path, actions[path])
+		}
+	}
+
+	if content, _ := fake.FileContent(7, "ai-analyzer-2024-01-01", "README.md"); content != "new readme" {
+		t.Errorf("expected README to be updated, got %q", content)
+	}
+	if content, _ := fake.FileContent(7, "main", "README.md"); content != "old readme" {
+		t.Errorf("expected main branch untouched, got %q", content)
+	}
+
+	mr, err := client.CreateMR(ctx, project, "ai-analyzer-2024-01-01", "main", "AI Analysis", "description")
+	if err != nil {
+		t.Fatalf("CreateMR failed: %v", err)
+	}
+	if mr.IID == 0 || mr.WebURL == "" {
+		t.Errorf("expected MR to have IID and web URL, got %+v", mr)
+	}
+	if mr.SourceBranch != "ai-analyzer-2024-01-01" || mr.TargetBranch != "main" {
+		t.Errorf("unexpected MR branches: %s -> %s", mr.SourceBranch, mr.TargetBranch)
+	}
+
+	hasMR, err := client.HasOpenMR(ctx, project, "ai-analyzer-2024-01-01")
+	if err != nil || !hasMR {
+		t.Errorf("expected open MR after creation, got %v (err: %v)", hasMR, err)
+	}
+}
+
+func TestClient_GetFileContentAndUpdateMR(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1})
+	fake.AddBranch(1, "docs", map[string]string{".ai/docs/api_analysis.md": "# API\n"})
+	fake.AddMergeRequest(1, testHelpers.FakeGitLabMR{IID: 5, SourceBranch: "docs", Description: "old"})
+
+	client := newTestClient(t, fake, "")
+	project := Project{ID: 1}
+	ctx := context.Background()
+
+	content, exists, err := client.GetFileContent(ctx, project, "docs", ".ai/docs/api_analysis.md")
+	if err != nil || !exists || content != "# API\n" {
+		t.Errorf("unexpected file: %q exists=%v err=%v", content, exists, err)
+	}
+	if _, exists, err := client.GetFileContent(ctx, project, "docs", "missing.md"); err != nil || exists {
+		t.Errorf("expected missing file, got exists=%v err=%v", exists, err)
+	}
+
+	if err := client.UpdateMRDescription(ctx, project, 5, "new"); err != nil {
+		t.Fatalf("UpdateMRDescription failed: %v", err)
+	}
+	if mrs := fake.MergeRequests(1); mrs[0].Description != "new" {
+		t.Errorf("expected description to be updated, got %q", mrs[0].Description)
+	}
+}
+
+func TestClient_MergeRequestReview(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 3, PathWithNamespace: "group/sub/app"})
+	fake.AddMergeRequest(3, testHelpers.FakeGitLabMR{
+		IID:          8,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		Diffs: []testHelpers.FakeGitLabMRDiff{
+			{OldPath: "main.go", NewPath: "main.go"},
+			{OldPath: "old.go", NewPath: "new.go", RenamedFile: true},
+		},
+	})
+
+	client := newTestClient(t, fake, "")
+	ctx := context.Background()
+
+	project, err := client.GetProject(ctx, "group/sub/app")
+	if err != nil {
+		t.Fatalf("GetProject by path failed: %v", err)
+	}
+	if project.ID != 3 {
+		t.Errorf("expected project 3, got %d", project.ID)
+	}
+	if _, err := client.GetProject(ctx, "3"); err != nil {
+		t.Errorf("GetProject by ID failed: %v", err)
+	}
+
+	mr, err := client.GetMR(ctx, *project, 8)
+	if err != nil || mr.TargetBranch != "main" {
+		t.Errorf("unexpected MR: %+v (err: %v)", mr, err)
+	}
+
+	diffs, err := client.ListMRDiffs(ctx, *project, 8)
+	if err != nil {
+		t.Fatalf("ListMRDiffs failed: %v", err)
+	}
+	if len(diffs) != 2 || !diffs[1].RenamedFile || diffs[1].OldPath != "old.go" {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+
+	if err := client.CreateMRNote(ctx, *project, 8, "stale docs"); err != nil {
+		t.Fatalf("CreateMRNote failed: %v", err)
+	}
+	if notes := fake.Notes(); len(notes) != 1 || notes[0].MRIID != 8 || notes[0].Body != "stale docs" {
+		t.Errorf("unexpected notes: %+v", notes)
+	}
+}
+
+func TestClient_CreateBranch_AlreadyExists(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	fake.AddProject(1, testHelpers.FakeGitLabProject{ID: 1})
+	fake.AddBranch(1, "existing", nil)
+
+	client := newTestClient(t, fake, "")
+	err := client.CreateBranch(context.Background(), Project{ID: 1}, "existing", "main")
+
+	var apiErr *errors.GitLabAPIError
+	if !stderrors.As(err, &apiErr) {
+		t.Fatalf("expected GitLabAPIError, got %v", err)
+	}
+}
+
+func TestClient_CreateCommit_NoFiles(t *testing.T) {
+	fake := testHelpers.NewFakeGitLab(t, "")
+	client := newTestClient(t, fake, "")
+
+	if err := client.CreateCommit(context.Background(), Project{ID: 1}, "main", "msg", nil); err == nil {
+		t.Error("expected error when committing no files")
+	}
+	if len(fake.Commits()) != 0 {
+		t.Error("expected no commit to be sent")
+	}
+}
+
+func TestClient_BaseURL(t *testing.T) {
+	tests := []struct {
+		apiURL   string
+		expected string
+	}{
+		{"", "https://gitlab.com/api/v4"},
+		{"https://gitlab.com", "https://gitlab.com/api/v4"},
+		{"https://gitlab.example.com/", "https://gitlab.example.com/api/v4"},
+		{"https://gitlab.example.com/api/v4", "https://gitlab.example.com/api/v4"},
+	}
+
+	for _, tt := range tests {
+		c := NewClient(config.GitLabConfig{APIURL: tt.apiURL}, logging.NewNopLogger())
+		if got := c.baseURL(); got != tt.expected {
+			t.Errorf("baseURL(%q) = %q, want %q", tt.apiURL, got, tt.expected)
+		}
+	}
+}
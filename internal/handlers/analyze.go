@@ -33,6 +33,13 @@ func (h *AnalyzeHandler) SetProgressReporter(p agents.ProgressReporter) {
 
 // Handle executes the analysis
 func (h *AnalyzeHandler) Handle(ctx context.Context) error {
+	_, err := h.Run(ctx)
+	return err
+}
+
+// Run executes the analysis and returns the per-agent results.
+// The result is also returned alongside an error when every analysis failed.
+func (h *AnalyzeHandler) Run(ctx context.Context) (*agents.AnalysisResult, error) {
 	h.Logger.Info("Starting analyze handler",
 		logging.String("repo_path", h.config.RepoPath),
 	)
@@ -51,26 +58,40 @@ func (h *AnalyzeHandler) Handle(ctx context.Context) error {
 	// Load with override support
 	promptManager, err := prompts.NewManagerWithOverrides(systemPromptsDir, projectPromptsDir)
 	if err != nil {
-		return errors.NewConfigurationError(fmt.Sprintf("failed to load prompts: %v", err))
+		return nil, errors.NewConfigurationError(fmt.Sprintf("failed to load prompts: %v", err))
 	}
 
-	analyzerAgent := agents.NewAnalyzerAgent(h.config, promptManager, h.Logger)
+	var analyzer interface {
+		SetProgressReporter(agents.ProgressReporter)
+		Run(context.Context) (*agents.AnalysisResult, error)
+	} = agents.NewAnalyzerAgent(h.config, promptManager, h.Logger)
+	if h.config.Monorepo {
+		analyzer = agents.NewMonorepoAnalyzer(h.config, promptManager, h.Logger)
+	}
 	if h.progress != nil {
-		analyzerAgent.SetProgressReporter(h.progress)
+		analyzer.SetProgressReporter(h.progress)
 	}
 
-	result, err := analyzerAgent.Run(ctx)
+	result, err := analyzer.Run(ctx)
 	if err != nil {
-		return errors.NewAnalysisError("analysis execution failed", err)
+		return nil, errors.NewAnalysisError("analysis execution failed", err)
 	}
 
 	// Log results
+	if result.Unchanged {
+		h.Logger.Info("No changes since the last analysis, using cached results")
+		return result, nil
+	}
 	h.Logger.Info(fmt.Sprintf("Analysis complete: %d/%d successful",
 		len(result.Successful), len(result.Successful)+len(result.Failed)))
 
 	// Determine exit code
 	if len(result.Failed) > 0 && len(result.Successful) == 0 {
-		return errors.NewAnalysisError("all analyses failed", fmt.Errorf("no successful analyses"))
+		return result, errors.NewAnalysisError("all analyses failed", fmt.Errorf("no successful analyses"))
+	}
+
+	if result.BudgetExceeded() {
+		h.Logger.Warn("Run budget exceeded, remaining analyses were stopped")
 	}
 
 	if len(result.Failed) > 0 {
@@ -80,5 +101,5 @@ func (h *AnalyzeHandler) Handle(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	return result, nil
 }
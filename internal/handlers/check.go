@@ -10,9 +10,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/user/gendocs/internal/agents"
 	"github.com/user/gendocs/internal/cache"
 	"github.com/user/gendocs/internal/config"
 	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/modules"
 )
 
 type DriftSeverity string
@@ -27,6 +29,7 @@ const (
 type AgentDriftStatus struct {
 	Name          string    `json:"name"`
 	DisplayName   string    `json:"display_name"`
+	OutputFile    string    `json:"output_file"`
 	LastRun       time.Time `json:"last_run"`
 	Success       bool      `json:"success"`
 	OutputExists  bool      `json:"output_exists"`
@@ -51,6 +54,8 @@ type DriftReport struct {
 	DocsDir          string             `json:"docs_dir"`
 	CacheFile        string             `json:"cache_file"`
 	IsFirstRun       bool               `json:"is_first_run"`
+	Module           string             `json:"module,omitempty"`  // Set on the reports of monorepo modules
+	Modules          []*DriftReport     `json:"modules,omitempty"` // Per-module reports of a monorepo
 }
 
 type CheckHandler struct {
@@ -73,12 +78,92 @@ func (h *CheckHandler) Handle(ctx context.Context) (*DriftReport, error) {
 		logging.String("repo_path", h.config.RepoPath),
 	)
 
+	// Repositories last analyzed with --monorepo are checked per module
+	if h.config.Monorepo || modules.HasManifest(h.config.RepoPath) {
+		return h.checkModules()
+	}
+	return h.checkRepo(h.config.RepoPath, nil)
+}
+
+// checkModules checks each module of a monorepo and summarizes the module reports
+func (h *CheckHandler) checkModules() (*DriftReport, error) {
+	mods, err := modules.Discover(h.config.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{
+		DocsDir:    filepath.Join(h.config.RepoPath, ".ai", "docs"),
+		CacheFile:  filepath.Join(h.config.RepoPath, cache.CacheFileName),
+		IsFirstRun: len(mods) > 0,
+		Severity:   DriftSeverityNone,
+	}
+
+	var drifted []string
+	for _, module := range mods {
+		moduleReport, err := h.checkRepo(filepath.Join(h.config.RepoPath, module.Path), modules.NestedPaths(mods, module))
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.Name, err)
+		}
+		moduleReport.Module = module.Name
+		report.Modules = append(report.Modules, moduleReport)
+
+		report.IsFirstRun = report.IsFirstRun && moduleReport.IsFirstRun
+		if moduleReport.LastAnalysis.After(report.LastAnalysis) {
+			report.LastAnalysis = moduleReport.LastAnalysis
+		}
+		if moduleReport.HasDrift {
+			report.HasDrift = true
+			drifted = append(drifted, module.Name)
+			if severityRank(moduleReport.Severity) > severityRank(report.Severity) {
+				report.Severity = moduleReport.Severity
+			}
+		}
+	}
+
+	switch {
+	case len(mods) == 0:
+		report.Summary = "No modules found"
+		report.Recommendation = "Run 'gendocs check' without --monorepo"
+	case !report.HasDrift:
+		report.Summary = fmt.Sprintf("All %d module(s) are up to date", len(mods))
+		report.Recommendation = "No action needed"
+	default:
+		report.Summary = fmt.Sprintf("%d of %d module(s) drifted: %s", len(drifted), len(mods), strings.Join(drifted, ", "))
+		report.Recommendation = "Run 'gendocs analyze --monorepo' to update the drifted modules"
+	}
+
+	return report, nil
+}
+
+// severityRank orders drift severities from none to major
+func severityRank(severity DriftSeverity) int {
+	switch severity {
+	case DriftSeverityMinor:
+		return 1
+	case DriftSeverityModerate:
+		return 2
+	case DriftSeverityMajor:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// checkRepo computes the drift report of one repository or module. excludePaths
+// are left out of the file scan (the nested modules of a monorepo module).
+func (h *CheckHandler) checkRepo(repoPath string, excludePaths []string) (*DriftReport, error) {
 	report := &DriftReport{
-		DocsDir:   filepath.Join(h.config.RepoPath, ".ai", "docs"),
-		CacheFile: filepath.Join(h.config.RepoPath, cache.CacheFileName),
+		DocsDir:   filepath.Join(repoPath, ".ai", "docs"),
+		CacheFile: filepath.Join(repoPath, cache.CacheFileName),
 	}
 
-	analysisCache, err := cache.LoadCache(h.config.RepoPath)
+	registry, err := agents.LoadRegistry(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	analysisCache, err := cache.LoadCache(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load cache: %w", err)
 	}
@@ -94,7 +179,7 @@ func (h *CheckHandler) Handle(ctx context.Context) (*DriftReport, error) {
 
 	report.LastAnalysis = analysisCache.LastAnalysis
 	report.CachedGitCommit = analysisCache.GitCommit
-	report.CurrentGitCommit = cache.GetCurrentGitCommit(h.config.RepoPath)
+	report.CurrentGitCommit = cache.GetCurrentGitCommit(repoPath)
 
 	if report.CurrentGitCommit != "" && report.CachedGitCommit != "" {
 		report.GitCommitChanged = report.CurrentGitCommit != report.CachedGitCommit
@@ -102,8 +187,8 @@ func (h *CheckHandler) Handle(ctx context.Context) (*DriftReport, error) {
 
 	var scanMetrics cache.ScanMetrics
 	currentFiles, err := cache.ScanFiles(
-		h.config.RepoPath,
-		nil,
+		repoPath,
+		excludePaths,
 		analysisCache,
 		&scanMetrics,
 		h.config.GetMaxHashWorkers(),
@@ -112,13 +197,13 @@ func (h *CheckHandler) Handle(ctx context.Context) (*DriftReport, error) {
 		return nil, fmt.Errorf("failed to scan files: %w", err)
 	}
 
-	changeReport := analysisCache.DetectChanges(h.config.RepoPath, currentFiles)
+	changeReport := analysisCache.DetectChangesForAgents(repoPath, currentFiles, registry.FilePatterns())
 	report.NewFiles = changeReport.NewFiles
 	report.ModifiedFiles = changeReport.ModifiedFiles
 	report.DeletedFiles = changeReport.DeletedFiles
 	report.HasDrift = changeReport.HasChanges
 
-	report.AgentStatus = h.buildAgentStatus(analysisCache, changeReport)
+	report.AgentStatus = h.buildAgentStatus(repoPath, registry, analysisCache, changeReport)
 
 	report.Severity = h.calculateSeverity(report)
 	report.Summary = h.generateSummary(report)
@@ -127,66 +212,43 @@ func (h *CheckHandler) Handle(ctx context.Context) (*DriftReport, error) {
 	return report, nil
 }
 
-func (h *CheckHandler) buildAgentStatus(analysisCache *cache.AnalysisCache, changeReport *cache.ChangeReport) []AgentDriftStatus {
-	agentDisplayNames := map[string]string{
-		"structure_analyzer":    "Structure Analysis",
-		"dependency_analyzer":   "Dependency Analysis",
-		"data_flow_analyzer":    "Data Flow Analysis",
-		"request_flow_analyzer": "Request Flow Analysis",
-		"api_analyzer":          "API Analysis",
-	}
-
-	agentOutputFiles := map[string]string{
-		"structure_analyzer":    "structure_analysis.md",
-		"dependency_analyzer":   "dependency_analysis.md",
-		"data_flow_analyzer":    "data_flow_analysis.md",
-		"request_flow_analyzer": "request_flow_analysis.md",
-		"api_analyzer":          "api_analysis.md",
-	}
+func (h *CheckHandler) buildAgentStatus(repoPath string, registry *agents.Registry, analysisCache *cache.AnalysisCache, changeReport *cache.ChangeReport) []AgentDriftStatus {
+	return buildAgentDriftStatus(registry, analysisCache, changeReport, func(outputFile string) bool {
+		_, err := os.Stat(filepath.Join(repoPath, ".ai", "docs", outputFile))
+		return err == nil
+	})
+}
 
+// buildAgentDriftStatus computes the drift status of each registry agent. outputExists
+// reports whether an agent's document (a file name in .ai/docs) is present, so the
+// documentation does not have to live on the local disk.
+func buildAgentDriftStatus(registry *agents.Registry, analysisCache *cache.AnalysisCache, changeReport *cache.ChangeReport, outputExists func(outputFile string) bool) []AgentDriftStatus {
 	var statuses []AgentDriftStatus
-	changedFiles := append(changeReport.NewFiles, changeReport.ModifiedFiles...)
-	changedFiles = append(changedFiles, changeReport.DeletedFiles...)
 
-	for agentName, displayName := range agentDisplayNames {
+	for _, spec := range registry.Agents() {
 		status := AgentDriftStatus{
-			Name:        agentName,
-			DisplayName: displayName,
+			Name:         spec.Name,
+			DisplayName:  spec.DisplayName,
+			OutputFile:   spec.OutputFile,
+			OutputExists: outputExists(spec.OutputFile),
 		}
 
-		if cachedStatus, exists := analysisCache.Agents[agentName]; exists {
+		if cachedStatus, exists := analysisCache.Agents[spec.Name]; exists {
 			status.LastRun = cachedStatus.LastRun
 			status.Success = cachedStatus.Success
 		}
 
-		if outputFile, exists := agentOutputFiles[agentName]; exists {
-			outputPath := filepath.Join(h.config.RepoPath, ".ai", "docs", outputFile)
-			if _, err := os.Stat(outputPath); err == nil {
-				status.OutputExists = true
-			}
-		}
-
 		for _, agent := range changeReport.AgentsToRun {
-			if agent == agentName {
+			if agent == spec.Name {
 				status.NeedsRerun = true
 				break
 			}
 		}
 
 		if status.NeedsRerun {
-			affectedCount := 0
-			if patterns, exists := cache.AgentFilePatterns[agentName]; exists {
-				for _, file := range changedFiles {
-					for _, pattern := range patterns {
-						if matchesPattern(file, pattern) {
-							affectedCount++
-							break
-						}
-					}
-				}
-			}
+			affectedCount := len(analysisCache.AffectedFiles(spec.Name, changeReport, spec.FilePatterns))
 			status.AffectedFiles = affectedCount
-			status.RerunReason = h.buildRerunReason(agentName, affectedCount, status)
+			status.RerunReason = buildRerunReason(affectedCount, status)
 		}
 
 		statuses = append(statuses, status)
@@ -202,7 +264,7 @@ func (h *CheckHandler) buildAgentStatus(analysisCache *cache.AnalysisCache, chan
 	return statuses
 }
 
-func (h *CheckHandler) buildRerunReason(_ string, affectedCount int, status AgentDriftStatus) string {
+func buildRerunReason(affectedCount int, status AgentDriftStatus) string {
 	if !status.Success && !status.LastRun.IsZero() {
 		return "Previous run failed"
 	}
@@ -306,6 +368,11 @@ func (h *CheckHandler) FormatTextReport(report *DriftReport) string {
 	sb.WriteString("📋 Documentation Drift Report\n")
 	sb.WriteString("==============================\n\n")
 
+	if len(report.Modules) > 0 {
+		h.writeModulesReport(&sb, report)
+		return sb.String()
+	}
+
 	if report.IsFirstRun {
 		sb.WriteString("⚠️  Status: No previous analysis found\n\n")
 		sb.WriteString("   This appears to be a new project or the cache has been cleared.\n")
@@ -313,17 +380,7 @@ func (h *CheckHandler) FormatTextReport(report *DriftReport) string {
 		return sb.String()
 	}
 
-	statusIcon := "✅"
-	if report.HasDrift {
-		switch report.Severity {
-		case DriftSeverityMajor:
-			statusIcon = "🔴"
-		case DriftSeverityModerate:
-			statusIcon = "🟠"
-		case DriftSeverityMinor:
-			statusIcon = "🟡"
-		}
-	}
+	statusIcon := severityIcon(report)
 
 	sb.WriteString(fmt.Sprintf("%s Status: %s\n", statusIcon, toTitleCase(string(report.Severity))))
 	sb.WriteString(fmt.Sprintf("   Last Analysis: %s\n", report.LastAnalysis.Format("2006-01-02 15:04:05")))
@@ -404,36 +461,73 @@ func (h *CheckHandler) FormatTextReport(report *DriftReport) string {
 	return sb.String()
 }
 
-func (h *CheckHandler) FormatJSONReport(report *DriftReport) (string, error) {
-	data, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal report: %w", err)
-	}
-	return string(data), nil
-}
-
-func matchesPattern(filename, pattern string) bool {
-	if strings.Contains(pattern, "*") {
-		pattern = strings.ToLower(pattern)
-		filename = strings.ToLower(filepath.Base(filename))
-
-		if strings.HasPrefix(pattern, "*.") && !strings.Contains(pattern[1:], "*") {
-			ext := strings.TrimPrefix(pattern, "*")
-			return strings.HasSuffix(filename, ext)
+// writeModulesReport renders a monorepo report: one line per module, followed
+// by the agents that need a re-run in the drifted modules
+func (h *CheckHandler) writeModulesReport(sb *strings.Builder, report *DriftReport) {
+	sb.WriteString(fmt.Sprintf("%s Status: %s (%d modules)\n\n", severityIcon(report), toTitleCase(string(report.Severity)), len(report.Modules)))
+
+	sb.WriteString("📦 Modules:\n")
+	for _, module := range report.Modules {
+		switch {
+		case module.IsFirstRun:
+			sb.WriteString(fmt.Sprintf("   %s %s - not analyzed yet\n", severityIcon(module), module.Module))
+		case module.HasDrift:
+			sb.WriteString(fmt.Sprintf("   %s %s - %s\n", severityIcon(module), module.Module, module.Summary))
+		default:
+			sb.WriteString(fmt.Sprintf("   %s %s - up to date\n", severityIcon(module), module.Module))
 		}
 
-		if strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") {
-			keyword := strings.Trim(pattern, "*")
-			return strings.Contains(filename, keyword)
+		if !module.HasDrift || module.IsFirstRun {
+			continue
 		}
-
-		if strings.HasPrefix(pattern, "*") {
-			suffix := strings.TrimPrefix(pattern, "*")
-			return strings.HasSuffix(filename, suffix)
+		for _, agent := range module.AgentStatus {
+			if agent.NeedsRerun {
+				sb.WriteString(fmt.Sprintf("      ⚠️ %s - needs re-run (%s)\n", agent.DisplayName, agent.RerunReason))
+			}
+		}
+		if h.config.Verbose {
+			changed := append(append(append([]string{}, module.NewFiles...), module.ModifiedFiles...), module.DeletedFiles...)
+			for _, f := range limitSlice(changed, 10) {
+				sb.WriteString(fmt.Sprintf("      • %s\n", f))
+			}
+			if len(changed) > 10 {
+				sb.WriteString(fmt.Sprintf("      ... and %d more\n", len(changed)-10))
+			}
 		}
 	}
+	sb.WriteString("\n")
 
-	return strings.EqualFold(filepath.Base(filename), pattern)
+	sb.WriteString("📊 Summary: ")
+	sb.WriteString(report.Summary)
+	sb.WriteString("\n\n")
+
+	sb.WriteString("💡 Recommendation: ")
+	sb.WriteString(report.Recommendation)
+	sb.WriteString("\n\n")
+}
+
+// severityIcon returns the status icon of a report
+func severityIcon(report *DriftReport) string {
+	if !report.HasDrift {
+		return "✅"
+	}
+	switch report.Severity {
+	case DriftSeverityMajor:
+		return "🔴"
+	case DriftSeverityModerate:
+		return "🟠"
+	case DriftSeverityMinor:
+		return "🟡"
+	}
+	return "✅"
+}
+
+func (h *CheckHandler) FormatJSONReport(report *DriftReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return string(data), nil
 }
 
 func limitSlice(slice []string, limit int) []string {
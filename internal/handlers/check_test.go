@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/user/gendocs/internal/agents"
 	"github.com/user/gendocs/internal/cache"
 	"github.com/user/gendocs/internal/config"
 	"github.com/user/gendocs/internal/logging"
@@ -163,6 +164,126 @@ func TestCheckHandler_Handle_WithDrift(t *testing.T) {
 	}
 }
 
+func TestCheckHandler_Handle_CustomAgent(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go": "package main\nfunc main() {}",
+		".ai/config.yaml": `
+agents:
+  schema_analyzer:
+    display_name: Schema Analysis
+    file_patterns: ["*.sql"]
+`,
+	})
+
+	analysisCache := cache.NewCache()
+	files, err := cache.ScanFiles(repoPath, nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("failed to scan files: %v", err)
+	}
+	agentResults := map[string]bool{"schema_analyzer": true}
+	for _, name := range agents.DefaultRegistry().Names() {
+		agentResults[name] = true
+	}
+	analysisCache.UpdateAfterAnalysis(repoPath, files, agentResults)
+	if err := analysisCache.Save(repoPath); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoPath, "schema.sql"), []byte("CREATE TABLE t (id int);"), 0644); err != nil {
+		t.Fatalf("failed to create new file: %v", err)
+	}
+
+	handler := NewCheckHandler(config.CheckConfig{BaseConfig: config.BaseConfig{RepoPath: repoPath}}, logging.NewNopLogger())
+	report, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.AgentStatus) != 9 {
+		t.Fatalf("expected 9 agents in the report, got %d", len(report.AgentStatus))
+	}
+	status := report.AgentStatus[0]
+	if status.Name != "schema_analyzer" || !status.NeedsRerun || status.AffectedFiles != 1 {
+		t.Errorf("expected only the schema agent to need a re-run, got %+v", report.AgentStatus)
+	}
+	if status.DisplayName != "Schema Analysis" || status.OutputFile != "schema_analysis.md" {
+		t.Errorf("unexpected custom agent status: %+v", status)
+	}
+	if report.AgentStatus[1].NeedsRerun {
+		t.Errorf("expected built-in agents to be up to date, got %+v", report.AgentStatus[1])
+	}
+}
+
+func TestCheckHandler_Handle_Monorepo(t *testing.T) {
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"go.mod":                  "module example.com/root\n",
+		"main.go":                 "package main\nfunc main() {}",
+		"services/api/go.mod":     "module example.com/api\n",
+		"services/api/handler.go": "package api",
+		"services/web/go.mod":     "module example.com/web\n",
+		"services/web/main.go":    "package main",
+	})
+
+	// The root and api modules are analyzed, web is not
+	for _, module := range []struct {
+		path     string
+		excludes []string
+	}{
+		{repoPath, []string{filepath.Join("services", "api"), filepath.Join("services", "web")}},
+		{filepath.Join(repoPath, "services", "api"), nil},
+	} {
+		analysisCache := cache.NewCache()
+		files, err := cache.ScanFiles(module.path, module.excludes, nil, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to scan files: %v", err)
+		}
+		agentResults := make(map[string]bool)
+		for _, name := range agents.DefaultRegistry().Names() {
+			agentResults[name] = true
+		}
+		analysisCache.UpdateAfterAnalysis(module.path, files, agentResults)
+		if err := analysisCache.Save(module.path); err != nil {
+			t.Fatalf("failed to save cache: %v", err)
+		}
+	}
+
+	handler := NewCheckHandler(config.CheckConfig{
+		BaseConfig: config.BaseConfig{RepoPath: repoPath},
+		Monorepo:   true,
+	}, logging.NewNopLogger())
+	report, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Modules) != 3 {
+		t.Fatalf("expected 3 module reports, got %d", len(report.Modules))
+	}
+	byModule := make(map[string]*DriftReport)
+	for _, module := range report.Modules {
+		byModule[module.Module] = module
+	}
+	if root := byModule["."]; root == nil || root.HasDrift {
+		t.Errorf("expected the root module to ignore the nested modules and be up to date, got %+v", root)
+	}
+	if api := byModule["services/api"]; api == nil || api.HasDrift {
+		t.Errorf("expected services/api to be up to date, got %+v", api)
+	}
+	if web := byModule["services/web"]; web == nil || !web.IsFirstRun {
+		t.Errorf("expected services/web to need its first analysis, got %+v", web)
+	}
+
+	if !report.HasDrift || report.Severity != DriftSeverityMajor || report.IsFirstRun {
+		t.Errorf("unexpected monorepo status: drift=%v severity=%s first_run=%v", report.HasDrift, report.Severity, report.IsFirstRun)
+	}
+	if !containsSubstring(report.Summary, "1 of 3 module(s) drifted: services/web") {
+		t.Errorf("unexpected summary: %s", report.Summary)
+	}
+	if text := handler.FormatTextReport(report); !containsSubstring(text, "services/web - not analyzed yet") {
+		t.Errorf("expected the module in the text report, got:\n%s", text)
+	}
+}
+
 func TestCheckHandler_Handle_ModifiedFile(t *testing.T) {
 	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
 		"main.go": "package main\nfunc main() {}",
@@ -427,34 +548,6 @@ func TestCalculateSeverity(t *testing.T) {
 	}
 }
 
-func TestMatchesPattern(t *testing.T) {
-	tests := []struct {
-		filename string
-		pattern  string
-		expected bool
-	}{
-		{"main.go", "*.go", true},
-		{"main.py", "*.go", false},
-		{"handler.go", "*handler*", true},
-		{"api_handler.go", "*handler*", true},
-		{"main.go", "*handler*", false},
-		{"go.mod", "go.mod", true},
-		{"go.sum", "go.mod", false},
-		{"test_file.go", "*_test.go", false},
-		{"file_test.go", "*_test.go", true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.filename+"_"+tt.pattern, func(t *testing.T) {
-			got := matchesPattern(tt.filename, tt.pattern)
-			if got != tt.expected {
-				t.Errorf("matchesPattern(%q, %q) = %v, expected %v",
-					tt.filename, tt.pattern, got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestLimitSlice(t *testing.T) {
 	tests := []struct {
 		name     string
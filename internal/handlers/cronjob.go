@@ -5,28 +5,40 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/user/gendocs/internal/agents"
 	"github.com/user/gendocs/internal/config"
 	"github.com/user/gendocs/internal/errors"
-	"github.com/user/gendocs/internal/gitlab"
+	"github.com/user/gendocs/internal/forge"
 	"github.com/user/gendocs/internal/logging"
+	"github.com/user/gendocs/internal/worker_pool"
+)
+
+// MR policies for projects that already have documentation pending review
+const (
+	// mrPolicyCreate opens a new dated branch and MR, skipping projects that already have one today
+	mrPolicyCreate = "create"
+	// mrPolicyUpdate pushes onto a stable branch and keeps a single open MR up to date
+	mrPolicyUpdate = "update"
 )
 
 // CronjobHandler handles the cronjob analyze command
 type CronjobHandler struct {
 	*BaseHandler
 	config      config.CronjobConfig
-	gitLabCfg   config.GitLabConfig
 	analyzerCfg config.AnalyzerConfig
-	gitlab      *gitlab.Client
+	forge       forge.Forge
 }
 
 // NewCronjobHandler creates a new cronjob handler
 func NewCronjobHandler(
 	cronjobCfg config.CronjobConfig,
-	gitLabCfg config.GitLabConfig,
+	f forge.Forge,
 	analyzerCfg config.AnalyzerConfig,
 	logger *logging.Logger,
 ) *CronjobHandler {
@@ -39,9 +51,8 @@ func NewCronjobHandler(
 			Logger: logger,
 		},
 		config:      cronjobCfg,
-		gitLabCfg:   gitLabCfg,
 		analyzerCfg: analyzerCfg,
-		gitlab:      gitlab.NewClient(gitLabCfg, logger),
+		forge:       f,
 	}
 }
 
@@ -51,7 +62,21 @@ type ProcessedResult struct {
 	SuccessCount   int
 	ErrorCount     int
 	SkippedCount   int
+	UnchangedCount int // Projects whose regenerated docs matched the repository or the open MR
 	FailedProjects []FailedProject
+	Projects       []ProjectResult
+	// SkippedProjects lists the projects filtered out before processing
+	SkippedProjects []string
+}
+
+// ProjectResult holds the outcome of a single processed project
+type ProjectResult struct {
+	Name      string
+	MRURL     string
+	Unchanged bool // Content was identical, nothing was pushed
+	Duration  time.Duration
+	Analysis  *agents.AnalysisResult
+	Error     error
 }
 
 // FailedProject represents a project that failed to process
@@ -61,35 +86,42 @@ type FailedProject struct {
 }
 
 // Handle executes the cronjob analysis
-func (h *CronjobHandler) Handle(ctx context.Context) error {
+func (h *CronjobHandler) Handle(ctx context.Context) (*ProcessedResult, error) {
+	startedAt := time.Now()
+	group := h.config.GetGroup()
 	h.Logger.Info("Starting cronjob analysis",
+		logging.String("forge", h.forge.Name()),
 		logging.String("working_path", h.config.WorkingPath),
-		logging.Int("group_project_id", h.config.GroupProjectID),
+		logging.String("group", group),
 		logging.Int("max_days", h.config.MaxDaysSinceLastCommit),
 	)
 
 	// Fetch all projects in the group
-	projects, err := h.gitlab.FetchProjectsInGroup(ctx, h.config.GroupProjectID)
+	projects, err := h.forge.ListProjects(ctx, group)
 	if err != nil {
-		return errors.NewCronjobError("failed to fetch projects", err)
+		return nil, errors.NewCronjobError("failed to fetch projects", err)
 	}
 
 	h.Logger.Info(fmt.Sprintf("Found %d projects in group", len(projects)))
 
 	// Filter projects
-	filter := gitlab.ProjectFilter{
-		MaxDaysSinceLastCommit: h.config.MaxDaysSinceLastCommit,
+	filter := forge.NewProjectFilter(h.config)
+	branchName := analyzerBranchName(time.Now())
+	if filter.UpdateExisting {
+		branchName = h.config.GetBranchName()
 	}
 
-	var applicableProjects []gitlab.Project
+	var applicableProjects []forge.Project
+	var skippedProjects []string
 	for _, project := range projects {
-		shouldAnalyze, err := h.gitlab.ShouldAnalyze(ctx, project, filter)
+		shouldAnalyze, err := forge.ShouldAnalyze(ctx, h.forge, project, filter, branchName)
 		if err != nil {
-			h.Logger.Warn(fmt.Sprintf("Error checking project %s: %v", project.PathWithNamespace, err))
-			continue
+			h.Logger.Warn(fmt.Sprintf("Error checking project %s: %v", project.FullName, err))
 		}
-		if shouldAnalyze {
+		if err == nil && shouldAnalyze {
 			applicableProjects = append(applicableProjects, project)
+		} else {
+			skippedProjects = append(skippedProjects, project.FullName)
 		}
 	}
 
@@ -97,115 +129,393 @@ func (h *CronjobHandler) Handle(ctx context.Context) error {
 
 	// Process each applicable project
 	result := &ProcessedResult{
-		FailedProjects: []FailedProject{},
+		SkippedCount:    len(projects) - len(applicableProjects),
+		FailedProjects:  []FailedProject{},
+		Projects:        []ProjectResult{},
+		SkippedProjects: skippedProjects,
+	}
+
+	concurrency := h.config.GetConcurrency()
+	h.Logger.Info(fmt.Sprintf("Processing projects with concurrency %d", concurrency),
+		logging.Duration("project_timeout", h.config.GetProjectTimeout()),
+	)
+
+	tasks := make([]worker_pool.Task, len(applicableProjects))
+	for i, project := range applicableProjects {
+		tasks[i] = h.newProjectTask(project)
 	}
+	results := worker_pool.NewFixedWorkerPool(concurrency).Run(ctx, tasks)
 
-	for _, project := range applicableProjects {
-		h.Logger.Info(fmt.Sprintf("Processing %s", project.PathWithNamespace))
+	for i, project := range applicableProjects {
+		projectResult, ok := results[i].Value.(ProjectResult)
+		if !ok {
+			// The task never ran (e.g. the parent context was cancelled)
+			projectResult = ProjectResult{Name: project.FullName, Error: results[i].Error}
+		}
 
-		if err := h.processProject(ctx, project); err != nil {
+		if projectResult.Error != nil {
 			result.ErrorCount++
 			result.FailedProjects = append(result.FailedProjects, FailedProject{
-				Name:  project.PathWithNamespace,
-				Error: err,
+				Name:  project.FullName,
+				Error: projectResult.Error,
 			})
-			h.Logger.Error(fmt.Sprintf("Failed to process %s: %v", project.PathWithNamespace, err))
+			h.Logger.Error(fmt.Sprintf("Failed to process %s: %v", project.FullName, projectResult.Error))
 		} else {
 			result.SuccessCount++
+			if projectResult.Unchanged {
+				result.UnchangedCount++
+			}
 		}
+		result.Projects = append(result.Projects, projectResult)
 		result.ProcessedCount++
 	}
 
 	// Log summary
-	h.Logger.Info(fmt.Sprintf("Cronjob complete: %d processed, %d succeeded, %d failed, %d skipped",
-		result.ProcessedCount, result.SuccessCount, result.ErrorCount,
-		len(projects)-len(applicableProjects)))
+	h.Logger.Info(fmt.Sprintf("Cronjob complete: %d processed, %d succeeded, %d failed, %d skipped, %d unchanged",
+		result.ProcessedCount, result.SuccessCount, result.ErrorCount, result.SkippedCount, result.UnchangedCount))
+
+	report := buildCronjobReport(result, h.forge.Name(), group, startedAt, time.Now())
+	if jsonPath, junitPath, err := writeCronjobReport(h.config.WorkingPath, report); err != nil {
+		h.Logger.Error(fmt.Sprintf("Failed to write cronjob report: %v", err))
+	} else {
+		h.Logger.Info("Cronjob report written",
+			logging.String("json", jsonPath),
+			logging.String("junit", junitPath),
+		)
+	}
 
 	if result.ErrorCount > 0 && result.SuccessCount == 0 {
-		return errors.NewCronjobError("all projects failed", fmt.Errorf("%d failures", result.ErrorCount))
+		return result, errors.NewCronjobError("all projects failed", fmt.Errorf("%d failures", result.ErrorCount))
 	}
 
-	return nil
+	return result, nil
+}
+
+// newProjectTask wraps processProject with a per-project logger and timeout.
+// Panics are recovered so one project cannot take down the whole run.
+func (h *CronjobHandler) newProjectTask(project forge.Project) worker_pool.Task {
+	return func(ctx context.Context) (value interface{}, err error) {
+		logger := h.Logger.Named(project.FullName).With(
+			logging.String("project", project.FullName),
+			logging.Int("project_id", project.ID),
+		)
+		logger.Info(fmt.Sprintf("Processing %s", project.FullName))
+
+		projectCtx, cancel := context.WithTimeout(ctx, h.config.GetProjectTimeout())
+		defer cancel()
+
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic while processing %s: %v", project.FullName, r)
+				value = ProjectResult{Name: project.FullName, Duration: time.Since(start), Error: err}
+			}
+		}()
+
+		result := h.processProject(projectCtx, project, logger)
+		result.Duration = time.Since(start)
+		if result.Error != nil && projectCtx.Err() == context.DeadlineExceeded {
+			result.Error = fmt.Errorf("project timed out after %s (%w): %w", h.config.GetProjectTimeout(), context.DeadlineExceeded, result.Error)
+		}
+		return result, result.Error
+	}
 }
 
 // processProject processes a single project
-func (h *CronjobHandler) processProject(ctx context.Context, project gitlab.Project) error {
+func (h *CronjobHandler) processProject(ctx context.Context, project forge.Project, logger *logging.Logger) ProjectResult {
+	result := ProjectResult{Name: project.FullName}
+
 	// Create temp directory for cloning
 	tempDir := filepath.Join(h.config.WorkingPath, "tmp", fmt.Sprintf("project_%d", project.ID))
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp dir: %w", err)
+	if err := os.MkdirAll(filepath.Dir(tempDir), 0755); err != nil {
+		result.Error = fmt.Errorf("failed to create temp dir: %w", err)
+		return result
 	}
 	defer func() { _ = os.RemoveAll(tempDir) }()
 
 	// Clone repository
-	if err := h.cloneRepository(ctx, project, tempDir); err != nil {
-		return errors.NewGitCloneError(project.HTTPURL, "clone failed", err)
+	if err := h.cloneRepository(ctx, project, tempDir, logger); err != nil {
+		result.Error = errors.NewGitCloneError(project.CloneURL, "clone failed", err)
+		return result
 	}
 
-	// Create branch
-	branchName := fmt.Sprintf("ai-analyzer-%s", time.Now().Format("2006-01-02"))
-	if err := h.gitlab.CreateBranch(ctx, project, branchName, project.DefaultBranch); err != nil {
-		return fmt.Errorf("failed to create branch: %w", err)
+	// Run analysis in-process
+	analysis, err := h.runAnalysis(ctx, project, tempDir, logger)
+	result.Analysis = analysis
+	if err != nil {
+		result.Error = fmt.Errorf("analysis failed: %w", err)
+		return result
 	}
 
-	// Run analysis
-	analyzerCfg := h.analyzerCfg
-	analyzerCfg.RepoPath = tempDir
+	// The project's custom agents name their analyses in the MR description
+	registry, err := agents.LoadRegistry(tempDir)
+	if err != nil {
+		registry = agents.DefaultRegistry()
+	}
+
+	// Collect results
+	files, err := collectGeneratedFiles(ctx, tempDir)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to collect generated files: %w", err)
+		return result
+	}
+	if len(files) == 0 {
+		logger.Info(fmt.Sprintf("Documentation for %s is unchanged, nothing to push", project.FullName))
+		result.Unchanged = true
+		return result
+	}
 
-	// Run analyze command (via subprocess for now, could be refactored to use handler directly)
-	if err := h.runAnalysis(ctx, tempDir); err != nil {
-		return fmt.Errorf("analysis failed: %w", err)
+	now := time.Now()
+	if h.config.GetMRPolicy() == mrPolicyUpdate {
+		mr, changed, err := h.updateExistingMR(ctx, project, files, analysis, registry, now, logger)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		result.Unchanged = !changed
+		if mr != nil {
+			result.MRURL = mr.WebURL
+		}
+		return result
+	}
+
+	// Create branch
+	date := now.Format("2006-01-02")
+	branchName := analyzerBranchName(now)
+	if err := h.forge.CreateBranch(ctx, project, branchName, project.DefaultBranch); err != nil {
+		result.Error = fmt.Errorf("failed to create branch: %w", err)
+		return result
 	}
 
 	// Commit results
-	commitMsg := fmt.Sprintf("[skip ci] AI analysis: %s", time.Now().Format("2006-01-02"))
-	if err := h.gitlab.CreateCommit(ctx, project, branchName, commitMsg, nil); err != nil {
-		return fmt.Errorf("failed to create commit: %w", err)
+	commitMsg := fmt.Sprintf("[skip ci] AI analysis: %s", date)
+	if err := h.forge.CommitFiles(ctx, project, branchName, commitMsg, files); err != nil {
+		result.Error = fmt.Errorf("failed to create commit: %w", err)
+		return result
 	}
 
 	// Create merge request
-	mrTitle := fmt.Sprintf("AI Analysis: %s", time.Now().Format("2006-01-02"))
-	mrDescription := fmt.Sprintf("Automated AI analysis generated on %s\n\nThis MR contains:\n- Structure analysis\n- Dependency analysis\n- Data flow analysis\n- Request flow analysis\n- API analysis", time.Now().Format("2006-01-02"))
-	mr, err := h.gitlab.CreateMR(ctx, project, branchName, project.DefaultBranch, mrTitle, mrDescription)
+	mrTitle := fmt.Sprintf("AI Analysis: %s", date)
+	mr, err := h.forge.CreatePR(ctx, project, branchName, project.DefaultBranch, mrTitle, buildMRDescription(date, analysis, registry))
 	if err != nil {
-		return fmt.Errorf("failed to create MR: %w", err)
+		result.Error = fmt.Errorf("failed to create pull request: %w", err)
+		return result
 	}
+	result.MRURL = mr.WebURL
 
-	h.Logger.Info(fmt.Sprintf("Created MR %d for %s", mr.IID, project.PathWithNamespace))
-	return nil
+	logger.Info(fmt.Sprintf("Created pull request #%d for %s", mr.Number, project.FullName))
+	return result
 }
 
-// cloneRepository clones a GitLab repository
-func (h *CronjobHandler) cloneRepository(ctx context.Context, project gitlab.Project, destDir string) error {
-	// Clone with authentication
-	url := project.HTTPURL
-	if h.gitlab.OAuthToken != "" {
-		// Inject token into URL
-		url = fmt.Sprintf("https://oauth2:%s@%s", h.gitlab.OAuthToken, project.HTTPURL[8:]) // Strip https://
+// updateExistingMR implements the update policy: regenerated files are pushed onto
+// the stable documentation branch, and the open MR (created on first use) gets a
+// changelog note. Nothing is pushed when every file is byte-identical to the branch;
+// changed reports whether a commit was made.
+func (h *CronjobHandler) updateExistingMR(ctx context.Context, project forge.Project, files map[string]string, analysis *agents.AnalysisResult, registry *agents.Registry, now time.Time, logger *logging.Logger) (*forge.PullRequest, bool, error) {
+	branchName := h.config.GetBranchName()
+	date := now.Format("2006-01-02")
+
+	branchExists, err := h.forge.BranchExists(ctx, project, branchName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check branch: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", url, destDir)
-	output, err := cmd.CombinedOutput()
+	changed := files
+	if branchExists {
+		changed, err = h.changedFiles(ctx, project, branchName, files)
+		if err != nil {
+			return nil, false, err
+		}
+	} else if err := h.forge.CreateBranch(ctx, project, branchName, project.DefaultBranch); err != nil {
+		return nil, false, fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	mr, err := h.forge.FindOpenPR(ctx, project, branchName)
 	if err != nil {
-		h.Logger.Info(fmt.Sprintf("Git clone output: %s", string(output)))
-		return err
+		return nil, false, fmt.Errorf("failed to look up open pull request: %w", err)
 	}
 
-	return nil
+	if len(changed) == 0 {
+		logger.Info(fmt.Sprintf("Documentation for %s is unchanged, nothing to push", project.FullName))
+		return mr, false, nil
+	}
+
+	commitMsg := fmt.Sprintf("[skip ci] AI analysis update: %s", date)
+	if err := h.forge.CommitFiles(ctx, project, branchName, commitMsg, changed); err != nil {
+		return nil, false, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if mr == nil {
+		mr, err = h.forge.CreatePR(ctx, project, branchName, project.DefaultBranch, "AI Analysis", buildMRDescription(date, analysis, registry))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create pull request: %w", err)
+		}
+		logger.Info(fmt.Sprintf("Created pull request #%d for %s", mr.Number, project.FullName))
+		return mr, true, nil
+	}
+
+	description := mr.Description + buildChangelogNote(now, changed)
+	if err := h.forge.UpdatePRDescription(ctx, project, mr.Number, description); err != nil {
+		return nil, false, fmt.Errorf("failed to update pull request: %w", err)
+	}
+	mr.Description = description
+
+	logger.Info(fmt.Sprintf("Updated pull request #%d for %s (%d files)", mr.Number, project.FullName, len(changed)))
+	return mr, true, nil
 }
 
-// runAnalysis runs the analysis on a repository
-func (h *CronjobHandler) runAnalysis(ctx context.Context, repoPath string) error {
-	// Run gendocs analyze command as subprocess
-	cmd := exec.CommandContext(ctx, "./gendocs", "analyze", "--repo-path", repoPath)
-	cmd.Dir = filepath.Dir(repoPath) // Run from parent directory to find binary
+// changedFiles returns the subset of files whose content differs from the branch
+func (h *CronjobHandler) changedFiles(ctx context.Context, project forge.Project, branchName string, files map[string]string) (map[string]string, error) {
+	changed := make(map[string]string)
+	for path, content := range files {
+		current, exists, err := h.forge.GetFileContent(ctx, project, branchName, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from %s: %w", path, branchName, err)
+		}
+		if !exists || current != content {
+			changed[path] = content
+		}
+	}
+	return changed, nil
+}
+
+// buildChangelogNote renders the note appended to an existing MR description on update
+func buildChangelogNote(now time.Time, changed map[string]string) string {
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n---\n**Updated %s**: regenerated %d file(s)\n", now.Format("2006-01-02 15:04 MST"), len(paths)))
+	for _, path := range paths {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", path))
+	}
+	return sb.String()
+}
+
+// buildMRDescription renders the merge request body from the analysis result,
+// naming the analyses as the registry of the project does
+func buildMRDescription(date string, analysis *agents.AnalysisResult, registry *agents.Registry) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Automated AI analysis generated on %s\n", date))
+
+	if analysis == nil {
+		return sb.String()
+	}
+
+	if len(analysis.Successful) > 0 {
+		sb.WriteString("\nThis update contains:\n")
+		for _, name := range analysis.Successful {
+			sb.WriteString(fmt.Sprintf("- %s\n", analysisDisplayName(registry, name)))
+		}
+	}
+
+	if len(analysis.Failed) > 0 {
+		sb.WriteString("\nThe following analyses failed and were not updated:\n")
+		for _, failed := range analysis.Failed {
+			sb.WriteString(fmt.Sprintf("- %s: %v\n", analysisDisplayName(registry, failed.Name), failed.Error))
+		}
+	}
+
+	return sb.String()
+}
+
+// analysisDisplayName returns the display name of an analysis such as
+// "data_flow" or "services/api/license" from the registry, keeping the module
+// prefix of monorepo analyses. Names missing from the registry (the
+// architecture overview) are spelled out from their snake_case form.
+func analysisDisplayName(registry *agents.Registry, name string) string {
+	prefix, analysis := "", name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		prefix, analysis = name[:i+1], name[i+1:]
+	}
+	if spec, ok := registry.ForAnalysis(analysis); ok && spec.DisplayName != "" {
+		return prefix + spec.DisplayName
+	}
+
+	words := strings.ReplaceAll(strings.TrimSuffix(analysis, "_analyzer"), "_", " ")
+	if words == "" {
+		return name
+	}
+	return prefix + strings.ToUpper(words[:1]) + words[1:]
+}
 
+// cloneRepository clones a project repository
+func (h *CronjobHandler) cloneRepository(ctx context.Context, project forge.Project, destDir string, logger *logging.Logger) error {
+	// Clone with authentication
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", h.forge.AuthenticatedCloneURL(project), destDir)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		h.Logger.Info(fmt.Sprintf("Analysis output: %s", string(output)))
+		logger.Info(fmt.Sprintf("Git clone output: %s", string(output)))
 		return err
 	}
 
-	h.Logger.Info(fmt.Sprintf("Analysis output: %s", string(output)))
 	return nil
 }
+
+// analyzerBranchName returns the documentation branch name for a given day
+func analyzerBranchName(t time.Time) string {
+	return fmt.Sprintf("ai-analyzer-%s", t.Format("2006-01-02"))
+}
+
+// collectGeneratedFiles reads the documentation the run wrote: .ai/docs/*.md,
+// README.md and CLAUDE.md that differ from the cloned commit or are new. Files
+// left untouched by the run are not included. Keys are repository-relative paths.
+func collectGeneratedFiles(ctx context.Context, repoPath string) (map[string]string, error) {
+	// Ignored files are listed too: a fresh clone has none, so the run wrote them
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all", "--ignored=traditional",
+		"--", ".ai/docs", "README.md", "CLAUDE.md")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Entries are "XY path"; the run never stages, so there are no renames
+		if len(entry) < 4 || strings.Contains(entry[:2], "D") {
+			continue
+		}
+		rel := entry[3:]
+		if rel != "README.md" && rel != "CLAUDE.md" && (path.Dir(rel) != ".ai/docs" || path.Ext(rel) != ".md") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		files[rel] = string(content)
+	}
+
+	return files, nil
+}
+
+// runAnalysis runs the analyzer in-process on a cloned repository.
+// Each project gets its own LLM cache file so concurrent runs never share state.
+func (h *CronjobHandler) runAnalysis(ctx context.Context, project forge.Project, repoPath string, logger *logging.Logger) (*agents.AnalysisResult, error) {
+	analyzerCfg := h.analyzerCfg
+	analyzerCfg.RepoPath = repoPath
+	analyzerCfg.LLM.Cache.CachePath = h.projectCachePath(project)
+
+	handler := NewAnalyzeHandler(analyzerCfg, logger)
+	result, err := handler.Run(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if len(result.Failed) > 0 {
+		logger.Warn(fmt.Sprintf("Partial analysis for %s: %d/%d agents failed",
+			project.FullName, len(result.Failed), len(result.Successful)+len(result.Failed)))
+	}
+
+	return result, nil
+}
+
+// projectCachePath returns the LLM cache file for a project. It lives outside
+// the temporary clone so cached responses survive between cronjob runs.
+func (h *CronjobHandler) projectCachePath(project forge.Project) string {
+	return filepath.Join(h.config.WorkingPath, "cache", fmt.Sprintf("project_%d", project.ID), "llm_cache.json")
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/llm"
+)
+
+// Report file names, written to the cronjob working path
+const (
+	CronjobReportJSONFile  = "cronjob-report.json"
+	CronjobReportJUnitFile = "cronjob-report.xml"
+)
+
+// Project statuses in the cronjob report
+const (
+	ProjectStatusSuccess   = "success"
+	ProjectStatusUnchanged = "unchanged"
+	ProjectStatusFailed    = "failed"
+	ProjectStatusSkipped   = "skipped"
+)
+
+// CronjobReport is the machine-readable summary of a cronjob run
+type CronjobReport struct {
+	Forge           string          `json:"forge"`
+	Group           string          `json:"group"`
+	StartedAt       time.Time       `json:"started_at"`
+	FinishedAt      time.Time       `json:"finished_at"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	Processed       int             `json:"processed"`
+	Succeeded       int             `json:"succeeded"`
+	Failed          int             `json:"failed"`
+	Skipped         int             `json:"skipped"`
+	Unchanged       int             `json:"unchanged"`
+	TokenUsage      TokenUsage      `json:"token_usage"`
+	Projects        []ProjectReport `json:"projects"`
+}
+
+// ProjectReport is the per-project entry of a CronjobReport
+type ProjectReport struct {
+	Name            string                `json:"name"`
+	Status          string                `json:"status"`
+	DurationSeconds float64               `json:"duration_seconds"`
+	AgentsRun       []string              `json:"agents_run"`
+	AgentsFailed    []string              `json:"agents_failed"`
+	AgentsSkipped   []string              `json:"agents_skipped"`
+	TokenUsage      TokenUsage            `json:"token_usage"`
+	AgentTokenUsage map[string]TokenUsage `json:"agent_token_usage,omitempty"`
+	MRURL           string                `json:"mr_url,omitempty"`
+	Error           string                `json:"error,omitempty"`
+	ErrorCategory   string                `json:"error_category,omitempty"`
+}
+
+// TokenUsage is the JSON form of llm.TokenUsage
+type TokenUsage struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+	Total  int `json:"total"`
+}
+
+func newTokenUsage(u llm.TokenUsage) TokenUsage {
+	return TokenUsage{Input: u.InputTokens, Output: u.OutputTokens, Total: u.TotalTokens}
+}
+
+func (u *TokenUsage) add(other TokenUsage) {
+	u.Input += other.Input
+	u.Output += other.Output
+	u.Total += other.Total
+}
+
+// buildCronjobReport converts the processed results into a report
+func buildCronjobReport(result *ProcessedResult, forgeName, group string, startedAt, finishedAt time.Time) *CronjobReport {
+	report := &CronjobReport{
+		Forge:           forgeName,
+		Group:           group,
+		StartedAt:       startedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+		Processed:       result.ProcessedCount,
+		Succeeded:       result.SuccessCount,
+		Failed:          result.ErrorCount,
+		Skipped:         result.SkippedCount,
+		Unchanged:       result.UnchangedCount,
+		Projects:        []ProjectReport{},
+	}
+
+	for _, project := range result.Projects {
+		entry := newProjectReport(project)
+		report.TokenUsage.add(entry.TokenUsage)
+		report.Projects = append(report.Projects, entry)
+	}
+	for _, name := range result.SkippedProjects {
+		report.Projects = append(report.Projects, ProjectReport{
+			Name:          name,
+			Status:        ProjectStatusSkipped,
+			AgentsRun:     []string{},
+			AgentsFailed:  []string{},
+			AgentsSkipped: []string{},
+		})
+	}
+
+	return report
+}
+
+func newProjectReport(project ProjectResult) ProjectReport {
+	entry := ProjectReport{
+		Name:            project.Name,
+		Status:          ProjectStatusSuccess,
+		DurationSeconds: project.Duration.Seconds(),
+		AgentsRun:       []string{},
+		AgentsFailed:    []string{},
+		AgentsSkipped:   []string{},
+		MRURL:           project.MRURL,
+	}
+
+	if project.Unchanged {
+		entry.Status = ProjectStatusUnchanged
+	}
+	if project.Error != nil {
+		entry.Status = ProjectStatusFailed
+		entry.Error = project.Error.Error()
+		entry.ErrorCategory = errors.Category(project.Error)
+	}
+
+	if project.Analysis != nil {
+		entry.AgentsRun = ranAnalyses(project.Analysis)
+		for _, failed := range project.Analysis.Failed {
+			entry.AgentsFailed = append(entry.AgentsFailed, failed.Name)
+		}
+		entry.AgentsSkipped = append(entry.AgentsSkipped, project.Analysis.Skipped...)
+
+		if len(project.Analysis.Usage) > 0 {
+			entry.AgentTokenUsage = make(map[string]TokenUsage, len(project.Analysis.Usage))
+			for name, usage := range project.Analysis.Usage {
+				entry.AgentTokenUsage[name] = newTokenUsage(usage)
+			}
+		}
+		entry.TokenUsage = newTokenUsage(project.Analysis.TotalUsage())
+	}
+
+	return entry
+}
+
+// ranAnalyses returns the analyses that actually ran and succeeded. Cached
+// results are reported as successful by the analyzer but are not runs.
+func ranAnalyses(analysis *agents.AnalysisResult) []string {
+	skipped := make(map[string]bool, len(analysis.Skipped))
+	for _, name := range analysis.Skipped {
+		skipped[name] = true
+	}
+
+	ran := []string{}
+	for _, name := range analysis.Successful {
+		if skipped[strings.TrimSuffix(name, "_analyzer")] {
+			continue
+		}
+		ran = append(ran, name)
+	}
+	return ran
+}
+
+// writeCronjobReport writes the JSON and JUnit XML reports to dir
+func writeCronjobReport(dir string, report *CronjobReport) (string, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+	jsonPath := filepath.Join(dir, CronjobReportJSONFile)
+	if err := os.WriteFile(jsonPath, append(jsonData, '\n'), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	junitData, err := xml.MarshalIndent(newJUnitReport(report), "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	junitPath := filepath.Join(dir, CronjobReportJUnitFile)
+	if err := os.WriteFile(junitPath, append([]byte(xml.Header), append(junitData, '\n')...), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return jsonPath, junitPath, nil
+}
+
+// JUnit XML schema subset understood by CI servers and schedulers
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// newJUnitReport maps each project to a test case: failed projects are failures
+// typed by error category, filtered projects are skipped
+func newJUnitReport(report *CronjobReport) *junitTestSuites {
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("gendocs cronjob %s/%s", report.Forge, report.Group),
+		Tests:     len(report.Projects),
+		Failures:  report.Failed,
+		Skipped:   report.Skipped,
+		Time:      formatSeconds(report.DurationSeconds),
+		Timestamp: report.StartedAt.UTC().Format(time.RFC3339),
+		Properties: []junitProperty{
+			{Name: "forge", Value: report.Forge},
+			{Name: "group", Value: report.Group},
+			{Name: "input_tokens", Value: fmt.Sprint(report.TokenUsage.Input)},
+			{Name: "output_tokens", Value: fmt.Sprint(report.TokenUsage.Output)},
+			{Name: "total_tokens", Value: fmt.Sprint(report.TokenUsage.Total)},
+		},
+	}
+
+	projects := append([]ProjectReport(nil), report.Projects...)
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+
+	for _, project := range projects {
+		testCase := junitTestCase{
+			Name:      project.Name,
+			ClassName: fmt.Sprintf("gendocs.%s", report.Forge),
+			Time:      formatSeconds(project.DurationSeconds),
+		}
+		switch project.Status {
+		case ProjectStatusFailed:
+			testCase.Failure = &junitFailure{
+				Message: project.Error,
+				Type:    project.ErrorCategory,
+				Text:    project.Error,
+			}
+		case ProjectStatusSkipped:
+			testCase.Skipped = &junitSkipped{Message: "filtered out by cronjob settings"}
+		}
+		if project.Status != ProjectStatusSkipped {
+			testCase.SystemOut = projectSummary(project)
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	return &junitTestSuites{
+		Name:     "gendocs-cronjob",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+}
+
+// projectSummary renders the details of a project for the JUnit system-out element
+func projectSummary(project ProjectReport) string {
+	lines := []string{
+		fmt.Sprintf("status: %s", project.Status),
+		fmt.Sprintf("agents run: %s", strings.Join(project.AgentsRun, ", ")),
+		fmt.Sprintf("agents skipped: %s", strings.Join(project.AgentsSkipped, ", ")),
+		fmt.Sprintf("tokens: %d input, %d output, %d total", project.TokenUsage.Input, project.TokenUsage.Output, project.TokenUsage.Total),
+	}
+	if len(project.AgentsFailed) > 0 {
+		lines = append(lines, fmt.Sprintf("agents failed: %s", strings.Join(project.AgentsFailed, ", ")))
+	}
+	if project.MRURL != "" {
+		lines = append(lines, fmt.Sprintf("mr: %s", project.MRURL))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
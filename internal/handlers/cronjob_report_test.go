@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/llm"
+)
+
+func TestBuildCronjobReport(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	result := &ProcessedResult{
+		ProcessedCount: 2,
+		SuccessCount:   1,
+		ErrorCount:     1,
+		SkippedCount:   1,
+		Projects: []ProjectResult{
+			{
+				Name:     "group/app",
+				MRURL:    "https://gitlab.example.com/group/app/-/merge_requests/1",
+				Duration: 90 * time.Second,
+				Analysis: &agents.AnalysisResult{
+					Successful: []string{"structure_analyzer", "api_analyzer"},
+					Failed:     []agents.FailedAnalysis{{Name: "data_flow_analyzer"}},
+					Skipped:    []string{"api"},
+					Usage: map[string]llm.TokenUsage{
+						"structure": {InputTokens: 100, OutputTokens: 20, TotalTokens: 120},
+						"data_flow": {InputTokens: 50, OutputTokens: 5, TotalTokens: 55},
+					},
+				},
+			},
+			{
+				Name:     "group/broken",
+				Duration: time.Minute,
+				Error:    fmt.Errorf("project timed out after 1m0s (%w)", context.DeadlineExceeded),
+			},
+		},
+		SkippedProjects: []string{"group/old"},
+	}
+
+	report := buildCronjobReport(result, "gitlab", "platform", startedAt, startedAt.Add(3*time.Minute))
+
+	if report.DurationSeconds != 180 || report.Processed != 2 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if report.TokenUsage != (TokenUsage{Input: 150, Output: 25, Total: 175}) {
+		t.Errorf("unexpected run token usage: %+v", report.TokenUsage)
+	}
+	if len(report.Projects) != 3 {
+		t.Fatalf("expected 3 projects, got %d", len(report.Projects))
+	}
+
+	app := report.Projects[0]
+	if app.Status != ProjectStatusSuccess || app.DurationSeconds != 90 || app.MRURL == "" {
+		t.Errorf("unexpected app entry: %+v", app)
+	}
+	if len(app.AgentsRun) != 1 || app.AgentsRun[0] != "structure_analyzer" {
+		t.Errorf("expected skipped analyses to be excluded from agents run, got %v", app.AgentsRun)
+	}
+	if len(app.AgentsFailed) != 1 || len(app.AgentsSkipped) != 1 || app.AgentTokenUsage["structure"].Total != 120 {
+		t.Errorf("unexpected agent details: %+v", app)
+	}
+
+	broken := report.Projects[1]
+	if broken.Status != ProjectStatusFailed || broken.ErrorCategory != errors.CategoryTimeout {
+		t.Errorf("expected timeout failure, got %+v", broken)
+	}
+	if old := report.Projects[2]; old.Name != "group/old" || old.Status != ProjectStatusSkipped {
+		t.Errorf("expected skipped project entry, got %+v", old)
+	}
+}
+
+func TestRanAnalyses(t *testing.T) {
+	analysis := &agents.AnalysisResult{
+		Successful: []string{"structure", "services/api/license check"},
+		Skipped:    []string{"api"},
+	}
+	if ran := ranAnalyses(analysis); len(ran) != 2 {
+		t.Errorf("expected every analysis that ran to be reported, got %v", ran)
+	}
+
+	if ran := ranAnalyses(&agents.AnalysisResult{Successful: []string{}, Skipped: []string{"structure"}, Unchanged: true}); len(ran) != 0 {
+		t.Errorf("expected no analyses for an unchanged repository, got %v", ran)
+	}
+}
+
+func TestWriteCronjobReport(t *testing.T) {
+	dir := t.TempDir()
+	report := &CronjobReport{
+		Forge:   "gitlab",
+		Group:   "platform",
+		Failed:  1,
+		Skipped: 1,
+		Projects: []ProjectReport{
+			{Name: "group/b", Status: ProjectStatusFailed, Error: "clone failed", ErrorCategory: errors.CategoryGitClone},
+			{Name: "group/a", Status: ProjectStatusSuccess, AgentsRun: []string{"structure_analyzer"}, MRURL: "https://example.com/mr/1"},
+			{Name: "group/c", Status: ProjectStatusSkipped},
+		},
+	}
+
+	jsonPath, junitPath, err := writeCronjobReport(dir, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jsonData, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read JSON report: %v", err)
+	}
+	var decoded CronjobReport
+	if err := json.Unmarshal(jsonData, &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(decoded.Projects) != 3 || decoded.Projects[0].ErrorCategory != errors.CategoryGitClone {
+		t.Errorf("unexpected decoded report: %+v", decoded)
+	}
+
+	junitData, err := os.ReadFile(junitPath)
+	if err != nil {
+		t.Fatalf("failed to read JUnit report: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(junitData, &suites); err != nil {
+		t.Fatalf("invalid JUnit report: %v", err)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("unexpected suite totals: %+v", suites)
+	}
+
+	cases := suites.Suites[0].TestCases
+	if cases[0].Name != "group/a" || cases[0].Failure != nil || cases[0].SystemOut == "" {
+		t.Errorf("expected successful test case for group/a, got %+v", cases[0])
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Type != errors.CategoryGitClone {
+		t.Errorf("expected git_clone failure for group/b, got %+v", cases[1])
+	}
+	if cases[2].Skipped == nil {
+		t.Errorf("expected group/c to be skipped, got %+v", cases[2])
+	}
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/forge"
+	"github.com/user/gendocs/internal/logging"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+func TestCollectGeneratedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// CreateTempRepo commits the files, standing in for the cloned repository
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go":                         "package main",
+		"README.md":                       "# Readme",
+		"CLAUDE.md":                       "# Rules",
+		".ai/docs/structure_analysis.md":  "# Structure",
+		".ai/docs/dependency_analysis.md": "# Dependencies",
+	})
+
+	// The run rewrites one document, adds another and leaves the rest alone
+	written := map[string]string{
+		".ai/docs/structure_analysis.md": "# Structure\n\nUpdated",
+		".ai/docs/api_analysis.md":       "# API",
+		".ai/docs/notes.txt":             "not markdown",
+		".ai/analysis_cache.json":        "{}",
+		"docs/unrelated.md":              "# Unrelated",
+	}
+	for path, content := range written {
+		fullPath := filepath.Join(repoPath, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Rewriting a file with identical content is not a change
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := collectGeneratedFiles(context.Background(), repoPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		".ai/docs/structure_analysis.md",
+		".ai/docs/api_analysis.md",
+	}
+	if len(files) != len(expected) {
+		t.Errorf("expected %d files, got %d: %v", len(expected), len(files), files)
+	}
+	for _, path := range expected {
+		if files[path] != written[path] {
+			t.Errorf("expected %s to be collected with its new content, got %q", path, files[path])
+		}
+	}
+}
+
+func TestCollectGeneratedFiles_IgnoredDocs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go":    "package main",
+		".gitignore": ".ai/\n",
+	})
+	docPath := filepath.Join(repoPath, ".ai", "docs", "structure_analysis.md")
+	_ = os.MkdirAll(filepath.Dir(docPath), 0755)
+	_ = os.WriteFile(docPath, []byte("# Structure"), 0644)
+
+	files, err := collectGeneratedFiles(context.Background(), repoPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[".ai/docs/structure_analysis.md"] != "# Structure" {
+		t.Errorf("expected the ignored document to be collected, got %v", files)
+	}
+}
+
+func TestCollectGeneratedFiles_Unchanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repoPath := testHelpers.CreateTempRepo(t, map[string]string{
+		"main.go":                        "package main",
+		"README.md":                      "# Readme",
+		".ai/docs/structure_analysis.md": "# Structure",
+	})
+
+	files, err := collectGeneratedFiles(context.Background(), repoPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files, got %v", files)
+	}
+}
+
+// newTestCronjobHandler wires a CronjobHandler to a fake GitLab and a mock LLM server.
+// It must be called after t.Chdir so the system prompts resolve.
+func newTestCronjobHandler(t *testing.T, fake *testHelpers.FakeGitLab, concurrency int) *CronjobHandler {
+	t.Helper()
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Analysis\\n\\nGenerated content"))
+	t.Cleanup(llmServer.Close)
+
+	cronjobCfg := config.CronjobConfig{
+		MaxDaysSinceLastCommit: 14,
+		WorkingPath:            t.TempDir(),
+		GroupProjectID:         10,
+		Concurrency:            concurrency,
+	}
+	gitLab := forge.NewGitLab(config.GitLabConfig{
+		APIURL:     fake.URL(),
+		OAuthToken: "secret",
+		UserName:   "AI Analyzer",
+	}, logging.NewNopLogger())
+	analyzerCfg := config.AnalyzerConfig{
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers: 1,
+	}
+
+	return NewCronjobHandler(cronjobCfg, gitLab, analyzerCfg, logging.NewNopLogger())
+}
+
+func TestCronjobHandler_Handle_EndToEnd(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	// CreateTempRepo commits the files, so the path can be cloned as a remote
+	remote := testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())
+
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	fake.AddProject(10, testHelpers.FakeGitLabProject{
+		ID:                1,
+		Name:              "app",
+		PathWithNamespace: "group/app",
+		HTTPURL:           remote,
+		LastActivityAt:    time.Now(),
+	})
+	fake.AddProject(10, testHelpers.FakeGitLabProject{
+		ID:                2,
+		Name:              "old",
+		PathWithNamespace: "group/old",
+		HTTPURL:           remote,
+		LastActivityAt:    time.Now().AddDate(0, -6, 0),
+	})
+
+	handler := newTestCronjobHandler(t, fake, 1)
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ProcessedCount != 1 || result.SuccessCount != 1 || result.SkippedCount != 1 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+
+	project := result.Projects[0]
+	if project.Analysis == nil || len(project.Analysis.Successful) != 8 {
+		t.Fatalf("expected 8 successful analyses, got %+v", project.Analysis)
+	}
+	if usage := project.Analysis.UsageSummary; usage == nil || usage.Calls < 8 || len(usage.ByAgent) != 8 {
+		t.Errorf("expected usage of every agent to be recorded, got %+v", usage)
+	}
+	if project.MRURL == "" {
+		t.Error("expected MR URL to be recorded")
+	}
+
+	commits := fake.Commits()
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit, got %d", len(commits))
+	}
+	paths := make(map[string]bool)
+	for _, action := range commits[0].Actions {
+		paths[action.FilePath] = true
+	}
+	if !paths[".ai/docs/structure_analysis.md"] || !paths[".ai/docs/api_analysis.md"] {
+		t.Errorf("expected analysis docs in commit, got %v", paths)
+	}
+
+	mrs := fake.MergeRequests(1)
+	if len(mrs) != 1 {
+		t.Fatalf("expected 1 MR, got %d", len(mrs))
+	}
+	if !strings.Contains(mrs[0].Description, "Structure Analysis") {
+		t.Errorf("expected MR description to list analyses, got %q", mrs[0].Description)
+	}
+
+	data, err := os.ReadFile(filepath.Join(handler.config.WorkingPath, CronjobReportJSONFile))
+	if err != nil {
+		t.Fatalf("expected JSON report in working path: %v", err)
+	}
+	var report CronjobReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(report.Projects) != 2 || report.Projects[0].Status != ProjectStatusSuccess || len(report.Projects[0].AgentsRun) != 8 {
+		t.Errorf("unexpected report projects: %+v", report.Projects)
+	}
+	if report.Projects[1].Name != "group/old" || report.Projects[1].Status != ProjectStatusSkipped {
+		t.Errorf("expected group/old to be reported as skipped, got %+v", report.Projects[1])
+	}
+	if _, err := os.Stat(filepath.Join(handler.config.WorkingPath, CronjobReportJUnitFile)); err != nil {
+		t.Errorf("expected JUnit report in working path: %v", err)
+	}
+}
+
+func TestCronjobHandler_Handle_ParallelIsolatesFailures(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Chdir(filepath.Join("..", ".."))
+
+	remote := testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())
+
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	for i := 1; i <= 4; i++ {
+		url := remote
+		if i == 2 {
+			url = filepath.Join(t.TempDir(), "does-not-exist")
+		}
+		fake.AddProject(10, testHelpers.FakeGitLabProject{
+			ID:                i,
+			PathWithNamespace: fmt.Sprintf("group/app-%d", i),
+			HTTPURL:           url,
+			LastActivityAt:    time.Now(),
+		})
+	}
+
+	handler := newTestCronjobHandler(t, fake, 3)
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ProcessedCount != 4 || result.SuccessCount != 3 || result.ErrorCount != 1 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	if len(result.FailedProjects) != 1 || result.FailedProjects[0].Name != "group/app-2" {
+		t.Errorf("expected group/app-2 to fail, got %+v", result.FailedProjects)
+	}
+
+	// Results keep the project order regardless of completion order
+	for i, project := range result.Projects {
+		if expected := fmt.Sprintf("group/app-%d", i+1); project.Name != expected {
+			t.Errorf("expected project %d to be %s, got %s", i, expected, project.Name)
+		}
+	}
+
+	for _, id := range []int{1, 3, 4} {
+		if len(fake.MergeRequests(id)) != 1 {
+			t.Errorf("expected an MR for project %d", id)
+		}
+	}
+}
+
+func TestCronjobHandler_Handle_UpdatePolicy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Chdir(filepath.Join("..", ".."))
+
+	remote := testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())
+
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	fake.AddProject(10, testHelpers.FakeGitLabProject{
+		ID:                1,
+		PathWithNamespace: "group/app",
+		HTTPURL:           remote,
+		LastActivityAt:    time.Now(),
+	})
+
+	handler := newTestCronjobHandler(t, fake, 1)
+	handler.config.MRPolicy = "update"
+	ctx := context.Background()
+
+	// First run creates the stable branch and the MR
+	result, err := handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+	if result.SuccessCount != 1 || result.UnchangedCount != 0 {
+		t.Fatalf("unexpected counts on first run: %+v", result)
+	}
+	if !fake.HasBranch(1, "ai-analyzer") {
+		t.Fatal("expected stable branch ai-analyzer")
+	}
+	mrURL := result.Projects[0].MRURL
+
+	// Second run regenerates identical content: nothing is pushed
+	result, err = handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if result.SuccessCount != 1 || result.UnchangedCount != 1 || !result.Projects[0].Unchanged {
+		t.Fatalf("expected unchanged project on second run: %+v", result)
+	}
+	if result.Projects[0].MRURL != mrURL {
+		t.Errorf("expected existing MR URL %s, got %s", mrURL, result.Projects[0].MRURL)
+	}
+	if len(fake.Commits()) != 1 {
+		t.Fatalf("expected no new commit, got %d commits", len(fake.Commits()))
+	}
+
+	// Third run after the branch drifted: only the changed file is pushed onto the same MR
+	fake.SetFile(1, "ai-analyzer", ".ai/docs/api_analysis.md", "stale")
+	result, err = handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("third run failed: %v", err)
+	}
+	if result.UnchangedCount != 0 {
+		t.Fatalf("expected changes on third run: %+v", result)
+	}
+
+	commits := fake.Commits()
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	last := commits[1]
+	if last.Branch != "ai-analyzer" || len(last.Actions) != 1 || last.Actions[0].FilePath != ".ai/docs/api_analysis.md" {
+		t.Errorf("expected a single update of api_analysis.md, got %+v", last)
+	}
+
+	mrs := fake.MergeRequests(1)
+	if len(mrs) != 1 {
+		t.Fatalf("expected the MR to be reused, got %d MRs", len(mrs))
+	}
+	if !strings.Contains(mrs[0].Description, "Structure Analysis") || !strings.Contains(mrs[0].Description, "`.ai/docs/api_analysis.md`") {
+		t.Errorf("expected changelog note appended to description, got %q", mrs[0].Description)
+	}
+}
+
+func TestBuildChangelogNote(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	note := buildChangelogNote(now, map[string]string{"README.md": "", ".ai/docs/api_analysis.md": ""})
+
+	if !strings.Contains(note, "**Updated 2024-03-01 09:30 UTC**: regenerated 2 file(s)") {
+		t.Errorf("unexpected header: %q", note)
+	}
+	if strings.Index(note, ".ai/docs/api_analysis.md") > strings.Index(note, "README.md") {
+		t.Errorf("expected paths sorted, got %q", note)
+	}
+}
+
+func TestCronjobHandler_ProjectCachePath(t *testing.T) {
+	handler := NewCronjobHandler(config.CronjobConfig{WorkingPath: "/work"}, nil, config.AnalyzerConfig{}, logging.NewNopLogger())
+
+	first := handler.projectCachePath(forge.Project{ID: 1})
+	second := handler.projectCachePath(forge.Project{ID: 2})
+
+	if first == second {
+		t.Errorf("expected distinct cache paths, got %s", first)
+	}
+	if !strings.HasPrefix(first, filepath.Join("/work", "cache")) {
+		t.Errorf("expected cache path under working path, got %s", first)
+	}
+}
+
+func TestBuildMRDescription(t *testing.T) {
+	registry, err := agents.NewRegistry(map[string]config.AgentDefinition{
+		"license_analyzer": {DisplayName: "License Compliance", OutputFile: "license_analysis.md"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	analysis := &agents.AnalysisResult{
+		Successful: []string{"structure", "api", "license", "services/web/data_flow", agents.OverviewAgentName},
+		Failed: []agents.FailedAnalysis{
+			{Name: "data_flow", Error: fmt.Errorf("timeout")},
+		},
+	}
+
+	description := buildMRDescription("2024-01-01", analysis, registry)
+
+	for _, expected := range []string{
+		"2024-01-01",
+		"- Structure Analysis\n",
+		"- API Analysis\n",
+		"- License Compliance\n",
+		"- services/web/Data Flow Analysis\n",
+		"- Architecture overview\n",
+		"- Data Flow Analysis: timeout",
+	} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("expected description to contain %q, got:\n%s", expected, description)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/cache"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/errors"
+	"github.com/user/gendocs/internal/forge"
+	"github.com/user/gendocs/internal/logging"
+)
+
+const (
+	// reviewCommentMarker identifies comments posted by the review command
+	reviewCommentMarker = "<!-- gendocs-review -->"
+	// maxPatchChars bounds each suggested patch so comments stay within forge size limits
+	maxPatchChars = 20000
+)
+
+// StaleSection is an .ai/docs document made stale by a pull/merge request
+type StaleSection struct {
+	Agent         string   `json:"agent"`
+	DisplayName   string   `json:"display_name"`
+	DocPath       string   `json:"doc_path"`
+	Reason        string   `json:"reason"`
+	AffectedFiles []string `json:"affected_files"`
+	Patch         string   `json:"patch,omitempty"`
+	PatchError    string   `json:"patch_error,omitempty"`
+}
+
+// ReviewResult holds the outcome of reviewing a pull/merge request
+type ReviewResult struct {
+	Project       string         `json:"project"`
+	Number        int            `json:"number"`
+	WebURL        string         `json:"web_url"`
+	TargetBranch  string         `json:"target_branch"`
+	Report        *DriftReport   `json:"report"`
+	StaleSections []StaleSection `json:"stale_sections"`
+	Comment       string         `json:"comment"`
+	Posted        bool           `json:"posted"`
+}
+
+// ReviewHandler reports which .ai/docs sections a pull/merge request makes stale.
+// The baseline is the analysis cache committed on the target branch.
+type ReviewHandler struct {
+	*BaseHandler
+	config      config.ReviewConfig
+	analyzerCfg config.AnalyzerConfig
+	forge       forge.Forge
+	check       *CheckHandler    // Severity, summary and recommendation rules
+	registry    *agents.Registry // Agents of the local checkout, set by Handle
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(cfg config.ReviewConfig, f forge.Forge, analyzerCfg config.AnalyzerConfig, logger *logging.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		BaseHandler: &BaseHandler{
+			Config: cfg.BaseConfig,
+			Logger: logger,
+		},
+		config:      cfg,
+		analyzerCfg: analyzerCfg,
+		forge:       f,
+		check:       NewCheckHandler(config.CheckConfig{BaseConfig: cfg.BaseConfig}, logger),
+	}
+}
+
+// Handle reviews the pull/merge request and posts the summary comment
+func (h *ReviewHandler) Handle(ctx context.Context) (*ReviewResult, error) {
+	h.Logger.Info("Starting review",
+		logging.String("forge", h.forge.Name()),
+		logging.String("project", h.config.Project),
+		logging.Int("mr", h.config.MRNumber),
+	)
+
+	registry, err := agents.LoadRegistry(h.config.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	h.registry = registry
+
+	project, err := h.forge.GetProject(ctx, h.config.Project)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := h.forge.GetPR(ctx, *project, h.config.MRNumber)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := h.forge.ListPRFiles(ctx, *project, pr.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	analysisCache, err := h.loadTargetCache(ctx, *project, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := h.loadTargetDocs(ctx, *project, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	report, changeReport := h.buildReport(analysisCache, changes, docs, pr)
+	result := &ReviewResult{
+		Project:      project.FullName,
+		Number:       pr.Number,
+		WebURL:       pr.WebURL,
+		TargetBranch: pr.TargetBranch,
+		Report:       report,
+	}
+	if changeReport != nil {
+		result.StaleSections = staleSections(h.registry, analysisCache, report, changeReport)
+	}
+
+	if !h.config.NoPatch && len(result.StaleSections) > 0 {
+		h.suggestPatches(ctx, result.StaleSections, docs)
+	}
+
+	result.Comment = buildReviewComment(result, h.changeTerm())
+
+	if h.config.DryRun {
+		h.Logger.Info("Dry run: not posting review comment")
+		return result, nil
+	}
+	// Later runs update the comment of the first one instead of adding another
+	commentID, err := h.forge.FindPRComment(ctx, *project, pr.Number, reviewCommentMarker)
+	if err != nil {
+		return result, err
+	}
+	if commentID != 0 {
+		err = h.forge.UpdatePRComment(ctx, *project, pr.Number, commentID, result.Comment)
+	} else {
+		err = h.forge.CommentOnPR(ctx, *project, pr.Number, result.Comment)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Posted = true
+
+	return result, nil
+}
+
+// loadTargetCache reads the analysis cache committed on the target branch.
+// A missing cache yields an empty one, which is reported as a first run.
+func (h *ReviewHandler) loadTargetCache(ctx context.Context, project forge.Project, branch string) (*cache.AnalysisCache, error) {
+	content, exists, err := h.forge.GetFileContent(ctx, project, branch, cache.CacheFileName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return cache.NewCache(), nil
+	}
+
+	analysisCache := cache.NewCache()
+	if err := json.Unmarshal([]byte(content), analysisCache); err != nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid %s on %s: %v", cache.CacheFileName, branch, err))
+	}
+	if analysisCache.Files == nil {
+		analysisCache.Files = make(map[string]cache.FileInfo)
+	}
+	if analysisCache.Agents == nil {
+		analysisCache.Agents = make(map[string]cache.AgentStatus)
+	}
+	return analysisCache, nil
+}
+
+// loadTargetDocs reads the agent documents committed on the target branch, keyed by file name
+func (h *ReviewHandler) loadTargetDocs(ctx context.Context, project forge.Project, branch string) (map[string]string, error) {
+	docs := make(map[string]string)
+	for _, spec := range h.registry.Agents() {
+		content, exists, err := h.forge.GetFileContent(ctx, project, branch, ".ai/docs/"+spec.OutputFile)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			docs[spec.OutputFile] = content
+		}
+	}
+	return docs, nil
+}
+
+// buildReport applies the pull/merge request changes to the cached file list and
+// runs drift detection on the result. The change report is nil on a first run.
+func (h *ReviewHandler) buildReport(analysisCache *cache.AnalysisCache, changes []forge.ChangedFile, docs map[string]string, pr *forge.PullRequest) (*DriftReport, *cache.ChangeReport) {
+	report := &DriftReport{
+		DocsDir:   ".ai/docs",
+		CacheFile: cache.CacheFileName,
+	}
+
+	if len(analysisCache.Files) == 0 || analysisCache.LastAnalysis.IsZero() {
+		report.IsFirstRun = true
+		report.HasDrift = true
+		report.Severity = DriftSeverityMajor
+		report.Summary = fmt.Sprintf("No analysis committed on %s", pr.TargetBranch)
+		report.Recommendation = fmt.Sprintf("Run 'gendocs analyze' on %s to generate initial documentation", pr.TargetBranch)
+		return report, nil
+	}
+
+	report.LastAnalysis = analysisCache.LastAnalysis
+	report.CachedGitCommit = analysisCache.GitCommit
+
+	currentFiles := applyPRChanges(analysisCache.Files, changes, fmt.Sprintf("pr-%d", pr.Number))
+	changeReport := analysisCache.DetectChangesForAgents(h.config.RepoPath, currentFiles, h.registry.FilePatterns())
+	sort.Strings(changeReport.NewFiles)
+	sort.Strings(changeReport.ModifiedFiles)
+	sort.Strings(changeReport.DeletedFiles)
+
+	report.NewFiles = changeReport.NewFiles
+	report.ModifiedFiles = changeReport.ModifiedFiles
+	report.DeletedFiles = changeReport.DeletedFiles
+	report.HasDrift = changeReport.HasChanges
+
+	report.AgentStatus = buildAgentDriftStatus(h.registry, analysisCache, changeReport, func(outputFile string) bool {
+		_, exists := docs[outputFile]
+		return exists
+	})
+
+	report.Severity = h.check.calculateSeverity(report)
+	report.Summary = h.check.generateSummary(report)
+	report.Recommendation = h.check.generateRecommendation(report)
+
+	return report, changeReport
+}
+
+// applyPRChanges returns the cached file list as it looks after the changes.
+// Changed files get a placeholder hash that never matches a cached one. Files
+// under .ai/ are generated documentation and cannot make the docs stale.
+func applyPRChanges(files map[string]cache.FileInfo, changes []forge.ChangedFile, marker string) map[string]cache.FileInfo {
+	current := make(map[string]cache.FileInfo, len(files))
+	for path, info := range files {
+		current[path] = info
+	}
+
+	for _, change := range changes {
+		if strings.HasPrefix(change.Path, ".ai/") {
+			continue
+		}
+		switch change.Status {
+		case forge.FileDeleted:
+			delete(current, change.Path)
+		case forge.FileRenamed:
+			delete(current, change.PreviousPath)
+			current[change.Path] = cache.FileInfo{Hash: marker}
+		default:
+			current[change.Path] = cache.FileInfo{Hash: marker}
+		}
+	}
+	return current
+}
+
+// staleSections lists the documents of agents that need a re-run
+func staleSections(registry *agents.Registry, analysisCache *cache.AnalysisCache, report *DriftReport, changeReport *cache.ChangeReport) []StaleSection {
+	var sections []StaleSection
+	for _, status := range report.AgentStatus {
+		if !status.NeedsRerun {
+			continue
+		}
+		spec, _ := registry.Get(status.Name)
+		sections = append(sections, StaleSection{
+			Agent:         status.Name,
+			DisplayName:   status.DisplayName,
+			DocPath:       ".ai/docs/" + status.OutputFile,
+			Reason:        status.RerunReason,
+			AffectedFiles: analysisCache.AffectedFiles(status.Name, changeReport, spec.FilePatterns),
+		})
+	}
+	return sections
+}
+
+// suggestPatches regenerates the stale sections on the local checkout and diffs
+// them against the documents on the target branch. Only stale agents are run.
+func (h *ReviewHandler) suggestPatches(ctx context.Context, sections []StaleSection, docs map[string]string) {
+	analyzerCfg := h.analyzerCfg
+	analyzerCfg.RepoPath = h.config.RepoPath
+	analyzerCfg.Force = true
+	analyzerCfg.OnlyAgents = nil
+	for _, section := range sections {
+		analyzerCfg.OnlyAgents = append(analyzerCfg.OnlyAgents, section.Agent)
+	}
+
+	result, err := NewAnalyzeHandler(analyzerCfg, h.Logger).Run(ctx)
+	failed := make(map[string]error)
+	if result != nil {
+		for _, f := range result.Failed {
+			failed[f.Name] = f.Error
+		}
+	}
+
+	for i := range sections {
+		section := &sections[i]
+		outputFile := strings.TrimPrefix(section.DocPath, ".ai/docs/")
+
+		if result == nil {
+			section.PatchError = err.Error()
+			continue
+		}
+		if agentErr, ok := failed[strings.TrimSuffix(section.Agent, "_analyzer")]; ok {
+			section.PatchError = agentErr.Error()
+			continue
+		}
+
+		regenerated, readErr := os.ReadFile(filepath.Join(h.config.RepoPath, ".ai", "docs", outputFile))
+		if readErr != nil {
+			section.PatchError = readErr.Error()
+			continue
+		}
+		section.Patch = unifiedDiff("a/"+section.DocPath, "b/"+section.DocPath, docs[outputFile], string(regenerated))
+	}
+}
+
+// changeTerm returns how the forge names a change request
+func (h *ReviewHandler) changeTerm() string {
+	if h.forge.Name() == forge.KindGitLab {
+		return "merge request"
+	}
+	return "pull request"
+}
+
+// buildReviewComment renders the markdown comment posted on the pull/merge request
+func buildReviewComment(result *ReviewResult, term string) string {
+	var sb strings.Builder
+
+	sb.WriteString("## 📚 Documentation review\n\n")
+
+	switch {
+	case result.Report.IsFirstRun:
+		sb.WriteString(fmt.Sprintf("No documentation analysis is committed on `%s`, so drift cannot be checked.\n\n", result.TargetBranch))
+	case len(result.StaleSections) == 0:
+		sb.WriteString(fmt.Sprintf("No `.ai/docs` sections are affected by this %s.\n\n", term))
+	default:
+		sb.WriteString(fmt.Sprintf("This %s changes files covered by %d `.ai/docs` section(s), which are now stale:\n\n", term, len(result.StaleSections)))
+		sb.WriteString("| Section | Document | Reason |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, section := range result.StaleSections {
+			sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", section.DisplayName, section.DocPath, section.Reason))
+		}
+		sb.WriteString("\n")
+
+		for _, section := range result.StaleSections {
+			switch {
+			case section.PatchError != "":
+				sb.WriteString(fmt.Sprintf("⚠️ Could not regenerate `%s`: %s\n\n", section.DocPath, section.PatchError))
+			case section.Patch != "":
+				patch := section.Patch
+				truncated := len(patch) > maxPatchChars
+				if truncated {
+					patch = patch[:maxPatchChars]
+					patch = patch[:strings.LastIndex(patch, "\n")+1]
+				}
+				sb.WriteString(fmt.Sprintf("<details><summary>Suggested patch for <code>%s</code></summary>\n\n", section.DocPath))
+				sb.WriteString("```diff\n")
+				sb.WriteString(patch)
+				sb.WriteString("```\n")
+				if truncated {
+					sb.WriteString("\n_Patch truncated; run `gendocs analyze` locally for the full update._\n")
+				}
+				sb.WriteString("\n</details>\n\n")
+			}
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("**Summary:** %s\n\n", result.Report.Summary))
+	sb.WriteString(fmt.Sprintf("**Recommendation:** %s\n\n", result.Report.Recommendation))
+	sb.WriteString(reviewCommentMarker + "\n")
+
+	return sb.String()
+}
+
+// FormatJSONReport renders the review result as JSON
+func (h *ReviewHandler) FormatJSONReport(result *ReviewResult) (string, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal review: %w", err)
+	}
+	return string(data), nil
+}
+
+const (
+	// diffContext is the number of unchanged lines shown around each hunk
+	diffContext = 3
+	// maxDiffCells bounds the LCS table; larger inputs are diffed as a full replacement
+	maxDiffCells = 4_000_000
+)
+
+// diffLine is one line of a line-based diff: ' ' unchanged, '-' removed, '+' added
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns a unified diff between two texts, or "" when they are equal
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	// Line numbers in each file before every diff line, for hunk headers
+	oldPos := make([]int, len(lines)+1)
+	newPos := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if l.kind != '+' {
+			oldPos[i+1]++
+		}
+		if l.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].kind == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].kind == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = run
+		}
+
+		oldStart, oldCount := oldPos[start], oldPos[end]-oldPos[start]
+		newStart, newCount := newPos[start], newPos[end]-newPos[start]
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		for _, l := range lines[start:end] {
+			sb.WriteByte(l.kind)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// diffLines computes a line diff from the longest common subsequence
+func diffLines(a, b []string) []diffLine {
+	// Common prefix and suffix keep the LCS table small for typical edits
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, l := range a[:prefix] {
+		lines = append(lines, diffLine{' ', l})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+	if n*m > maxDiffCells {
+		for _, l := range midA {
+			lines = append(lines, diffLine{'-', l})
+		}
+		for _, l := range midB {
+			lines = append(lines, diffLine{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of midA[i:] and midB[j:]
+		lcs := make([][]int, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				lines = append(lines, diffLine{' ', midA[i]})
+				i++
+				j++
+			case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+				lines = append(lines, diffLine{'-', midA[i]})
+				i++
+			default:
+				lines = append(lines, diffLine{'+', midB[j]})
+				j++
+			}
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', l})
+	}
+	return lines
+}
+
+// splitLines splits text into lines without their terminators
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
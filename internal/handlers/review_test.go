@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/cache"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/forge"
+	"github.com/user/gendocs/internal/logging"
+	testHelpers "github.com/user/gendocs/internal/testing"
+)
+
+// newReviewFake registers a project whose main branch holds a committed analysis
+// and an open merge request !5 with the given diffs
+func newReviewFake(t *testing.T, withAnalysis bool, diffs []testHelpers.FakeGitLabMRDiff) *testHelpers.FakeGitLab {
+	t.Helper()
+	fake := testHelpers.NewFakeGitLab(t, "secret")
+	fake.AddProject(10, testHelpers.FakeGitLabProject{ID: 1, Name: "app", PathWithNamespace: "group/app"})
+
+	files := map[string]string{}
+	if withAnalysis {
+		lastRun := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		analysisCache := cache.NewCache()
+		analysisCache.LastAnalysis = lastRun
+		for _, path := range []string{"main.go", "go.mod", "README.md"} {
+			analysisCache.Files[path] = cache.FileInfo{Hash: "hash-" + path}
+		}
+		for _, spec := range agents.DefaultRegistry().Agents() {
+			analysisCache.Agents[spec.Name] = cache.AgentStatus{LastRun: lastRun, Success: true}
+			files[".ai/docs/"+spec.OutputFile] = "# Old\n\nPrevious content\n"
+		}
+		data, err := json.Marshal(analysisCache)
+		if err != nil {
+			t.Fatalf("failed to marshal cache: %v", err)
+		}
+		files[cache.CacheFileName] = string(data)
+	}
+	fake.AddBranch(1, "main", files)
+	fake.AddMergeRequest(1, testHelpers.FakeGitLabMR{
+		IID:          5,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		Diffs:        diffs,
+	})
+	return fake
+}
+
+func newTestReviewHandler(fake *testHelpers.FakeGitLab, cfg config.ReviewConfig, analyzerCfg config.AnalyzerConfig) *ReviewHandler {
+	gitLab := forge.NewGitLab(config.GitLabConfig{APIURL: fake.URL(), OAuthToken: "secret"}, logging.NewNopLogger())
+	cfg.Project = "group/app"
+	cfg.MRNumber = 5
+	return NewReviewHandler(cfg, gitLab, analyzerCfg, logging.NewNopLogger())
+}
+
+func TestReviewHandler_Handle_PostsStaleSections(t *testing.T) {
+	fake := newReviewFake(t, true, []testHelpers.FakeGitLabMRDiff{
+		{OldPath: "go.mod", NewPath: "go.mod"},
+		{OldPath: ".ai/docs/api_analysis.md", NewPath: ".ai/docs/api_analysis.md"},
+	})
+	handler := newTestReviewHandler(fake, config.ReviewConfig{NoPatch: true}, config.AnalyzerConfig{})
+
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale := make(map[string]StaleSection)
+	for _, section := range result.StaleSections {
+		stale[section.Agent] = section
+	}
+	if len(stale) != 2 {
+		t.Fatalf("expected structure and dependency sections to be stale, got %+v", result.StaleSections)
+	}
+	dependency, ok := stale["dependency_analyzer"]
+	if !ok || dependency.DocPath != ".ai/docs/dependency_analysis.md" {
+		t.Errorf("expected dependency section, got %+v", dependency)
+	}
+	if len(dependency.AffectedFiles) != 1 || dependency.AffectedFiles[0] != "go.mod" {
+		t.Errorf("expected go.mod as affected file, got %v", dependency.AffectedFiles)
+	}
+	if len(result.Report.ModifiedFiles) != 1 {
+		t.Errorf("expected changes under .ai/ to be ignored, got %v", result.Report.ModifiedFiles)
+	}
+
+	notes := fake.Notes()
+	if !result.Posted || len(notes) != 1 || notes[0].MRIID != 5 {
+		t.Fatalf("expected one comment on !5, got %+v", notes)
+	}
+	for _, want := range []string{"merge request", "`.ai/docs/dependency_analysis.md`", "`.ai/docs/structure_analysis.md`", reviewCommentMarker} {
+		if !strings.Contains(notes[0].Body, want) {
+			t.Errorf("expected comment to contain %q, got:\n%s", want, notes[0].Body)
+		}
+	}
+	if strings.Contains(notes[0].Body, "api_analysis.md") {
+		t.Errorf("expected API section to be up to date, got:\n%s", notes[0].Body)
+	}
+
+	// A later run updates the comment instead of posting another one
+	if _, err := handler.Handle(context.Background()); err != nil {
+		t.Fatalf("unexpected error on second run: %v", err)
+	}
+	if notes := fake.Notes(); len(notes) != 1 || !strings.Contains(notes[0].Body, reviewCommentMarker) {
+		t.Errorf("expected the review comment to be updated in place, got %+v", notes)
+	}
+}
+
+func TestReviewHandler_Handle_NoAnalysisOnTarget(t *testing.T) {
+	fake := newReviewFake(t, false, []testHelpers.FakeGitLabMRDiff{{OldPath: "main.go", NewPath: "main.go"}})
+	handler := newTestReviewHandler(fake, config.ReviewConfig{NoPatch: true, DryRun: true}, config.AnalyzerConfig{})
+
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Report.IsFirstRun || len(result.StaleSections) != 0 {
+		t.Errorf("expected first run without stale sections, got %+v", result)
+	}
+	if !strings.Contains(result.Comment, "No documentation analysis is committed on `main`") {
+		t.Errorf("unexpected comment:\n%s", result.Comment)
+	}
+	if result.Posted || len(fake.Notes()) != 0 {
+		t.Error("expected dry run to not post a comment")
+	}
+}
+
+func TestReviewHandler_Handle_SuggestsPatch(t *testing.T) {
+	// Run from the module root so ./prompts resolves
+	t.Chdir(filepath.Join("..", ".."))
+
+	llmServer := testHelpers.NewMockServer(t, testHelpers.OpenAIStreamHandler("# Analysis\\n\\nGenerated content"))
+	t.Cleanup(llmServer.Close)
+
+	fake := newReviewFake(t, true, []testHelpers.FakeGitLabMRDiff{{OldPath: "go.sum", NewPath: "go.sum", NewFile: true}})
+	repoPath := testHelpers.CreateTempRepo(t, testHelpers.SampleGoProject())
+	analyzerCfg := config.AnalyzerConfig{
+		LLM: config.LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+			APIKey:   "test-key",
+			BaseURL:  llmServer.URL,
+			Retries:  1,
+		},
+		MaxWorkers: 1,
+	}
+	cfg := config.ReviewConfig{BaseConfig: config.BaseConfig{RepoPath: repoPath}, DryRun: true}
+	handler := newTestReviewHandler(fake, cfg, analyzerCfg)
+
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.StaleSections) != 1 || result.StaleSections[0].Agent != "dependency_analyzer" {
+		t.Fatalf("expected only the dependency section to be stale, got %+v", result.StaleSections)
+	}
+
+	section := result.StaleSections[0]
+	if section.PatchError != "" {
+		t.Fatalf("unexpected patch error: %s", section.PatchError)
+	}
+	for _, want := range []string{"--- a/.ai/docs/dependency_analysis.md", "-# Old", "+# Analysis"} {
+		if !strings.Contains(section.Patch, want) {
+			t.Errorf("expected patch to contain %q, got:\n%s", want, section.Patch)
+		}
+	}
+	if !strings.Contains(result.Comment, "```diff") {
+		t.Errorf("expected comment to embed the patch, got:\n%s", result.Comment)
+	}
+}
+
+func TestApplyPRChanges(t *testing.T) {
+	files := map[string]cache.FileInfo{
+		"main.go":   {Hash: "a"},
+		"old.go":    {Hash: "b"},
+		"routes.go": {Hash: "c"},
+	}
+	changes := []forge.ChangedFile{
+		{Path: "main.go", Status: forge.FileModified},
+		{Path: "old.go", Status: forge.FileDeleted},
+		{Path: "api/routes.go", PreviousPath: "routes.go", Status: forge.FileRenamed},
+		{Path: "users.go", Status: forge.FileAdded},
+		{Path: ".ai/docs/api_analysis.md", Status: forge.FileModified},
+	}
+
+	current := applyPRChanges(files, changes, "pr-1")
+
+	expected := map[string]string{"main.go": "pr-1", "api/routes.go": "pr-1", "users.go": "pr-1"}
+	if len(current) != len(expected) {
+		t.Fatalf("expected %d files, got %v", len(expected), current)
+	}
+	for path, hash := range expected {
+		if current[path].Hash != hash {
+			t.Errorf("expected %s to have hash %s, got %+v", path, hash, current[path])
+		}
+	}
+	if files["main.go"].Hash != "a" || len(files) != 3 {
+		t.Error("expected the cached file list to be left untouched")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "replace line",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "new file",
+			old:      "",
+			new:      "x\ny\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.old, tt.new); got != tt.expected {
+				t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -21,13 +21,13 @@ type AnthropicClient struct {
 
 // anthropicRequest represents the request body for Anthropic API
 type anthropicRequest struct {
-	Model       string             `json:"model"`
-	Messages    []anthropicMessage `json:"messages"`
-	System      string             `json:"system,omitempty"`
-	MaxTokens   int                `json:"max_tokens"`
-	Temperature float64            `json:"temperature,omitempty"`
-	Tools       []anthropicTool    `json:"tools,omitempty"`
-	Stream      bool               `json:"stream,omitempty"`
+	Model       string                  `json:"model"`
+	Messages    []anthropicMessage      `json:"messages"`
+	System      []anthropicContentBlock `json:"system,omitempty"`
+	MaxTokens   int                     `json:"max_tokens"`
+	Temperature float64                 `json:"temperature,omitempty"`
+	Tools       []anthropicTool         `json:"tools,omitempty"`
+	Stream      bool                    `json:"stream,omitempty"`
 }
 
 // anthropicMessage represents a message in Anthropic format
@@ -47,13 +47,26 @@ type anthropicContentBlock struct {
 	// Tool result fields (flat when type=="tool_result")
 	ToolUseID string `json:"tool_use_id,omitempty"`
 	Content   string `json:"content,omitempty"` // Can be string for tool results
+
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+// anthropicCacheControl marks a prompt cache breakpoint: the prompt up to and
+// including the marked block is cached and reused by later requests
+type anthropicCacheControl struct {
+	Type string `json:"type"` // "ephemeral"
 }
 
+// ephemeralCache is the cache control of every breakpoint
+var ephemeralCache = &anthropicCacheControl{Type: "ephemeral"}
+
 // anthropicTool represents a tool definition
 type anthropicTool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"input_schema"`
+
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
 }
 
 // anthropicResponse represents the response from Anthropic API
@@ -183,6 +196,12 @@ func (a *anthropicAccumulator) HandleEvent(event SSEEvent) error {
 				if output, ok := usage["output_tokens"].(float64); ok {
 					a.usage.OutputTokens = int(output)
 				}
+				if created, ok := usage["cache_creation_input_tokens"].(float64); ok {
+					a.usage.CacheCreationInputTokens = int(created)
+				}
+				if read, ok := usage["cache_read_input_tokens"].(float64); ok {
+					a.usage.CacheReadInputTokens = int(read)
+				}
 			}
 		}
 	case "content_block_start":
@@ -250,7 +269,7 @@ func (a *anthropicAccumulator) IsComplete() bool {
 }
 
 func (a *anthropicAccumulator) Build() CompletionResponse {
-	a.usage.TotalTokens = a.usage.InputTokens + a.usage.OutputTokens
+	a.usage.TotalTokens = a.usage.InputTokens + a.usage.CacheCreationInputTokens + a.usage.CacheReadInputTokens + a.usage.OutputTokens
 	return CompletionResponse{
 		Content:   a.content.String(),
 		ToolCalls: a.toolCalls,
@@ -343,13 +362,39 @@ func (c *AnthropicClient) convertRequest(req CompletionRequest) anthropicRequest
 		}
 	}
 
+	var system []anthropicContentBlock
+	if req.SystemPrompt != "" {
+		system = []anthropicContentBlock{{Type: "text", Text: req.SystemPrompt}}
+	}
+	markCacheBreakpoints(tools, system, messages)
+
 	return anthropicRequest{
 		Model:       c.model,
 		Messages:    messages,
-		System:      req.SystemPrompt,
+		System:      system,
 		MaxTokens:   req.MaxTokens,
 		Temperature: req.Temperature,
 		Tools:       tools,
 		Stream:      true,
 	}
 }
+
+// markCacheBreakpoints caches the prompt prefix that every tool-loop iteration
+// resends. The cache covers tools, then the system prompt, then messages, so
+// breakpoints on the last tool and the system prompt cache the fixed part, and
+// one on the last message caches the history for the next iteration, which
+// extends it. Prompts below the model's minimum cacheable length are sent uncached.
+func markCacheBreakpoints(tools []anthropicTool, system []anthropicContentBlock, messages []anthropicMessage) {
+	if len(tools) > 0 {
+		tools[len(tools)-1].CacheControl = ephemeralCache
+	}
+	if len(system) > 0 {
+		system[len(system)-1].CacheControl = ephemeralCache
+	}
+	if len(messages) > 0 {
+		last := messages[len(messages)-1].Content
+		if len(last) > 0 {
+			last[len(last)-1].CacheControl = ephemeralCache
+		}
+	}
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -585,3 +586,56 @@ func TestAnthropicClient_Streaming_LargeToolArguments(t *testing.T) {
 		t.Errorf("Expected query '%s', got '%v'", expectedQuery, resp.ToolCalls[0].Arguments["query"])
 	}
 }
+
+func TestAnthropicClient_PromptCaching(t *testing.T) {
+	var request anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintln(w, "event: message_start")
+		_, _ = fmt.Fprintln(w, `data: {"type":"message_start","message":{"usage":{"input_tokens":20,"cache_creation_input_tokens":300,"cache_read_input_tokens":4000,"output_tokens":1}}}`)
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "event: message_delta")
+		_, _ = fmt.Fprintln(w, `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`)
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "event: message_stop")
+		_, _ = fmt.Fprintln(w, `data: {"type":"message_stop"}`)
+		_, _ = fmt.Fprintln(w)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(config.LLMConfig{APIKey: "test-key", BaseURL: server.URL, Model: "claude-sonnet-4"}, nil)
+	resp, err := client.GenerateCompletion(context.Background(), CompletionRequest{
+		SystemPrompt: "You are a code analyst",
+		Messages: []Message{
+			{Role: "user", Content: "analyze"},
+			{Role: "assistant", ToolCalls: []ToolCall{{Name: "read_file", Arguments: map[string]interface{}{"file_path": "main.go"}}}},
+			{Role: "tool", ToolID: "read_file", Content: "package main"},
+		},
+		Tools: []ToolDefinition{
+			{Name: "list_files", Parameters: map[string]interface{}{"type": "object"}},
+			{Name: "read_file", Parameters: map[string]interface{}{"type": "object"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Breakpoints on the last tool, the system prompt and the end of the history
+	if request.Tools[0].CacheControl != nil || request.Tools[1].CacheControl == nil {
+		t.Errorf("Expected a breakpoint on the last tool only, got %+v", request.Tools)
+	}
+	if len(request.System) != 1 || request.System[0].Text != "You are a code analyst" || request.System[0].CacheControl == nil {
+		t.Errorf("Expected a cached system prompt, got %+v", request.System)
+	}
+	last := request.Messages[len(request.Messages)-1].Content[0]
+	if last.Type != "tool_result" || last.CacheControl == nil || request.Messages[0].Content[0].CacheControl != nil {
+		t.Errorf("Expected a breakpoint on the last message only, got %+v", request.Messages)
+	}
+
+	usage := resp.Usage
+	if usage.InputTokens != 20 || usage.CacheCreationInputTokens != 300 || usage.CacheReadInputTokens != 4000 || usage.TotalTokens != 4325 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
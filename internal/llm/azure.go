@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// AzureOpenAIClient implements LLMClient for Azure OpenAI, which serves
+// OpenAI models from per-deployment URLs
+type AzureOpenAIClient struct {
+	*OpenAIClient
+	deployment string
+	apiVersion string
+}
+
+// NewAzureOpenAIClient creates a new Azure OpenAI client. The base URL is the
+// resource endpoint, e.g. https://my-resource.openai.azure.com.
+func NewAzureOpenAIClient(cfg config.LLMConfig, retryClient *RetryClient) *AzureOpenAIClient {
+	deployment := cfg.Azure.Deployment
+	if deployment == "" {
+		deployment = cfg.Model
+	}
+	apiVersion := cfg.Azure.APIVersion
+	if apiVersion == "" {
+		apiVersion = config.DefaultAzureAPIVersion
+	}
+
+	return &AzureOpenAIClient{
+		OpenAIClient: &OpenAIClient{
+			BaseLLMClient: NewBaseLLMClient(retryClient),
+			apiKey:        cfg.APIKey,
+			baseURL:       strings.TrimSuffix(cfg.BaseURL, "/"),
+			model:         cfg.Model,
+		},
+		deployment: deployment,
+		apiVersion: apiVersion,
+	}
+}
+
+// GenerateCompletion generates a completion from an Azure OpenAI deployment
+func (c *AzureOpenAIClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	endpoint := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		c.baseURL, url.PathEscape(c.deployment), url.QueryEscape(c.apiVersion))
+	headers := map[string]string{
+		"api-key": c.apiKey,
+	}
+	return c.streamCompletion(ctx, endpoint, headers, req)
+}
+
+// GetProvider returns the provider name
+func (c *AzureOpenAIClient) GetProvider() string {
+	return "azure"
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+func TestAzureOpenAIClient_GenerateCompletion_DeploymentURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/gpt4o-prod/chat/completions" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if version := r.URL.Query().Get("api-version"); version != "2024-06-01" {
+			t.Errorf("Expected api-version 2024-06-01, got %q", version)
+		}
+		if apiKey := r.Header.Get("api-key"); apiKey != "azure-key" {
+			t.Errorf("Expected api-key header 'azure-key', got %q", apiKey)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %q", auth)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Azure sends content filter results in a chunk without choices first
+		_, _ = fmt.Fprintln(w, `data: {"choices":[],"prompt_filter_results":[{"prompt_index":0}]}`)
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"name":"read_file","arguments":"{\"file_path\":\"go.mod\"}"}}]},"finish_reason":"tool_calls"}]}`)
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: [DONE]")
+		_, _ = fmt.Fprintln(w)
+	}))
+	defer server.Close()
+
+	client := NewAzureOpenAIClient(config.LLMConfig{
+		Model:   "gpt-4o",
+		APIKey:  "azure-key",
+		BaseURL: server.URL + "/",
+		Azure:   config.AzureConfig{Deployment: "gpt4o-prod", APIVersion: "2024-06-01"},
+	}, nil)
+
+	resp, err := client.GenerateCompletion(context.Background(), CompletionRequest{
+		Messages: []Message{{Role: "user", Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["file_path"] != "go.mod" {
+		t.Errorf("Expected a read_file call on go.mod, got %+v", resp.ToolCalls)
+	}
+	if client.GetProvider() != "azure" {
+		t.Errorf("Expected provider 'azure', got %q", client.GetProvider())
+	}
+}
+
+func TestAzureOpenAIClient_Defaults(t *testing.T) {
+	client := NewAzureOpenAIClient(config.LLMConfig{Model: "gpt-4o", BaseURL: "https://example.openai.azure.com"}, nil)
+	if client.deployment != "gpt-4o" || client.apiVersion != config.DefaultAzureAPIVersion {
+		t.Errorf("Expected the model as deployment and the default API version, got %s %s", client.deployment, client.apiVersion)
+	}
+}
@@ -0,0 +1,368 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// BedrockClient implements LLMClient for AWS Bedrock with the Converse API
+type BedrockClient struct {
+	*BaseLLMClient
+	apiKey      string // Bedrock API key; requests are signed with credentials otherwise
+	credentials awsCredentials
+	region      string
+	baseURL     string
+	model       string
+	now         func() time.Time
+}
+
+// bedrockRequest represents the request body of the ConverseStream API
+type bedrockRequest struct {
+	Messages        []bedrockMessage       `json:"messages"`
+	System          []bedrockContentBlock  `json:"system,omitempty"`
+	InferenceConfig bedrockInferenceConfig `json:"inferenceConfig"`
+	ToolConfig      *bedrockToolConfig     `json:"toolConfig,omitempty"`
+}
+
+// bedrockMessage represents a message in Converse format
+type bedrockMessage struct {
+	Role    string                `json:"role"`
+	Content []bedrockContentBlock `json:"content"`
+}
+
+// bedrockContentBlock holds exactly one of its fields
+type bedrockContentBlock struct {
+	Text       string             `json:"text,omitempty"`
+	ToolUse    *bedrockToolUse    `json:"toolUse,omitempty"`
+	ToolResult *bedrockToolResult `json:"toolResult,omitempty"`
+}
+
+// bedrockToolUse represents a tool call of the model
+type bedrockToolUse struct {
+	ToolUseID string                 `json:"toolUseId"`
+	Name      string                 `json:"name"`
+	Input     map[string]interface{} `json:"input"`
+}
+
+// bedrockToolResult represents the result of a tool call
+type bedrockToolResult struct {
+	ToolUseID string                `json:"toolUseId"`
+	Content   []bedrockContentBlock `json:"content"`
+}
+
+// bedrockInferenceConfig represents the inference parameters
+type bedrockInferenceConfig struct {
+	MaxTokens   int     `json:"maxTokens,omitempty"`
+	Temperature float64 `json:"temperature"`
+}
+
+// bedrockToolConfig represents the tools available to the model
+type bedrockToolConfig struct {
+	Tools []bedrockTool `json:"tools"`
+}
+
+// bedrockTool represents a tool definition
+type bedrockTool struct {
+	ToolSpec bedrockToolSpec `json:"toolSpec"`
+}
+
+// bedrockToolSpec represents the specification of a tool
+type bedrockToolSpec struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	InputSchema bedrockInputSchema `json:"inputSchema"`
+}
+
+// bedrockInputSchema wraps the JSON schema of a tool's input
+type bedrockInputSchema struct {
+	JSON map[string]interface{} `json:"json"`
+}
+
+// NewBedrockClient creates a new Bedrock client. Region and credentials not
+// configured are read from the AWS environment variables.
+func NewBedrockClient(cfg config.LLMConfig, retryClient *RetryClient) *BedrockClient {
+	bedrock := cfg.Bedrock.WithEnvDefaults()
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", bedrock.Region)
+	}
+	return &BedrockClient{
+		BaseLLMClient: NewBaseLLMClient(retryClient),
+		apiKey:        cfg.APIKey,
+		credentials: awsCredentials{
+			AccessKeyID:     bedrock.AccessKeyID,
+			SecretAccessKey: bedrock.SecretAccessKey,
+			SessionToken:    bedrock.SessionToken,
+		},
+		region:  bedrock.Region,
+		baseURL: baseURL,
+		model:   cfg.Model,
+		now:     time.Now,
+	}
+}
+
+// GenerateCompletion generates a completion from Bedrock
+func (c *BedrockClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	body, err := json.Marshal(c.convertRequest(req))
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Model IDs contain ":" (e.g. anthropic.claude-3-5-sonnet-20240620-v1:0)
+	url := fmt.Sprintf("%s/model/%s/converse-stream", c.baseURL, awsURIEncode(c.model))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	} else {
+		signV4(httpReq, body, c.credentials, c.region, "bedrock", c.now())
+	}
+
+	resp, err := c.retryClient.Do(httpReq)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return CompletionResponse{}, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return c.parseStreamingResponse(resp.Body)
+}
+
+// parseStreamingResponse parses the event stream of ConverseStream and builds the response
+func (c *BedrockClient) parseStreamingResponse(body io.Reader) (CompletionResponse, error) {
+	decoder := newEventStreamDecoder(body)
+	accumulator := newBedrockAccumulator()
+
+	for {
+		message, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return CompletionResponse{}, fmt.Errorf("stream parsing error: %w", err)
+		}
+
+		if err := accumulator.HandleMessage(message); err != nil {
+			return CompletionResponse{}, err
+		}
+
+		if accumulator.IsComplete() {
+			break
+		}
+	}
+
+	if !accumulator.stopped {
+		return CompletionResponse{}, fmt.Errorf("stream ended unexpectedly")
+	}
+
+	return accumulator.Build()
+}
+
+// bedrockAccumulator builds CompletionResponse from ConverseStream events
+type bedrockAccumulator struct {
+	content    strings.Builder
+	toolCalls  []ToolCall
+	toolBlocks map[int]int              // Content block index to tool call index
+	toolInputs map[int]*strings.Builder // Partial JSON input, by content block index
+	usage      TokenUsage
+	stopped    bool // messageStop received
+	complete   bool // metadata received; it follows messageStop
+}
+
+func newBedrockAccumulator() *bedrockAccumulator {
+	return &bedrockAccumulator{
+		toolBlocks: make(map[int]int),
+		toolInputs: make(map[int]*strings.Builder),
+	}
+}
+
+// bedrockStreamEvent is the union of the ConverseStream event payloads
+type bedrockStreamEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *struct {
+			ToolUseID string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse"`
+	} `json:"start"`
+	Delta struct {
+		Text    string `json:"text"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse"`
+	} `json:"delta"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+		TotalTokens  int `json:"totalTokens"`
+	} `json:"usage"`
+	Message string `json:"message"` // Exceptions
+}
+
+func (a *bedrockAccumulator) HandleMessage(message eventStreamMessage) error {
+	var event bedrockStreamEvent
+	if len(message.Payload) > 0 {
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return fmt.Errorf("failed to parse event data: %w", err)
+		}
+	}
+
+	switch message.Headers[":message-type"] {
+	case "exception":
+		return fmt.Errorf("API error: %s: %s", message.Headers[":exception-type"], event.Message)
+	case "error":
+		return fmt.Errorf("API error: %s: %s", message.Headers[":error-code"], message.Headers[":error-message"])
+	}
+
+	switch message.Headers[":event-type"] {
+	case "contentBlockStart":
+		if tool := event.Start.ToolUse; tool != nil {
+			a.toolBlocks[event.ContentBlockIndex] = len(a.toolCalls)
+			a.toolInputs[event.ContentBlockIndex] = &strings.Builder{}
+			a.toolCalls = append(a.toolCalls, ToolCall{Name: tool.Name})
+		}
+	case "contentBlockDelta":
+		a.content.WriteString(event.Delta.Text)
+		if tool := event.Delta.ToolUse; tool != nil {
+			if input, ok := a.toolInputs[event.ContentBlockIndex]; ok {
+				input.WriteString(tool.Input)
+			}
+		}
+	case "messageStop":
+		a.stopped = true
+	case "metadata":
+		a.usage = TokenUsage{
+			InputTokens:  event.Usage.InputTokens,
+			OutputTokens: event.Usage.OutputTokens,
+			TotalTokens:  event.Usage.TotalTokens,
+		}
+		a.complete = true
+	}
+	return nil
+}
+
+func (a *bedrockAccumulator) IsComplete() bool {
+	return a.complete
+}
+
+func (a *bedrockAccumulator) Build() (CompletionResponse, error) {
+	for block, i := range a.toolBlocks {
+		input := a.toolInputs[block].String()
+		if input == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(input), &a.toolCalls[i].Arguments); err != nil {
+			return CompletionResponse{}, fmt.Errorf("failed to parse arguments of tool %s: %w", a.toolCalls[i].Name, err)
+		}
+	}
+	if a.usage.TotalTokens == 0 {
+		a.usage.TotalTokens = a.usage.InputTokens + a.usage.OutputTokens
+	}
+	return CompletionResponse{
+		Content:   a.content.String(),
+		ToolCalls: a.toolCalls,
+		Usage:     a.usage,
+	}, nil
+}
+
+// SupportsTools returns true
+func (c *BedrockClient) SupportsTools() bool {
+	return true
+}
+
+// GetProvider returns the provider name
+func (c *BedrockClient) GetProvider() string {
+	return "bedrock"
+}
+
+// convertRequest converts internal request to Converse format. Converse
+// alternates user and assistant messages, so tool results join the user
+// message that follows the assistant's tool calls.
+func (c *BedrockClient) convertRequest(req CompletionRequest) bedrockRequest {
+	callIDs, resultIDs := toolCallIDs(req.Messages)
+
+	var messages []bedrockMessage
+	add := func(role string, block bedrockContentBlock) {
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, block)
+			return
+		}
+		messages = append(messages, bedrockMessage{Role: role, Content: []bedrockContentBlock{block}})
+	}
+
+	for i, msg := range req.Messages {
+		switch msg.Role {
+		case "tool":
+			content := msg.Content
+			if content == "" {
+				content = "(empty)"
+			}
+			add("user", bedrockContentBlock{ToolResult: &bedrockToolResult{
+				ToolUseID: resultIDs[i],
+				Content:   []bedrockContentBlock{{Text: content}},
+			}})
+		case "assistant":
+			if msg.Content != "" {
+				add("assistant", bedrockContentBlock{Text: msg.Content})
+			}
+			for j, tc := range msg.ToolCalls {
+				input := tc.Arguments
+				if input == nil {
+					input = map[string]interface{}{}
+				}
+				add("assistant", bedrockContentBlock{ToolUse: &bedrockToolUse{
+					ToolUseID: callIDs[i][j],
+					Name:      tc.Name,
+					Input:     input,
+				}})
+			}
+		case "user":
+			if msg.Content != "" {
+				add("user", bedrockContentBlock{Text: msg.Content})
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		add("user", bedrockContentBlock{Text: "Analyze this codebase."})
+	}
+
+	bdReq := bedrockRequest{
+		Messages: messages,
+		InferenceConfig: bedrockInferenceConfig{
+			MaxTokens:   req.MaxTokens,
+			Temperature: req.Temperature,
+		},
+	}
+	if req.SystemPrompt != "" {
+		bdReq.System = []bedrockContentBlock{{Text: req.SystemPrompt}}
+	}
+	if len(req.Tools) > 0 {
+		bdReq.ToolConfig = &bedrockToolConfig{}
+		for _, tool := range req.Tools {
+			bdReq.ToolConfig.Tools = append(bdReq.ToolConfig.Tools, bedrockTool{ToolSpec: bedrockToolSpec{
+				Name:        tool.Name,
+				Description: tool.Description,
+				InputSchema: bedrockInputSchema{JSON: tool.Parameters},
+			}})
+		}
+	}
+	return bdReq
+}
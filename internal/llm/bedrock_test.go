@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// encodeEventStreamMessage encodes an event-stream message with string headers
+func encodeEventStreamMessage(headers map[string]string, payload string) []byte {
+	var headerBytes bytes.Buffer
+	for name, value := range headers {
+		headerBytes.WriteByte(byte(len(name)))
+		headerBytes.WriteString(name)
+		headerBytes.WriteByte(7)
+		_ = binary.Write(&headerBytes, binary.BigEndian, uint16(len(value)))
+		headerBytes.WriteString(value)
+	}
+
+	totalLength := uint32(16 + headerBytes.Len() + len(payload))
+	var message bytes.Buffer
+	_ = binary.Write(&message, binary.BigEndian, totalLength)
+	_ = binary.Write(&message, binary.BigEndian, uint32(headerBytes.Len()))
+	_ = binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	message.Write(headerBytes.Bytes())
+	message.WriteString(payload)
+	_ = binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	return message.Bytes()
+}
+
+func bedrockEvent(eventType, payload string) []byte {
+	return encodeEventStreamMessage(map[string]string{
+		":message-type": "event",
+		":event-type":   eventType,
+		":content-type": "application/json",
+	}, payload)
+}
+
+func TestBedrockClient_GenerateCompletion_StreamsToolCalls(t *testing.T) {
+	var request bedrockRequest
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, event := range [][]byte{
+			bedrockEvent("messageStart", `{"role":"assistant"}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Reading "}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"the file"}}`),
+			bedrockEvent("contentBlockStop", `{"contentBlockIndex":0}`),
+			bedrockEvent("contentBlockStart", `{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"tooluse_1","name":"read_file"}}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"file_path\":"}}}`),
+			bedrockEvent("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"\"main.go\"}"}}}`),
+			bedrockEvent("contentBlockStop", `{"contentBlockIndex":1}`),
+			bedrockEvent("messageStop", `{"stopReason":"tool_use"}`),
+			bedrockEvent("metadata", `{"usage":{"inputTokens":20,"outputTokens":7,"totalTokens":27},"metrics":{"latencyMs":100}}`),
+		} {
+			_, _ = w.Write(event)
+		}
+	}))
+	defer server.Close()
+
+	client := NewBedrockClient(config.LLMConfig{
+		Model:   "anthropic.claude-3-5-sonnet-20240620-v1:0",
+		BaseURL: server.URL,
+		Bedrock: config.BedrockConfig{Region: "us-east-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+	}, nil)
+
+	resp, err := client.GenerateCompletion(context.Background(), CompletionRequest{
+		SystemPrompt: "You are a code analyst",
+		Messages: []Message{
+			{Role: "user", Content: "analyze"},
+			{Role: "assistant", ToolCalls: []ToolCall{{Name: "list_files", Arguments: map[string]interface{}{"path": "."}}}},
+			{Role: "tool", ToolID: "list_files", Content: "main.go"},
+		},
+		Tools:     []ToolDefinition{{Name: "read_file", Description: "Read a file", Parameters: map[string]interface{}{"type": "object"}}},
+		MaxTokens: 100,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Content != "Reading the file" {
+		t.Errorf("Expected content 'Reading the file', got %q", resp.Content)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || resp.ToolCalls[0].Arguments["file_path"] != "main.go" {
+		t.Errorf("Expected a read_file call on main.go, got %+v", resp.ToolCalls)
+	}
+	if resp.Usage.InputTokens != 20 || resp.Usage.OutputTokens != 7 || resp.Usage.TotalTokens != 27 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+
+	if path != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/converse-stream" {
+		t.Errorf("Unexpected path %q", path)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(authorization, "/us-east-1/bedrock/aws4_request") {
+		t.Errorf("Expected a SigV4 authorization for bedrock, got %q", authorization)
+	}
+
+	// The tool result answers the tool call in the following user message
+	if len(request.Messages) != 3 || request.System[0].Text != "You are a code analyst" {
+		t.Fatalf("Unexpected request: %+v", request)
+	}
+	toolUse := request.Messages[1].Content[0].ToolUse
+	toolResult := request.Messages[2].Content[0].ToolResult
+	if toolUse == nil || toolResult == nil || toolResult.ToolUseID != toolUse.ToolUseID || request.Messages[2].Role != "user" {
+		t.Errorf("Expected the tool result to reference the tool call, got %+v", request.Messages)
+	}
+	if request.ToolConfig == nil || request.ToolConfig.Tools[0].ToolSpec.InputSchema.JSON["type"] != "object" {
+		t.Errorf("Expected the tool specification, got %+v", request.ToolConfig)
+	}
+}
+
+func TestBedrockClient_GenerateCompletion_Exception(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bedrockEvent("messageStart", `{"role":"assistant"}`))
+		_, _ = w.Write(encodeEventStreamMessage(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, `{"message":"Too many requests, please wait before trying again."}`))
+	}))
+	defer server.Close()
+
+	client := NewBedrockClient(config.LLMConfig{Model: "amazon.nova-pro-v1:0", APIKey: "bedrock-api-key", BaseURL: server.URL}, nil)
+	_, err := client.GenerateCompletion(context.Background(), CompletionRequest{Messages: []Message{{Role: "user", Content: "hello"}}})
+	if err == nil || !strings.Contains(err.Error(), "throttlingException") {
+		t.Fatalf("Expected the stream exception, got %v", err)
+	}
+	if !isFailoverError(err) {
+		t.Error("Expected throttling to allow a failover")
+	}
+}
+
+func TestBedrockClient_APIKeyUsesBearerToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write(bedrockEvent("messageStop", `{"stopReason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	client := NewBedrockClient(config.LLMConfig{Model: "amazon.nova-pro-v1:0", APIKey: "bedrock-api-key", BaseURL: server.URL}, nil)
+	if _, err := client.GenerateCompletion(context.Background(), CompletionRequest{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authorization != "Bearer bedrock-api-key" {
+		t.Errorf("Expected the API key as bearer token, got %q", authorization)
+	}
+}
+
+func TestEventStreamDecoder_ChecksumMismatch(t *testing.T) {
+	message := bedrockEvent("messageStop", `{"stopReason":"end_turn"}`)
+	message[len(message)-5] ^= 0xff
+
+	if _, err := newEventStreamDecoder(bytes.NewReader(message)).Next(); err == nil {
+		t.Fatal("Expected a checksum error for a corrupted message")
+	}
+}
+
+func TestSignV4_AWSTestSuite(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Unexpected authorization:\n got  %s\n want %s", got, expected)
+	}
+}
+
+func TestCanonicalURI_DoubleEncodes(t *testing.T) {
+	if got := canonicalURI("/model/anthropic.claude-v2%3A1/converse-stream"); got != "/model/anthropic.claude-v2%253A1/converse-stream" {
+		t.Errorf("Unexpected canonical URI %q", got)
+	}
+}
@@ -79,6 +79,7 @@ func (c *CachedLLMClient) GenerateCompletion(
 
 	if c.memoryCache != nil {
 		if cached, found := c.memoryCache.Get(cacheKey); found {
+			markCacheHit(ctx)
 			return cached.Response, nil
 		}
 	}
@@ -88,6 +89,7 @@ func (c *CachedLLMClient) GenerateCompletion(
 			if c.memoryCache != nil {
 				c.memoryCache.Put(cacheKey, cached)
 			}
+			markCacheHit(ctx)
 			return cached.Response, nil
 		}
 	}
@@ -97,3 +97,35 @@ func (b *BaseLLMClient) doHTTPRequest(
 
 	return resp, nil
 }
+
+// toolCallIDs gives every tool call of a conversation an ID and returns, by
+// message index, the IDs of the calls and the ID each tool result answers.
+// Messages only name the tool, so a result answers the first unanswered call
+// of its tool in the preceding assistant message.
+func toolCallIDs(messages []Message) (map[int][]string, map[int]string) {
+	calls := make(map[int][]string)
+	results := make(map[int]string)
+
+	type pendingCall struct{ id, name string }
+	var pending []pendingCall
+	for i, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			pending = nil
+			for j, tc := range msg.ToolCalls {
+				id := fmt.Sprintf("call_%d_%d", i, j)
+				calls[i] = append(calls[i], id)
+				pending = append(pending, pendingCall{id: id, name: tc.Name})
+			}
+		case "tool":
+			for j, call := range pending {
+				if call.name == msg.ToolID {
+					results[i] = call.id
+					pending = append(pending[:j], pending[j+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return calls, results
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// maxEventStreamMessage bounds a single event-stream message (16 MB, as AWS does)
+const maxEventStreamMessage = 16 * 1024 * 1024
+
+// eventStreamMessage is one message of the AWS event-stream encoding
+// (application/vnd.amazon.eventstream) used by Bedrock streaming APIs
+type eventStreamMessage struct {
+	Headers map[string]string // String-valued headers, e.g. ":event-type"
+	Payload []byte
+}
+
+// eventStreamDecoder reads event-stream messages. Each message is a prelude
+// (total length, headers length, prelude CRC), the headers, the payload and
+// a CRC of the whole message.
+type eventStreamDecoder struct {
+	reader io.Reader
+}
+
+func newEventStreamDecoder(reader io.Reader) *eventStreamDecoder {
+	return &eventStreamDecoder{reader: reader}
+}
+
+// Next reads the next message. It returns io.EOF at the end of the stream.
+func (d *eventStreamDecoder) Next() (eventStreamMessage, error) {
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(d.reader, prelude); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return eventStreamMessage{}, fmt.Errorf("stream ended mid-message: %w", err)
+		}
+		return eventStreamMessage{}, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return eventStreamMessage{}, fmt.Errorf("event stream prelude checksum mismatch")
+	}
+	if totalLength < 16 || totalLength > maxEventStreamMessage || headersLength > totalLength-16 {
+		return eventStreamMessage{}, fmt.Errorf("invalid event stream message length %d", totalLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(d.reader, message[12:]); err != nil {
+		return eventStreamMessage{}, fmt.Errorf("stream ended mid-message: %w", err)
+	}
+	crcOffset := totalLength - 4
+	if crc32.ChecksumIEEE(message[:crcOffset]) != binary.BigEndian.Uint32(message[crcOffset:]) {
+		return eventStreamMessage{}, fmt.Errorf("event stream message checksum mismatch")
+	}
+
+	headers, err := parseEventStreamHeaders(message[12 : 12+headersLength])
+	if err != nil {
+		return eventStreamMessage{}, err
+	}
+	return eventStreamMessage{Headers: headers, Payload: message[12+headersLength : crcOffset]}, nil
+}
+
+// eventStreamValueSizes are the sizes of the fixed-size header value types, by type
+var eventStreamValueSizes = map[byte]int{0: 0, 1: 0, 2: 1, 3: 2, 4: 4, 5: 8, 8: 8, 9: 16}
+
+// parseEventStreamHeaders returns the string headers; other types are skipped
+func parseEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, fmt.Errorf("truncated event stream header")
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		switch valueType {
+		case 6, 7: // Byte array, string
+			if len(data) < 2 {
+				return nil, fmt.Errorf("truncated event stream header %s", name)
+			}
+			length := int(binary.BigEndian.Uint16(data[:2]))
+			if len(data) < 2+length {
+				return nil, fmt.Errorf("truncated event stream header %s", name)
+			}
+			if valueType == 7 {
+				headers[name] = string(data[2 : 2+length])
+			}
+			data = data[2+length:]
+		default:
+			size, ok := eventStreamValueSizes[valueType]
+			if !ok || len(data) < size {
+				return nil, fmt.Errorf("invalid event stream header %s", name)
+			}
+			data = data[size:]
+		}
+	}
+	return headers, nil
+}
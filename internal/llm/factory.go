@@ -15,6 +15,7 @@ type Factory struct {
 	diskCache    *llmcache.DiskCache
 	cacheEnabled bool
 	cacheTTL     time.Duration
+	usageLedger  *UsageLedger
 }
 
 // NewFactory creates a new LLM factory
@@ -29,9 +30,34 @@ func NewFactory(retryClient *RetryClient, memoryCache *llmcache.LRUCache, diskCa
 	}
 }
 
+// SetUsageLedger records the usage of all clients created afterwards in ledger
+func (f *Factory) SetUsageLedger(ledger *UsageLedger) {
+	f.usageLedger = ledger
+}
+
 // CreateClient creates an LLM client based on the provider configuration
-// If caching is enabled and cache instances are available, wraps the client with caching
+// If caching is enabled and cache instances are available, wraps the client with caching.
+// With fallbacks configured, the client fails over between the providers in order.
 func (f *Factory) CreateClient(cfg config.LLMConfig) (LLMClient, error) {
+	backends := cfg.Backends()
+	if len(backends) == 1 {
+		return f.createBackend(backends[0])
+	}
+
+	fallbackBackends := make([]FallbackBackend, len(backends))
+	for i, backend := range backends {
+		client, err := f.createBackend(backend)
+		if err != nil {
+			return nil, err
+		}
+		fallbackBackends[i] = FallbackBackend{Client: client, Provider: backend.Provider, Model: backend.Model}
+	}
+	return NewFallbackClient(fallbackBackends), nil
+}
+
+// createBackend creates the client of a single provider, with its own cache
+// and usage tracking so the ledger records which backend served each call
+func (f *Factory) createBackend(cfg config.LLMConfig) (LLMClient, error) {
 	// Create base client (without caching)
 	var baseClient LLMClient
 	switch cfg.Provider {
@@ -41,20 +67,31 @@ func (f *Factory) CreateClient(cfg config.LLMConfig) (LLMClient, error) {
 		baseClient = NewAnthropicClient(cfg, f.retryClient)
 	case "gemini":
 		baseClient = NewGeminiClient(cfg, f.retryClient)
+	case "azure":
+		baseClient = NewAzureOpenAIClient(cfg, f.retryClient)
+	case "bedrock":
+		baseClient = NewBedrockClient(cfg, f.retryClient)
 	case "ollama", "lmstudio":
 		baseClient = NewOpenAIClient(cfg, f.retryClient)
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: openai, anthropic, gemini, ollama, lmstudio)", cfg.Provider)
+		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: openai, anthropic, gemini, azure, bedrock, ollama, lmstudio)", cfg.Provider)
 	}
 
+	client := baseClient
+
 	// Wrap with caching if enabled and cache instances are available
 	if f.cacheEnabled && f.memoryCache != nil {
 		ttl := f.cacheTTL
 		if ttl == 0 {
 			ttl = llmcache.DefaultTTL
 		}
-		return NewCachedLLMClient(baseClient, f.memoryCache, f.diskCache, true, ttl), nil
+		client = NewCachedLLMClient(baseClient, f.memoryCache, f.diskCache, true, ttl)
+	}
+
+	// Usage tracking wraps the cache so cache hits are recorded too
+	if f.usageLedger != nil {
+		client = NewUsageTrackingClient(client, f.usageLedger, cfg.Provider, cfg.Model)
 	}
 
-	return baseClient, nil
+	return client, nil
 }
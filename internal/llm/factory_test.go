@@ -22,6 +22,8 @@ func TestFactory_CreateClient_AllProviders(t *testing.T) {
 		{"openai", "openai", false},
 		{"anthropic", "anthropic", false},
 		{"gemini", "gemini", false},
+		{"azure", "azure", false},
+		{"bedrock", "bedrock", false},
 		{"ollama", "ollama", false},
 		{"lmstudio", "lmstudio", false},
 		{"unsupported", "unsupported", true},
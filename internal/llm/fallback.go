@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fallbackCooldown is how long a backend that failed over is skipped, so that
+// a sustained outage does not cost every call the full retry budget
+const fallbackCooldown = 5 * time.Minute
+
+// geminiSkipSignature is the thought signature Gemini accepts on function calls
+// it did not produce, e.g. those of a conversation started on another provider
+const geminiSkipSignature = "skip_thought_signature_validator"
+
+// FallbackBackend is one provider/model of a FallbackClient
+type FallbackBackend struct {
+	Client   LLMClient
+	Provider string
+	Model    string
+}
+
+// FallbackClient sends each completion to the first available backend and
+// fails over to the next one when a backend is overloaded, rate limited or
+// out of quota. It is safe for concurrent use.
+type FallbackClient struct {
+	backends []FallbackBackend
+
+	mu        sync.Mutex
+	coolUntil []time.Time
+	served    map[string]int // Calls served, keyed by provider/model
+	now       func() time.Time
+}
+
+// NewFallbackClient creates a client that tries backends in order
+func NewFallbackClient(backends []FallbackBackend) *FallbackClient {
+	return &FallbackClient{
+		backends:  backends,
+		coolUntil: make([]time.Time, len(backends)),
+		served:    make(map[string]int),
+		now:       time.Now,
+	}
+}
+
+// GenerateCompletion tries each backend in order, skipping those cooling down
+// after a failover unless no other backend is left
+func (c *FallbackClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	var errs []string
+	for _, i := range c.order() {
+		backend := c.backends[i]
+		resp, err := backend.Client.GenerateCompletion(ctx, adaptRequest(req, backend.Provider))
+		if err == nil {
+			c.mu.Lock()
+			c.served[backend.Provider+"/"+backend.Model]++
+			c.mu.Unlock()
+			return resp, nil
+		}
+		if ctx.Err() != nil || !isFailoverError(err) {
+			return CompletionResponse{}, err
+		}
+
+		c.mu.Lock()
+		c.coolUntil[i] = c.now().Add(fallbackCooldown)
+		c.mu.Unlock()
+		errs = append(errs, fmt.Sprintf("%s/%s: %v", backend.Provider, backend.Model, err))
+	}
+	return CompletionResponse{}, fmt.Errorf("all LLM backends failed: %s", strings.Join(errs, "; "))
+}
+
+// order returns the backends to try: available ones first, then those cooling down
+func (c *FallbackClient) order() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	var available, cooling []int
+	for i := range c.backends {
+		if now.Before(c.coolUntil[i]) {
+			cooling = append(cooling, i)
+		} else {
+			available = append(available, i)
+		}
+	}
+	return append(available, cooling...)
+}
+
+// Served returns the number of calls each backend served, keyed by provider/model
+func (c *FallbackClient) Served() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	served := make(map[string]int, len(c.served))
+	for backend, calls := range c.served {
+		served[backend] = calls
+	}
+	return served
+}
+
+// SupportsTools returns true only if every backend supports tools, since a
+// conversation may move between them
+func (c *FallbackClient) SupportsTools() bool {
+	for _, backend := range c.backends {
+		if !backend.Client.SupportsTools() {
+			return false
+		}
+	}
+	return true
+}
+
+// GetProvider returns the provider of the primary backend
+func (c *FallbackClient) GetProvider() string {
+	return c.backends[0].Client.GetProvider()
+}
+
+// adaptRequest makes a conversation valid for provider when it was started on
+// another one. Gemini requires a thought signature on the function calls it is
+// sent back, which calls made by other providers lack; other providers must not
+// receive Gemini's raw function calls, whose arguments may differ from Arguments.
+func adaptRequest(req CompletionRequest, provider string) CompletionRequest {
+	messages := make([]Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = msg
+		if len(msg.ToolCalls) == 0 {
+			continue
+		}
+		toolCalls := make([]ToolCall, len(msg.ToolCalls))
+		for j, tc := range msg.ToolCalls {
+			if provider == "gemini" {
+				if tc.RawFunctionCall == nil && tc.ThoughtSignature == "" {
+					tc.ThoughtSignature = geminiSkipSignature
+				}
+			} else {
+				tc.RawFunctionCall = nil
+				tc.ThoughtSignature = ""
+			}
+			toolCalls[j] = tc
+		}
+		messages[i].ToolCalls = toolCalls
+	}
+	req.Messages = messages
+	return req
+}
+
+var statusPattern = regexp.MustCompile(`status (\d{3})`)
+
+// failoverMessages mark provider errors worth another backend: overload,
+// rate limits and exhausted quota or credit
+var failoverMessages = []string{
+	"overloaded", "rate limit", "rate_limit", "quota", "resource_exhausted", "resource exhausted",
+	"credit balance", "insufficient", "capacity", "unavailable", "timeout", "throttl",
+}
+
+// isFailoverError reports whether another backend may succeed where err
+// occurred. Invalid requests and authentication errors are not retried elsewhere.
+func isFailoverError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	text := strings.ToLower(err.Error())
+	if match := statusPattern.FindStringSubmatch(text); match != nil {
+		// A status settles it: the messages of e.g. a 400 or 401 may mention a
+		// quota or a timeout without another backend doing any better
+		status, _ := strconv.Atoi(match[1])
+		return status == 429 || status == 402 || status == 408 || status >= 500
+	}
+	for _, message := range failoverMessages {
+		if strings.Contains(text, message) {
+			return true
+		}
+	}
+	// Connection errors surface from the HTTP client before any status
+	return strings.Contains(text, "request failed")
+}
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+func TestFactory_CreateClient_FailsOverMidConversation(t *testing.T) {
+	anthropicCalls := 0
+	anthropicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anthropicCalls++
+		w.WriteHeader(529)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	}))
+	defer anthropicServer.Close()
+
+	var openaiRequest openaiRequest
+	openaiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&openaiRequest)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintln(w, `data: {"choices":[{"index":0,"delta":{"content":"done"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`)
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: [DONE]")
+		_, _ = fmt.Fprintln(w)
+	}))
+	defer openaiServer.Close()
+
+	retryClient := NewRetryClient(&RetryConfig{
+		MaxAttempts:       2,
+		Multiplier:        1,
+		MaxWaitPerAttempt: 10 * time.Millisecond,
+		MaxTotalWait:      100 * time.Millisecond,
+	})
+	factory := NewFactory(retryClient, nil, nil, false, 0)
+	ledger := NewUsageLedger(nil)
+	factory.SetUsageLedger(ledger)
+
+	client, err := factory.CreateClient(config.LLMConfig{
+		Provider: "anthropic",
+		Model:    "claude-sonnet-4",
+		APIKey:   "anthropic-key",
+		BaseURL:  anthropicServer.URL,
+		Fallbacks: []config.LLMFallback{
+			{Provider: "openai", Model: "gpt-4o", APIKey: "openai-key", BaseURL: openaiServer.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fallback, ok := client.(*FallbackClient)
+	if !ok {
+		t.Fatalf("expected *FallbackClient, got %T", client)
+	}
+
+	// A conversation whose tool calls were made by the primary provider
+	req := CompletionRequest{Messages: []Message{
+		{Role: "user", Content: "analyze"},
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{Name: "list_files", Arguments: map[string]interface{}{"path": "."}},
+			{Name: "read_file", Arguments: map[string]interface{}{"file_path": "main.go"}},
+		}},
+		{Role: "tool", ToolID: "list_files", Content: "main.go"},
+		{Role: "tool", ToolID: "read_file", Content: "package main"},
+	}}
+
+	resp, err := client.GenerateCompletion(WithUsageScope(context.Background(), "StructureAnalyzer", 2), req)
+	if err != nil {
+		t.Fatalf("expected the fallback to serve the call, got %v", err)
+	}
+	if resp.Content != "done" {
+		t.Errorf("expected the fallback response, got %q", resp.Content)
+	}
+
+	// Tool results reference the calls they answer
+	messages := openaiRequest.Messages
+	if len(messages) != 4 || len(messages[1].ToolCalls) != 2 {
+		t.Fatalf("expected the tool calls to be sent to the fallback, got %+v", messages)
+	}
+	if messages[2].ToolCallID != messages[1].ToolCalls[0].ID || messages[3].ToolCallID != messages[1].ToolCalls[1].ID {
+		t.Errorf("expected tool results to reference their calls, got %+v", messages)
+	}
+	if messages[1].ToolCalls[1].Function.Arguments != `{"file_path":"main.go"}` {
+		t.Errorf("unexpected tool call arguments: %s", messages[1].ToolCalls[1].Function.Arguments)
+	}
+
+	records := ledger.Records()
+	if len(records) != 1 || records[0].Provider != "openai" || records[0].Model != "gpt-4o" {
+		t.Errorf("expected the call recorded under the fallback, got %+v", records)
+	}
+	if served := fallback.Served(); served["openai/gpt-4o"] != 1 || served["anthropic/claude-sonnet-4"] != 0 {
+		t.Errorf("unexpected served calls: %+v", served)
+	}
+
+	// The overloaded provider cools down instead of being retried on every call
+	calls := anthropicCalls
+	if _, err := client.GenerateCompletion(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if anthropicCalls != calls {
+		t.Errorf("expected the overloaded provider to be skipped, got %d more calls", anthropicCalls-calls)
+	}
+}
+
+func TestFallbackClient_DoesNotFailOverOnInvalidRequests(t *testing.T) {
+	primary := &mockLLMClient{provider: "anthropic", error: errors.New("API error: status 400, body: invalid request")}
+	secondary := &mockLLMClient{provider: "openai", response: CompletionResponse{Content: "ok"}}
+	client := NewFallbackClient([]FallbackBackend{
+		{Client: primary, Provider: "anthropic", Model: "claude-sonnet-4"},
+		{Client: secondary, Provider: "openai", Model: "gpt-4o"},
+	})
+
+	if _, err := client.GenerateCompletion(context.Background(), CompletionRequest{}); err == nil {
+		t.Fatal("expected the primary error")
+	}
+	if secondary.callCount != 0 {
+		t.Errorf("expected no failover on an invalid request, got %d calls", secondary.callCount)
+	}
+}
+
+func TestFallbackClient_CooldownExpires(t *testing.T) {
+	primary := &mockLLMClient{provider: "anthropic", error: errors.New("API error: Overloaded")}
+	secondary := &mockLLMClient{provider: "openai", response: CompletionResponse{Content: "ok"}}
+	client := NewFallbackClient([]FallbackBackend{
+		{Client: primary, Provider: "anthropic", Model: "claude-sonnet-4"},
+		{Client: secondary, Provider: "openai", Model: "gpt-4o"},
+	})
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GenerateCompletion(context.Background(), CompletionRequest{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if primary.callCount != 1 {
+		t.Errorf("expected the primary to be skipped while cooling down, got %d calls", primary.callCount)
+	}
+
+	now = now.Add(fallbackCooldown + time.Second)
+	primary.error = nil
+	if _, err := client.GenerateCompletion(context.Background(), CompletionRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.callCount != 2 {
+		t.Errorf("expected the primary to be tried again after the cooldown, got %d calls", primary.callCount)
+	}
+}
+
+func TestFallbackClient_AllBackendsFail(t *testing.T) {
+	client := NewFallbackClient([]FallbackBackend{
+		{Client: &mockLLMClient{error: errors.New("request failed with status 529 after 3 attempts")}, Provider: "anthropic", Model: "claude-sonnet-4"},
+		{Client: &mockLLMClient{error: errors.New("API error: You exceeded your current quota")}, Provider: "openai", Model: "gpt-4o"},
+	})
+
+	_, err := client.GenerateCompletion(context.Background(), CompletionRequest{})
+	if err == nil {
+		t.Fatal("expected an error when every backend fails")
+	}
+}
+
+func TestIsFailoverError(t *testing.T) {
+	tests := []struct {
+		err      error
+		failover bool
+	}{
+		{errors.New("request failed: request failed with status 529 after 3 attempts"), true},
+		{errors.New("request failed: request failed with status 429 after 3 attempts"), true},
+		{errors.New("API error: Overloaded"), true},
+		{errors.New("API error: status 402, body: payment required"), true},
+		{errors.New("API error: You exceeded your current quota, please check your plan"), true},
+		{errors.New("API error: Your credit balance is too low"), true},
+		{errors.New("request failed: request failed after 3 attempts: dial tcp: connection refused"), true},
+		{fmt.Errorf("stream: %w", context.DeadlineExceeded), true},
+		{errors.New("API error: status 400, body: invalid request"), false},
+		{errors.New("API error: status 400, body: max_tokens exceeds the model's capacity"), false},
+		{errors.New("API error: status 401, body: quota project not set"), false},
+		{errors.New("API error: invalid x-api-key"), false},
+		{fmt.Errorf("request failed: %w", context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		if got := isFailoverError(tt.err); got != tt.failover {
+			t.Errorf("isFailoverError(%q) = %v, want %v", tt.err, got, tt.failover)
+		}
+	}
+}
+
+func TestAdaptRequest(t *testing.T) {
+	req := CompletionRequest{Messages: []Message{
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{Name: "read_file", RawFunctionCall: map[string]interface{}{"name": "read_file"}, ThoughtSignature: "sig"},
+			{Name: "list_files"},
+		}},
+	}}
+
+	gemini := adaptRequest(req, "gemini").Messages[0].ToolCalls
+	if gemini[0].ThoughtSignature != "sig" || gemini[1].ThoughtSignature != geminiSkipSignature {
+		t.Errorf("expected Gemini's own signature kept and others' calls marked, got %+v", gemini)
+	}
+
+	anthropic := adaptRequest(req, "anthropic").Messages[0].ToolCalls
+	if anthropic[0].RawFunctionCall != nil || anthropic[0].ThoughtSignature != "" {
+		t.Errorf("expected Gemini fields stripped for other providers, got %+v", anthropic[0])
+	}
+
+	// The conversation itself is left untouched
+	if req.Messages[0].ToolCalls[0].ThoughtSignature != "sig" || req.Messages[0].ToolCalls[1].ThoughtSignature != "" {
+		t.Errorf("expected the original request unchanged, got %+v", req.Messages[0].ToolCalls)
+	}
+}
@@ -11,12 +11,21 @@ import (
 	"github.com/user/gendocs/internal/config"
 )
 
-// GeminiClient implements LLMClient for Google Gemini
+// GeminiClient implements LLMClient for Google Gemini, through the Gemini API
+// or Vertex AI
 type GeminiClient struct {
 	*BaseLLMClient
 	apiKey  string
 	model   string
 	baseURL string
+
+	// Vertex AI, when enabled
+	vertex   bool
+	project  string
+	location string
+	tokens   *googleTokenSource
+
+	cache *geminiPromptCache
 }
 
 // geminiRequest represents the request body for Gemini API
@@ -25,6 +34,7 @@ type geminiRequest struct {
 	Tools             []geminiTool           `json:"tools,omitempty"`
 	GenerationConfig  geminiGenerationConfig `json:"generationConfig,omitempty"`
 	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	CachedContent     string                 `json:"cachedContent,omitempty"` // Replaces the cached tools and leading contents
 }
 
 // geminiContent represents content in Gemini format
@@ -68,9 +78,10 @@ type geminiGenerationConfig struct {
 
 // geminiUsageMetadata represents token usage
 type geminiUsageMetadata struct {
-	PromptTokenCount     int `json:"promptTokenCount"`
-	CandidatesTokenCount int `json:"candidatesTokenCount"`
-	TotalTokenCount      int `json:"totalTokenCount"`
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount"` // Part of the prompt read from cachedContent
 }
 
 // geminiError represents an error
@@ -188,9 +199,10 @@ func (a *geminiAccumulator) Build() CompletionResponse {
 		Content:   a.textBuilder.String(),
 		ToolCalls: a.toolCalls,
 		Usage: TokenUsage{
-			InputTokens:  a.usage.PromptTokenCount,
-			OutputTokens: a.usage.CandidatesTokenCount,
-			TotalTokens:  a.usage.TotalTokenCount,
+			InputTokens:          a.usage.PromptTokenCount - a.usage.CachedContentTokenCount,
+			OutputTokens:         a.usage.CandidatesTokenCount,
+			TotalTokens:          a.usage.TotalTokenCount,
+			CacheReadInputTokens: a.usage.CachedContentTokenCount,
 		},
 	}
 }
@@ -200,42 +212,107 @@ func (a *geminiAccumulator) IsComplete() bool {
 	return a.complete
 }
 
-// NewGeminiClient creates a new Gemini client
+// NewGeminiClient creates a new Gemini client. With cfg.Gemini.UseVertexAI it
+// calls Vertex AI, authenticating with the configured Google credentials.
 func NewGeminiClient(cfg config.LLMConfig, retryClient *RetryClient) *GeminiClient {
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = "https://generativelanguage.googleapis.com"
-	}
-	return &GeminiClient{
+	client := &GeminiClient{
 		BaseLLMClient: NewBaseLLMClient(retryClient),
 		apiKey:        cfg.APIKey,
 		model:         cfg.Model,
-		baseURL:       baseURL,
+		baseURL:       cfg.BaseURL,
+		cache:         newGeminiPromptCache(),
+	}
+
+	if cfg.Gemini.UseVertexAI {
+		vertex := cfg.Gemini.WithEnvDefaults()
+		client.vertex = true
+		client.project = vertex.ProjectID
+		client.location = vertex.Location
+		client.tokens = newGoogleTokenSource(vertex.CredentialsFile, client.retryClient.Do)
+		if client.baseURL == "" {
+			client.baseURL = vertexBaseURL(vertex.Location)
+		}
+	} else if client.baseURL == "" {
+		client.baseURL = "https://generativelanguage.googleapis.com"
+	}
+	return client
+}
+
+// vertexBaseURL returns the regional Vertex AI endpoint of a location
+func vertexBaseURL(location string) string {
+	if location == "global" {
+		return "https://aiplatform.googleapis.com"
 	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)
 }
 
 // GenerateCompletion generates a completion from Gemini
 func (c *GeminiClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
 	gemReq := c.convertRequest(req)
+	cacheCreated := c.applyPromptCache(ctx, &gemReq)
 
-	modelName := c.model
-	if !strings.HasPrefix(modelName, "models/") {
-		modelName = "models/" + modelName
+	url, headers, err := c.endpoint(ctx, c.modelResource()+":streamGenerateContent")
+	if err != nil {
+		return CompletionResponse{}, err
 	}
-	url := fmt.Sprintf("%s/v1beta/%s:streamGenerateContent?key=%s", c.baseURL, modelName, c.apiKey)
 
-	resp, err := c.doHTTPRequest(ctx, "POST", url, nil, gemReq)
+	resp, err := c.doHTTPRequest(ctx, "POST", url, headers, gemReq)
 	if err != nil {
 		return CompletionResponse{}, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
+		c.checkAuthFailure(resp.StatusCode)
+		if gemReq.CachedContent != "" {
+			// The cache may have expired early; create a new one next time
+			c.cache.forget(gemReq.CachedContent)
+		}
 		body, _ := io.ReadAll(resp.Body)
 		return CompletionResponse{}, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	return c.parseStreamingResponse(resp.Body)
+	result, err := c.parseStreamingResponse(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	result.Usage.CacheCreationInputTokens = cacheCreated
+	result.Usage.TotalTokens += cacheCreated
+	return result, nil
+}
+
+// modelResource returns the resource name of the model
+func (c *GeminiClient) modelResource() string {
+	model := strings.TrimPrefix(c.model, "models/")
+	if c.vertex {
+		return fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s", c.project, c.location, model)
+	}
+	return "models/" + model
+}
+
+// endpoint returns the URL of an API resource, relative to the project and
+// location on Vertex AI, and the headers that authenticate the request
+func (c *GeminiClient) endpoint(ctx context.Context, resource string) (string, map[string]string, error) {
+	if !c.vertex {
+		return fmt.Sprintf("%s/v1beta/%s?key=%s", c.baseURL, resource, c.apiKey), nil, nil
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("vertex AI authentication failed: %w", err)
+	}
+	if !strings.HasPrefix(resource, "projects/") {
+		resource = fmt.Sprintf("projects/%s/locations/%s/%s", c.project, c.location, resource)
+	}
+	return fmt.Sprintf("%s/v1/%s", c.baseURL, resource), map[string]string{"Authorization": "Bearer " + token}, nil
+}
+
+// checkAuthFailure drops a Vertex AI token the API rejected, so the next
+// request fetches a new one
+func (c *GeminiClient) checkAuthFailure(statusCode int) {
+	if statusCode == http.StatusUnauthorized && c.vertex {
+		c.tokens.Invalidate()
+	}
 }
 
 // parseStreamingResponse parses Gemini's streaming response (JSON array format)
@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// geminiCacheTTL is how long a cachedContent lives. Agents call the model
+	// every few seconds, so a short TTL keeps storage costs low.
+	geminiCacheTTL = 5 * time.Minute
+
+	// geminiCacheMargin stops using a cache this long before it expires
+	geminiCacheMargin = 30 * time.Second
+
+	// geminiMinCacheTokens is the estimated number of uncached prompt tokens
+	// that triggers a new cache. It is above the minimum size Gemini models
+	// accept for cachedContent.
+	geminiMinCacheTokens = 4096
+)
+
+// geminiCachedPrefix is a cachedContent holding the tools and the leading
+// contents of a conversation
+type geminiCachedPrefix struct {
+	name     string // cachedContents/{id}
+	contents int    // Number of leading contents in the cache
+	hash     string // Hash of the tools and those contents
+	expires  time.Time
+}
+
+// geminiPromptCache tracks the cachedContents a client created. A request
+// whose tools and leading contents match a cached prefix sends only the rest.
+type geminiPromptCache struct {
+	mu       sync.Mutex
+	prefixes []geminiCachedPrefix
+	disabled bool // Set when the model or API refuses cachedContent
+	now      func() time.Time
+}
+
+func newGeminiPromptCache() *geminiPromptCache {
+	return &geminiPromptCache{now: time.Now}
+}
+
+// lookup returns the longest live prefix matching the prefix hashes of a request
+func (pc *geminiPromptCache) lookup(hashes []string) (geminiCachedPrefix, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	now := pc.now()
+	var best geminiCachedPrefix
+	found := false
+	live := pc.prefixes[:0]
+	for _, prefix := range pc.prefixes {
+		if !now.Before(prefix.expires) {
+			continue
+		}
+		live = append(live, prefix)
+		if prefix.contents < len(hashes)-1 && hashes[prefix.contents] == prefix.hash && prefix.contents > best.contents {
+			best, found = prefix, true
+		}
+	}
+	pc.prefixes = live
+	return best, found
+}
+
+func (pc *geminiPromptCache) add(prefix geminiCachedPrefix) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.prefixes = append(pc.prefixes, prefix)
+}
+
+// forget drops a cachedContent the API no longer accepts
+func (pc *geminiPromptCache) forget(name string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	for i, prefix := range pc.prefixes {
+		if prefix.name == name {
+			pc.prefixes = append(pc.prefixes[:i], pc.prefixes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (pc *geminiPromptCache) isDisabled() bool {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.disabled
+}
+
+func (pc *geminiPromptCache) disable() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.disabled = true
+}
+
+// prefixHashes returns, for each n, the hash of the tools and the first n contents
+func prefixHashes(tools []geminiTool, contents []geminiContent) []string {
+	hashes := make([]string, 0, len(contents)+1)
+	toolsJSON, _ := json.Marshal(tools)
+	sum := sha256.Sum256(toolsJSON)
+	hashes = append(hashes, hex.EncodeToString(sum[:]))
+	for _, content := range contents {
+		contentJSON, _ := json.Marshal(content)
+		sum = sha256.Sum256(append(sum[:], contentJSON...))
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+	}
+	return hashes
+}
+
+// applyPromptCache points the request at a cachedContent holding its tools and
+// leading contents, creating or extending one once enough uncached history has
+// accumulated. The last content is always sent, and the system prompt is part
+// of the contents. It returns the number of tokens written to a new cache.
+// Caching failures only cost the savings; the request is sent uncached.
+func (c *GeminiClient) applyPromptCache(ctx context.Context, gemReq *geminiRequest) int {
+	if len(gemReq.Contents) < 2 || c.cache.isDisabled() {
+		return 0
+	}
+
+	hashes := prefixHashes(gemReq.Tools, gemReq.Contents)
+	prefix, cached := c.cache.lookup(hashes)
+
+	last := len(gemReq.Contents) - 1
+	uncached := gemReq.Contents[prefix.contents:last]
+	var created int
+	if estimateGeminiTokens(gemReq.Tools, uncached, !cached) >= geminiMinCacheTokens {
+		if newPrefix, tokens, err := c.createCachedContent(ctx, gemReq, last, hashes[last]); err == nil {
+			prefix, cached, created = newPrefix, true, tokens
+		}
+	}
+
+	if cached {
+		gemReq.CachedContent = prefix.name
+		gemReq.Contents = gemReq.Contents[prefix.contents:]
+		gemReq.Tools = nil
+	}
+	return created
+}
+
+// estimateGeminiTokens estimates the prompt tokens of contents, and of the tools if included
+func estimateGeminiTokens(tools []geminiTool, contents []geminiContent, includeTools bool) int {
+	size := 0
+	if includeTools {
+		toolsJSON, _ := json.Marshal(tools)
+		size += len(toolsJSON)
+	}
+	contentsJSON, _ := json.Marshal(contents)
+	size += len(contentsJSON)
+	return size / 4
+}
+
+// geminiCachedContent is the cachedContents resource
+type geminiCachedContent struct {
+	Name          string               `json:"name,omitempty"`
+	Model         string               `json:"model,omitempty"`
+	Contents      []geminiContent      `json:"contents,omitempty"`
+	Tools         []geminiTool         `json:"tools,omitempty"`
+	TTL           string               `json:"ttl,omitempty"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// createCachedContent caches the tools and the first n contents of a request
+func (c *GeminiClient) createCachedContent(ctx context.Context, gemReq *geminiRequest, n int, hash string) (geminiCachedPrefix, int, error) {
+	url, headers, err := c.endpoint(ctx, "cachedContents")
+	if err != nil {
+		return geminiCachedPrefix{}, 0, err
+	}
+
+	expires := c.cache.now().Add(geminiCacheTTL - geminiCacheMargin)
+	resp, err := c.doHTTPRequest(ctx, "POST", url, headers, geminiCachedContent{
+		Model:    c.modelResource(),
+		Contents: gemReq.Contents[:n],
+		Tools:    gemReq.Tools,
+		TTL:      fmt.Sprintf("%ds", int(geminiCacheTTL.Seconds())),
+	})
+	if err != nil {
+		return geminiCachedPrefix{}, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		c.checkAuthFailure(resp.StatusCode)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusTooManyRequests {
+			// The model does not support caching or the prompt is below its minimum
+			c.cache.disable()
+		}
+		return geminiCachedPrefix{}, 0, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var cachedContent geminiCachedContent
+	if err := json.Unmarshal(body, &cachedContent); err != nil {
+		return geminiCachedPrefix{}, 0, fmt.Errorf("failed to parse cached content: %w", err)
+	}
+	if cachedContent.Name == "" {
+		return geminiCachedPrefix{}, 0, fmt.Errorf("cached content has no name")
+	}
+
+	prefix := geminiCachedPrefix{name: cachedContent.Name, contents: n, hash: hash, expires: expires}
+	c.cache.add(prefix)
+
+	tokens := 0
+	if cachedContent.UsageMetadata != nil {
+		tokens = cachedContent.UsageMetadata.TotalTokenCount
+	}
+	return prefix, tokens, nil
+}
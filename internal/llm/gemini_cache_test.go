@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// geminiCacheServer serves cachedContents and streamGenerateContent, recording the requests
+type geminiCacheServer struct {
+	*httptest.Server
+	created   []geminiCachedContent
+	generated []geminiRequest
+	createErr int // Status returned by cachedContents, when set
+}
+
+func newGeminiCacheServer() *geminiCacheServer {
+	s := &geminiCacheServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cachedContents") {
+			var cachedContent geminiCachedContent
+			_ = json.NewDecoder(r.Body).Decode(&cachedContent)
+			s.created = append(s.created, cachedContent)
+			if s.createErr != 0 {
+				w.WriteHeader(s.createErr)
+				_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Cached content is too small"}}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"name":"cachedContents/c%d","usageMetadata":{"totalTokenCount":6000}}`, len(s.created))
+			return
+		}
+
+		var req geminiRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.generated = append(s.generated, req)
+		cached := 0
+		if req.CachedContent != "" {
+			cached = 6000
+		}
+		usage, _ := json.Marshal(geminiUsageMetadata{PromptTokenCount: cached + 50, CandidatesTokenCount: 10, TotalTokenCount: cached + 60, CachedContentTokenCount: cached})
+		_, _ = w.Write([]byte(`[{"candidates":[{"content":{"parts":[{"text":"ok"}],"role":"model"},"finishReason":"STOP","index":0}],"usageMetadata":` + string(usage) + `}]`))
+	}))
+	return s
+}
+
+// largeConversation is a tool-loop request whose system prompt is big enough to cache
+func largeConversation(toolResults int) CompletionRequest {
+	req := CompletionRequest{
+		SystemPrompt: strings.Repeat("Analyze the code structure carefully. ", 600),
+		Messages:     []Message{{Role: "user", Content: "analyze"}},
+		Tools:        []ToolDefinition{{Name: "read_file", Parameters: map[string]interface{}{"type": "object"}}},
+	}
+	for i := 0; i < toolResults; i++ {
+		req.Messages = append(req.Messages,
+			Message{Role: "assistant", ToolCalls: []ToolCall{{Name: "read_file", Arguments: map[string]interface{}{"file_path": "main.go"}}}},
+			Message{Role: "tool", ToolID: "read_file", Content: "package main"},
+		)
+	}
+	return req
+}
+
+func TestGeminiClient_PromptCache_CreatesAndReusesPrefix(t *testing.T) {
+	server := newGeminiCacheServer()
+	defer server.Close()
+	client := NewGeminiClient(config.LLMConfig{APIKey: "test-key", BaseURL: server.URL, Model: "gemini-2.5-pro"}, nil)
+
+	// The first call caches the tools and every content but the last
+	resp, err := client.GenerateCompletion(context.Background(), largeConversation(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.created) != 1 || len(server.created[0].Tools) != 1 || server.created[0].Model != "models/gemini-2.5-pro" || server.created[0].TTL != "300s" {
+		t.Fatalf("expected one cachedContent with the tools, got %+v", server.created)
+	}
+	first := server.generated[0]
+	if first.CachedContent != "cachedContents/c1" || len(first.Contents) != 1 || first.Tools != nil {
+		t.Errorf("expected the request to send only the last content, got %+v", first)
+	}
+	if resp.Usage.CacheCreationInputTokens != 6000 || resp.Usage.CacheReadInputTokens != 6000 || resp.Usage.InputTokens != 50 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+
+	// The next iteration reuses the cached prefix and sends the new contents
+	resp, err = client.GenerateCompletion(context.Background(), largeConversation(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.created) != 1 {
+		t.Errorf("expected the cache to be reused, got %d creations", len(server.created))
+	}
+	second := server.generated[1]
+	cachedContents := len(server.created[0].Contents)
+	if second.CachedContent != "cachedContents/c1" || len(second.Contents) != len(client.convertRequest(largeConversation(2)).Contents)-cachedContents {
+		t.Errorf("expected the contents after the cached prefix, got %+v", second)
+	}
+	if resp.Usage.CacheCreationInputTokens != 0 || resp.Usage.CacheReadInputTokens != 6000 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+
+	// A different system prompt does not match the cached prefix
+	other := largeConversation(1)
+	other.SystemPrompt += "Focus on data flow."
+	if _, err := client.GenerateCompletion(context.Background(), other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.created) != 2 {
+		t.Errorf("expected a new cache for a different prompt, got %d creations", len(server.created))
+	}
+}
+
+func TestGeminiClient_PromptCache_Expires(t *testing.T) {
+	server := newGeminiCacheServer()
+	defer server.Close()
+	client := NewGeminiClient(config.LLMConfig{APIKey: "test-key", BaseURL: server.URL, Model: "gemini-2.5-pro"}, nil)
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+
+	_, _ = client.GenerateCompletion(context.Background(), largeConversation(1))
+	now = now.Add(geminiCacheTTL)
+	_, _ = client.GenerateCompletion(context.Background(), largeConversation(1))
+
+	if len(server.created) != 2 || server.generated[1].CachedContent != "cachedContents/c2" {
+		t.Errorf("expected an expired cache to be replaced, got %d creations", len(server.created))
+	}
+}
+
+func TestGeminiClient_PromptCache_DisabledWhenRefused(t *testing.T) {
+	server := newGeminiCacheServer()
+	server.createErr = http.StatusBadRequest
+	defer server.Close()
+	client := NewGeminiClient(config.LLMConfig{APIKey: "test-key", BaseURL: server.URL, Model: "gemini-2.0-flash-lite"}, nil)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.GenerateCompletion(context.Background(), largeConversation(1))
+		if err != nil || resp.Content != "ok" {
+			t.Fatalf("expected the request to be sent uncached, got %v", err)
+		}
+	}
+	if len(server.created) != 1 {
+		t.Errorf("expected no further cache attempts after a refusal, got %d", len(server.created))
+	}
+	if req := server.generated[1]; req.CachedContent != "" || len(req.Tools) != 1 {
+		t.Errorf("expected a full uncached request, got %+v", req)
+	}
+}
+
+func TestGeminiClient_PromptCache_SkipsSmallPrompts(t *testing.T) {
+	server := newGeminiCacheServer()
+	defer server.Close()
+	client := NewGeminiClient(config.LLMConfig{APIKey: "test-key", BaseURL: server.URL, Model: "gemini-2.5-pro"}, nil)
+
+	req := largeConversation(1)
+	req.SystemPrompt = "You are a code analyst"
+	if _, err := client.GenerateCompletion(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.created) != 0 || server.generated[0].CachedContent != "" {
+		t.Errorf("expected a small prompt to be sent uncached, got %d creations", len(server.created))
+	}
+}
@@ -0,0 +1,237 @@
+package llm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleTokenURL   = "https://oauth2.googleapis.com/token"
+	googleCloudScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// googleTokenLeeway refreshes tokens this long before they expire
+	googleTokenLeeway = time.Minute
+)
+
+// googleCredentials is a service account key or an application default
+// credentials file written by `gcloud auth application-default login`
+type googleCredentials struct {
+	Type string `json:"type"` // service_account or authorized_user
+
+	// Service account
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+
+	// Authorized user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// googleTokenSource issues OAuth access tokens for Google Cloud APIs. Tokens
+// are cached and refreshed shortly before they expire.
+type googleTokenSource struct {
+	credentialsFile string
+	do              func(*http.Request) (*http.Response, error)
+	now             func() time.Time
+
+	mu      sync.Mutex
+	creds   *googleCredentials
+	key     *rsa.PrivateKey
+	token   string
+	expires time.Time
+}
+
+// newGoogleTokenSource creates a token source for a credentials file. The file
+// is read on the first request for a token.
+func newGoogleTokenSource(credentialsFile string, do func(*http.Request) (*http.Response, error)) *googleTokenSource {
+	return &googleTokenSource{credentialsFile: credentialsFile, do: do, now: time.Now}
+}
+
+// Token returns a valid access token, fetching a new one when needed
+func (s *googleTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(googleTokenLeeway).Before(s.expires) {
+		return s.token, nil
+	}
+	if s.creds == nil {
+		if err := s.loadCredentials(); err != nil {
+			return "", err
+		}
+	}
+
+	var form url.Values
+	tokenURL := googleTokenURL
+	switch s.creds.Type {
+	case "service_account":
+		assertion, err := s.signJWT()
+		if err != nil {
+			return "", err
+		}
+		if s.creds.TokenURI != "" {
+			tokenURL = s.creds.TokenURI
+		}
+		form = url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+	case "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {s.creds.ClientID},
+			"client_secret": {s.creds.ClientSecret},
+			"refresh_token": {s.creds.RefreshToken},
+		}
+	}
+
+	token, expiresIn, err := s.exchange(ctx, tokenURL, form)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.expires = s.now().Add(time.Duration(expiresIn) * time.Second)
+	return s.token, nil
+}
+
+// Invalidate drops the cached token, e.g. after the API rejected it
+func (s *googleTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+func (s *googleTokenSource) loadCredentials() error {
+	data, err := os.ReadFile(s.credentialsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var creds googleCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return fmt.Errorf("failed to parse Google credentials %s: %w", s.credentialsFile, err)
+	}
+
+	switch creds.Type {
+	case "service_account":
+		key, err := parseRSAPrivateKey(creds.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("invalid service account key in %s: %w", s.credentialsFile, err)
+		}
+		s.key = key
+	case "authorized_user":
+		if creds.RefreshToken == "" {
+			return fmt.Errorf("no refresh token in %s", s.credentialsFile)
+		}
+	default:
+		return fmt.Errorf("unsupported Google credentials type %q in %s (expected service_account or authorized_user)", creds.Type, s.credentialsFile)
+	}
+	s.creds = &creds
+	return nil
+}
+
+// signJWT builds the RS256-signed assertion a service account exchanges for an access token
+func (s *googleTokenSource) signJWT() (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if s.creds.PrivateKeyID != "" {
+		header["kid"] = s.creds.PrivateKeyID
+	}
+	audience := s.creds.TokenURI
+	if audience == "" {
+		audience = googleTokenURL
+	}
+	now := s.now()
+	claims := map[string]interface{}{
+		"iss":   s.creds.ClientEmail,
+		"scope": googleCloudScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// exchange posts a token request and returns the access token and its lifetime in seconds
+func (s *googleTokenSource) exchange(ctx context.Context, tokenURL string, form url.Values) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", 0, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response has no access token")
+	}
+	return token.AccessToken, token.ExpiresIn, nil
+}
+
+// parseRSAPrivateKey parses a PEM-encoded PKCS#8 or PKCS#1 RSA key
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// writeServiceAccount writes a service account key file whose tokens are issued by tokenURL
+func writeServiceAccount(t *testing.T, key *rsa.PrivateKey, tokenURL string) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "sa-project",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "gendocs@sa-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// verifyJWT checks an RS256 assertion against the public key and returns its claims
+func verifyJWT(t *testing.T, assertion string, key *rsa.PublicKey) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed JWT %q", assertion)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("invalid JWT signature: %v", err)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	_ = json.Unmarshal(payload, &claims)
+	return claims
+}
+
+func TestGoogleTokenSource_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued := 0
+	var tokenServer *httptest.Server
+	tokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("unexpected grant type %q", r.Form.Get("grant_type"))
+		}
+		claims := verifyJWT(t, r.Form.Get("assertion"), &key.PublicKey)
+		if claims["iss"] != "gendocs@sa-project.iam.gserviceaccount.com" || claims["aud"] != tokenServer.URL || claims["scope"] != googleCloudScope {
+			t.Errorf("unexpected claims %+v", claims)
+		}
+		issued++
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"token_type":"Bearer"}`, issued)
+	}))
+	defer tokenServer.Close()
+
+	source := newGoogleTokenSource(writeServiceAccount(t, key, tokenServer.URL), http.DefaultClient.Do)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != "token-1" {
+			t.Errorf("expected the cached token, got %q", token)
+		}
+	}
+
+	// Tokens are refreshed shortly before they expire
+	now = now.Add(time.Hour - googleTokenLeeway/2)
+	if token, _ := source.Token(context.Background()); token != "token-2" {
+		t.Errorf("expected a refreshed token, got %q", token)
+	}
+	if issued != 2 {
+		t.Errorf("expected 2 token requests, got %d", issued)
+	}
+}
+
+func TestGoogleTokenSource_AuthorizedUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application_default_credentials.json")
+	_ = os.WriteFile(path, []byte(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh"}`), 0600)
+
+	var form map[string][]string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		form = r.Form
+		_, _ = w.Write([]byte(`{"access_token":"user-token","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	// Redirect the fixed Google token endpoint to the test server
+	source := newGoogleTokenSource(path, func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme, req.URL.Host = "http", strings.TrimPrefix(tokenServer.URL, "http://")
+		return http.DefaultClient.Do(req)
+	})
+
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "user-token" {
+		t.Errorf("unexpected token %q", token)
+	}
+	if form["grant_type"][0] != "refresh_token" || form["refresh_token"][0] != "refresh" || form["client_secret"][0] != "secret" {
+		t.Errorf("unexpected refresh request %+v", form)
+	}
+}
+
+func TestGoogleTokenSource_UnsupportedCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	_ = os.WriteFile(path, []byte(`{"type":"external_account"}`), 0600)
+
+	if _, err := newGoogleTokenSource(path, http.DefaultClient.Do).Token(context.Background()); err == nil || !strings.Contains(err.Error(), "external_account") {
+		t.Errorf("expected an unsupported credentials error, got %v", err)
+	}
+}
+
+func TestGeminiClient_VertexAI(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"vertex-token","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var path, authorization, apiKey string
+	vertexServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.URL.Query().Get("key")
+		_, _ = w.Write([]byte(`[{"candidates":[{"content":{"parts":[{"text":"hello from vertex"}],"role":"model"},"finishReason":"STOP","index":0}]}]`))
+	}))
+	defer vertexServer.Close()
+
+	client := NewGeminiClient(config.LLMConfig{
+		Model:   "gemini-2.5-pro",
+		BaseURL: vertexServer.URL,
+		Gemini: config.GeminiConfig{
+			UseVertexAI:     true,
+			Location:        "europe-west4",
+			CredentialsFile: writeServiceAccount(t, key, tokenServer.URL),
+		},
+	}, nil)
+
+	resp, err := client.GenerateCompletion(context.Background(), CompletionRequest{Messages: []Message{{Role: "user", Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Content != "hello from vertex" {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+
+	// The project comes from the service account key
+	if path != "/v1/projects/sa-project/locations/europe-west4/publishers/google/models/gemini-2.5-pro:streamGenerateContent" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if authorization != "Bearer vertex-token" || apiKey != "" {
+		t.Errorf("expected only the OAuth token, got authorization %q and key %q", authorization, apiKey)
+	}
+}
+
+func TestVertexBaseURL(t *testing.T) {
+	if got := vertexBaseURL("us-central1"); got != "https://us-central1-aiplatform.googleapis.com" {
+		t.Errorf("unexpected regional endpoint %q", got)
+	}
+	if got := vertexBaseURL("global"); got != "https://aiplatform.googleapis.com" {
+		t.Errorf("unexpected global endpoint %q", got)
+	}
+}
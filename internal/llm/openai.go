@@ -248,12 +248,16 @@ func NewOpenAIClient(cfg config.LLMConfig, retryClient *RetryClient) *OpenAIClie
 
 // GenerateCompletion generates a completion from OpenAI
 func (c *OpenAIClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
-	oaReq := c.convertRequest(req)
-
 	url := fmt.Sprintf("%s/chat/completions", c.baseURL)
 	headers := map[string]string{
 		"Authorization": fmt.Sprintf("Bearer %s", c.apiKey),
 	}
+	return c.streamCompletion(ctx, url, headers, req)
+}
+
+// streamCompletion sends a chat completion request to url and parses the streamed response
+func (c *OpenAIClient) streamCompletion(ctx context.Context, url string, headers map[string]string, req CompletionRequest) (CompletionResponse, error) {
+	oaReq := c.convertRequest(req)
 
 	resp, err := c.doHTTPRequest(ctx, "POST", url, headers, oaReq)
 	if err != nil {
@@ -324,11 +328,28 @@ func (c *OpenAIClient) convertRequest(req CompletionRequest) openaiRequest {
 	}
 
 	// Add messages
-	for _, msg := range req.Messages {
-		messages = append(messages, openaiMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+	callIDs, resultIDs := toolCallIDs(req.Messages)
+	for i, msg := range req.Messages {
+		oaMsg := openaiMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: resultIDs[i],
+		}
+		for j, tc := range msg.ToolCalls {
+			args := []byte("{}")
+			if tc.Arguments != nil {
+				args, _ = json.Marshal(tc.Arguments)
+			}
+			oaMsg.ToolCalls = append(oaMsg.ToolCalls, openaiToolCall{
+				ID:   callIDs[i][j],
+				Type: "function",
+				Function: openaiToolCallFunc{
+					Name:      tc.Name,
+					Arguments: string(args),
+				},
+			})
+		}
+		messages = append(messages, oaMsg)
 	}
 
 	oaReq := openaiRequest{
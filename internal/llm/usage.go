@@ -0,0 +1,424 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+)
+
+// UsageHistoryFile is the per-repository history of run usage
+const UsageHistoryFile = ".ai/usage.json"
+
+// maxUsageHistoryRuns bounds the history file; older runs are dropped
+const maxUsageHistoryRuns = 100
+
+// PriceTable maps model names, or model name prefixes, to prices
+type PriceTable map[string]config.ModelPrice
+
+// defaultPrices are list prices in USD per million tokens. Local providers
+// (ollama, lmstudio) are not listed and are reported as unpriced.
+var defaultPrices = PriceTable{
+	"gpt-4o":            {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+	"gpt-4.1":           {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":      {Input: 0.10, Output: 0.40},
+	"gpt-4-turbo":       {Input: 10.00, Output: 30.00},
+	"gpt-4":             {Input: 30.00, Output: 60.00},
+	"gpt-3.5-turbo":     {Input: 0.50, Output: 1.50},
+	"o3-mini":           {Input: 1.10, Output: 4.40},
+	"claude-opus-4":     {Input: 15.00, Output: 75.00},
+	"claude-sonnet-4":   {Input: 3.00, Output: 15.00},
+	"claude-3-7-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4.00},
+	"claude-3-opus":     {Input: 15.00, Output: 75.00},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"gemini-2.5-pro":    {Input: 1.25, Output: 10.00, CacheWrite: 1.25, CacheRead: 0.31},
+	"gemini-2.5-flash":  {Input: 0.30, Output: 2.50, CacheWrite: 0.30, CacheRead: 0.075},
+	"gemini-2.0-flash":  {Input: 0.10, Output: 0.40, CacheWrite: 0.10, CacheRead: 0.025},
+	"gemini-1.5-pro":    {Input: 1.25, Output: 5.00, CacheWrite: 1.25, CacheRead: 0.3125},
+	"gemini-1.5-flash":  {Input: 0.075, Output: 0.30, CacheWrite: 0.075, CacheRead: 0.01875},
+}
+
+// NewPriceTable returns the built-in prices with the configured overrides applied
+func NewPriceTable(overrides map[string]config.ModelPrice) PriceTable {
+	table := make(PriceTable, len(defaultPrices)+len(overrides))
+	for model, price := range defaultPrices {
+		table[model] = price
+	}
+	for model, price := range overrides {
+		table[strings.ToLower(model)] = price
+	}
+	return table
+}
+
+// Lookup returns the price of a model. An exact match wins, otherwise the
+// longest matching prefix is used (e.g. "gpt-4o-2024-08-06" uses "gpt-4o").
+func (t PriceTable) Lookup(model string) (config.ModelPrice, bool) {
+	model = strings.ToLower(model)
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	var best string
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return config.ModelPrice{}, false
+	}
+	return t[best], true
+}
+
+// Cost estimates the cost in USD of the given token counts
+func (t PriceTable) Cost(model string, inputTokens, outputTokens int) (float64, bool) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1_000_000, true
+}
+
+// UsageCost estimates the cost in USD of a call, pricing prompt cache writes and reads
+func (t PriceTable) UsageCost(model string, usage TokenUsage) (float64, bool) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	cacheWrite, cacheRead := price.CacheWrite, price.CacheRead
+	if cacheWrite == 0 {
+		cacheWrite = price.Input * 1.25
+	}
+	if cacheRead == 0 {
+		cacheRead = price.Input * 0.1
+	}
+	return (float64(usage.InputTokens)*price.Input +
+		float64(usage.CacheCreationInputTokens)*cacheWrite +
+		float64(usage.CacheReadInputTokens)*cacheRead +
+		float64(usage.OutputTokens)*price.Output) / 1_000_000, true
+}
+
+// UsageRecord is the usage of a single LLM call
+type UsageRecord struct {
+	Agent     string
+	Provider  string
+	Model     string
+	Iteration int // Tool-loop iteration of the agent, starting at 1
+	Usage     TokenUsage
+	CacheHit  bool // Served from the response cache; not billed
+}
+
+// UsageTotals aggregates usage records
+type UsageTotals struct {
+	Calls        int     `json:"calls"`
+	CacheHits    int     `json:"cache_hits"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	CachedTokens int     `json:"cached_tokens"` // Tokens served from the response cache
+	Cost         float64 `json:"estimated_cost_usd"`
+
+	// Prompt tokens written to and read from the provider's prompt cache
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// UsageSummary is the aggregated usage of a run
+type UsageSummary struct {
+	UsageTotals
+	ByAgent    map[string]UsageTotals   `json:"by_agent"`
+	ByModel    map[string]UsageTotals   `json:"by_model"`   // Keyed by provider/model
+	Iterations map[string][]UsageTotals `json:"iterations"` // Per agent, indexed by tool-loop iteration
+	Unpriced   []string                 `json:"unpriced_models,omitempty"`
+}
+
+func (t *UsageTotals) add(record UsageRecord, cost float64) {
+	t.Calls++
+	if record.CacheHit {
+		t.CacheHits++
+		t.CachedTokens += record.Usage.TotalTokens
+		return
+	}
+	t.InputTokens += record.Usage.InputTokens
+	t.OutputTokens += record.Usage.OutputTokens
+	t.TotalTokens += record.Usage.TotalTokens
+	t.CacheCreationInputTokens += record.Usage.CacheCreationInputTokens
+	t.CacheReadInputTokens += record.Usage.CacheReadInputTokens
+	t.Cost += cost
+}
+
+// Merge adds the usage of another summary, prefixing its agent names with
+// agentPrefix (e.g. the module of a monorepo run)
+func (s *UsageSummary) Merge(other UsageSummary, agentPrefix string) {
+	if s.ByAgent == nil {
+		s.ByAgent = make(map[string]UsageTotals)
+	}
+	if s.ByModel == nil {
+		s.ByModel = make(map[string]UsageTotals)
+	}
+	if s.Iterations == nil {
+		s.Iterations = make(map[string][]UsageTotals)
+	}
+
+	s.UsageTotals.merge(other.UsageTotals)
+	for agent, totals := range other.ByAgent {
+		merged := s.ByAgent[agentPrefix+agent]
+		merged.merge(totals)
+		s.ByAgent[agentPrefix+agent] = merged
+	}
+	for model, totals := range other.ByModel {
+		merged := s.ByModel[model]
+		merged.merge(totals)
+		s.ByModel[model] = merged
+	}
+	for agent, iterations := range other.Iterations {
+		s.Iterations[agentPrefix+agent] = append(s.Iterations[agentPrefix+agent], iterations...)
+	}
+	for _, model := range other.Unpriced {
+		if !slices.Contains(s.Unpriced, model) {
+			s.Unpriced = append(s.Unpriced, model)
+		}
+	}
+	sort.Strings(s.Unpriced)
+}
+
+func (t *UsageTotals) merge(other UsageTotals) {
+	t.Calls += other.Calls
+	t.CacheHits += other.CacheHits
+	t.InputTokens += other.InputTokens
+	t.OutputTokens += other.OutputTokens
+	t.TotalTokens += other.TotalTokens
+	t.CachedTokens += other.CachedTokens
+	t.CacheCreationInputTokens += other.CacheCreationInputTokens
+	t.CacheReadInputTokens += other.CacheReadInputTokens
+	t.Cost += other.Cost
+}
+
+// UsageLedger records the usage of every LLM call made through a Factory.
+// It is safe for concurrent use.
+type UsageLedger struct {
+	mu      sync.Mutex
+	prices  PriceTable
+	records []UsageRecord
+}
+
+// NewUsageLedger creates a ledger that prices calls with the given table
+func NewUsageLedger(prices PriceTable) *UsageLedger {
+	if prices == nil {
+		prices = NewPriceTable(nil)
+	}
+	return &UsageLedger{prices: prices}
+}
+
+// Record adds a call to the ledger
+func (l *UsageLedger) Record(record UsageRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+}
+
+// Records returns a copy of all recorded calls
+func (l *UsageLedger) Records() []UsageRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]UsageRecord(nil), l.records...)
+}
+
+// Summary aggregates the recorded calls per agent, model and iteration
+func (l *UsageLedger) Summary() UsageSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	summary := UsageSummary{
+		ByAgent:    make(map[string]UsageTotals),
+		ByModel:    make(map[string]UsageTotals),
+		Iterations: make(map[string][]UsageTotals),
+	}
+	unpriced := make(map[string]bool)
+
+	for _, record := range l.records {
+		cost, priced := l.prices.UsageCost(record.Model, record.Usage)
+		modelKey := record.Provider + "/" + record.Model
+		if !priced && !record.CacheHit {
+			unpriced[modelKey] = true
+		}
+
+		summary.add(record, cost)
+
+		agent := summary.ByAgent[record.Agent]
+		agent.add(record, cost)
+		summary.ByAgent[record.Agent] = agent
+
+		model := summary.ByModel[modelKey]
+		model.add(record, cost)
+		summary.ByModel[modelKey] = model
+
+		if record.Iteration > 0 {
+			iterations := summary.Iterations[record.Agent]
+			for len(iterations) < record.Iteration {
+				iterations = append(iterations, UsageTotals{})
+			}
+			iterations[record.Iteration-1].add(record, cost)
+			summary.Iterations[record.Agent] = iterations
+		}
+	}
+
+	for model := range unpriced {
+		summary.Unpriced = append(summary.Unpriced, model)
+	}
+	sort.Strings(summary.Unpriced)
+
+	return summary
+}
+
+// String renders the totals on one line, e.g. for progress summaries
+func (t UsageTotals) String() string {
+	text := fmt.Sprintf("Tokens: %d in / %d out", t.InputTokens, t.OutputTokens)
+	if t.CacheReadInputTokens > 0 || t.CacheCreationInputTokens > 0 {
+		text += fmt.Sprintf(" | Prompt cache: %d read / %d written", t.CacheReadInputTokens, t.CacheCreationInputTokens)
+	}
+	if t.CacheHits > 0 {
+		text += fmt.Sprintf(" | Cache hits: %d/%d calls", t.CacheHits, t.Calls)
+	}
+	text += fmt.Sprintf(" | Est. cost: $%.4f", t.Cost)
+	return text
+}
+
+type usageScopeKey struct{}
+
+type usageScope struct {
+	agent     string
+	iteration int
+}
+
+// WithUsageScope attributes the LLM calls made with ctx to an agent and tool-loop iteration
+func WithUsageScope(ctx context.Context, agent string, iteration int) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, usageScope{agent: agent, iteration: iteration})
+}
+
+type cacheHitKey struct{}
+
+// markCacheHit flags the current call as served from the response cache
+func markCacheHit(ctx context.Context) {
+	if hit, ok := ctx.Value(cacheHitKey{}).(*bool); ok {
+		*hit = true
+	}
+}
+
+// UsageTrackingClient records the usage of every completion in a ledger
+type UsageTrackingClient struct {
+	client   LLMClient
+	ledger   *UsageLedger
+	provider string
+	model    string
+}
+
+// NewUsageTrackingClient wraps client so its calls are recorded in ledger
+func NewUsageTrackingClient(client LLMClient, ledger *UsageLedger, provider, model string) *UsageTrackingClient {
+	return &UsageTrackingClient{
+		client:   client,
+		ledger:   ledger,
+		provider: provider,
+		model:    model,
+	}
+}
+
+// GenerateCompletion delegates to the wrapped client and records the usage of successful calls
+func (c *UsageTrackingClient) GenerateCompletion(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	var cacheHit bool
+	resp, err := c.client.GenerateCompletion(context.WithValue(ctx, cacheHitKey{}, &cacheHit), req)
+	if err != nil {
+		return resp, err
+	}
+
+	scope, _ := ctx.Value(usageScopeKey{}).(usageScope)
+	c.ledger.Record(UsageRecord{
+		Agent:     scope.agent,
+		Provider:  c.provider,
+		Model:     c.model,
+		Iteration: scope.iteration,
+		Usage:     resp.Usage,
+		CacheHit:  cacheHit,
+	})
+	return resp, nil
+}
+
+// SupportsTools delegates to the wrapped client
+func (c *UsageTrackingClient) SupportsTools() bool {
+	return c.client.SupportsTools()
+}
+
+// GetProvider delegates to the wrapped client
+func (c *UsageTrackingClient) GetProvider() string {
+	return c.client.GetProvider()
+}
+
+// UsageHistoryEntry is one run in the usage history file
+type UsageHistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Command   string    `json:"command"`
+	UsageSummary
+}
+
+// UsageHistory is the content of the usage history file
+type UsageHistory struct {
+	Runs []UsageHistoryEntry `json:"runs"`
+}
+
+// LoadUsageHistory reads the usage history of a repository. A missing file
+// yields an empty history.
+func LoadUsageHistory(repoPath string) (*UsageHistory, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, UsageHistoryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &UsageHistory{}, nil
+		}
+		return nil, fmt.Errorf("failed to read usage history: %w", err)
+	}
+
+	var history UsageHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse usage history: %w", err)
+	}
+	return &history, nil
+}
+
+// AppendUsageHistory adds a run to the usage history of a repository
+func AppendUsageHistory(repoPath string, entry UsageHistoryEntry) error {
+	history, err := LoadUsageHistory(repoPath)
+	if err != nil {
+		// A corrupt history is replaced rather than blocking the run
+		history = &UsageHistory{}
+	}
+
+	history.Runs = append(history.Runs, entry)
+	if len(history.Runs) > maxUsageHistoryRuns {
+		history.Runs = history.Runs[len(history.Runs)-maxUsageHistoryRuns:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage history: %w", err)
+	}
+
+	path := filepath.Join(repoPath, UsageHistoryFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage history directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/llmcache"
+)
+
+func TestPriceTable_Lookup(t *testing.T) {
+	table := NewPriceTable(map[string]config.ModelPrice{
+		"My-Local-Model": {Input: 1, Output: 2},
+	})
+
+	tests := []struct {
+		model    string
+		expected config.ModelPrice
+		found    bool
+	}{
+		{"gpt-4o", config.ModelPrice{Input: 2.50, Output: 10.00}, true},
+		{"gpt-4o-mini-2024-07-18", config.ModelPrice{Input: 0.15, Output: 0.60}, true},
+		{"gpt-4-0613", config.ModelPrice{Input: 30.00, Output: 60.00}, true},
+		{"my-local-model", config.ModelPrice{Input: 1, Output: 2}, true},
+		{"llama3", config.ModelPrice{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			price, found := table.Lookup(tt.model)
+			if found != tt.found || price != tt.expected {
+				t.Errorf("Lookup(%q) = %+v, %v; want %+v, %v", tt.model, price, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestUsageLedger_Summary(t *testing.T) {
+	ledger := NewUsageLedger(NewPriceTable(map[string]config.ModelPrice{
+		"test-model": {Input: 1, Output: 10},
+	}))
+
+	ledger.Record(UsageRecord{Agent: "A", Provider: "openai", Model: "test-model", Iteration: 1,
+		Usage: TokenUsage{InputTokens: 1000, OutputTokens: 100, TotalTokens: 1100}})
+	ledger.Record(UsageRecord{Agent: "A", Provider: "openai", Model: "test-model", Iteration: 2,
+		Usage: TokenUsage{InputTokens: 2000, OutputTokens: 200, TotalTokens: 2200}})
+	ledger.Record(UsageRecord{Agent: "B", Provider: "openai", Model: "test-model", Iteration: 1,
+		Usage: TokenUsage{InputTokens: 500, OutputTokens: 50, TotalTokens: 550}, CacheHit: true})
+	ledger.Record(UsageRecord{Agent: "B", Provider: "ollama", Model: "llama3", Iteration: 1,
+		Usage: TokenUsage{InputTokens: 10, OutputTokens: 1, TotalTokens: 11}})
+
+	summary := ledger.Summary()
+
+	if summary.Calls != 4 || summary.CacheHits != 1 || summary.CachedTokens != 550 {
+		t.Errorf("unexpected call counts: %+v", summary.UsageTotals)
+	}
+	if summary.InputTokens != 3010 || summary.OutputTokens != 301 {
+		t.Errorf("expected cache hits to be excluded from billed tokens, got %+v", summary.UsageTotals)
+	}
+	// 3000 input * $1/M + 300 output * $10/M
+	if math.Abs(summary.Cost-0.006) > 1e-9 {
+		t.Errorf("expected cost 0.006, got %f", summary.Cost)
+	}
+	if summary.ByAgent["A"].InputTokens != 3000 || summary.ByAgent["B"].CacheHits != 1 {
+		t.Errorf("unexpected per-agent totals: %+v", summary.ByAgent)
+	}
+	if summary.ByModel["openai/test-model"].Calls != 3 || summary.ByModel["ollama/llama3"].Calls != 1 {
+		t.Errorf("unexpected per-model totals: %+v", summary.ByModel)
+	}
+	if iterations := summary.Iterations["A"]; len(iterations) != 2 || iterations[1].InputTokens != 2000 {
+		t.Errorf("unexpected iterations for A: %+v", iterations)
+	}
+	if len(summary.Unpriced) != 1 || summary.Unpriced[0] != "ollama/llama3" {
+		t.Errorf("expected llama3 to be unpriced, got %v", summary.Unpriced)
+	}
+}
+
+func TestPriceTable_UsageCost_PromptCache(t *testing.T) {
+	table := NewPriceTable(map[string]config.ModelPrice{
+		"test-model":   {Input: 10, Output: 100},
+		"cached-model": {Input: 10, Output: 100, CacheWrite: 10, CacheRead: 2.5},
+	})
+	usage := TokenUsage{InputTokens: 1000, CacheCreationInputTokens: 2000, CacheReadInputTokens: 10000, OutputTokens: 100}
+
+	// 1000 * $10 + 2000 * $12.5 + 10000 * $1 + 100 * $100, per million
+	if cost, _ := table.UsageCost("test-model", usage); math.Abs(cost-0.055) > 1e-9 {
+		t.Errorf("expected default cache prices to give 0.055, got %f", cost)
+	}
+	// 1000 * $10 + 2000 * $10 + 10000 * $2.5 + 100 * $100, per million
+	if cost, _ := table.UsageCost("cached-model", usage); math.Abs(cost-0.065) > 1e-9 {
+		t.Errorf("expected configured cache prices to give 0.065, got %f", cost)
+	}
+
+	ledger := NewUsageLedger(table)
+	ledger.Record(UsageRecord{Agent: "A", Provider: "anthropic", Model: "test-model", Usage: usage})
+	summary := ledger.Summary()
+	if summary.CacheCreationInputTokens != 2000 || summary.CacheReadInputTokens != 10000 || math.Abs(summary.Cost-0.055) > 1e-9 {
+		t.Errorf("unexpected prompt cache totals: %+v", summary.UsageTotals)
+	}
+}
+
+func TestFactory_UsageLedger_RecordsCallsAndCacheHits(t *testing.T) {
+	memoryCache := llmcache.NewLRUCache(10)
+	factory := NewFactory(nil, memoryCache, nil, true, time.Hour)
+	ledger := NewUsageLedger(nil)
+	factory.SetUsageLedger(ledger)
+
+	client, err := factory.CreateClient(config.LLMConfig{Provider: "openai", Model: "gpt-4o", APIKey: "key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tracking, ok := client.(*UsageTrackingClient)
+	if !ok {
+		t.Fatalf("expected *UsageTrackingClient, got %T", client)
+	}
+
+	// Swap the network client for a mock behind the real cache
+	mock := &mockLLMClient{
+		response: CompletionResponse{Content: "ok", Usage: TokenUsage{InputTokens: 100, OutputTokens: 10, TotalTokens: 110}},
+		provider: "openai",
+	}
+	tracking.client = NewCachedLLMClient(mock, memoryCache, nil, true, time.Hour)
+
+	req := CompletionRequest{Messages: []Message{{Role: "user", Content: "hello"}}}
+	for i := 1; i <= 2; i++ {
+		if _, err := client.GenerateCompletion(WithUsageScope(context.Background(), "StructureAnalyzer", i), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	records := ledger.Records()
+	if len(records) != 2 || mock.callCount != 1 {
+		t.Fatalf("expected 2 records and 1 API call, got %d records and %d calls", len(records), mock.callCount)
+	}
+	if records[0].CacheHit || !records[1].CacheHit {
+		t.Errorf("expected only the second call to be a cache hit, got %+v", records)
+	}
+	if records[1].Agent != "StructureAnalyzer" || records[1].Iteration != 2 || records[1].Model != "gpt-4o" {
+		t.Errorf("unexpected record attribution: %+v", records[1])
+	}
+}
+
+func TestAppendUsageHistory(t *testing.T) {
+	repoPath := t.TempDir()
+
+	for i := 0; i < maxUsageHistoryRuns+5; i++ {
+		entry := UsageHistoryEntry{
+			Timestamp:    time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			Command:      "analyze",
+			UsageSummary: UsageSummary{UsageTotals: UsageTotals{Calls: i}},
+		}
+		if err := AppendUsageHistory(repoPath, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	history, err := LoadUsageHistory(repoPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history.Runs) != maxUsageHistoryRuns {
+		t.Fatalf("expected %d runs, got %d", maxUsageHistoryRuns, len(history.Runs))
+	}
+	if history.Runs[0].Calls != 5 || history.Runs[len(history.Runs)-1].Calls != maxUsageHistoryRuns+4 {
+		t.Errorf("expected the oldest runs to be dropped, got first=%d last=%d",
+			history.Runs[0].Calls, history.Runs[len(history.Runs)-1].Calls)
+	}
+}
@@ -32,11 +32,14 @@ type CompletionResponse struct {
 	Usage     TokenUsage
 }
 
-// TokenUsage tracks token usage
+// TokenUsage tracks token usage. InputTokens excludes the prompt tokens
+// written to or read from the provider's prompt cache; TotalTokens includes them.
 type TokenUsage struct {
-	InputTokens  int
-	OutputTokens int
-	TotalTokens  int
+	InputTokens              int
+	OutputTokens             int
+	TotalTokens              int
+	CacheCreationInputTokens int // Prompt tokens written to the prompt cache
+	CacheReadInputTokens     int // Prompt tokens read from the prompt cache
 }
 
 // ToolDefinition defines a tool for the LLM
@@ -0,0 +1,220 @@
+// Package modules discovers the modules of a monorepo: Go modules, npm
+// workspaces, Cargo workspace members and Maven modules.
+package modules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFileName is the file, relative to the repository root, listing the
+// modules of the last monorepo analysis
+const ManifestFileName = ".ai/modules.json"
+
+// Module kinds
+const (
+	KindGo    = "go"
+	KindNPM   = "npm"
+	KindCargo = "cargo"
+	KindMaven = "maven"
+)
+
+// Module is a separately analyzed part of a monorepo
+type Module struct {
+	Name string `json:"name"` // Slash-separated path relative to the root, "." for the root itself
+	Path string `json:"path"` // Path relative to the root, in OS format
+	Kind string `json:"kind"`
+}
+
+// skipDirs are never searched for go.mod files
+var skipDirs = map[string]bool{
+	".git": true, ".ai": true, "node_modules": true, "vendor": true,
+	"testdata": true, "dist": true, "build": true, ".venv": true, "venv": true,
+}
+
+// Discover returns the modules of the repository at repoPath, sorted by path.
+// Go modules are found anywhere in the tree; npm, Cargo and Maven modules are
+// read from the workspace declarations at the root. A directory that is a
+// module for several tools is reported once.
+func Discover(repoPath string) ([]Module, error) {
+	found := make(map[string]Module)
+	add := func(dir, kind string) {
+		rel, err := filepath.Rel(repoPath, dir)
+		if err != nil {
+			return
+		}
+		if _, exists := found[rel]; !exists {
+			found[rel] = Module{Name: filepath.ToSlash(rel), Path: rel, Kind: kind}
+		}
+	}
+
+	err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != repoPath && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			add(filepath.Dir(path), KindGo)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for go.mod files: %w", err)
+	}
+
+	for _, dir := range npmWorkspaces(repoPath) {
+		add(dir, KindNPM)
+	}
+	for _, dir := range cargoMembers(repoPath) {
+		add(dir, KindCargo)
+	}
+	for _, dir := range mavenModules(repoPath) {
+		add(dir, KindMaven)
+	}
+
+	modules := make([]Module, 0, len(found))
+	for _, module := range found {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return modules, nil
+}
+
+// NestedPaths returns the paths, relative to parent, of the modules that live
+// inside parent. They are excluded from parent's own analysis.
+func NestedPaths(all []Module, parent Module) []string {
+	var nested []string
+	for _, module := range all {
+		if module.Path == parent.Path {
+			continue
+		}
+		rel, err := filepath.Rel(parent.Path, module.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		nested = append(nested, rel)
+	}
+	return nested
+}
+
+// expandDirs expands workspace globs relative to repoPath into directories
+// containing marker
+func expandDirs(repoPath string, patterns []string, marker string) []string {
+	var dirs []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(pattern)))
+		if err != nil {
+			continue
+		}
+		for _, dir := range matches {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// npmWorkspaces reads the "workspaces" field of the root package.json, either
+// a list of globs or an object with a "packages" list
+func npmWorkspaces(repoPath string) []string {
+	data, err := os.ReadFile(filepath.Join(repoPath, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &object); err != nil {
+			return nil
+		}
+		patterns = object.Packages
+	}
+	return expandDirs(repoPath, patterns, "package.json")
+}
+
+var (
+	cargoMembersList = regexp.MustCompile(`(?s)\[workspace\].*?members\s*=\s*\[(.*?)\]`)
+	quotedString     = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// cargoMembers reads the workspace members of the root Cargo.toml
+func cargoMembers(repoPath string) []string {
+	data, err := os.ReadFile(filepath.Join(repoPath, "Cargo.toml"))
+	if err != nil {
+		return nil
+	}
+	match := cargoMembersList.FindSubmatch(data)
+	if match == nil {
+		return nil
+	}
+	var patterns []string
+	for _, member := range quotedString.FindAllSubmatch(match[1], -1) {
+		patterns = append(patterns, string(member[1]))
+	}
+	return expandDirs(repoPath, patterns, "Cargo.toml")
+}
+
+// mavenModules reads the <modules> of the root pom.xml
+func mavenModules(repoPath string) []string {
+	data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml"))
+	if err != nil {
+		return nil
+	}
+	var pom struct {
+		Modules []string `xml:"modules>module"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil
+	}
+	return expandDirs(repoPath, pom.Modules, "pom.xml")
+}
+
+// Manifest lists the modules of the last monorepo analysis
+type Manifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Modules     []Module  `json:"modules"`
+}
+
+// SaveManifest writes the module list to .ai/modules.json
+func SaveManifest(repoPath string, modules []Module) error {
+	data, err := json.MarshalIndent(Manifest{GeneratedAt: time.Now(), Modules: modules}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal module manifest: %w", err)
+	}
+	path := filepath.Join(repoPath, ManifestFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// HasManifest reports whether the repository was last analyzed as a monorepo
+func HasManifest(repoPath string) bool {
+	_, err := os.Stat(filepath.Join(repoPath, ManifestFileName))
+	return err == nil
+}
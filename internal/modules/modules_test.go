@@ -0,0 +1,75 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                            "module example.com/root\n",
+		"services/api/go.mod":               "module example.com/api\n",
+		"services/api/testdata/fake/go.mod": "module fake\n",
+		"vendor/example.com/lib/go.mod":     "module example.com/lib\n",
+		"package.json":                      `{"workspaces": {"packages": ["web/*"]}}`,
+		"web/app/package.json":              `{"name": "app"}`,
+		"web/README.md":                     "not a package",
+		"Cargo.toml":                        "[workspace]\nmembers = [\n  \"crates/core\",\n]\n",
+		"crates/core/Cargo.toml":            "[package]\nname = \"core\"\n",
+		"pom.xml":                           "<project><modules><module>java/billing</module></modules></project>",
+		"java/billing/pom.xml":              "<project/>",
+	})
+
+	got, err := Discover(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Module{
+		{Name: ".", Path: ".", Kind: KindGo},
+		{Name: "crates/core", Path: filepath.Join("crates", "core"), Kind: KindCargo},
+		{Name: "java/billing", Path: filepath.Join("java", "billing"), Kind: KindMaven},
+		{Name: "services/api", Path: filepath.Join("services", "api"), Kind: KindGo},
+		{Name: "web/app", Path: filepath.Join("web", "app"), Kind: KindNPM},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %+v, want %+v", got, want)
+	}
+
+	nested := NestedPaths(got, got[0])
+	if len(nested) != 4 {
+		t.Errorf("expected the root module to contain the 4 others, got %v", nested)
+	}
+	if nested := NestedPaths(got, got[3]); len(nested) != 0 {
+		t.Errorf("expected no modules nested in services/api, got %v", nested)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	root := t.TempDir()
+	if HasManifest(root) {
+		t.Fatal("expected no manifest in an empty repository")
+	}
+	if err := SaveManifest(root, []Module{{Name: "svc", Path: "svc", Kind: KindGo}}); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+	if !HasManifest(root) {
+		t.Error("expected the manifest to exist after saving")
+	}
+}
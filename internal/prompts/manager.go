@@ -118,6 +118,14 @@ func (pm *Manager) validateRequiredPrompts() error {
 		"request_flow_analyzer_user",
 		"api_analyzer_system",
 		"api_analyzer_user",
+		"security_analyzer_system",
+		"security_analyzer_user",
+		"testing_analyzer_system",
+		"testing_analyzer_user",
+		"infrastructure_analyzer_system",
+		"infrastructure_analyzer_user",
+		"monorepo_overview_system",
+		"monorepo_overview_user",
 		"documenter_system_prompt",
 		"documenter_user_prompt",
 		"ai_rules_system_prompt",
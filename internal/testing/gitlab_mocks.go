@@ -0,0 +1,573 @@
+package testing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeGitLabProject mirrors the fields of the GitLab project payload used by gendocs
+type FakeGitLabProject struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	HTTPURL           string    `json:"http_url_to_repo"`
+	SSHURL            string    `json:"ssh_url_to_repo"`
+	DefaultBranch     string    `json:"default_branch"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	Archived          bool      `json:"archived"`
+	Topics            []string  `json:"topics,omitempty"`
+	Visibility        string    `json:"visibility,omitempty"`
+	// Languages is served by the languages endpoint, not in the project payload
+	Languages map[string]float64 `json:"-"`
+}
+
+// FakeGitLabMR mirrors the fields of the GitLab merge request payload
+type FakeGitLabMR struct {
+	ID           int    `json:"id"`
+	IID          int    `json:"iid"`
+	ProjectID    int    `json:"project_id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	State        string `json:"state"`
+	WebURL       string `json:"web_url"`
+	// Diffs is served by the diffs endpoint, not in the merge request payload
+	Diffs []FakeGitLabMRDiff `json:"-"`
+}
+
+// FakeGitLabMRDiff mirrors a file entry of the merge request diffs payload
+type FakeGitLabMRDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// FakeGitLabNote records a comment posted on a merge request
+type FakeGitLabNote struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"-"`
+	MRIID     int    `json:"-"`
+	Body      string `json:"body"`
+}
+
+// FakeGitLabCommitAction is a single file action received by the commits API
+type FakeGitLabCommitAction struct {
+	Action   string `json:"action"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// FakeGitLabCommit records a commit created through the commits API
+type FakeGitLabCommit struct {
+	ProjectID   int                      `json:"-"`
+	Branch      string                   `json:"branch"`
+	Message     string                   `json:"commit_message"`
+	AuthorName  string                   `json:"author_name"`
+	AuthorEmail string                   `json:"author_email"`
+	Actions     []FakeGitLabCommitAction `json:"actions"`
+}
+
+// FakeGitLab is an in-memory GitLab REST API (v4) backed by httptest.
+// It implements the subset of endpoints used by the cronjob flow.
+type FakeGitLab struct {
+	Server *httptest.Server
+	token  string
+
+	mu       sync.Mutex
+	groups   map[int][]int
+	projects map[int]FakeGitLabProject
+	// files holds project -> branch -> path -> content
+	files   map[int]map[string]map[string]string
+	mrs     map[int][]FakeGitLabMR
+	commits []FakeGitLabCommit
+	notes   []FakeGitLabNote
+	nextMR  int
+}
+
+var (
+	fakeGroupProjectsRe = regexp.MustCompile(`^/api/v4/groups/(\d+)/projects$`)
+	fakeBranchesRe      = regexp.MustCompile(`^/api/v4/projects/(\d+)/repository/branches$`)
+	fakeBranchRe        = regexp.MustCompile(`^/api/v4/projects/(\d+)/repository/branches/([^/]+)$`)
+	fakeFileRe          = regexp.MustCompile(`^/api/v4/projects/(\d+)/repository/files/([^/]+)$`)
+	fakeCommitsRe       = regexp.MustCompile(`^/api/v4/projects/(\d+)/repository/commits$`)
+	fakeMRsRe           = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests$`)
+	fakeLanguagesRe     = regexp.MustCompile(`^/api/v4/projects/(\d+)/languages$`)
+	fakeMRRe            = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)$`)
+	fakeMRDiffsRe       = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/diffs$`)
+	fakeMRNotesRe       = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/notes$`)
+	fakeMRNoteRe        = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/notes/(\d+)$`)
+	fakeProjectRe       = regexp.MustCompile(`^/api/v4/projects/([^/]+)$`)
+)
+
+// NewFakeGitLab starts a fake GitLab server. When token is non-empty, requests
+// without a matching Bearer token are rejected with 401.
+func NewFakeGitLab(t *testing.T, token string) *FakeGitLab {
+	t.Helper()
+	f := &FakeGitLab{
+		token:    token,
+		groups:   make(map[int][]int),
+		projects: make(map[int]FakeGitLabProject),
+		files:    make(map[int]map[string]map[string]string),
+		mrs:      make(map[int][]FakeGitLabMR),
+		nextMR:   1,
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Server.Close)
+	return f
+}
+
+// URL returns the instance root URL (without /api/v4)
+func (f *FakeGitLab) URL() string {
+	return f.Server.URL
+}
+
+// AddProject registers a project in a group with its default branch
+func (f *FakeGitLab) AddProject(groupID int, project FakeGitLabProject) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if project.DefaultBranch == "" {
+		project.DefaultBranch = "main"
+	}
+	f.groups[groupID] = append(f.groups[groupID], project.ID)
+	f.projects[project.ID] = project
+	f.files[project.ID] = map[string]map[string]string{
+		project.DefaultBranch: {},
+	}
+}
+
+// AddBranch creates a branch in a project with the given files
+func (f *FakeGitLab) AddBranch(projectID int, branch string, files map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.files[projectID] == nil {
+		f.files[projectID] = make(map[string]map[string]string)
+	}
+	content := make(map[string]string, len(files))
+	for path, data := range files {
+		content[path] = data
+	}
+	f.files[projectID][branch] = content
+}
+
+// SetFile writes a file directly on an existing branch, bypassing the commits API
+func (f *FakeGitLab) SetFile(projectID int, branch, path, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[projectID][branch][path] = content
+}
+
+// AddMergeRequest registers an existing merge request
+func (f *FakeGitLab) AddMergeRequest(projectID int, mr FakeGitLabMR) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if mr.State == "" {
+		mr.State = "opened"
+	}
+	if mr.IID == 0 {
+		mr.IID = f.nextMR
+		f.nextMR++
+	}
+	mr.ProjectID = projectID
+	f.mrs[projectID] = append(f.mrs[projectID], mr)
+}
+
+// Commits returns all commits received so far
+func (f *FakeGitLab) Commits() []FakeGitLabCommit {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeGitLabCommit(nil), f.commits...)
+}
+
+// MergeRequests returns all merge requests of a project
+func (f *FakeGitLab) MergeRequests(projectID int) []FakeGitLabMR {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeGitLabMR(nil), f.mrs[projectID]...)
+}
+
+// Notes returns all merge request comments received so far
+func (f *FakeGitLab) Notes() []FakeGitLabNote {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeGitLabNote(nil), f.notes...)
+}
+
+// FileContent returns a file's content on a branch
+func (f *FakeGitLab) FileContent(projectID int, branch, path string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.files[projectID][branch][path]
+	return content, ok
+}
+
+// HasBranch reports whether a branch exists in a project
+func (f *FakeGitLab) HasBranch(projectID int, branch string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.files[projectID][branch]
+	return ok
+}
+
+func (f *FakeGitLab) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.token != "" && r.Header.Get("Authorization") != "Bearer "+f.token {
+		writeGitLabError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := r.URL.EscapedPath()
+	switch {
+	case fakeGroupProjectsRe.MatchString(path) && r.Method == http.MethodGet:
+		f.listGroupProjects(w, r, atoiMatch(fakeGroupProjectsRe, path, 1))
+	case fakeBranchesRe.MatchString(path) && r.Method == http.MethodPost:
+		f.createBranch(w, r, atoiMatch(fakeBranchesRe, path, 1))
+	case fakeBranchRe.MatchString(path) && r.Method == http.MethodGet:
+		m := fakeBranchRe.FindStringSubmatch(path)
+		branch, _ := url.PathUnescape(m[2])
+		if _, ok := f.files[atoiMatch(fakeBranchRe, path, 1)][branch]; !ok {
+			writeGitLabError(w, http.StatusNotFound, "404 Branch Not Found")
+			return
+		}
+		writeGitLabJSON(w, http.StatusOK, map[string]string{"name": branch})
+	case fakeFileRe.MatchString(path) && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		m := fakeFileRe.FindStringSubmatch(path)
+		filePath, _ := url.PathUnescape(m[2])
+		content, ok := f.files[atoiMatch(fakeFileRe, path, 1)][r.URL.Query().Get("ref")][filePath]
+		if !ok {
+			writeGitLabError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+		writeGitLabJSON(w, http.StatusOK, map[string]string{
+			"file_path": filePath,
+			"encoding":  "base64",
+			"content":   base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+	case fakeLanguagesRe.MatchString(path) && r.Method == http.MethodGet:
+		project, ok := f.projects[atoiMatch(fakeLanguagesRe, path, 1)]
+		if !ok {
+			writeGitLabError(w, http.StatusNotFound, "404 Project Not Found")
+			return
+		}
+		languages := project.Languages
+		if languages == nil {
+			languages = map[string]float64{}
+		}
+		writeGitLabJSON(w, http.StatusOK, languages)
+	case fakeCommitsRe.MatchString(path) && r.Method == http.MethodPost:
+		f.createCommit(w, r, atoiMatch(fakeCommitsRe, path, 1))
+	case fakeMRsRe.MatchString(path) && r.Method == http.MethodGet:
+		f.listMergeRequests(w, r, atoiMatch(fakeMRsRe, path, 1))
+	case fakeMRsRe.MatchString(path) && r.Method == http.MethodPost:
+		f.createMergeRequest(w, r, atoiMatch(fakeMRsRe, path, 1))
+	case fakeMRRe.MatchString(path) && r.Method == http.MethodGet:
+		mr := f.findMergeRequest(atoiMatch(fakeMRRe, path, 1), atoiMatch(fakeMRRe, path, 2))
+		if mr == nil {
+			writeGitLabError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		writeGitLabJSON(w, http.StatusOK, mr)
+	case fakeMRRe.MatchString(path) && r.Method == http.MethodPut:
+		f.updateMergeRequest(w, r, atoiMatch(fakeMRRe, path, 1), atoiMatch(fakeMRRe, path, 2))
+	case fakeMRDiffsRe.MatchString(path) && r.Method == http.MethodGet:
+		mr := f.findMergeRequest(atoiMatch(fakeMRDiffsRe, path, 1), atoiMatch(fakeMRDiffsRe, path, 2))
+		if mr == nil {
+			writeGitLabError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		diffs := mr.Diffs
+		if diffs == nil {
+			diffs = []FakeGitLabMRDiff{}
+		}
+		w.Header().Set("X-Next-Page", "")
+		writeGitLabJSON(w, http.StatusOK, diffs)
+	case fakeMRNotesRe.MatchString(path) && r.Method == http.MethodGet:
+		notes := []FakeGitLabNote{}
+		for _, note := range f.notes {
+			if note.ProjectID == atoiMatch(fakeMRNotesRe, path, 1) && note.MRIID == atoiMatch(fakeMRNotesRe, path, 2) {
+				notes = append(notes, note)
+			}
+		}
+		w.Header().Set("X-Next-Page", "")
+		writeGitLabJSON(w, http.StatusOK, notes)
+	case fakeMRNotesRe.MatchString(path) && r.Method == http.MethodPost:
+		f.createNote(w, r, atoiMatch(fakeMRNotesRe, path, 1), atoiMatch(fakeMRNotesRe, path, 2))
+	case fakeMRNoteRe.MatchString(path) && r.Method == http.MethodPut:
+		f.updateNote(w, r, atoiMatch(fakeMRNoteRe, path, 3))
+	case fakeProjectRe.MatchString(path) && r.Method == http.MethodGet:
+		f.getProject(w, fakeProjectRe.FindStringSubmatch(path)[1])
+	default:
+		writeGitLabError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (f *FakeGitLab) listGroupProjects(w http.ResponseWriter, r *http.Request, groupID int) {
+	ids, ok := f.groups[groupID]
+	if !ok {
+		writeGitLabError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage <= 0 {
+		perPage = 20
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+	end := start + perPage
+	if start > len(ids) {
+		start = len(ids)
+	}
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	projects := make([]FakeGitLabProject, 0, end-start)
+	for _, id := range ids[start:end] {
+		projects = append(projects, f.projects[id])
+	}
+
+	if end < len(ids) {
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+	} else {
+		w.Header().Set("X-Next-Page", "")
+	}
+	writeGitLabJSON(w, http.StatusOK, projects)
+}
+
+func (f *FakeGitLab) createBranch(w http.ResponseWriter, r *http.Request, projectID int) {
+	var req struct {
+		Branch string `json:"branch"`
+		Ref    string `json:"ref"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	branches := f.files[projectID]
+	if branches == nil {
+		writeGitLabError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	if _, exists := branches[req.Branch]; exists {
+		writeGitLabError(w, http.StatusBadRequest, "Branch already exists")
+		return
+	}
+	source, ok := branches[req.Ref]
+	if !ok {
+		writeGitLabError(w, http.StatusBadRequest, "Invalid reference name")
+		return
+	}
+	content := make(map[string]string, len(source))
+	for path, data := range source {
+		content[path] = data
+	}
+	branches[req.Branch] = content
+	writeGitLabJSON(w, http.StatusCreated, map[string]string{"name": req.Branch})
+}
+
+func (f *FakeGitLab) createCommit(w http.ResponseWriter, r *http.Request, projectID int) {
+	var commit FakeGitLabCommit
+	if err := json.NewDecoder(r.Body).Decode(&commit); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	files, ok := f.files[projectID][commit.Branch]
+	if !ok {
+		writeGitLabError(w, http.StatusBadRequest, "You can only create or edit files when you are on a branch")
+		return
+	}
+
+	// Validate all actions before applying, like GitLab does
+	for _, action := range commit.Actions {
+		_, exists := files[action.FilePath]
+		switch action.Action {
+		case "create":
+			if exists {
+				writeGitLabError(w, http.StatusBadRequest, fmt.Sprintf("A file with this name already exists: %s", action.FilePath))
+				return
+			}
+		case "update":
+			if !exists {
+				writeGitLabError(w, http.StatusBadRequest, fmt.Sprintf("A file with this name doesn't exist: %s", action.FilePath))
+				return
+			}
+		default:
+			writeGitLabError(w, http.StatusBadRequest, fmt.Sprintf("unsupported action: %s", action.Action))
+			return
+		}
+	}
+	for _, action := range commit.Actions {
+		files[action.FilePath] = action.Content
+	}
+
+	commit.ProjectID = projectID
+	f.commits = append(f.commits, commit)
+	writeGitLabJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":      fmt.Sprintf("%040d", len(f.commits)),
+		"message": commit.Message,
+	})
+}
+
+func (f *FakeGitLab) listMergeRequests(w http.ResponseWriter, r *http.Request, projectID int) {
+	state := r.URL.Query().Get("state")
+	source := r.URL.Query().Get("source_branch")
+
+	result := []FakeGitLabMR{}
+	for _, mr := range f.mrs[projectID] {
+		if state != "" && mr.State != state {
+			continue
+		}
+		if source != "" && mr.SourceBranch != source {
+			continue
+		}
+		result = append(result, mr)
+	}
+	writeGitLabJSON(w, http.StatusOK, result)
+}
+
+func (f *FakeGitLab) createMergeRequest(w http.ResponseWriter, r *http.Request, projectID int) {
+	var mr FakeGitLabMR
+	if err := json.NewDecoder(r.Body).Decode(&mr); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := f.files[projectID][mr.SourceBranch]; !ok {
+		writeGitLabError(w, http.StatusBadRequest, "Source branch does not exist")
+		return
+	}
+	for _, existing := range f.mrs[projectID] {
+		if existing.State == "opened" && existing.SourceBranch == mr.SourceBranch {
+			writeGitLabError(w, http.StatusConflict, "Another open merge request already exists for this source branch")
+			return
+		}
+	}
+
+	mr.ID = 1000 + f.nextMR
+	mr.IID = f.nextMR
+	mr.ProjectID = projectID
+	mr.State = "opened"
+	mr.WebURL = fmt.Sprintf("%s/%s/-/merge_requests/%d", f.Server.URL, f.projects[projectID].PathWithNamespace, mr.IID)
+	f.nextMR++
+	f.mrs[projectID] = append(f.mrs[projectID], mr)
+	writeGitLabJSON(w, http.StatusCreated, mr)
+}
+
+func (f *FakeGitLab) updateMergeRequest(w http.ResponseWriter, r *http.Request, projectID, iid int) {
+	var req struct {
+		Description *string `json:"description"`
+		Title       *string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for i := range f.mrs[projectID] {
+		mr := &f.mrs[projectID][i]
+		if mr.IID != iid {
+			continue
+		}
+		if req.Description != nil {
+			mr.Description = *req.Description
+		}
+		if req.Title != nil {
+			mr.Title = *req.Title
+		}
+		writeGitLabJSON(w, http.StatusOK, mr)
+		return
+	}
+	writeGitLabError(w, http.StatusNotFound, "404 Not found")
+}
+
+func (f *FakeGitLab) findMergeRequest(projectID, iid int) *FakeGitLabMR {
+	for i := range f.mrs[projectID] {
+		if f.mrs[projectID][i].IID == iid {
+			return &f.mrs[projectID][i]
+		}
+	}
+	return nil
+}
+
+// getProject resolves a project by numeric ID or URL-encoded full path
+func (f *FakeGitLab) getProject(w http.ResponseWriter, ref string) {
+	ref, _ = url.PathUnescape(ref)
+	if id, err := strconv.Atoi(ref); err == nil {
+		if project, ok := f.projects[id]; ok {
+			writeGitLabJSON(w, http.StatusOK, project)
+			return
+		}
+	}
+	for _, project := range f.projects {
+		if project.PathWithNamespace == ref {
+			writeGitLabJSON(w, http.StatusOK, project)
+			return
+		}
+	}
+	writeGitLabError(w, http.StatusNotFound, "404 Project Not Found")
+}
+
+func (f *FakeGitLab) createNote(w http.ResponseWriter, r *http.Request, projectID, iid int) {
+	if f.findMergeRequest(projectID, iid) == nil {
+		writeGitLabError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+	var note FakeGitLabNote
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	note.ID = len(f.notes) + 1
+	note.ProjectID = projectID
+	note.MRIID = iid
+	f.notes = append(f.notes, note)
+	writeGitLabJSON(w, http.StatusCreated, note)
+}
+
+func (f *FakeGitLab) updateNote(w http.ResponseWriter, r *http.Request, noteID int) {
+	if noteID < 1 || noteID > len(f.notes) {
+		writeGitLabError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+	var update struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeGitLabError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f.notes[noteID-1].Body = update.Body
+	writeGitLabJSON(w, http.StatusOK, f.notes[noteID-1])
+}
+
+func atoiMatch(re *regexp.Regexp, path string, group int) int {
+	n, _ := strconv.Atoi(re.FindStringSubmatch(path)[group])
+	return n
+}
+
+func writeGitLabJSON(w http.ResponseWriter, status int, v interface{}) {
+	SetJSONHeaders(w)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeGitLabError(w http.ResponseWriter, status int, message string) {
+	writeGitLabJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,88 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Encoding is a byte-level BPE vocabulary in the tiktoken format
+type Encoding struct {
+	Name  string
+	ranks map[string]int // Token bytes to merge priority; lower merges first
+	split splitter
+}
+
+// LoadEncoding reads a tiktoken rank file: one base64-encoded token and its
+// rank per line, as published for cl100k_base and o200k_base
+func LoadEncoding(name, path string) (*Encoding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected a token and a rank", path, line)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid token: %w", path, line, err)
+		}
+		value, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rank: %w", path, line, err)
+		}
+		ranks[string(decoded)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return newEncoding(name, ranks), nil
+}
+
+func newEncoding(name string, ranks map[string]int) *Encoding {
+	split := splitCL100K
+	if name == EncodingO200K {
+		split = splitO200K
+	}
+	return &Encoding{Name: name, ranks: ranks, split: split}
+}
+
+// countPiece returns the number of tokens BPE merges a piece into
+func (e *Encoding) countPiece(piece string) int {
+	if _, ok := e.ranks[piece]; ok {
+		return 1
+	}
+
+	// Start from single bytes and merge the lowest-ranked adjacent pair until none is in the vocabulary
+	parts := make([]int, len(piece)+1) // Part boundaries
+	for i := range parts {
+		parts[i] = i
+	}
+	for len(parts) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(parts); i++ {
+			if rank, ok := e.ranks[piece[parts[i]:parts[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return len(parts) - 1
+}
@@ -0,0 +1,105 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRanks is a small vocabulary: single bytes and a few merges
+var testRanks = map[string]int{
+	"a": 0, "b": 1, "c": 2, "d": 3, " ": 4,
+	"ab": 5, "cd": 6, "abcd": 7, " ab": 8,
+}
+
+func splitAll(split splitter, text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		end := split(text, i)
+		pieces = append(pieces, text[i:end])
+		i = end
+	}
+	return pieces
+}
+
+func TestSplitCL100K(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world's  foo\n\n", []string{"Hello", " world", "'s", " ", " foo", "\n\n"}},
+		{"x := 12345", []string{"x", " :=", " ", "123", "45"}},
+		{"if (a) {\n\treturn\n}", []string{"if", " (", "a", ")", " {\n", "\treturn", "\n", "}"}},
+		{"ñandú café", []string{"ñandú", " café"}},
+		{"HelloWorld", []string{"HelloWorld"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitAll(splitCL100K, tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("splitCL100K(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitO200K(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"HelloWorld's CSVParser", []string{"Hello", "World's", " CSVParser"}},
+		{"path/to\n", []string{"path", "/to", "\n"}},
+		{"a //\n", []string{"a", " //\n"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitAll(splitO200K, tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("splitO200K(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEncoding_CountPiece(t *testing.T) {
+	encoding := newEncoding(EncodingCL100K, testRanks)
+
+	tests := map[string]int{
+		"abcd":   1, // In the vocabulary
+		"abcdab": 2, // ab, ab, cd, then abcd
+		" abcd":  2, // cd before " ab" by rank, then abcd
+		"dcba":   4, // No merges
+	}
+	for piece, want := range tests {
+		if got := encoding.countPiece(piece); got != want {
+			t.Errorf("countPiece(%q) = %d, want %d", piece, got, want)
+		}
+	}
+}
+
+func TestLoadEncoding(t *testing.T) {
+	var lines []string
+	for token, rank := range testRanks {
+		lines = append(lines, fmt.Sprintf("%s %d", base64.StdEncoding.EncodeToString([]byte(token)), rank))
+	}
+	path := filepath.Join(t.TempDir(), EncodingO200K+".tiktoken")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	encoding, err := LoadEncoding(EncodingO200K, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoding.Name != EncodingO200K || len(encoding.ranks) != len(testRanks) || encoding.ranks["abcd"] != 7 {
+		t.Errorf("unexpected encoding: %+v", encoding)
+	}
+
+	if err := os.WriteFile(path, []byte("YWI=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEncoding(EncodingO200K, path); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("expected an error naming the line, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package tokenizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Encodings lists the BPE vocabularies used by the models of the registry
+var Encodings = []string{EncodingCL100K, EncodingO200K}
+
+// encodingsURL is where OpenAI publishes the rank files
+var encodingsURL = "https://openaipublic.blob.core.windows.net/encodings/"
+
+// encodingChecksums are the SHA-256 digests of the published rank files
+var encodingChecksums = map[string]string{
+	EncodingCL100K: "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7",
+	EncodingO200K:  "446a9538cb6c348e3516120d7c08b09f57c36495e2acfffe59a5bf8b0cfb1a2d",
+}
+
+// Fetch downloads the rank file of an encoding into dir, verifies its checksum
+// and makes it available to ForModel. It returns the path of the file.
+func Fetch(ctx context.Context, name, dir string) (string, error) {
+	checksum, ok := encodingChecksums[name]
+	if !ok {
+		return "", fmt.Errorf("unknown encoding %q", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, encodingsURL+name+".tiktoken", nil)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", name, resp.Status)
+	}
+
+	// Write to a temporary file so a failed download never replaces a good one
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != checksum {
+		return "", fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, checksum)
+	}
+
+	encoding, err := LoadEncoding(name, tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name+".tiktoken")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", path, err)
+	}
+
+	encodingsMu.Lock()
+	encodings[name] = encoding
+	encodingsMu.Unlock()
+	return path, nil
+}
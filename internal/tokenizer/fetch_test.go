@@ -0,0 +1,76 @@
+package tokenizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	var lines []string
+	for token, rank := range testRanks {
+		lines = append(lines, fmt.Sprintf("%s %d", base64.StdEncoding.EncodeToString([]byte(token)), rank))
+	}
+	rankFile := []byte(strings.Join(lines, "\n"))
+	sum := sha256.Sum256(rankFile)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+EncodingCL100K+".tiktoken" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(rankFile)
+	}))
+	defer server.Close()
+
+	originalURL, originalChecksum := encodingsURL, encodingChecksums[EncodingCL100K]
+	encodingsURL = server.URL + "/"
+	encodingChecksums[EncodingCL100K] = hex.EncodeToString(sum[:])
+	t.Cleanup(func() {
+		encodingsURL = originalURL
+		encodingChecksums[EncodingCL100K] = originalChecksum
+		encodingsMu.Lock()
+		encodings = make(map[string]*Encoding)
+		encodingsMu.Unlock()
+	})
+
+	dir := filepath.Join(t.TempDir(), "tokenizers")
+	path, err := Fetch(context.Background(), EncodingCL100K, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, EncodingCL100K+".tiktoken") {
+		t.Errorf("unexpected path %s", path)
+	}
+	if tok := ForModel("gpt-4"); !tok.Exact() || tok.Count("abcd") != 1 {
+		t.Error("expected the fetched rank file to be used without a restart")
+	}
+	if tok := ForModel("claude-sonnet-4"); tok.Exact() || tok.Count("abcd") != 1 {
+		t.Error("expected Claude to be estimated with the fetched rank file")
+	}
+
+	// A corrupted download is rejected and leaves the saved file alone
+	encodingChecksums[EncodingCL100K] = strings.Repeat("0", 64)
+	if _, err := Fetch(context.Background(), EncodingCL100K, dir); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+	if saved, err := os.ReadFile(path); err != nil || string(saved) != string(rankFile) {
+		t.Errorf("expected the saved rank file to be kept, got %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left, got %d entries", len(entries))
+	}
+
+	if _, err := Fetch(context.Background(), "p50k_base", dir); err == nil {
+		t.Error("expected an error for an unknown encoding")
+	}
+}
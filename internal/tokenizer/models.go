@@ -0,0 +1,83 @@
+package tokenizer
+
+import "strings"
+
+// Encoding names of the BPE vocabularies used by the model families
+const (
+	EncodingCL100K = "cl100k_base"
+	EncodingO200K  = "o200k_base"
+)
+
+// ModelInfo describes the limits of a model
+type ModelInfo struct {
+	ContextWindow   int    // Prompt and output tokens the model accepts
+	MaxOutputTokens int    // Tokens the model can generate in one response
+	Encoding        string // BPE vocabulary counting its tokens; empty when unavailable
+	Approximate     bool   // Encoding only approximates the model's own vocabulary
+}
+
+// DefaultModelInfo is used for models missing from the registry, e.g. local models
+var DefaultModelInfo = ModelInfo{ContextWindow: 128_000, MaxOutputTokens: 8192}
+
+// models maps model names, or model name prefixes, to their limits. Claude's
+// tokenizer is not public; its counts are estimated with cl100k_base.
+var models = map[string]ModelInfo{
+	"gpt-4o":            {ContextWindow: 128_000, MaxOutputTokens: 16_384, Encoding: EncodingO200K},
+	"gpt-4.1":           {ContextWindow: 1_047_576, MaxOutputTokens: 32_768, Encoding: EncodingO200K},
+	"gpt-4-turbo":       {ContextWindow: 128_000, MaxOutputTokens: 4096, Encoding: EncodingCL100K},
+	"gpt-4":             {ContextWindow: 8192, MaxOutputTokens: 4096, Encoding: EncodingCL100K},
+	"gpt-3.5-turbo":     {ContextWindow: 16_385, MaxOutputTokens: 4096, Encoding: EncodingCL100K},
+	"o1":                {ContextWindow: 200_000, MaxOutputTokens: 100_000, Encoding: EncodingO200K},
+	"o3":                {ContextWindow: 200_000, MaxOutputTokens: 100_000, Encoding: EncodingO200K},
+	"o4-mini":           {ContextWindow: 200_000, MaxOutputTokens: 100_000, Encoding: EncodingO200K},
+	"claude-opus-4":     {ContextWindow: 200_000, MaxOutputTokens: 32_000, Encoding: EncodingCL100K, Approximate: true},
+	"claude-sonnet-4":   {ContextWindow: 200_000, MaxOutputTokens: 64_000, Encoding: EncodingCL100K, Approximate: true},
+	"claude-3-7-sonnet": {ContextWindow: 200_000, MaxOutputTokens: 64_000, Encoding: EncodingCL100K, Approximate: true},
+	"claude-3-5-sonnet": {ContextWindow: 200_000, MaxOutputTokens: 8192, Encoding: EncodingCL100K, Approximate: true},
+	"claude-3-5-haiku":  {ContextWindow: 200_000, MaxOutputTokens: 8192, Encoding: EncodingCL100K, Approximate: true},
+	"claude-3-opus":     {ContextWindow: 200_000, MaxOutputTokens: 4096, Encoding: EncodingCL100K, Approximate: true},
+	"claude-3-haiku":    {ContextWindow: 200_000, MaxOutputTokens: 4096, Encoding: EncodingCL100K, Approximate: true},
+	"gemini-2.5-pro":    {ContextWindow: 1_048_576, MaxOutputTokens: 65_536},
+	"gemini-2.5-flash":  {ContextWindow: 1_048_576, MaxOutputTokens: 65_536},
+	"gemini-2.0-flash":  {ContextWindow: 1_048_576, MaxOutputTokens: 8192},
+	"gemini-1.5-pro":    {ContextWindow: 2_097_152, MaxOutputTokens: 8192},
+	"gemini-1.5-flash":  {ContextWindow: 1_048_576, MaxOutputTokens: 8192},
+}
+
+// LookupModel returns the limits of a model. An exact match wins, otherwise
+// the longest matching prefix is used (e.g. "gpt-4o-2024-08-06" uses "gpt-4o").
+// Provider prefixes such as "models/" or Bedrock's "us.anthropic." are ignored.
+func LookupModel(model string) (ModelInfo, bool) {
+	model = strings.ToLower(model)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+
+	for {
+		if info, ok := lookupPrefix(model); ok {
+			return info, true
+		}
+		// Bedrock model IDs prefix the vendor, e.g. "anthropic.claude-3-5-sonnet-20240620-v1:0"
+		i := strings.Index(model, ".")
+		if i < 0 {
+			return DefaultModelInfo, false
+		}
+		model = model[i+1:]
+	}
+}
+
+func lookupPrefix(model string) (ModelInfo, bool) {
+	if info, ok := models[model]; ok {
+		return info, true
+	}
+	var best string
+	for name := range models {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelInfo{}, false
+	}
+	return models[best], true
+}
@@ -0,0 +1,32 @@
+package tokenizer
+
+import "testing"
+
+func TestLookupModel(t *testing.T) {
+	tests := []struct {
+		model    string
+		window   int
+		encoding string
+		known    bool
+	}{
+		{"gpt-4o", 128_000, EncodingO200K, true},
+		{"gpt-4o-2024-08-06", 128_000, EncodingO200K, true},
+		{"gpt-4-0613", 8192, EncodingCL100K, true},
+		{"gpt-4.1-mini", 1_047_576, EncodingO200K, true},
+		{"claude-sonnet-4-20250514", 200_000, EncodingCL100K, true},
+		{"us.anthropic.claude-3-5-sonnet-20240620-v1:0", 200_000, EncodingCL100K, true},
+		{"models/gemini-2.5-pro", 1_048_576, "", true},
+		{"Gemini-1.5-Pro-002", 2_097_152, "", true},
+		{"llama3.1:8b", DefaultModelInfo.ContextWindow, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			info, known := LookupModel(tt.model)
+			if known != tt.known || info.ContextWindow != tt.window || info.Encoding != tt.encoding {
+				t.Errorf("LookupModel(%q) = %+v, %v; want window %d, encoding %q, known %v",
+					tt.model, info, known, tt.window, tt.encoding, tt.known)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitter returns the end of the piece starting at byte offset i. Pieces are
+// the units BPE merges within; they never span two words.
+type splitter func(text string, i int) int
+
+// splitCL100K follows the cl100k_base pre-tokenizer pattern:
+//
+//	'(?i:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitCL100K(text string, i int) int {
+	if end := matchContraction(text, i); end > i {
+		return end
+	}
+	if end := matchLetters(text, i); end > i {
+		return end
+	}
+	return splitCommon(text, i, "\r\n")
+}
+
+// splitO200K follows the o200k_base pre-tokenizer pattern, which also splits
+// words at case changes and keeps contractions with their word:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?
+//	|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?
+//	|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitO200K(text string, i int) int {
+	start := i
+	if r, size := utf8.DecodeRuneInString(text[i:]); isPrefix(r) {
+		start = i + size
+	}
+	if end := matchCasedWord(text, start); end > start {
+		if contraction := matchContraction(text, end); contraction > end {
+			return contraction
+		}
+		return end
+	}
+	return splitCommon(text, i, "\r\n/")
+}
+
+// splitCommon matches the numbers, punctuation and whitespace alternatives
+// both patterns share. trailing holds what may follow a punctuation run.
+func splitCommon(text string, i int, trailing string) int {
+	r, size := utf8.DecodeRuneInString(text[i:])
+
+	// \p{N}{1,3}
+	if unicode.IsNumber(r) {
+		end := i
+		for n := 0; n < 3 && end < len(text); n++ {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsNumber(r) {
+				break
+			}
+			end += size
+		}
+		return end
+	}
+
+	//  ?[^\s\p{L}\p{N}]+[\r\n]*
+	punct := i
+	if r == ' ' {
+		punct += size
+	}
+	if end := runEnd(text, punct, isPunct); end > punct {
+		for end < len(text) && strings.IndexByte(trailing, text[end]) >= 0 {
+			end++
+		}
+		return end
+	}
+
+	// \s*[\r\n]+
+	spaces := runEnd(text, i, unicode.IsSpace)
+	if newline := strings.LastIndexAny(text[i:spaces], "\r\n"); newline >= 0 {
+		return i + newline + 1
+	}
+
+	// \s+(?!\S) leaves the last space to the following word; \s+ otherwise
+	if spaces > i {
+		if spaces == len(text) {
+			return spaces
+		}
+		_, last := utf8.DecodeLastRuneInString(text[i:spaces])
+		if spaces-last > i {
+			return spaces - last
+		}
+		return spaces
+	}
+
+	// Not reachable with valid patterns; consume one rune
+	return i + size
+}
+
+// matchContraction matches '(?i:s|t|re|ve|m|ll|d) at i
+func matchContraction(text string, i int) int {
+	if i >= len(text) || text[i] != '\'' {
+		return i
+	}
+	rest := strings.ToLower(text[i+1 : min(i+3, len(text))])
+	switch {
+	case strings.HasPrefix(rest, "ll"), strings.HasPrefix(rest, "ve"), strings.HasPrefix(rest, "re"):
+		return i + 3
+	case rest != "" && strings.IndexByte("sdmt", rest[0]) >= 0:
+		return i + 2
+	}
+	return i
+}
+
+// matchLetters matches [^\r\n\p{L}\p{N}]?\p{L}+ at i
+func matchLetters(text string, i int) int {
+	start := i
+	if r, size := utf8.DecodeRuneInString(text[i:]); isPrefix(r) {
+		start = i + size
+	}
+	if end := runEnd(text, start, unicode.IsLetter); end > start {
+		return end
+	}
+	return i
+}
+
+// matchCasedWord matches [upper]*[lower]+ or else [upper]+[lower]* at i, the
+// classes being o200k_base's, which overlap for uncased letters and marks
+func matchCasedWord(text string, i int) int {
+	upperEnd := runEnd(text, i, isUpperClass)
+	if r, _ := utf8.DecodeRuneInString(text[upperEnd:]); upperEnd < len(text) && isLowerClass(r) {
+		return runEnd(text, upperEnd, isLowerClass)
+	}
+	// Backtrack into the upper run for its last letter that is also lowercase-class
+	for end := upperEnd; end > i; {
+		r, size := utf8.DecodeLastRuneInString(text[i:end])
+		if isLowerClass(r) {
+			return end
+		}
+		end -= size
+	}
+	return upperEnd
+}
+
+func runEnd(text string, i int, in func(rune) bool) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !in(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isPrefix reports whether r may precede a word: [^\r\n\p{L}\p{N}]
+func isPrefix(r rune) bool {
+	return r != '\r' && r != '\n' && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// isPunct matches [^\s\p{L}\p{N}]
+func isPunct(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isUpperClass(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLowerClass(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
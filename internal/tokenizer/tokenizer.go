@@ -0,0 +1,169 @@
+// Package tokenizer counts tokens for context budgeting. No vocabulary ships
+// with gendocs: OpenAI models are counted exactly once their rank file has been
+// downloaded (see Fetch), Claude models are estimated with cl100k_base, and
+// other models, or those without a rank file, use a heuristic estimate.
+package tokenizer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenizerDirEnv overrides the directory rank files are read from
+const TokenizerDirEnv = "GENDOCS_TOKENIZER_DIR"
+
+// maxPieceBytes bounds the pieces merged at once; BPE is quadratic in the piece
+// length and longer pieces (minified code, base64 blobs) are counted in chunks
+const maxPieceBytes = 256
+
+// Tokenizer counts the tokens of text for a model
+type Tokenizer struct {
+	encoding    *Encoding // nil for the heuristic estimate
+	approximate bool      // encoding stands in for the model's own vocabulary
+}
+
+// ForModel returns the tokenizer of a model: its BPE encoding when the rank
+// file can be loaded, otherwise the heuristic estimate
+func ForModel(model string) *Tokenizer {
+	info, _ := LookupModel(model)
+	if info.Encoding == "" {
+		return Heuristic()
+	}
+	return &Tokenizer{encoding: loadEncoding(info.Encoding), approximate: info.Approximate}
+}
+
+// Heuristic returns a tokenizer that estimates counts from the text's words,
+// numbers and punctuation, tuned to over- rather than under-count
+func Heuristic() *Tokenizer {
+	return &Tokenizer{}
+}
+
+// Exact reports whether counts come from the model's own BPE vocabulary rather
+// than an estimate
+func (t *Tokenizer) Exact() bool {
+	return t.encoding != nil && !t.approximate
+}
+
+// Count returns the number of tokens in text
+func (t *Tokenizer) Count(text string) int {
+	count := 0
+	t.pieces(text, func(_, _ int, tokens int) bool {
+		count += tokens
+		return true
+	})
+	return count
+}
+
+// Truncate returns the longest prefix of text with at most maxTokens tokens,
+// cut at a piece boundary, and whether text was cut
+func (t *Tokenizer) Truncate(text string, maxTokens int) (string, bool) {
+	count, end := 0, 0
+	t.pieces(text, func(_, pieceEnd int, tokens int) bool {
+		if count+tokens > maxTokens {
+			return false
+		}
+		count += tokens
+		end = pieceEnd
+		return true
+	})
+	return text[:end], end < len(text)
+}
+
+// pieces calls fn with the byte range and token count of each piece of text until fn returns false
+func (t *Tokenizer) pieces(text string, fn func(start, end, tokens int) bool) {
+	split := splitCL100K
+	if t.encoding != nil {
+		split = t.encoding.split
+	}
+
+	for i := 0; i < len(text); {
+		end := split(text, i)
+		for chunk := i; chunk < end; {
+			chunkEnd := min(chunk+maxPieceBytes, end)
+			for chunkEnd < end && !utf8.RuneStart(text[chunkEnd]) {
+				chunkEnd++
+			}
+			if !fn(chunk, chunkEnd, t.countPiece(text[chunk:chunkEnd])) {
+				return
+			}
+			chunk = chunkEnd
+		}
+		i = end
+	}
+}
+
+func (t *Tokenizer) countPiece(piece string) int {
+	if t.encoding != nil {
+		return t.encoding.countPiece(piece)
+	}
+	return estimatePiece(piece)
+}
+
+// estimatePiece estimates the tokens of a piece: words of up to five letters,
+// numbers (at most three digits) and short punctuation runs are usually a single
+// token, long whitespace runs (indentation) merge well, and characters outside
+// ASCII take about one token each
+func estimatePiece(piece string) int {
+	var letters, digits, spaces, punct, other int
+	for _, r := range piece {
+		switch {
+		case r >= utf8.RuneSelf:
+			other++
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsSpace(r):
+			spaces++
+		default:
+			punct++
+		}
+	}
+
+	tokens := other + (letters+4)/5 + (punct+1)/2
+	if digits > 0 {
+		tokens++
+	}
+	// A space before a word or punctuation is part of its token
+	if letters+digits+punct+other == 0 {
+		tokens += (spaces + 7) / 8
+	}
+	return max(tokens, 1)
+}
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = make(map[string]*Encoding)
+)
+
+// loadEncoding loads a rank file once, remembering a missing file as nil
+func loadEncoding(name string) *Encoding {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+
+	if encoding, ok := encodings[name]; ok {
+		return encoding
+	}
+	var encoding *Encoding
+	if dir := Dir(); dir != "" {
+		encoding, _ = LoadEncoding(name, filepath.Join(dir, name+".tiktoken"))
+	}
+	encodings[name] = encoding
+	return encoding
+}
+
+// Dir returns the directory rank files are read from: $GENDOCS_TOKENIZER_DIR,
+// or gendocs/tokenizers in the user cache directory
+func Dir() string {
+	if dir := os.Getenv(TokenizerDirEnv); dir != "" {
+		return dir
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "gendocs", "tokenizers")
+}
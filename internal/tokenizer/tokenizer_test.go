@@ -0,0 +1,83 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenizer_CountAndTruncate(t *testing.T) {
+	tok := &Tokenizer{encoding: newEncoding(EncodingCL100K, testRanks)}
+
+	if got := tok.Count("ab abcd"); got != 3 {
+		t.Errorf("Count = %d, want 3", got)
+	}
+	if got, cut := tok.Truncate("ab abcd", 2); got != "ab" || !cut {
+		t.Errorf("Truncate = %q, %v; want the first piece", got, cut)
+	}
+	if got, cut := tok.Truncate("ab abcd", 3); got != "ab abcd" || cut {
+		t.Errorf("Truncate = %q, %v; want the whole text", got, cut)
+	}
+}
+
+func TestTokenizer_LongPiecesAreChunked(t *testing.T) {
+	tok := &Tokenizer{encoding: newEncoding(EncodingCL100K, testRanks)}
+
+	// One piece of 4000 bytes merges into 1000 "abcd" tokens, a chunk at a time
+	if got := tok.Count(strings.Repeat("abcd", 1000)); got != 1000 {
+		t.Errorf("Count = %d, want 1000", got)
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	tok := Heuristic()
+
+	tests := map[string]int{
+		"":                     0,
+		"Hello world":          2,
+		"func main() {\n}":     5, // func, " main", "()", " {\n", "}"
+		"12345":                2,
+		"        return":       3, // Seven spaces, " return"
+		"日本語":                  3,
+		"internationalization": 4,
+	}
+	for text, want := range tests {
+		if got := tok.Count(text); got != want {
+			t.Errorf("Count(%q) = %d, want %d", text, got, want)
+		}
+	}
+
+	code := strings.Repeat("func (s *Server) handle(w http.ResponseWriter, r *http.Request) {\n", 100)
+	if got := tok.Count(code); got < len(code)/4 {
+		t.Errorf("expected the heuristic not to undercount code, got %d tokens for %d bytes", got, len(code))
+	}
+}
+
+func TestForModel(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(TokenizerDirEnv, dir)
+	encodingsMu.Lock()
+	encodings = make(map[string]*Encoding)
+	encodingsMu.Unlock()
+
+	var lines []string
+	for token, rank := range testRanks {
+		lines = append(lines, fmt.Sprintf("%s %d", base64.StdEncoding.EncodeToString([]byte(token)), rank))
+	}
+	if err := os.WriteFile(filepath.Join(dir, EncodingO200K+".tiktoken"), []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if tok := ForModel("gpt-4o-mini"); !tok.Exact() || tok.Count("abcd") != 1 {
+		t.Errorf("expected gpt-4o to use the o200k_base rank file")
+	}
+	if tok := ForModel("claude-sonnet-4"); tok.Exact() {
+		t.Errorf("expected the heuristic without a cl100k_base rank file")
+	}
+	if tok := ForModel("gemini-2.5-pro"); tok.Exact() {
+		t.Errorf("expected the heuristic for Gemini")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/user/gendocs/internal/tokenizer"
 )
 
 // MaxToolResponseSize is the maximum size of a tool response in bytes
@@ -361,8 +363,7 @@ func simpleGlobMatch(name, pattern string) bool {
 	return name == pattern
 }
 
-// EstimateTokens estimates the number of tokens in a string
-// Approximate rule: 1 token ≈ 4 characters for English text
+// EstimateTokens estimates the number of tokens in a string when the model is unknown
 func EstimateTokens(text string) int {
-	return len(text) / 4
+	return tokenizer.Heuristic().Count(text)
 }
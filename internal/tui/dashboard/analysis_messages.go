@@ -2,6 +2,8 @@ package dashboard
 
 import (
 	"time"
+
+	"github.com/user/gendocs/internal/llm"
 )
 
 type RunAnalysisMsg struct{}
@@ -28,6 +30,7 @@ type AnalysisCompleteMsg struct {
 	Successful []string
 	Failed     []FailedAnalysis
 	Duration   time.Duration
+	Usage      *llm.UsageSummary
 }
 
 type FailedAnalysis struct {
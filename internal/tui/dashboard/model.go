@@ -273,6 +273,7 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			Successful: len(msg.Successful),
 			Failed:     len(msg.Failed),
 			Duration:   msg.Duration,
+			Usage:      msg.Usage,
 		})
 		if section, ok := m.sections["analysis"]; ok {
 			section.Update(sections.AnalysisStoppedMsg{})
@@ -296,7 +297,7 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if section, ok := m.sections["analysis"]; ok {
 			section.Update(sections.AnalysisStoppedMsg{})
 		}
-		return m, ShowInfo("Analysis cancelled")
+		return m, ShowInfo("Analysis cancelled - resume it with gendocs analyze --resume")
 
 	case TickMsg:
 		if m.progressView.Visible() && !m.progressView.IsComplete() {
@@ -483,7 +484,7 @@ func (m *DashboardModel) runAnalysis() tea.Cmd {
 		handler.SetProgressReporter(reporter)
 
 		startTime := time.Now()
-		err := handler.Handle(m.analysisCtx)
+		result, err := handler.Run(m.analysisCtx)
 		duration := time.Since(startTime)
 
 		if m.analysisCtx.Err() == context.Canceled {
@@ -496,6 +497,7 @@ func (m *DashboardModel) runAnalysis() tea.Cmd {
 
 		return AnalysisCompleteMsg{
 			Duration: duration,
+			Usage:    result.UsageSummary,
 		}
 	}
 }
@@ -514,6 +516,9 @@ func (m *DashboardModel) buildAnalyzerConfig() config.AnalyzerConfig {
 		cfg.ExcludeDeps = m.cfg.Analyzer.ExcludeDeps
 		cfg.ExcludeReqFlow = m.cfg.Analyzer.ExcludeReqFlow
 		cfg.ExcludeAPI = m.cfg.Analyzer.ExcludeAPI
+		cfg.ExcludeSecurity = m.cfg.Analyzer.ExcludeSecurity
+		cfg.ExcludeTesting = m.cfg.Analyzer.ExcludeTesting
+		cfg.ExcludeInfra = m.cfg.Analyzer.ExcludeInfra
 		cfg.MaxWorkers = m.cfg.Analyzer.MaxWorkers
 		cfg.MaxHashWorkers = m.cfg.Analyzer.MaxHashWorkers
 		cfg.Force = m.cfg.Analyzer.Force
@@ -545,6 +550,15 @@ func (m *DashboardModel) applyAnalysisValues(cfg *config.AnalyzerConfig, values
 	if v, ok := values["exclude_api_analysis"].(bool); ok {
 		cfg.ExcludeAPI = v
 	}
+	if v, ok := values["exclude_security_analysis"].(bool); ok {
+		cfg.ExcludeSecurity = v
+	}
+	if v, ok := values["exclude_testing_analysis"].(bool); ok {
+		cfg.ExcludeTesting = v
+	}
+	if v, ok := values["exclude_infrastructure_analysis"].(bool); ok {
+		cfg.ExcludeInfra = v
+	}
 	if v, ok := values["max_workers"].(int); ok {
 		cfg.MaxWorkers = v
 	}
@@ -624,15 +638,18 @@ func (m *DashboardModel) populateSections() {
 
 	if section, ok := m.sections["analysis"]; ok {
 		_ = section.SetValues(map[string]any{
-			"exclude_code_structure": m.cfg.Analyzer.ExcludeStructure,
-			"exclude_data_flow":      m.cfg.Analyzer.ExcludeDataFlow,
-			"exclude_dependencies":   m.cfg.Analyzer.ExcludeDeps,
-			"exclude_request_flow":   m.cfg.Analyzer.ExcludeReqFlow,
-			"exclude_api_analysis":   m.cfg.Analyzer.ExcludeAPI,
-			"max_workers":            m.cfg.Analyzer.MaxWorkers,
-			"max_hash_workers":       m.cfg.Analyzer.MaxHashWorkers,
-			"force":                  m.cfg.Analyzer.Force,
-			"incremental":            m.cfg.Analyzer.Incremental,
+			"exclude_code_structure":          m.cfg.Analyzer.ExcludeStructure,
+			"exclude_data_flow":               m.cfg.Analyzer.ExcludeDataFlow,
+			"exclude_dependencies":            m.cfg.Analyzer.ExcludeDeps,
+			"exclude_request_flow":            m.cfg.Analyzer.ExcludeReqFlow,
+			"exclude_api_analysis":            m.cfg.Analyzer.ExcludeAPI,
+			"exclude_security_analysis":       m.cfg.Analyzer.ExcludeSecurity,
+			"exclude_testing_analysis":        m.cfg.Analyzer.ExcludeTesting,
+			"exclude_infrastructure_analysis": m.cfg.Analyzer.ExcludeInfra,
+			"max_workers":                     m.cfg.Analyzer.MaxWorkers,
+			"max_hash_workers":                m.cfg.Analyzer.MaxHashWorkers,
+			"force":                           m.cfg.Analyzer.Force,
+			"incremental":                     m.cfg.Analyzer.Incremental,
 		})
 	}
 
@@ -647,9 +664,25 @@ func (m *DashboardModel) populateSections() {
 
 	if section, ok := m.sections["gemini"]; ok {
 		_ = section.SetValues(map[string]any{
-			"use_vertex_ai": m.cfg.Gemini.UseVertexAI,
-			"project_id":    m.cfg.Gemini.ProjectID,
-			"location":      m.cfg.Gemini.Location,
+			"use_vertex_ai":    m.cfg.Gemini.UseVertexAI,
+			"project_id":       m.cfg.Gemini.ProjectID,
+			"location":         m.cfg.Gemini.Location,
+			"credentials_file": m.cfg.Gemini.CredentialsFile,
+		})
+	}
+
+	if section, ok := m.sections["azure"]; ok {
+		_ = section.SetValues(map[string]any{
+			"azure_deployment":  m.cfg.Analyzer.LLM.Azure.Deployment,
+			"azure_api_version": m.cfg.Analyzer.LLM.Azure.APIVersion,
+		})
+	}
+
+	if section, ok := m.sections["bedrock"]; ok {
+		_ = section.SetValues(map[string]any{
+			"bedrock_region":            m.cfg.Analyzer.LLM.Bedrock.Region,
+			"bedrock_access_key_id":     m.cfg.Analyzer.LLM.Bedrock.AccessKeyID,
+			"bedrock_secret_access_key": m.cfg.Analyzer.LLM.Bedrock.SecretAccessKey,
 		})
 	}
 
@@ -668,6 +701,12 @@ func (m *DashboardModel) populateSections() {
 			"max_days_since_last_commit": m.cfg.Cronjob.MaxDaysSinceLastCommit,
 			"working_path":               m.cfg.Cronjob.WorkingPath,
 			"group_project_id":           m.cfg.Cronjob.GroupProjectID,
+			"ignore_projects":            m.cfg.Cronjob.IgnoreProjects,
+			"ignore_subgroups":           m.cfg.Cronjob.IgnoreSubgroups,
+			"topics":                     m.cfg.Cronjob.Topics,
+			"visibility":                 m.cfg.Cronjob.Visibility,
+			"languages":                  m.cfg.Cronjob.Languages,
+			"min_repo_size_kb":           m.cfg.Cronjob.MinRepoSizeKB,
 		})
 	}
 
@@ -788,6 +827,15 @@ func (m *DashboardModel) applyValuesToConfig(values map[string]any) {
 	if v, ok := values["exclude_api_analysis"].(bool); ok {
 		m.cfg.Analyzer.ExcludeAPI = v
 	}
+	if v, ok := values["exclude_security_analysis"].(bool); ok {
+		m.cfg.Analyzer.ExcludeSecurity = v
+	}
+	if v, ok := values["exclude_testing_analysis"].(bool); ok {
+		m.cfg.Analyzer.ExcludeTesting = v
+	}
+	if v, ok := values["exclude_infrastructure_analysis"].(bool); ok {
+		m.cfg.Analyzer.ExcludeInfra = v
+	}
 	if v, ok := values["max_workers"].(int); ok {
 		m.cfg.Analyzer.MaxWorkers = v
 	}
@@ -823,6 +871,26 @@ func (m *DashboardModel) applyValuesToConfig(values map[string]any) {
 	if v, ok := values["location"].(string); ok {
 		m.cfg.Gemini.Location = v
 	}
+	if v, ok := values["credentials_file"].(string); ok {
+		m.cfg.Gemini.CredentialsFile = v
+	}
+
+	if v, ok := values["azure_deployment"].(string); ok {
+		m.cfg.Analyzer.LLM.Azure.Deployment = v
+	}
+	if v, ok := values["azure_api_version"].(string); ok {
+		m.cfg.Analyzer.LLM.Azure.APIVersion = v
+	}
+
+	if v, ok := values["bedrock_region"].(string); ok {
+		m.cfg.Analyzer.LLM.Bedrock.Region = v
+	}
+	if v, ok := values["bedrock_access_key_id"].(string); ok {
+		m.cfg.Analyzer.LLM.Bedrock.AccessKeyID = v
+	}
+	if v, ok := values["bedrock_secret_access_key"].(string); ok {
+		m.cfg.Analyzer.LLM.Bedrock.SecretAccessKey = v
+	}
 
 	if v, ok := values["gitlab_api_url"].(string); ok {
 		m.cfg.GitLab.APIURL = v
@@ -849,6 +917,24 @@ func (m *DashboardModel) applyValuesToConfig(values map[string]any) {
 	if v, ok := values["group_project_id"].(int); ok {
 		m.cfg.Cronjob.GroupProjectID = v
 	}
+	if v, ok := values["ignore_projects"].([]string); ok {
+		m.cfg.Cronjob.IgnoreProjects = v
+	}
+	if v, ok := values["ignore_subgroups"].([]string); ok {
+		m.cfg.Cronjob.IgnoreSubgroups = v
+	}
+	if v, ok := values["topics"].([]string); ok {
+		m.cfg.Cronjob.Topics = v
+	}
+	if v, ok := values["visibility"].([]string); ok {
+		m.cfg.Cronjob.Visibility = v
+	}
+	if v, ok := values["languages"].([]string); ok {
+		m.cfg.Cronjob.Languages = v
+	}
+	if v, ok := values["min_repo_size_kb"].(int); ok {
+		m.cfg.Cronjob.MinRepoSizeKB = v
+	}
 
 	if v, ok := values["log_dir"].(string); ok {
 		m.cfg.Logging.LogDir = v
@@ -6,6 +6,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/user/gendocs/internal/llm"
 	"github.com/user/gendocs/internal/tui"
 )
 
@@ -34,6 +35,7 @@ type AnalysisSummary struct {
 	Failed     int
 	Skipped    int
 	Duration   time.Duration
+	Usage      *llm.UsageSummary
 }
 
 type ProgressViewModel struct {
@@ -233,10 +235,16 @@ func (m *ProgressViewModel) formatSummary() string {
 	text := fmt.Sprintf("  Completed: %d | Failed: %d | Skipped: %d | Duration: %s",
 		s.Successful, s.Failed, s.Skipped, s.Duration.Round(time.Second))
 
+	style := tui.StyleSuccess
 	if s.Failed > 0 {
-		return tui.StyleWarning.Render(text)
+		style = tui.StyleWarning
 	}
-	return tui.StyleSuccess.Render(text)
+	text = style.Render(text)
+
+	if s.Usage != nil && s.Usage.Calls > 0 {
+		text += "\n" + tui.StyleMuted.Render("  "+s.Usage.String())
+	}
+	return text
 }
 
 type TickMsg time.Time
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/user/gendocs/internal/llm"
 )
 
 func TestProgressView_NewProgressView(t *testing.T) {
@@ -310,6 +312,29 @@ func TestProgressView_FormatSummary(t *testing.T) {
 	}
 }
 
+func TestProgressView_FormatSummary_Usage(t *testing.T) {
+	pv := NewProgressView()
+	pv.summary = &AnalysisSummary{
+		Successful: 5,
+		Duration:   time.Minute,
+		Usage: &llm.UsageSummary{UsageTotals: llm.UsageTotals{
+			Calls:        10,
+			InputTokens:  5000,
+			OutputTokens: 800,
+			Cost:         0.025,
+		}},
+	}
+
+	summary := pv.formatSummary()
+
+	if !strings.Contains(summary, "Tokens: 5000 in / 800 out") {
+		t.Errorf("summary should contain token usage, got: %s", summary)
+	}
+	if !strings.Contains(summary, "Est. cost: $0.0250") {
+		t.Errorf("summary should contain estimated cost, got: %s", summary)
+	}
+}
+
 func TestProgressView_MultipleTasks(t *testing.T) {
 	pv := NewProgressView()
 	pv.Show()
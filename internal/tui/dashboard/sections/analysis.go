@@ -19,6 +19,9 @@ type AnalysisSectionModel struct {
 	excludeDeps      components.ToggleModel
 	excludeReqFlow   components.ToggleModel
 	excludeAPI       components.ToggleModel
+	excludeSecurity  components.ToggleModel
+	excludeTesting   components.ToggleModel
+	excludeInfra     components.ToggleModel
 	maxWorkers       components.TextFieldModel
 	maxHashWorkers   components.TextFieldModel
 	force            components.ToggleModel
@@ -36,6 +39,9 @@ func NewAnalysisSection() *AnalysisSectionModel {
 		excludeDeps:      components.NewToggle("Exclude Dependencies", "Skip dependency analysis"),
 		excludeReqFlow:   components.NewToggle("Exclude Request Flow", "Skip request flow analysis"),
 		excludeAPI:       components.NewToggle("Exclude API Analysis", "Skip API analysis"),
+		excludeSecurity:  components.NewToggle("Exclude Security Analysis", "Skip security analysis"),
+		excludeTesting:   components.NewToggle("Exclude Testing Analysis", "Skip testing analysis"),
+		excludeInfra:     components.NewToggle("Exclude Infrastructure", "Skip infrastructure analysis"),
 		maxWorkers: components.NewTextField("Max Workers",
 			components.WithPlaceholder("0 (auto)"),
 			components.WithValidator(validation.ValidateIntRange(0, 32)),
@@ -59,6 +65,9 @@ func NewAnalysisSection() *AnalysisSectionModel {
 		components.WrapToggle(&m.excludeDeps),
 		components.WrapToggle(&m.excludeReqFlow),
 		components.WrapToggle(&m.excludeAPI),
+		components.WrapToggle(&m.excludeSecurity),
+		components.WrapToggle(&m.excludeTesting),
+		components.WrapToggle(&m.excludeInfra),
 		components.WrapTextField(&m.maxWorkers),
 		components.WrapTextField(&m.maxHashWorkers),
 		components.WrapToggle(&m.force),
@@ -118,6 +127,9 @@ func (m *AnalysisSectionModel) View() string {
 		m.excludeDeps.View(),
 		m.excludeReqFlow.View(),
 		m.excludeAPI.View(),
+		m.excludeSecurity.View(),
+		m.excludeTesting.View(),
+		m.excludeInfra.View(),
 		"",
 		workers,
 		"",
@@ -145,6 +157,9 @@ func (m *AnalysisSectionModel) GetValues() map[string]any {
 		KeyExcludeDependencies:  m.excludeDeps.Value(),
 		KeyExcludeRequestFlow:   m.excludeReqFlow.Value(),
 		KeyExcludeAPIAnalysis:   m.excludeAPI.Value(),
+		KeyExcludeSecurity:      m.excludeSecurity.Value(),
+		KeyExcludeTesting:       m.excludeTesting.Value(),
+		KeyExcludeInfra:         m.excludeInfra.Value(),
 		KeyForce:                m.force.Value(),
 		KeyIncremental:          m.incremental.Value(),
 	}
@@ -177,6 +192,15 @@ func (m *AnalysisSectionModel) SetValues(values map[string]any) error {
 	if v, ok := values[KeyExcludeAPIAnalysis].(bool); ok {
 		m.excludeAPI.SetValue(v)
 	}
+	if v, ok := values[KeyExcludeSecurity].(bool); ok {
+		m.excludeSecurity.SetValue(v)
+	}
+	if v, ok := values[KeyExcludeTesting].(bool); ok {
+		m.excludeTesting.SetValue(v)
+	}
+	if v, ok := values[KeyExcludeInfra].(bool); ok {
+		m.excludeInfra.SetValue(v)
+	}
 	if v, ok := values[KeyMaxWorkers].(int); ok {
 		m.maxWorkers.SetValue(strconv.Itoa(v))
 	}
@@ -0,0 +1,132 @@
+package sections
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/gendocs/internal/config"
+	"github.com/user/gendocs/internal/tui"
+	"github.com/user/gendocs/internal/tui/dashboard/components"
+	"github.com/user/gendocs/internal/tui/dashboard/types"
+)
+
+type AzureSectionModel struct {
+	deployment components.TextFieldModel
+	apiVersion components.TextFieldModel
+
+	focusIndex int
+}
+
+func NewAzureSection() *AzureSectionModel {
+	return &AzureSectionModel{
+		deployment: components.NewTextField("Deployment",
+			components.WithPlaceholder("gpt-4o"),
+			components.WithHelp("Azure deployment name; defaults to the model name")),
+		apiVersion: components.NewTextField("API Version",
+			components.WithPlaceholder(config.DefaultAzureAPIVersion),
+			components.WithHelp("Value of the api-version query parameter")),
+	}
+}
+
+func (m *AzureSectionModel) Title() string       { return "Azure OpenAI" }
+func (m *AzureSectionModel) Icon() string        { return "🔷" }
+func (m *AzureSectionModel) Description() string { return "Configure Azure OpenAI deployments" }
+
+func (m *AzureSectionModel) Init() tea.Cmd { return nil }
+
+func (m *AzureSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab":
+			m.blurCurrent()
+			m.focusIndex = (m.focusIndex + 1) % 2
+			return m, m.focusCurrent()
+		}
+	}
+
+	switch m.focusIndex {
+	case 0:
+		m.deployment, _ = m.deployment.Update(msg)
+	case 1:
+		m.apiVersion, _ = m.apiVersion.Update(msg)
+	}
+
+	return m, nil
+}
+
+func (m *AzureSectionModel) blurCurrent() {
+	switch m.focusIndex {
+	case 0:
+		m.deployment.Blur()
+	case 1:
+		m.apiVersion.Blur()
+	}
+}
+
+func (m *AzureSectionModel) focusCurrent() tea.Cmd {
+	switch m.focusIndex {
+	case 0:
+		return m.deployment.Focus()
+	case 1:
+		return m.apiVersion.Focus()
+	}
+	return nil
+}
+
+func (m *AzureSectionModel) View() string {
+	header := tui.StyleSectionHeader.Render(m.Icon() + " " + m.Title())
+	desc := tui.StyleMuted.Render(m.Description())
+	note := tui.StyleInfo.Render("Select the azure provider and set the Base URL to the resource endpoint")
+
+	fields := lipgloss.JoinVertical(lipgloss.Left,
+		note,
+		"",
+		m.deployment.View(),
+		"",
+		m.apiVersion.View(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, desc, "", fields)
+}
+
+func (m *AzureSectionModel) Validate() []types.ValidationError {
+	return nil
+}
+
+func (m *AzureSectionModel) IsDirty() bool {
+	return m.deployment.IsDirty() || m.apiVersion.IsDirty()
+}
+
+func (m *AzureSectionModel) GetValues() map[string]any {
+	return map[string]any{
+		KeyAzureDeployment: m.deployment.Value(),
+		KeyAzureAPIVersion: m.apiVersion.Value(),
+	}
+}
+
+func (m *AzureSectionModel) SetValues(values map[string]any) error {
+	if v, ok := values[KeyAzureDeployment].(string); ok {
+		m.deployment.SetValue(v)
+	}
+	if v, ok := values[KeyAzureAPIVersion].(string); ok {
+		m.apiVersion.SetValue(v)
+	}
+	return nil
+}
+
+func (m *AzureSectionModel) FocusFirst() tea.Cmd {
+	m.blurAll()
+	m.focusIndex = 0
+	return m.deployment.Focus()
+}
+
+func (m *AzureSectionModel) FocusLast() tea.Cmd {
+	m.blurAll()
+	m.focusIndex = 1
+	return m.apiVersion.Focus()
+}
+
+func (m *AzureSectionModel) blurAll() {
+	m.deployment.Blur()
+	m.apiVersion.Blur()
+}
@@ -0,0 +1,170 @@
+package sections
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/gendocs/internal/tui"
+	"github.com/user/gendocs/internal/tui/dashboard/components"
+	"github.com/user/gendocs/internal/tui/dashboard/types"
+)
+
+type BedrockSectionModel struct {
+	region          components.TextFieldModel
+	accessKeyID     components.TextFieldModel
+	secretAccessKey components.MaskedInputModel
+
+	focusIndex int
+}
+
+func NewBedrockSection() *BedrockSectionModel {
+	return &BedrockSectionModel{
+		region: components.NewTextField("Region",
+			components.WithPlaceholder("us-east-1"),
+			components.WithHelp("AWS region; defaults to AWS_REGION")),
+		accessKeyID: components.NewTextField("Access Key ID",
+			components.WithPlaceholder("AKIA..."),
+			components.WithHelp("Optional: defaults to AWS_ACCESS_KEY_ID")),
+		secretAccessKey: components.NewMaskedInput("Secret Access Key", "Optional: defaults to AWS_SECRET_ACCESS_KEY"),
+	}
+}
+
+func (m *BedrockSectionModel) Title() string { return "AWS Bedrock" }
+func (m *BedrockSectionModel) Icon() string  { return "🟧" }
+func (m *BedrockSectionModel) Description() string {
+	return "Configure AWS Bedrock region and credentials"
+}
+
+func (m *BedrockSectionModel) Init() tea.Cmd { return nil }
+
+func (m *BedrockSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab":
+			m.blurCurrent()
+			m.focusIndex = (m.focusIndex + 1) % 3
+			cmds = append(cmds, m.focusCurrent())
+			return m, tea.Batch(cmds...)
+
+		case "shift+tab":
+			m.blurCurrent()
+			m.focusIndex--
+			if m.focusIndex < 0 {
+				m.focusIndex = 2
+			}
+			cmds = append(cmds, m.focusCurrent())
+			return m, tea.Batch(cmds...)
+		}
+	}
+
+	switch m.focusIndex {
+	case 0:
+		m.region, _ = m.region.Update(msg)
+	case 1:
+		m.accessKeyID, _ = m.accessKeyID.Update(msg)
+	case 2:
+		m.secretAccessKey, _ = m.secretAccessKey.Update(msg)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m *BedrockSectionModel) blurCurrent() {
+	switch m.focusIndex {
+	case 0:
+		m.region.Blur()
+	case 1:
+		m.accessKeyID.Blur()
+	case 2:
+		m.secretAccessKey.Blur()
+	}
+}
+
+func (m *BedrockSectionModel) focusCurrent() tea.Cmd {
+	switch m.focusIndex {
+	case 0:
+		return m.region.Focus()
+	case 1:
+		return m.accessKeyID.Focus()
+	case 2:
+		return m.secretAccessKey.Focus()
+	}
+	return nil
+}
+
+func (m *BedrockSectionModel) View() string {
+	header := tui.StyleSectionHeader.Render(m.Icon() + " " + m.Title())
+	desc := tui.StyleMuted.Render(m.Description())
+	note := tui.StyleInfo.Render("Requests are signed with these keys unless a Bedrock API key is set in the LLM section")
+
+	fields := lipgloss.JoinVertical(lipgloss.Left,
+		note,
+		"",
+		m.region.View(),
+		"",
+		m.accessKeyID.View(),
+		"",
+		m.secretAccessKey.View(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, desc, "", fields)
+}
+
+func (m *BedrockSectionModel) Validate() []types.ValidationError {
+	var errors []types.ValidationError
+
+	if (m.accessKeyID.Value() == "") != (m.secretAccessKey.Value() == "") {
+		errors = append(errors, types.ValidationError{
+			Field:    "Secret Access Key",
+			Message:  "Access Key ID and Secret Access Key must be set together",
+			Severity: types.SeverityError,
+		})
+	}
+
+	return errors
+}
+
+func (m *BedrockSectionModel) IsDirty() bool {
+	return m.region.IsDirty() || m.accessKeyID.IsDirty() || m.secretAccessKey.IsDirty()
+}
+
+func (m *BedrockSectionModel) GetValues() map[string]any {
+	return map[string]any{
+		KeyBedrockRegion:          m.region.Value(),
+		KeyBedrockAccessKeyID:     m.accessKeyID.Value(),
+		KeyBedrockSecretAccessKey: m.secretAccessKey.Value(),
+	}
+}
+
+func (m *BedrockSectionModel) SetValues(values map[string]any) error {
+	if v, ok := values[KeyBedrockRegion].(string); ok {
+		m.region.SetValue(v)
+	}
+	if v, ok := values[KeyBedrockAccessKeyID].(string); ok {
+		m.accessKeyID.SetValue(v)
+	}
+	if v, ok := values[KeyBedrockSecretAccessKey].(string); ok {
+		m.secretAccessKey.SetValue(v)
+	}
+	return nil
+}
+
+func (m *BedrockSectionModel) FocusFirst() tea.Cmd {
+	m.blurAll()
+	m.focusIndex = 0
+	return m.region.Focus()
+}
+
+func (m *BedrockSectionModel) FocusLast() tea.Cmd {
+	m.blurAll()
+	m.focusIndex = 2
+	return m.secretAccessKey.Focus()
+}
+
+func (m *BedrockSectionModel) blurAll() {
+	m.region.Blur()
+	m.accessKeyID.Blur()
+	m.secretAccessKey.Blur()
+}
@@ -22,6 +22,9 @@ const (
 	KeyExcludeDependencies  = "exclude_dependencies"
 	KeyExcludeRequestFlow   = "exclude_request_flow"
 	KeyExcludeAPIAnalysis   = "exclude_api_analysis"
+	KeyExcludeSecurity      = "exclude_security_analysis"
+	KeyExcludeTesting       = "exclude_testing_analysis"
+	KeyExcludeInfra         = "exclude_infrastructure_analysis"
 	KeyMaxWorkers           = "max_workers"
 	KeyMaxHashWorkers       = "max_hash_workers"
 	KeyForce                = "force"
@@ -49,6 +52,12 @@ const (
 	KeyMaxDaysSinceLastCommit = "max_days_since_last_commit"
 	KeyGroupProjectID         = "group_project_id"
 	KeyWorkingPath            = "working_path"
+	KeyIgnoreProjects         = "ignore_projects"
+	KeyIgnoreSubgroups        = "ignore_subgroups"
+	KeyTopics                 = "topics"
+	KeyVisibility             = "visibility"
+	KeyLanguages              = "languages"
+	KeyMinRepoSizeKB          = "min_repo_size_kb"
 )
 
 // Gemini configuration keys
@@ -56,6 +65,20 @@ const (
 	KeyUseVertexAI = "use_vertex_ai"
 	KeyProjectID   = "project_id"
 	KeyLocation    = "location"
+	KeyCredentials = "credentials_file"
+)
+
+// Azure OpenAI configuration keys
+const (
+	KeyAzureDeployment = "azure_deployment"
+	KeyAzureAPIVersion = "azure_api_version"
+)
+
+// AWS Bedrock configuration keys
+const (
+	KeyBedrockRegion          = "bedrock_region"
+	KeyBedrockAccessKeyID     = "bedrock_access_key_id"
+	KeyBedrockSecretAccessKey = "bedrock_secret_access_key"
 )
 
 // GitLab configuration keys
@@ -2,6 +2,7 @@ package sections
 
 import (
 	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,12 +16,18 @@ type CronjobSectionModel struct {
 	maxDaysSinceLastCommit components.TextFieldModel
 	workingPath            components.TextFieldModel
 	groupProjectID         components.TextFieldModel
-
-	focusIndex int
+	ignoreProjects         components.TextFieldModel
+	ignoreSubgroups        components.TextFieldModel
+	topics                 components.TextFieldModel
+	visibility             components.TextFieldModel
+	languages              components.TextFieldModel
+	minRepoSizeKB          components.TextFieldModel
+
+	inputs *components.FocusableSlice
 }
 
 func NewCronjobSection() *CronjobSectionModel {
-	return &CronjobSectionModel{
+	m := &CronjobSectionModel{
 		maxDaysSinceLastCommit: components.NewTextField("Max Days Since Last Commit",
 			components.WithPlaceholder("30"),
 			components.WithValidator(validation.ValidateIntRange(1, 365)),
@@ -33,7 +40,41 @@ func NewCronjobSection() *CronjobSectionModel {
 			components.WithPlaceholder("12345"),
 			components.WithValidator(validation.ValidatePositiveInt()),
 			components.WithHelp("GitLab group or project ID")),
+		ignoreProjects: components.NewTextField("Ignore Projects",
+			components.WithPlaceholder("group/legacy-*, group/sandbox"),
+			components.WithHelp("Comma-separated globs matched against the project path")),
+		ignoreSubgroups: components.NewTextField("Ignore Subgroups",
+			components.WithPlaceholder("group/archive, group/*/experiments"),
+			components.WithHelp("Comma-separated globs; nested subgroups are ignored too")),
+		topics: components.NewTextField("Topics",
+			components.WithPlaceholder("service, api"),
+			components.WithHelp("Only analyze projects with at least one of these topics")),
+		visibility: components.NewTextField("Visibility",
+			components.WithPlaceholder("internal, private"),
+			components.WithValidator(validation.ValidateEnumList("public", "internal", "private")),
+			components.WithHelp("Allowed visibilities (public, internal, private)")),
+		languages: components.NewTextField("Languages",
+			components.WithPlaceholder("Go, Python"),
+			components.WithHelp("Allowed primary languages")),
+		minRepoSizeKB: components.NewTextField("Min Repo Size (KB)",
+			components.WithPlaceholder("0"),
+			components.WithValidator(validation.ValidateIntRange(0, 100000000)),
+			components.WithHelp("Skip repositories smaller than this")),
 	}
+
+	m.inputs = components.NewFocusableSlice(
+		components.WrapTextField(&m.maxDaysSinceLastCommit),
+		components.WrapTextField(&m.workingPath),
+		components.WrapTextField(&m.groupProjectID),
+		components.WrapTextField(&m.ignoreProjects),
+		components.WrapTextField(&m.ignoreSubgroups),
+		components.WrapTextField(&m.topics),
+		components.WrapTextField(&m.visibility),
+		components.WrapTextField(&m.languages),
+		components.WrapTextField(&m.minRepoSizeKB),
+	)
+
+	return m
 }
 
 func (m *CronjobSectionModel) Title() string { return "Cronjob Settings" }
@@ -45,61 +86,16 @@ func (m *CronjobSectionModel) Description() string {
 func (m *CronjobSectionModel) Init() tea.Cmd { return nil }
 
 func (m *CronjobSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
+	if msg, ok := msg.(tea.KeyMsg); ok {
 		switch msg.String() {
 		case "tab":
-			m.blurCurrent()
-			m.focusIndex = (m.focusIndex + 1) % 3
-			cmds = append(cmds, m.focusCurrent())
-			return m, tea.Batch(cmds...)
-
+			return m, m.inputs.FocusNext()
 		case "shift+tab":
-			m.blurCurrent()
-			m.focusIndex--
-			if m.focusIndex < 0 {
-				m.focusIndex = 2
-			}
-			cmds = append(cmds, m.focusCurrent())
-			return m, tea.Batch(cmds...)
+			return m, m.inputs.FocusPrev()
 		}
 	}
 
-	switch m.focusIndex {
-	case 0:
-		m.maxDaysSinceLastCommit, _ = m.maxDaysSinceLastCommit.Update(msg)
-	case 1:
-		m.workingPath, _ = m.workingPath.Update(msg)
-	case 2:
-		m.groupProjectID, _ = m.groupProjectID.Update(msg)
-	}
-
-	return m, tea.Batch(cmds...)
-}
-
-func (m *CronjobSectionModel) blurCurrent() {
-	switch m.focusIndex {
-	case 0:
-		m.maxDaysSinceLastCommit.Blur()
-	case 1:
-		m.workingPath.Blur()
-	case 2:
-		m.groupProjectID.Blur()
-	}
-}
-
-func (m *CronjobSectionModel) focusCurrent() tea.Cmd {
-	switch m.focusIndex {
-	case 0:
-		return m.maxDaysSinceLastCommit.Focus()
-	case 1:
-		return m.workingPath.Focus()
-	case 2:
-		return m.groupProjectID.Focus()
-	}
-	return nil
+	return m, m.inputs.UpdateCurrent(msg)
 }
 
 func (m *CronjobSectionModel) View() string {
@@ -114,7 +110,22 @@ func (m *CronjobSectionModel) View() string {
 		m.groupProjectID.View(),
 	)
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, desc, "", fields)
+	filtersHeader := tui.StyleMuted.Render("Project filters")
+	filters := lipgloss.JoinVertical(lipgloss.Left,
+		m.ignoreProjects.View(),
+		"",
+		m.ignoreSubgroups.View(),
+		"",
+		m.topics.View(),
+		"",
+		m.visibility.View(),
+		"",
+		m.languages.View(),
+		"",
+		m.minRepoSizeKB.View(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, desc, "", fields, "", filtersHeader, "", filters)
 }
 
 func (m *CronjobSectionModel) Validate() []types.ValidationError {
@@ -122,12 +133,17 @@ func (m *CronjobSectionModel) Validate() []types.ValidationError {
 }
 
 func (m *CronjobSectionModel) IsDirty() bool {
-	return m.maxDaysSinceLastCommit.IsDirty() || m.workingPath.IsDirty() || m.groupProjectID.IsDirty()
+	return m.inputs.IsDirty()
 }
 
 func (m *CronjobSectionModel) GetValues() map[string]any {
 	values := map[string]any{
-		KeyWorkingPath: m.workingPath.Value(),
+		KeyWorkingPath:     m.workingPath.Value(),
+		KeyIgnoreProjects:  splitList(m.ignoreProjects.Value()),
+		KeyIgnoreSubgroups: splitList(m.ignoreSubgroups.Value()),
+		KeyTopics:          splitList(m.topics.Value()),
+		KeyVisibility:      splitList(m.visibility.Value()),
+		KeyLanguages:       splitList(m.languages.Value()),
 	}
 	if v := m.maxDaysSinceLastCommit.Value(); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
@@ -139,6 +155,11 @@ func (m *CronjobSectionModel) GetValues() map[string]any {
 			values[KeyGroupProjectID] = i
 		}
 	}
+	if v := m.minRepoSizeKB.Value(); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			values[KeyMinRepoSizeKB] = i
+		}
+	}
 	return values
 }
 
@@ -152,23 +173,42 @@ func (m *CronjobSectionModel) SetValues(values map[string]any) error {
 	if v, ok := values[KeyGroupProjectID].(int); ok {
 		m.groupProjectID.SetValue(strconv.Itoa(v))
 	}
+	if v, ok := values[KeyIgnoreProjects].([]string); ok {
+		m.ignoreProjects.SetValue(strings.Join(v, ", "))
+	}
+	if v, ok := values[KeyIgnoreSubgroups].([]string); ok {
+		m.ignoreSubgroups.SetValue(strings.Join(v, ", "))
+	}
+	if v, ok := values[KeyTopics].([]string); ok {
+		m.topics.SetValue(strings.Join(v, ", "))
+	}
+	if v, ok := values[KeyVisibility].([]string); ok {
+		m.visibility.SetValue(strings.Join(v, ", "))
+	}
+	if v, ok := values[KeyLanguages].([]string); ok {
+		m.languages.SetValue(strings.Join(v, ", "))
+	}
+	if v, ok := values[KeyMinRepoSizeKB].(int); ok && v > 0 {
+		m.minRepoSizeKB.SetValue(strconv.Itoa(v))
+	}
 	return nil
 }
 
 func (m *CronjobSectionModel) FocusFirst() tea.Cmd {
-	m.blurAll()
-	m.focusIndex = 0
-	return m.maxDaysSinceLastCommit.Focus()
+	return m.inputs.FocusFirst()
 }
 
 func (m *CronjobSectionModel) FocusLast() tea.Cmd {
-	m.blurAll()
-	m.focusIndex = 2
-	return m.groupProjectID.Focus()
+	return m.inputs.FocusLast()
 }
 
-func (m *CronjobSectionModel) blurAll() {
-	m.maxDaysSinceLastCommit.Blur()
-	m.workingPath.Blur()
-	m.groupProjectID.Blur()
+// splitList parses a comma-separated field into its trimmed, non-empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,60 @@
+package sections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCronjobSection_NewCronjobSection_InitializesInputs(t *testing.T) {
+	s := NewCronjobSection()
+
+	if s.inputs == nil {
+		t.Fatal("inputs slice should be initialized")
+	}
+	if s.inputs.Len() != 9 {
+		t.Errorf("Expected 9 focusable inputs, got %d", s.inputs.Len())
+	}
+}
+
+func TestCronjobSection_SetValues_RoundTripsFilters(t *testing.T) {
+	s := NewCronjobSection()
+	values := map[string]any{
+		KeyMaxDaysSinceLastCommit: 14,
+		KeyWorkingPath:            "./work",
+		KeyGroupProjectID:         42,
+		KeyIgnoreProjects:         []string{"group/legacy-*", "group/sandbox"},
+		KeyIgnoreSubgroups:        []string{"group/archive"},
+		KeyTopics:                 []string{"service"},
+		KeyVisibility:             []string{"internal", "private"},
+		KeyLanguages:              []string{"Go"},
+		KeyMinRepoSizeKB:          100,
+	}
+
+	if err := s.SetValues(values); err != nil {
+		t.Fatalf("SetValues returned error: %v", err)
+	}
+
+	got := s.GetValues()
+	for key, expected := range values {
+		if !reflect.DeepEqual(got[key], expected) {
+			t.Errorf("Expected %s=%v, got %v", key, expected, got[key])
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{" a , b ,, c ", []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitList(tt.input); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("splitList(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
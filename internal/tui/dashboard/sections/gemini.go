@@ -12,6 +12,7 @@ type GeminiSectionModel struct {
 	useVertexAI components.ToggleModel
 	projectID   components.TextFieldModel
 	location    components.TextFieldModel
+	credentials components.TextFieldModel
 
 	focusIndex int
 }
@@ -24,7 +25,10 @@ func NewGeminiSection() *GeminiSectionModel {
 			components.WithHelp("Required when using Vertex AI")),
 		location: components.NewTextField("Location",
 			components.WithPlaceholder("us-central1"),
-			components.WithHelp("GCP region for Vertex AI")),
+			components.WithHelp("GCP region for Vertex AI; defaults to us-central1")),
+		credentials: components.NewTextField("Credentials File",
+			components.WithPlaceholder("~/keys/service-account.json"),
+			components.WithHelp("Service account key or ADC file; defaults to GOOGLE_APPLICATION_CREDENTIALS or gcloud's ADC")),
 	}
 }
 
@@ -42,7 +46,7 @@ func (m *GeminiSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "tab":
 			m.blurCurrent()
-			m.focusIndex = (m.focusIndex + 1) % 3
+			m.focusIndex = (m.focusIndex + 1) % 4
 			cmds = append(cmds, m.focusCurrent())
 			return m, tea.Batch(cmds...)
 
@@ -50,7 +54,7 @@ func (m *GeminiSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.blurCurrent()
 			m.focusIndex--
 			if m.focusIndex < 0 {
-				m.focusIndex = 2
+				m.focusIndex = 3
 			}
 			cmds = append(cmds, m.focusCurrent())
 			return m, tea.Batch(cmds...)
@@ -64,6 +68,8 @@ func (m *GeminiSectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.projectID, _ = m.projectID.Update(msg)
 	case 2:
 		m.location, _ = m.location.Update(msg)
+	case 3:
+		m.credentials, _ = m.credentials.Update(msg)
 	}
 
 	return m, tea.Batch(cmds...)
@@ -77,6 +83,8 @@ func (m *GeminiSectionModel) blurCurrent() {
 		m.projectID.Blur()
 	case 2:
 		m.location.Blur()
+	case 3:
+		m.credentials.Blur()
 	}
 }
 
@@ -88,6 +96,8 @@ func (m *GeminiSectionModel) focusCurrent() tea.Cmd {
 		return m.projectID.Focus()
 	case 2:
 		return m.location.Focus()
+	case 3:
+		return m.credentials.Focus()
 	}
 	return nil
 }
@@ -98,7 +108,7 @@ func (m *GeminiSectionModel) View() string {
 
 	var vertexNote string
 	if m.useVertexAI.Value() {
-		vertexNote = tui.StyleInfo.Render("Vertex AI mode: Project ID and Location are required")
+		vertexNote = tui.StyleInfo.Render("Vertex AI mode: authenticates with Google Cloud credentials instead of an API key")
 	}
 
 	fields := lipgloss.JoinVertical(lipgloss.Left,
@@ -108,6 +118,8 @@ func (m *GeminiSectionModel) View() string {
 		m.projectID.View(),
 		"",
 		m.location.View(),
+		"",
+		m.credentials.View(),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left, header, desc, "", fields)
@@ -120,15 +132,8 @@ func (m *GeminiSectionModel) Validate() []types.ValidationError {
 		if m.projectID.Value() == "" {
 			errors = append(errors, types.ValidationError{
 				Field:    "Project ID",
-				Message:  "Project ID is required when using Vertex AI",
-				Severity: types.SeverityError,
-			})
-		}
-		if m.location.Value() == "" {
-			errors = append(errors, types.ValidationError{
-				Field:    "Location",
-				Message:  "Location is required when using Vertex AI",
-				Severity: types.SeverityError,
+				Message:  "Project ID not set: GOOGLE_CLOUD_PROJECT or the credentials' project will be used",
+				Severity: types.SeverityWarning,
 			})
 		}
 	}
@@ -137,7 +142,7 @@ func (m *GeminiSectionModel) Validate() []types.ValidationError {
 }
 
 func (m *GeminiSectionModel) IsDirty() bool {
-	return m.useVertexAI.IsDirty() || m.projectID.IsDirty() || m.location.IsDirty()
+	return m.useVertexAI.IsDirty() || m.projectID.IsDirty() || m.location.IsDirty() || m.credentials.IsDirty()
 }
 
 func (m *GeminiSectionModel) GetValues() map[string]any {
@@ -145,6 +150,7 @@ func (m *GeminiSectionModel) GetValues() map[string]any {
 		KeyUseVertexAI: m.useVertexAI.Value(),
 		KeyProjectID:   m.projectID.Value(),
 		KeyLocation:    m.location.Value(),
+		KeyCredentials: m.credentials.Value(),
 	}
 }
 
@@ -158,6 +164,9 @@ func (m *GeminiSectionModel) SetValues(values map[string]any) error {
 	if v, ok := values[KeyLocation].(string); ok {
 		m.location.SetValue(v)
 	}
+	if v, ok := values[KeyCredentials].(string); ok {
+		m.credentials.SetValue(v)
+	}
 	return nil
 }
 
@@ -169,12 +178,13 @@ func (m *GeminiSectionModel) FocusFirst() tea.Cmd {
 
 func (m *GeminiSectionModel) FocusLast() tea.Cmd {
 	m.blurAll()
-	m.focusIndex = 2
-	return m.location.Focus()
+	m.focusIndex = 3
+	return m.credentials.Focus()
 }
 
 func (m *GeminiSectionModel) blurAll() {
 	m.useVertexAI.Blur()
 	m.projectID.Blur()
 	m.location.Blur()
+	m.credentials.Blur()
 }
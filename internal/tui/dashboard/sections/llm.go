@@ -35,6 +35,11 @@ func isLocalProvider(provider string) bool {
 	return provider == "ollama" || provider == "lmstudio"
 }
 
+// requiresAPIKey returns false for local providers and for Bedrock, which can sign with AWS credentials
+func requiresAPIKey(provider string) bool {
+	return !isLocalProvider(provider) && provider != "bedrock"
+}
+
 // getDefaultBaseURL returns the default BaseURL for a provider
 func getDefaultBaseURL(provider string) string {
 	switch provider {
@@ -56,6 +61,10 @@ func getModelPlaceholder(provider string) string {
 		return "e.g., claude-3-5-sonnet-20241022, claude-3-opus"
 	case "gemini":
 		return "e.g., gemini-1.5-pro, gemini-1.5-flash"
+	case "azure":
+		return "e.g., gpt-4o (the deployment defaults to this name)"
+	case "bedrock":
+		return "e.g., anthropic.claude-3-5-sonnet-20240620-v1:0"
 	case "ollama":
 		return "e.g., llama3, codellama, mistral, deepseek-coder"
 	case "lmstudio":
@@ -132,6 +141,8 @@ func NewLLMSectionWithTarget(target LLMTarget) *LLMSectionModel {
 		{Value: "openai", Label: "OpenAI (GPT-4o, GPT-4)"},
 		{Value: "anthropic", Label: "Anthropic (Claude)"},
 		{Value: "gemini", Label: "Google (Gemini)"},
+		{Value: "azure", Label: "Azure OpenAI"},
+		{Value: "bedrock", Label: "AWS Bedrock"},
 		{Value: "ollama", Label: "Ollama (Local)"},
 		{Value: "lmstudio", Label: "LM Studio (Local)"},
 	}
@@ -242,7 +253,7 @@ func (m *LLMSectionModel) onProviderChange(newProvider, oldProvider string) {
 		}
 	}
 
-	m.apiKey.SetRequired(!isLocalProvider(newProvider))
+	m.apiKey.SetRequired(requiresAPIKey(newProvider))
 	m.model.SetPlaceholder(getModelPlaceholder(newProvider))
 }
 
@@ -283,7 +294,14 @@ func (m *LLMSectionModel) View() string {
 func (m *LLMSectionModel) Validate() []types.ValidationError {
 	var errors []types.ValidationError
 
-	if !isLocalProvider(m.provider.Value()) && m.apiKey.Value() == "" {
+	if m.provider.Value() == "gemini" && m.apiKey.Value() == "" {
+		// Vertex AI, enabled in the Gemini section, authenticates without an API key
+		errors = append(errors, types.ValidationError{
+			Field:    "API Key",
+			Message:  "API Key is required unless Vertex AI is enabled in the Gemini section",
+			Severity: types.SeverityWarning,
+		})
+	} else if requiresAPIKey(m.provider.Value()) && m.apiKey.Value() == "" {
 		errors = append(errors, types.ValidationError{
 			Field:    "API Key",
 			Message:  "API Key is required",
@@ -307,6 +325,14 @@ func (m *LLMSectionModel) Validate() []types.ValidationError {
 		})
 	}
 
+	if m.provider.Value() == "azure" && m.baseURL.Value() == "" {
+		errors = append(errors, types.ValidationError{
+			Field:    "Base URL",
+			Message:  "Base URL is required for Azure OpenAI (https://<resource>.openai.azure.com)",
+			Severity: types.SeverityError,
+		})
+	}
+
 	return errors
 }
 
@@ -390,7 +416,7 @@ func (m *LLMSectionModel) testConnection() tea.Msg {
 	apiKey := m.apiKey.Value()
 	baseURL := m.baseURL.Value()
 
-	if !isLocalProvider(provider) && apiKey == "" {
+	if requiresAPIKey(provider) && apiKey == "" {
 		return TestConnectionResultMsg{
 			Success: false,
 			Message: "API Key is required to test connection",
@@ -411,6 +437,13 @@ func (m *LLMSectionModel) testConnection() tea.Msg {
 		}
 	}
 
+	if provider == "azure" && baseURL == "" {
+		return TestConnectionResultMsg{
+			Success: false,
+			Message: "Base URL is required for Azure OpenAI",
+		}
+	}
+
 	if provider == "" {
 		provider = "openai"
 	}
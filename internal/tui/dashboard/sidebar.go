@@ -21,6 +21,8 @@ var DefaultNavItems = []NavItem{
 	{ID: "analysis", Title: "Analysis", Icon: "🔍", Description: "Analyzer options"},
 	{ID: "retry", Title: "Retry Policy", Icon: "🔄", Description: "HTTP retry settings"},
 	{ID: "gemini", Title: "Gemini/Vertex", Icon: "☁️", Description: "Google Cloud options"},
+	{ID: "azure", Title: "Azure OpenAI", Icon: "🔷", Description: "Azure deployments"},
+	{ID: "bedrock", Title: "AWS Bedrock", Icon: "🟧", Description: "AWS region and keys"},
 	{ID: "gitlab", Title: "GitLab", Icon: "🦊", Description: "GitLab integration"},
 	{ID: "cronjob", Title: "Cronjob", Icon: "⏰", Description: "Scheduled tasks"},
 	{ID: "logging", Title: "Logging", Icon: "📝", Description: "Log configuration"},
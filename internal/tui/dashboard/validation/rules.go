@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 func ValidateNonEmpty(fieldName string) func(string) error {
@@ -107,3 +108,16 @@ func ValidateEmail() func(string) error {
 		return nil
 	}
 }
+
+// ValidateEnumList validates a comma-separated list whose items must all be allowed values
+func ValidateEnumList(allowed ...string) func(string) error {
+	validate := ValidateEnum(allowed...)
+	return func(s string) error {
+		for _, item := range strings.Split(s, ",") {
+			if err := validate(strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/user/gendocs/internal/llm"
 )
 
 // Progress styles for the TUI
@@ -83,6 +85,7 @@ type Progress struct {
 	started       bool
 	startTime     time.Time
 	lastLineCount int
+	usage         *llm.UsageSummary
 }
 
 func NewProgress(title string) *Progress {
@@ -108,6 +111,13 @@ func (p *Progress) SetWriter(w io.Writer) {
 	p.writer = w
 }
 
+// SetUsage sets the token usage printed by PrintSummary
+func (p *Progress) SetUsage(usage *llm.UsageSummary) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.usage = usage
+}
+
 func (p *Progress) getWriter() io.Writer {
 	return p.writer
 }
@@ -336,6 +346,21 @@ func (p *Progress) PrintSummary() {
 			progressWarningStyle.Render(summary))
 	}
 
+	if p.usage != nil && p.usage.Calls > 0 {
+		_, _ = fmt.Fprintf(p.getWriter(), "  %s\n", progressInfoStyle.Render(p.usage.String()))
+		// With fallback providers, show which backends served the calls
+		if len(p.usage.ByModel) > 1 {
+			models := make([]string, 0, len(p.usage.ByModel))
+			for model := range p.usage.ByModel {
+				models = append(models, model)
+			}
+			sort.Strings(models)
+			for _, model := range models {
+				_, _ = fmt.Fprintf(p.getWriter(), "    %s\n", progressInfoStyle.Render(fmt.Sprintf("%s: %d calls", model, p.usage.ByModel[model].Calls)))
+			}
+		}
+	}
+
 	if failed > 0 {
 		_, _ = fmt.Fprintln(p.getWriter())
 		_, _ = fmt.Fprintln(p.getWriter(), progressErrorStyle.Render("Failed tasks:"))
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/user/gendocs/internal/agents"
+	"github.com/user/gendocs/internal/llm"
 )
 
 // captureOutput captures stdout during test execution
@@ -508,6 +509,29 @@ func TestProgress_PrintSummary_NoTasks(t *testing.T) {
 	}
 }
 
+// TestProgress_PrintSummary_Usage tests that token usage is included in the summary
+func TestProgress_PrintSummary_Usage(t *testing.T) {
+	progress := NewProgress("Test")
+	progress.SetUsage(&llm.UsageSummary{UsageTotals: llm.UsageTotals{
+		Calls:        4,
+		CacheHits:    1,
+		InputTokens:  1200,
+		OutputTokens: 300,
+		Cost:         0.0075,
+	}})
+
+	output := captureOutputWithWriter(func(buf *bytes.Buffer) {
+		progress.SetWriter(buf)
+		progress.PrintSummary()
+	})
+
+	for _, want := range []string{"Tokens: 1200 in / 300 out", "Cache hits: 1/4 calls", "Est. cost: $0.0075"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in summary, got: %s", want, output)
+		}
+	}
+}
+
 // TestProgress_PrintSummary_AllCompleted tests summary with all tasks completed
 func TestProgress_PrintSummary_AllCompleted(t *testing.T) {
 	progress := NewProgress("Test")
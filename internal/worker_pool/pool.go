@@ -39,6 +39,16 @@ func NewWorkerPool(maxWorkers int) *WorkerPool {
 		maxWorkers = maxCPU
 	}
 
+	return NewFixedWorkerPool(maxWorkers)
+}
+
+// NewFixedWorkerPool creates a worker pool running exactly maxWorkers tasks at
+// a time (at least one), without the CPU cap. It suits tasks that mostly wait
+// on the network, such as cloning and analyzing whole repositories.
+func NewFixedWorkerPool(maxWorkers int) *WorkerPool {
+	if maxWorkers <= 0 {
+		maxWorkers = 1
+	}
 	return &WorkerPool{
 		maxWorkers: maxWorkers,
 		semaphore:  make(chan struct{}, maxWorkers),
@@ -79,6 +89,65 @@ func (wp *WorkerPool) Run(ctx context.Context, tasks []Task) []Result {
 	return results
 }
 
+// RunDAG executes tasks concurrently like Run, but starts a task only once the
+// tasks it depends on have finished. deps[i] lists the indexes of the tasks that
+// task i depends on and must not contain cycles. Tasks waiting on dependencies
+// do not hold a worker, so independent branches still run in parallel.
+func (wp *WorkerPool) RunDAG(ctx context.Context, tasks []Task, deps [][]int) []Result {
+	if len(tasks) == 0 {
+		return []Result{}
+	}
+
+	numTasks := len(tasks)
+	results := make([]Result, numTasks)
+	done := make([]chan struct{}, numTasks)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(index int, t Task) {
+			defer wg.Done()
+			defer close(done[index])
+
+			// Wait for dependencies without holding a worker
+			if index < len(deps) {
+				for _, dep := range deps[index] {
+					select {
+					case <-done[dep]:
+					case <-ctx.Done():
+						results[index] = Result{Error: ctx.Err()}
+						return
+					}
+				}
+			}
+
+			select {
+			case wp.semaphore <- struct{}{}:
+				defer func() { <-wp.semaphore }()
+			case <-ctx.Done():
+				results[index] = Result{Error: ctx.Err()}
+				return
+			}
+
+			// A select picks randomly among ready cases, so a dependency finishing
+			// as the context is cancelled could otherwise still start the task
+			if err := ctx.Err(); err != nil {
+				results[index] = Result{Error: err}
+				return
+			}
+
+			value, err := t(ctx)
+			results[index] = Result{Value: value, Error: err}
+		}(i, task)
+	}
+
+	wg.Wait()
+	return results
+}
+
 // GetMaxWorkers returns the maximum number of workers
 func (wp *WorkerPool) GetMaxWorkers() int {
 	return wp.maxWorkers
@@ -0,0 +1,176 @@
+package worker_pool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewWorkerPool_CapsAtNumCPU(t *testing.T) {
+	pool := NewWorkerPool(runtime.NumCPU() + 4)
+	if pool.maxWorkers != runtime.NumCPU() {
+		t.Errorf("expected %d workers, got %d", runtime.NumCPU(), pool.maxWorkers)
+	}
+}
+
+func TestNewFixedWorkerPool_ExceedsNumCPU(t *testing.T) {
+	workers := runtime.NumCPU() + 2
+	pool := NewFixedWorkerPool(workers)
+
+	// Every task waits until all of them are running, which only succeeds if
+	// the pool runs more tasks at once than there are CPUs
+	var started sync.WaitGroup
+	started.Add(workers)
+	allStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(allStarted)
+	}()
+
+	tasks := make([]Task, workers)
+	for i := range tasks {
+		tasks[i] = func(ctx context.Context) (interface{}, error) {
+			started.Done()
+			select {
+			case <-allStarted:
+				return true, nil
+			case <-time.After(5 * time.Second):
+				return false, nil
+			}
+		}
+	}
+
+	for i, result := range pool.Run(context.Background(), tasks) {
+		if ok, _ := result.Value.(bool); !ok {
+			t.Fatalf("task %d did not run concurrently with the others", i)
+		}
+	}
+}
+
+func TestNewFixedWorkerPool_AtLeastOneWorker(t *testing.T) {
+	if pool := NewFixedWorkerPool(0); pool.maxWorkers != 1 {
+		t.Errorf("expected 1 worker, got %d", pool.maxWorkers)
+	}
+}
+
+func TestRunDAG_RespectsDependencies(t *testing.T) {
+	pool := NewFixedWorkerPool(4)
+
+	// Diamond: 1 and 2 depend on 0, 3 depends on 1 and 2
+	deps := [][]int{nil, {0}, {0}, {1, 2}}
+
+	var mu sync.Mutex
+	finished := make(map[int]bool)
+	tasks := make([]Task, len(deps))
+	for i := range tasks {
+		index := i
+		tasks[i] = func(ctx context.Context) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, dep := range deps[index] {
+				if !finished[dep] {
+					t.Errorf("task %d started before its dependency %d finished", index, dep)
+				}
+			}
+			finished[index] = true
+			return index, nil
+		}
+	}
+
+	for i, result := range pool.RunDAG(context.Background(), tasks, deps) {
+		if result.Error != nil || result.Value != i {
+			t.Errorf("task %d: unexpected result %+v", i, result)
+		}
+	}
+}
+
+func TestRunDAG_IndependentBranchesRunInParallel(t *testing.T) {
+	pool := NewFixedWorkerPool(2)
+
+	// Task 1 waits on task 0. Tasks 0 and 2 only succeed if they run at the
+	// same time, which requires that the waiting task 1 does not hold a worker.
+	var started sync.WaitGroup
+	started.Add(2)
+	allStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(allStarted)
+	}()
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	track := func(delta int) {
+		mu.Lock()
+		defer mu.Unlock()
+		running += delta
+		if running > maxRunning {
+			maxRunning = running
+		}
+	}
+
+	rendezvous := func(ctx context.Context) (interface{}, error) {
+		track(1)
+		defer track(-1)
+		started.Done()
+		select {
+		case <-allStarted:
+			return true, nil
+		case <-time.After(5 * time.Second):
+			return false, nil
+		}
+	}
+	dependent := func(ctx context.Context) (interface{}, error) {
+		track(1)
+		defer track(-1)
+		return true, nil
+	}
+
+	tasks := []Task{rendezvous, dependent, rendezvous}
+	deps := [][]int{nil, {0}, nil}
+	for i, result := range pool.RunDAG(context.Background(), tasks, deps) {
+		if ok, _ := result.Value.(bool); !ok {
+			t.Errorf("task %d did not run alongside the independent branch", i)
+		}
+	}
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 tasks running at once, got %d", maxRunning)
+	}
+}
+
+func TestRunDAG_CancelledWhileWaitingOnDependency(t *testing.T) {
+	pool := NewFixedWorkerPool(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	ran := false
+	tasks := []Task{
+		func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		func(ctx context.Context) (interface{}, error) {
+			ran = true
+			return nil, nil
+		},
+	}
+	deps := [][]int{nil, {0}}
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	results := pool.RunDAG(ctx, tasks, deps)
+	if ran {
+		t.Error("expected the dependent task not to run after cancellation")
+	}
+	for i, result := range results {
+		if result.Error != context.Canceled {
+			t.Errorf("task %d: expected context.Canceled, got %v", i, result.Error)
+		}
+	}
+}